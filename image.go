@@ -539,6 +539,9 @@ type DrawTrianglesShaderOptions struct {
 	// For example, if the uniform variable type is [4]vec4, the length will be 16.
 	//
 	// If a uniform variable's name doesn't exist in Uniforms, this is treated as if zero values are specified.
+	//
+	// If the shader refers to Time without declaring it, Time is a float uniform variable declared implicitly,
+	// and its value is the current frame's time in seconds unless Time is specified in Uniforms.
 	Uniforms map[string]any
 
 	// Images is a set of the source images.
@@ -713,6 +716,9 @@ type DrawRectShaderOptions struct {
 	// For example, if the uniform variable type is [4]vec4, the length will be 16.
 	//
 	// If a uniform variable's name doesn't exist in Uniforms, this is treated as if zero values are specified.
+	//
+	// If the shader refers to Time without declaring it, Time is a float uniform variable declared implicitly,
+	// and its value is the current frame's time in seconds unless Time is specified in Uniforms.
 	Uniforms map[string]any
 
 	// Images is a set of the source images.
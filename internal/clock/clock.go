@@ -162,6 +162,14 @@ func UpdateFrame() int {
 	return c
 }
 
+// FrameTime returns the time of the current frame since the game started.
+// The value is updated at UpdateFrame, so the value is the same during one frame.
+func FrameTime() time.Duration {
+	m.Lock()
+	defer m.Unlock()
+	return time.Duration(lastNow)
+}
+
 func SetTPS(newTPS int) {
 	m.Lock()
 	defer m.Unlock()
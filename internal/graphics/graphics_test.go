@@ -15,9 +15,11 @@
 package graphics_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/hajimehoshi/ebiten/v2/internal/graphics"
+	"github.com/hajimehoshi/ebiten/v2/internal/shaderir/glsl"
 )
 
 func TestInternalImageSize(t *testing.T) {
@@ -79,3 +81,142 @@ func BenchmarkAdjustPixel(b *testing.B) {
 		graphics.AdjustDestinationPixelForTesting(float32(i) / 17)
 	}
 }
+
+func TestCompileShaderWithFragmentEntry(t *testing.T) {
+	src := []byte(`//kage:unit pixels
+
+package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return vec4(1)
+}
+
+func Fragment2(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return vec4(0)
+}
+`)
+
+	cases := []struct {
+		entry   string
+		want    string
+		notWant string
+	}{
+		{
+			entry:   "",
+			want:    "return vec4(1.0);",
+			notWant: "return vec4(0.0);",
+		},
+		{
+			entry:   "Fragment",
+			want:    "return vec4(1.0);",
+			notWant: "return vec4(0.0);",
+		},
+		{
+			entry:   "Fragment2",
+			want:    "return vec4(0.0);",
+			notWant: "return vec4(1.0);",
+		},
+	}
+	for _, c := range cases {
+		ir, err := graphics.CompileShaderWithOptions(src, &graphics.CompileShaderOptions{
+			FragmentEntry: c.entry,
+		})
+		if err != nil {
+			t.Errorf("entry: %q, err: %v", c.entry, err)
+			continue
+		}
+		// Only the entry point is emitted, as the other function is not reachable.
+		_, fs := glsl.Compile(ir, glsl.GLSLVersionES300)
+		if !strings.Contains(fs, c.want) {
+			t.Errorf("entry: %q: the output must include %q but does not:\n%s", c.entry, c.want, fs)
+		}
+		if strings.Contains(fs, c.notWant) {
+			t.Errorf("entry: %q: the output must not include %q but does:\n%s", c.entry, c.notWant, fs)
+		}
+	}
+
+	if _, err := graphics.CompileShaderWithOptions(src, &graphics.CompileShaderOptions{
+		FragmentEntry: "Fragment3",
+	}); err == nil {
+		t.Errorf("compiling with a missing entry must return an error but does not")
+	}
+}
+
+func TestCompileShaderWithVertexEntry(t *testing.T) {
+	src := []byte(`//kage:unit pixels
+
+package main
+
+var ScreenSize vec2
+
+func Vertex(dstPos vec2, srcPos vec2, color vec4) (vec4, vec2, vec4) {
+	pos := dstPos/ScreenSize*2 - 1
+	return vec4(pos.x, -pos.y, 0, 1), srcPos, color
+}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return color
+}
+`)
+
+	ir, err := graphics.CompileShaderWithOptions(src, &graphics.CompileShaderOptions{
+		VertexEntry: "Vertex",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vs, _ := glsl.Compile(ir, glsl.GLSLVersionDefault)
+	// A0 is the first attribute, the destination position.
+	if !strings.Contains(vs, "in vec2 A0;") || !strings.Contains(vs, "(A0)") {
+		t.Errorf("the vertex shader must read the position attribute A0 but does not:\n%s", vs)
+	}
+
+	for _, vertex := range []string{
+		`func Vertex2(dstPos vec2) vec4 {
+	return vec4(dstPos, 0, 1)
+}`,
+		`func Vertex2(dstPos vec4, srcPos vec2, color vec4) (vec4, vec2, vec4) {
+	return dstPos, srcPos, color
+}`,
+		`func Vertex2(dstPos vec2, srcPos vec2) (vec4, vec2, vec4) {
+	return vec4(dstPos, 0, 1), srcPos, vec4(1)
+}`,
+	} {
+		if _, err := graphics.CompileShaderWithOptions(append(src, vertex...), &graphics.CompileShaderOptions{
+			VertexEntry: "Vertex2",
+		}); err == nil {
+			t.Errorf("compiling with an invalid vertex entry must return an error but does not:\n%s", vertex)
+		}
+	}
+}
+
+func TestCompileShaderWithVertexAttribute(t *testing.T) {
+	src := []byte(`//kage:unit pixels
+//kage:attribute Normal vec3
+
+package main
+
+func Vertex(dstPos vec2, srcPos vec2, color vec4) (vec4, vec2, vec4) {
+	return vec4(dstPos, 0, 1), srcPos, vec4(Normal, color.a)
+}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return color
+}
+`)
+
+	ir, err := graphics.CompileShaderWithOptions(src, &graphics.CompileShaderOptions{
+		VertexEntry: "Vertex",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(ir.Attributes), 4; got != want {
+		t.Errorf("len(ir.Attributes): got: %d, want: %d", got, want)
+	}
+	vs, _ := glsl.Compile(ir, glsl.GLSLVersionDefault)
+	// A3 is the declared attribute following the built-in ones.
+	if !strings.Contains(vs, "in vec3 A3;") {
+		t.Errorf("the vertex shader must declare the attribute A3 but does not:\n%s", vs)
+	}
+}
@@ -161,7 +161,36 @@ func __vertex(dstPos vec2, srcPos vec2, color vec4) (vec4, vec2, vec4) {
 	return shaderSuffix, nil
 }
 
+// CompileShaderOptions represents options for CompileShaderWithOptions.
+type CompileShaderOptions struct {
+	// FragmentEntry is the name of the fragment shader entry point function.
+	// If FragmentEntry is empty, "Fragment" is used.
+	FragmentEntry string
+
+	// VertexEntry is the name of the vertex shader entry point function.
+	// The function takes the vertex attributes (the destination position, the source position, and the color)
+	// as (vec2, vec2, vec4), and returns the position as vec4 and the values passed to the fragment entry point.
+	// If VertexEntry is empty, the default vertex shader is used.
+	VertexEntry string
+}
+
+// vertexAttributes is the types of the vertex attributes Ebitengine provides.
+// See QuadVertices for the layout.
+var vertexAttributes = []shaderir.Type{
+	{Main: shaderir.Vec2}, // the destination position in pixels
+	{Main: shaderir.Vec2}, // the source position in texels or pixels
+	{Main: shaderir.Vec4}, // the color scale
+}
+
 func CompileShader(src []byte) (*shaderir.Program, error) {
+	return CompileShaderWithOptions(src, nil)
+}
+
+func CompileShaderWithOptions(src []byte, options *CompileShaderOptions) (*shaderir.Program, error) {
+	if options == nil {
+		options = &CompileShaderOptions{}
+	}
+
 	unit, err := shader.ParseCompilerDirectives(src)
 	if err != nil {
 		return nil, err
@@ -175,10 +204,14 @@ func CompileShader(src []byte) (*shaderir.Program, error) {
 	buf.Write(src)
 	buf.WriteString(suffix)
 
-	const (
-		vert = "__vertex"
-		frag = "Fragment"
-	)
+	vert := "__vertex"
+	if options.VertexEntry != "" {
+		vert = options.VertexEntry
+	}
+	frag := "Fragment"
+	if options.FragmentEntry != "" {
+		frag = options.FragmentEntry
+	}
 	ir, err := shader.Compile(buf.Bytes(), vert, frag, ShaderImageCount)
 	if err != nil {
 		return nil, err
@@ -191,5 +224,15 @@ func CompileShader(src []byte) (*shaderir.Program, error) {
 		return nil, fmt.Errorf("graphics: fragment shader entry point '%s' is missing", frag)
 	}
 
+	// The built-in attributes come first. The attributes declared by //kage:attribute follow them.
+	if len(ir.Attributes) < len(vertexAttributes) {
+		return nil, fmt.Errorf("graphics: vertex shader entry point '%s' must take at least %d attributes but %d", vert, len(vertexAttributes), len(ir.Attributes))
+	}
+	for i, t := range ir.Attributes[:len(vertexAttributes)] {
+		if !t.Equal(&vertexAttributes[i]) {
+			return nil, fmt.Errorf("graphics: vertex shader entry point '%s' must take %s as the attribute at %d but %s", vert, vertexAttributes[i].String(), i, t.String())
+		}
+	}
+
 	return ir, nil
 }
@@ -486,6 +486,10 @@ func (g *graphics11) MaxImageSize() int {
 }
 
 func (g *graphics11) NewShader(program *shaderir.Program) (graphicsdriver.Shader, error) {
+	if err := program.CheckFeatures(hlsl.Features()); err != nil {
+		return nil, err
+	}
+
 	vs, ps, offsets := hlsl.Compile(program)
 	vsh, psh, err := compileShader(vs, ps)
 	if err != nil {
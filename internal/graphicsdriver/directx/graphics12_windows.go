@@ -1068,6 +1068,10 @@ func (g *graphics12) MaxImageSize() int {
 }
 
 func (g *graphics12) NewShader(program *shaderir.Program) (graphicsdriver.Shader, error) {
+	if err := program.CheckFeatures(hlsl.Features()); err != nil {
+		return nil, err
+	}
+
 	vs, ps, offsets := hlsl.Compile(program)
 	vsh, psh, err := compileShader(vs, ps)
 	if err != nil {
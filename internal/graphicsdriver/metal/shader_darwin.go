@@ -68,6 +68,10 @@ func (s *Shader) init(device mtl.Device) error {
 		f = "Fragment"
 	)
 
+	if err := s.ir.CheckFeatures(msl.Features()); err != nil {
+		return err
+	}
+
 	src := msl.Compile(s.ir, v, f)
 	lib, err := device.MakeLibrary(src, mtl.CompileOptions{})
 	if err != nil {
@@ -53,6 +53,10 @@ func (s *Shader) Dispose() {
 }
 
 func (s *Shader) compile() error {
+	if err := s.ir.CheckFeatures(glsl.Features(s.graphics.context.glslVersion())); err != nil {
+		return err
+	}
+
 	vssrc, fssrc := glsl.Compile(s.ir, s.graphics.context.glslVersion())
 
 	vs, err := s.graphics.context.newShader(gl.VERTEX_SHADER, vssrc)
@@ -19,6 +19,7 @@ import (
 	"go/ast"
 	gconstant "go/constant"
 	"go/token"
+	"math"
 	"regexp"
 	"strconv"
 	"strings"
@@ -34,6 +35,71 @@ func canTruncateToFloat(v gconstant.Value) bool {
 	return gconstant.ToFloat(v).Kind() != gconstant.Unknown
 }
 
+func canRepresentAsInt32(v gconstant.Value) bool {
+	i, ok := gconstant.Int64Val(gconstant.ToInt(v))
+	if !ok {
+		return false
+	}
+	return math.MinInt32 <= i && i <= math.MaxInt32
+}
+
+func canRepresentAsFloat32(v gconstant.Value) bool {
+	f, _ := gconstant.Float64Val(gconstant.ToFloat(v))
+	return !math.IsInf(f, 0) && math.Abs(f) <= math.MaxFloat32
+}
+
+// isIntegerDivision reports whether expr is a division of integers.
+// Like Go, a division of integers truncates the result toward zero, e.g. 1/2 is 0 even in a float context.
+// To perform a float division, convert either operand with float().
+func isIntegerDivision(expr ast.Expr, e shaderir.Expr, t shaderir.Type) bool {
+	for {
+		p, ok := expr.(*ast.ParenExpr)
+		if !ok {
+			break
+		}
+		expr = p.X
+	}
+	b, ok := expr.(*ast.BinaryExpr)
+	if !ok || b.Op != token.QUO {
+		return false
+	}
+	if t.Main == shaderir.Int {
+		return true
+	}
+	return t.Main == shaderir.None && e.Const != nil && e.Const.Kind() == gconstant.Int
+}
+
+// useFragmentOnlyFeature records the use of a feature available only in the fragment entry point and the functions called from it,
+// like discard and dfdx. useFragmentOnlyFeature reports an error and returns false when fname is the vertex entry point.
+func (cs *compileState) useFragmentOnlyFeature(fname string, pos token.Pos, feature string) bool {
+	if fname == cs.vertexEntry {
+		cs.addError(pos, fmt.Sprintf("%s is available only in %s and the functions called from it", feature, cs.fragmentEntry))
+		return false
+	}
+	if fname == cs.fragmentEntry {
+		return true
+	}
+	// Whether the function is called from the vertex entry point is checked after all the functions are parsed.
+	if cs.fragmentOnlyFeatures == nil {
+		cs.fragmentOnlyFeatures = map[string]fragmentOnlyFeature{}
+	}
+	if _, ok := cs.fragmentOnlyFeatures[fname]; !ok {
+		cs.fragmentOnlyFeatures[fname] = fragmentOnlyFeature{
+			name: feature,
+			pos:  pos,
+		}
+	}
+	return true
+}
+
+// warnIntegerDivisionInFloatContext reports a warning when an integer division is used as a float value.
+func (cs *compileState) warnIntegerDivisionInFloatContext(expr ast.Expr, e shaderir.Expr, t shaderir.Type) {
+	if !isIntegerDivision(expr, e, t) {
+		return
+	}
+	cs.addWarning(expr.Pos(), "the result of an integer division is used as float; convert an operand with float() for a float division")
+}
+
 var textureVariableRe = regexp.MustCompile(`\A__t(\d+)\z`)
 
 func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, markLocalVariableUsed bool) ([]shaderir.Expr, []shaderir.Type, []shaderir.Stmt, bool) {
@@ -68,7 +134,7 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 		if !ok {
 			return nil, nil, nil, false
 		}
-		if len(lhs) != 1 {
+		if len(lhs) != 1 || len(ts) != 1 {
 			cs.addError(e.Pos(), fmt.Sprintf("multiple-value context is not available at a binary operator: %s", e.X))
 			return nil, nil, nil, false
 		}
@@ -79,7 +145,7 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 		if !ok {
 			return nil, nil, nil, false
 		}
-		if len(rhs) != 1 {
+		if len(rhs) != 1 || len(ts) != 1 {
 			cs.addError(e.Pos(), fmt.Sprintf("multiple-value context is not available at a binary operator: %s", e.Y))
 			return nil, nil, nil, false
 		}
@@ -94,17 +160,49 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 			op = token.QUO_ASSIGN
 		}
 
-		op2, ok := shaderir.OpFromToken(e.Op, lhst, rhst)
+		// x &^ y is lowered to x & ^y below.
+		optok := e.Op
+		if optok == token.AND_NOT {
+			optok = token.AND
+		}
+		op2, ok := shaderir.OpFromToken(optok, lhst, rhst)
 		if !ok {
 			cs.addError(e.Pos(), fmt.Sprintf("unexpected operator: %s", e.Op))
 			return nil, nil, nil, false
 		}
 
+		// No operator is defined on structs, as some backends like HLSL don't support comparing structs.
+		for _, t := range []shaderir.Type{lhst, rhst} {
+			if t.Main == shaderir.Struct {
+				cs.addError(e.Pos(), fmt.Sprintf("invalid operation: operator %s not defined on %s", e.Op, t.String()))
+				return nil, nil, nil, false
+			}
+		}
+
+		// The operands of && and || must be booleans. Check this before resolving untyped constants for a precise error message.
+		if op2 == shaderir.AndAnd || op2 == shaderir.OrOr {
+			if !isBoolOperand(lhst, lhs[0].Const) {
+				cs.addError(e.Pos(), fmt.Sprintf("invalid operation: operator %s not defined on %s", e.Op, operandTypeString(lhst, lhs[0].Const)))
+				return nil, nil, nil, false
+			}
+			if !isBoolOperand(rhst, rhs[0].Const) {
+				cs.addError(e.Pos(), fmt.Sprintf("invalid operation: operator %s not defined on %s", e.Op, operandTypeString(rhst, rhs[0].Const)))
+				return nil, nil, nil, false
+			}
+		}
+
 		// Resolve untyped constants.
 		l, r, ok := shaderir.ResolveUntypedConstsForBinaryOp(lhs[0].Const, rhs[0].Const, lhst, rhst)
 		if !ok {
-			// TODO: Show a better type name for untyped constants.
-			cs.addError(e.Pos(), fmt.Sprintf("types don't match: %s %s %s", lhst.String(), op, rhst.String()))
+			if isIntOperand(lhst) && rhst.Main == shaderir.None && rhs[0].Const != nil && rhs[0].Const.Kind() == gconstant.Float {
+				cs.addError(e.Pos(), fmt.Sprintf("constant %s truncated to integer", rhs[0].Const.String()))
+				return nil, nil, nil, false
+			}
+			if isIntOperand(rhst) && lhst.Main == shaderir.None && lhs[0].Const != nil && lhs[0].Const.Kind() == gconstant.Float {
+				cs.addError(e.Pos(), fmt.Sprintf("constant %s truncated to integer", lhs[0].Const.String()))
+				return nil, nil, nil, false
+			}
+			cs.addError(e.Pos(), fmt.Sprintf("types don't match: %s %s %s", operandTypeString(lhst, lhs[0].Const), op, operandTypeString(rhst, rhs[0].Const)))
 			return nil, nil, nil, false
 		}
 		lhs[0].Const, rhs[0].Const = l, r
@@ -136,8 +234,41 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 
 		t, ok := shaderir.TypeFromBinaryOp(op2, lhst, rhst, lhs[0].Const, rhs[0].Const)
 		if !ok {
-			// TODO: Show a better type name for untyped constants.
-			cs.addError(e.Pos(), fmt.Sprintf("types don't match: %s %s %s", lhst.String(), op, rhst.String()))
+			if isBitwiseOp(op2) {
+				if t := lhst; t.Main != shaderir.None && t.Main != shaderir.Int && !t.IsIntVector() {
+					cs.addError(e.Pos(), fmt.Sprintf("invalid operation: operator %s not defined on %s", e.Op, t.String()))
+					return nil, nil, nil, false
+				}
+				if t := rhst; t.Main != shaderir.None && t.Main != shaderir.Int && !t.IsIntVector() {
+					cs.addError(e.Pos(), fmt.Sprintf("invalid operation: operator %s not defined on %s", e.Op, t.String()))
+					return nil, nil, nil, false
+				}
+			}
+			if f, ok := vectorComparisonFunc(op2); ok && (isVector(lhst) || isVector(rhst)) {
+				cs.addError(e.Pos(), fmt.Sprintf("invalid operation: operator %s not defined on vectors; use %s(x, y) to compare vectors component-wise", e.Op, f))
+				return nil, nil, nil, false
+			}
+			// % on typed floats is lowered to mod, but % on untyped float constants is not defined as in Go.
+			if op2 == shaderir.ModOp && lhst.Main == shaderir.None && rhst.Main == shaderir.None && lhs[0].Const.Kind() == gconstant.Float {
+				cs.addError(e.Pos(), fmt.Sprintf("invalid operation: operator %% not defined on %s; use mod(x, y) for floats", operandTypeString(lhst, lhs[0].Const)))
+				return nil, nil, nil, false
+			}
+			// Like Go, an int and a float are never converted implicitly.
+			if (isIntOperand(lhst) && isFloatOperand(rhst)) || (isFloatOperand(lhst) && isIntOperand(rhst)) {
+				cs.addError(e.Pos(), fmt.Sprintf("invalid operation: mismatched types %s and %s", lhst.String(), rhst.String()))
+				return nil, nil, nil, false
+			}
+			cs.addError(e.Pos(), fmt.Sprintf("types don't match: %s %s %s", operandTypeString(lhst, lhs[0].Const), op, operandTypeString(rhst, rhs[0].Const)))
+			return nil, nil, nil, false
+		}
+
+		if (op2 == shaderir.LeftShift || op2 == shaderir.RightShift) && rhs[0].Const != nil && gconstant.Sign(rhs[0].Const) < 0 {
+			cs.addError(e.Pos(), fmt.Sprintf("invalid shift count: %s", rhs[0].Const.String()))
+			return nil, nil, nil, false
+		}
+
+		if (op2 == shaderir.Div || op2 == shaderir.ModOp) && hasConstantZero(&rhs[0]) {
+			cs.addError(e.Pos(), "invalid operation: division by zero")
 			return nil, nil, nil, false
 		}
 
@@ -149,6 +280,24 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 				v = gconstant.MakeBool(b)
 			case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
 				v = gconstant.MakeBool(gconstant.Compare(lhs[0].Const, op, rhs[0].Const))
+			case token.SHL, token.SHR:
+				x := gconstant.ToInt(lhs[0].Const)
+				s, ok := gconstant.Uint64Val(gconstant.ToInt(rhs[0].Const))
+				if x.Kind() != gconstant.Int || !ok {
+					cs.addError(e.Pos(), fmt.Sprintf("invalid shift: %s %s %s", lhs[0].Const.String(), op, rhs[0].Const.String()))
+					return nil, nil, nil, false
+				}
+				v = gconstant.Shift(x, op, uint(s))
+			case token.QUO, token.QUO_ASSIGN, token.REM:
+				// A division by zero is already rejected above.
+				if op == token.REM && t.Main == shaderir.Float {
+					// % for floats is mod, whose result has the same sign as the divisor.
+					x, _ := gconstant.Float64Val(gconstant.ToFloat(lhs[0].Const))
+					y, _ := gconstant.Float64Val(gconstant.ToFloat(rhs[0].Const))
+					v = gconstant.MakeFloat64(x - y*math.Floor(x/y))
+				} else {
+					v = gconstant.BinaryOp(lhs[0].Const, op, rhs[0].Const)
+				}
 			default:
 				v = gconstant.BinaryOp(lhs[0].Const, op, rhs[0].Const)
 			}
@@ -161,6 +310,32 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 			}, []shaderir.Type{t}, stmts, true
 		}
 
+		if cs.options.Optimize {
+			if expr, ok := cs.simplifyIdentity(op2, &lhs[0], &rhs[0], &lhst, &rhst, &t); ok {
+				return []shaderir.Expr{expr}, []shaderir.Type{t}, stmts, true
+			}
+		}
+
+		// transpose(m) * v is equivalent to v * m. Avoid materializing the transposed matrix.
+		if cs.options.Optimize && op2 == shaderir.MatrixMul && lhst.IsMatrix() && rhst.IsFloatVector() && isTransposeCall(&lhs[0]) {
+			return []shaderir.Expr{
+				{
+					Type:  shaderir.Binary,
+					Op:    op2,
+					Exprs: []shaderir.Expr{rhs[0], lhs[0].Exprs[1]},
+				},
+			}, []shaderir.Type{t}, stmts, true
+		}
+
+		if op == token.AND_NOT {
+			rhs[0] = bitwiseComplement(rhs[0])
+		}
+
+		// % is available only for integers in backends like GLSL. Use mod for floats.
+		if op2 == shaderir.ModOp && (t.Main == shaderir.Float || t.IsFloatVector()) {
+			return []shaderir.Expr{builtinCall(shaderir.Mod, lhs[0], rhs[0])}, []shaderir.Type{t}, stmts, true
+		}
+
 		return []shaderir.Expr{
 			{
 				Type:  shaderir.Binary,
@@ -183,6 +358,10 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 			if !ok {
 				return nil, nil, nil, false
 			}
+			// A function name has no type. Keep args and argts aligned.
+			if len(es) == 1 && len(ts) == 0 {
+				ts = []shaderir.Type{{}}
+			}
 			if len(es) > 1 && len(e.Args) > 1 {
 				cs.addError(e.Pos(), fmt.Sprintf("single-value context and multiple-value context cannot be mixed: %s", e.Fun))
 				return nil, nil, nil, false
@@ -207,6 +386,20 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 		// For built-in functions, we can call this in this position. Return an expression for the function
 		// call.
 		if callee.Type == shaderir.BuiltinFuncExpr {
+			switch callee.BuiltinFunc {
+			case shaderir.FloatF, shaderir.Vec2F, shaderir.Vec3F, shaderir.Vec4F, shaderir.Mat2F, shaderir.Mat3F, shaderir.Mat4F:
+				if len(e.Args) == len(args) {
+					for i := range args {
+						cs.warnIntegerDivisionInFloatContext(e.Args[i], args[i], argts[i])
+					}
+				}
+			case shaderir.Dfdx, shaderir.Dfdy, shaderir.Fwidth, shaderir.AAStep, shaderir.DiscardF:
+				// The derivatives are defined only for fragments.
+				if !cs.useFragmentOnlyFeature(fname, e.Pos(), string(callee.BuiltinFunc)) {
+					return nil, nil, nil, false
+				}
+			}
+
 			// Process compile-time evaluations.
 			switch callee.BuiltinFunc {
 			case shaderir.Len, shaderir.Cap:
@@ -224,6 +417,55 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 						Const: gconstant.MakeInt64(int64(argts[0].Length)),
 					},
 				}, []shaderir.Type{{Main: shaderir.Int}}, stmts, true
+			case shaderir.Rand:
+				if len(args) != 1 {
+					cs.addError(e.Pos(), fmt.Sprintf("number of %s's arguments must be 1 but %d", callee.BuiltinFunc, len(args)))
+					return nil, nil, nil, false
+				}
+				return cs.randExpr(e.Pos(), args[0], argts[0], stmts)
+			case shaderir.LinearToSRGB, shaderir.SRGBToLinear:
+				if len(args) != 1 {
+					cs.addError(e.Pos(), fmt.Sprintf("number of %s's arguments must be 1 but %d", callee.BuiltinFunc, len(args)))
+					return nil, nil, nil, false
+				}
+				return cs.colorSpaceExpr(block, e.Pos(), callee.BuiltinFunc, args[0], argts[0], stmts)
+			case shaderir.Mix:
+				// mix with a bool selects one of the values without branches.
+				if len(args) == 3 && (argts[2].Main == shaderir.Bool || args[2].Const != nil && args[2].Const.Kind() == gconstant.Bool) {
+					return cs.selectExpr(e.Pos(), args, argts, stmts)
+				}
+				if len(args) == 3 && argts[2].IsBoolVector() {
+					return cs.selectVectorExpr(block, e.Pos(), args, argts, stmts)
+				}
+			case shaderir.Convolve3x3:
+				return cs.convolve3x3Expr(block, e.Pos(), args, argts, stmts)
+			case shaderir.BilinearSample:
+				return cs.bilinearSampleExpr(block, e.Pos(), args, argts, stmts)
+			case shaderir.AAStep:
+				return cs.aastepExpr(block, e.Pos(), args, argts, stmts)
+			case shaderir.LowpF, shaderir.MediumpF, shaderir.HighpF:
+				if len(args) != 1 {
+					cs.addError(e.Pos(), fmt.Sprintf("number of %s's arguments must be 1 but %d", callee.BuiltinFunc, len(args)))
+					return nil, nil, nil, false
+				}
+				t := argts[0]
+				if t.Main == shaderir.None && args[0].Const != nil {
+					t = toDefaultType(args[0].Const)
+				}
+				if t.Main != shaderir.Int && t.Main != shaderir.Float && !t.IsFloatVector() && !t.IsIntVector() && !t.IsMatrix() {
+					cs.addError(e.Pos(), fmt.Sprintf("cannot use %s as a numeric value in argument to %s", t.String(), callee.BuiltinFunc))
+					return nil, nil, nil, false
+				}
+				// The precision is applied to a local variable declared with this value. See conversionPrecision.
+				switch callee.BuiltinFunc {
+				case shaderir.LowpF:
+					t.Precision = shaderir.PrecisionLow
+				case shaderir.MediumpF:
+					t.Precision = shaderir.PrecisionMedium
+				case shaderir.HighpF:
+					t.Precision = shaderir.PrecisionHigh
+				}
+				return args[:1], []shaderir.Type{t}, stmts, true
 			case shaderir.BoolF:
 				if len(args) == 1 && args[0].Const != nil {
 					if args[0].Const.Kind() != gconstant.Bool {
@@ -246,6 +488,10 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 						cs.addError(e.Pos(), fmt.Sprintf("cannot convert %s to type int", args[0].Const.String()))
 						return nil, nil, nil, false
 					}
+					// int is a 32-bit integer on GPUs.
+					if !canRepresentAsInt32(v) {
+						cs.addWarning(e.Pos(), fmt.Sprintf("constant %s overflows int and the conversion loses precision", args[0].Const.String()))
+					}
 					return []shaderir.Expr{
 						{
 							Type:  shaderir.NumberExpr,
@@ -260,6 +506,13 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 						cs.addError(e.Pos(), fmt.Sprintf("cannot convert %s to type float", args[0].Const.String()))
 						return nil, nil, nil, false
 					}
+					// float is a 32-bit floating point number on GPUs.
+					// An integer beyond float's exact range cannot be converted without loss.
+					if args[0].Const.Kind() == gconstant.Int {
+						if _, exact := gconstant.Float32Val(v); !exact {
+							cs.addWarning(e.Pos(), fmt.Sprintf("constant %s cannot be represented exactly as float and the conversion loses precision", args[0].Const.String()))
+						}
+					}
 					return []shaderir.Expr{
 						{
 							Type:  shaderir.NumberExpr,
@@ -267,6 +520,15 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 						},
 					}, []shaderir.Type{{Main: shaderir.Float}}, stmts, true
 				}
+			case shaderir.Vec2F, shaderir.Vec3F, shaderir.IVec2F, shaderir.IVec3F:
+				// A longer vector is narrowed by a swizzling, e.g. vec2(v) for a vec4 v is vec2(v.xy).
+				n := 2
+				if callee.BuiltinFunc == shaderir.Vec3F || callee.BuiltinFunc == shaderir.IVec3F {
+					n = 3
+				}
+				if len(args) == 1 && (argts[0].IsFloatVector() || argts[0].IsIntVector()) && argts[0].VectorElementCount() > n {
+					args[0], argts[0] = narrowVector(args[0], argts[0], n)
+				}
 			}
 
 			// Process the expression as a regular function call.
@@ -334,19 +596,35 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 					cs.addError(e.Pos(), err.Error())
 					return nil, nil, nil, false
 				}
+				convertUntypedConstsToInt(args, argts)
 				t = shaderir.Type{Main: shaderir.IVec2}
 			case shaderir.IVec3F:
 				if err := checkArgsForIVec3BuiltinFunc(args, argts); err != nil {
 					cs.addError(e.Pos(), err.Error())
 					return nil, nil, nil, false
 				}
+				convertUntypedConstsToInt(args, argts)
 				t = shaderir.Type{Main: shaderir.IVec3}
 			case shaderir.IVec4F:
 				if err := checkArgsForIVec4BuiltinFunc(args, argts); err != nil {
 					cs.addError(e.Pos(), err.Error())
 					return nil, nil, nil, false
 				}
+				convertUntypedConstsToInt(args, argts)
 				t = shaderir.Type{Main: shaderir.IVec4}
+			case shaderir.BVec2F, shaderir.BVec3F, shaderir.BVec4F:
+				n := 2
+				switch callee.BuiltinFunc {
+				case shaderir.BVec3F:
+					n = 3
+				case shaderir.BVec4F:
+					n = 4
+				}
+				if err := checkArgsForBVecBuiltinFunc(callee.BuiltinFunc, n, args, argts); err != nil {
+					cs.addError(e.Pos(), err.Error())
+					return nil, nil, nil, false
+				}
+				t = boolVectorType(n)
 			case shaderir.Mat2F:
 				if err := checkArgsForMat2BuiltinFunc(args, argts); err != nil {
 					cs.addError(e.Pos(), err.Error())
@@ -400,20 +678,86 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 					return nil, nil, nil, false
 				}
 				t = shaderir.Type{Main: shaderir.Vec4}
+			case shaderir.TextureLod:
+				if len(args) != 3 {
+					cs.addError(e.Pos(), fmt.Sprintf("number of %s's arguments must be 3 but %d", callee.BuiltinFunc, len(args)))
+					return nil, nil, nil, false
+				}
+				if argts[0].Main != shaderir.Texture {
+					cs.addError(e.Pos(), fmt.Sprintf("cannot use %s as texture value in argument to %s", argts[0].String(), callee.BuiltinFunc))
+					return nil, nil, nil, false
+				}
+				if argts[1].Main != shaderir.Vec2 {
+					cs.addError(e.Pos(), fmt.Sprintf("cannot use %s as vec2 value in argument to %s", argts[1].String(), callee.BuiltinFunc))
+					return nil, nil, nil, false
+				}
+				if args[2].Const != nil && (argts[2].Main == shaderir.None || argts[2].Main == shaderir.Float) && canTruncateToFloat(args[2].Const) {
+					args[2].Const = gconstant.ToFloat(args[2].Const)
+					argts[2] = shaderir.Type{Main: shaderir.Float}
+				}
+				if argts[2].Main != shaderir.Float {
+					cs.addError(e.Pos(), fmt.Sprintf("cannot use %s as float value in argument to %s", argts[2].String(), callee.BuiltinFunc))
+					return nil, nil, nil, false
+				}
+				// In pixels, the position is at the level 0. Scale it to the position at the level.
+				if cs.unit == shaderir.Pixels {
+					var scale shaderir.Expr
+					if args[2].Const != nil {
+						v, _ := gconstant.Float64Val(args[2].Const)
+						scale = floatExpr(math.Exp2(math.Floor(v)))
+					} else {
+						args[2], stmts = cs.evaluateOnce(block, args[2], argts[2], stmts)
+						scale = builtinCall(shaderir.Exp2, builtinCall(shaderir.Floor, args[2]))
+					}
+					args[1] = binaryExpr(shaderir.Div, args[1], scale)
+				}
+				t = shaderir.Type{Main: shaderir.Vec4}
 			case shaderir.DiscardF:
 				if len(args) != 0 {
 					cs.addError(e.Pos(), fmt.Sprintf("number of %s's arguments must be 0 but %d", callee.BuiltinFunc, len(args)))
 					return nil, nil, nil, false
 				}
-				if fname != cs.fragmentEntry {
-					cs.addError(e.Pos(), fmt.Sprintf("discard is available only in %s", cs.fragmentEntry))
-					return nil, nil, nil, false
-				}
 				stmts = append(stmts, shaderir.Stmt{
 					Type: shaderir.Discard,
 				})
 				return nil, nil, stmts, true
 
+			case shaderir.LessThan, shaderir.LessThanEqual, shaderir.GreaterThan, shaderir.GreaterThanEqual, shaderir.Equal, shaderir.NotEqual:
+				if len(args) != 2 {
+					cs.addError(e.Pos(), fmt.Sprintf("number of %s's arguments must be 2 but %d", callee.BuiltinFunc, len(args)))
+					return nil, nil, nil, false
+				}
+				for i := range args {
+					if argts[i].IsFloatVector() || argts[i].IsIntVector() {
+						continue
+					}
+					if argts[i].IsBoolVector() && (callee.BuiltinFunc == shaderir.Equal || callee.BuiltinFunc == shaderir.NotEqual) {
+						continue
+					}
+					cs.addError(e.Pos(), fmt.Sprintf("cannot use %s as vector value in argument to %s", operandTypeString(argts[i], args[i].Const), callee.BuiltinFunc))
+					return nil, nil, nil, false
+				}
+				if !argts[0].Equal(&argts[1]) {
+					cs.addError(e.Pos(), fmt.Sprintf("%s and %s don't match in argument to %s", argts[0].String(), argts[1].String(), callee.BuiltinFunc))
+					return nil, nil, nil, false
+				}
+				t = boolVectorType(argts[0].VectorElementCount())
+
+			case shaderir.Any, shaderir.All, shaderir.Not:
+				if len(args) != 1 {
+					cs.addError(e.Pos(), fmt.Sprintf("number of %s's arguments must be 1 but %d", callee.BuiltinFunc, len(args)))
+					return nil, nil, nil, false
+				}
+				if !argts[0].IsBoolVector() {
+					cs.addError(e.Pos(), fmt.Sprintf("cannot use %s as bvec2, bvec3, or bvec4 value in argument to %s", argts[0].String(), callee.BuiltinFunc))
+					return nil, nil, nil, false
+				}
+				if callee.BuiltinFunc == shaderir.Not {
+					t = argts[0]
+				} else {
+					t = shaderir.Type{Main: shaderir.Bool}
+				}
+
 			case shaderir.Clamp, shaderir.Mix, shaderir.Smoothstep, shaderir.Faceforward, shaderir.Refract:
 				// 3 arguments
 				if len(args) != 3 {
@@ -431,8 +775,16 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 						return nil, nil, nil, false
 					}
 				}
+				if err := checkArgDimensions(callee.BuiltinFunc, argts); err != nil {
+					cs.addError(e.Pos(), err.Error())
+					return nil, nil, nil, false
+				}
 				switch callee.BuiltinFunc {
 				case shaderir.Clamp:
+					// GLSL's clamp has an overload only for a vector and two scalar bounds.
+					if !cs.broadcastFloatArgs(e.Pos(), callee.BuiltinFunc, args, argts, 1, 2) {
+						return nil, nil, nil, false
+					}
 					if (!argts[0].Equal(&argts[1]) || !argts[0].Equal(&argts[2])) && (argts[1].Main != shaderir.Float || argts[2].Main != shaderir.Float) {
 						cs.addError(e.Pos(), fmt.Sprintf("the second and the third arguments for %s must equal to the first argument %s or float but %s and %s", callee.BuiltinFunc, argts[0].String(), argts[1].String(), argts[2].String()))
 						return nil, nil, nil, false
@@ -447,6 +799,11 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 						return nil, nil, nil, false
 					}
 				case shaderir.Smoothstep:
+					// The result type follows x, so only scalar edges are broadcast to a vector x.
+					// GLSL's smoothstep has an overload only for two scalar edges and a vector.
+					if argts[2].IsFloatVector() && !cs.broadcastFloatArgs(e.Pos(), callee.BuiltinFunc, args, argts, 0, 1) {
+						return nil, nil, nil, false
+					}
 					if (!argts[0].Equal(&argts[1]) || !argts[0].Equal(&argts[2])) && (argts[0].Main != shaderir.Float || argts[1].Main != shaderir.Float) {
 						cs.addError(e.Pos(), fmt.Sprintf("the first and the second arguments for %s must equal to the third argument %s or float but %s and %s", callee.BuiltinFunc, argts[2].String(), argts[0].String(), argts[1].String()))
 						return nil, nil, nil, false
@@ -474,6 +831,21 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 					t = argts[0]
 				}
 
+				// Fold mix with constant arguments: mix(x, y, a) = x*(1-a) + y*a.
+				if callee.BuiltinFunc == shaderir.Mix && args[0].Const != nil && args[1].Const != nil && args[2].Const != nil {
+					x, y, a := args[0].Const, args[1].Const, args[2].Const
+					v := gconstant.BinaryOp(
+						gconstant.BinaryOp(x, token.MUL, gconstant.BinaryOp(gconstant.MakeFloat64(1), token.SUB, a)),
+						token.ADD,
+						gconstant.BinaryOp(y, token.MUL, a))
+					return []shaderir.Expr{
+						{
+							Type:  shaderir.NumberExpr,
+							Const: v,
+						},
+					}, []shaderir.Type{t}, stmts, true
+				}
+
 			case shaderir.Atan2, shaderir.Pow, shaderir.Mod, shaderir.Min, shaderir.Max, shaderir.Step, shaderir.Distance, shaderir.Dot, shaderir.Cross, shaderir.Reflect:
 				// 2 arguments
 				if len(args) != 2 {
@@ -491,18 +863,39 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 						return nil, nil, nil, false
 					}
 				}
+				if err := checkArgDimensions(callee.BuiltinFunc, argts); err != nil {
+					cs.addError(e.Pos(), err.Error())
+					return nil, nil, nil, false
+				}
 
 				switch callee.BuiltinFunc {
 				case shaderir.Mod, shaderir.Min, shaderir.Max:
+					// GLSL's min and max have an overload only for a vector and a scalar in this order.
+					if callee.BuiltinFunc != shaderir.Mod && !cs.broadcastFloatArgs(e.Pos(), callee.BuiltinFunc, args, argts, 1) {
+						return nil, nil, nil, false
+					}
 					if !argts[0].Equal(&argts[1]) && argts[1].Main != shaderir.Float {
 						cs.addError(e.Pos(), fmt.Sprintf("the second argument for %s must equal to the first argument %s or float but %s", callee.BuiltinFunc, argts[0].String(), argts[1].String()))
 						return nil, nil, nil, false
 					}
 				case shaderir.Step:
+					// The result type follows x, so a vector edge requires x of the same type.
+					// GLSL's step has an overload for a scalar edge and a vector.
 					if !argts[0].Equal(&argts[1]) && argts[0].Main != shaderir.Float {
 						cs.addError(e.Pos(), fmt.Sprintf("the first argument for %s must equal to the second argument %s or float but %s", callee.BuiltinFunc, argts[1].String(), argts[0].String()))
 						return nil, nil, nil, false
 					}
+				case shaderir.Pow:
+					// A scalar exponent is broadcast to the base vector.
+					// GLSL's pow doesn't have an overload for a vector and a scalar.
+					if argts[0].IsFloatVector() && argts[1].Main == shaderir.Float {
+						args[1] = broadcastFloat(args[1], argts[0])
+						argts[1] = argts[0]
+					}
+					if !argts[0].Equal(&argts[1]) {
+						cs.addError(e.Pos(), fmt.Sprintf("%s and %s don't match in argument to %s", argts[0].String(), argts[1].String(), callee.BuiltinFunc))
+						return nil, nil, nil, false
+					}
 				case shaderir.Cross:
 					for i := range argts {
 						if argts[i].Main != shaderir.Vec3 {
@@ -554,6 +947,37 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 					t = argts[0]
 				}
 			}
+
+			// Fold a built-in math function with constant scalar arguments.
+			if t.Main == shaderir.Float {
+				if fargs, ok := constantFloatArgs(args, argts); ok {
+					v, ok, err := foldBuiltinCall(callee.BuiltinFunc, fargs)
+					if err != nil {
+						cs.addError(e.Pos(), err.Error())
+						return nil, nil, nil, false
+					}
+					if ok {
+						return []shaderir.Expr{
+							{
+								Type:  shaderir.NumberExpr,
+								Const: gconstant.MakeFloat64(v),
+							},
+						}, []shaderir.Type{t}, stmts, true
+					}
+				}
+			}
+
+			switch callee.BuiltinFunc {
+			case shaderir.Normalize:
+				if isConstantZero(&args[0]) {
+					cs.addWarning(e.Pos(), fmt.Sprintf("%s of a zero vector is undefined", callee.BuiltinFunc))
+				}
+			case shaderir.Reflect, shaderir.Refract:
+				if isConstantZero(&args[1]) {
+					cs.addWarning(e.Pos(), fmt.Sprintf("%s with a zero normal vector is undefined", callee.BuiltinFunc))
+				}
+			}
+
 			return []shaderir.Expr{
 				{
 					Type:  shaderir.Call,
@@ -583,6 +1007,9 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 				cs.addError(e.Pos(), fmt.Sprintf("cannot use type %s as type %s in argument", argts[i].String(), p.String()))
 				return nil, nil, nil, false
 			}
+			if p.Main == shaderir.Float && len(e.Args) == len(args) {
+				cs.warnIntegerDivisionInFloatContext(e.Args[i], args[i], argts[i])
+			}
 
 			if args[i].Const != nil {
 				switch p.Main {
@@ -665,6 +1092,10 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 				},
 			}, []shaderir.Type{{}}, nil, true
 		}
+		// The error of a variable whose declaration failed is already reported.
+		if block.isInvalidLocalVariable(e.Name) {
+			return nil, nil, nil, false
+		}
 		if i, t, ok := block.findLocalVariable(e.Name, markLocalVariableUsed); ok {
 			return []shaderir.Expr{
 				{
@@ -714,6 +1145,14 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 				},
 			}, []shaderir.Type{{Main: shaderir.Texture}}, nil, true
 		}
+		if e.Name == "iota" && cs.inConstDecl {
+			return []shaderir.Expr{
+				{
+					Type:  shaderir.NumberExpr,
+					Const: gconstant.MakeInt64(cs.iota),
+				},
+			}, []shaderir.Type{{}}, nil, true
+		}
 		if e.Name == "true" || e.Name == "false" {
 			return []shaderir.Expr{
 				{
@@ -722,6 +1161,24 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 				},
 			}, []shaderir.Type{{Main: shaderir.Bool}}, nil, true
 		}
+		if e.Name == shaderir.TimeUniformName && !cs.options.DisableAutoTime {
+			// Declare the time uniform variable implicitly. The runtime populates this every frame.
+			cs.ir.UniformNames = append(cs.ir.UniformNames, e.Name)
+			cs.ir.Uniforms = append(cs.ir.Uniforms, shaderir.Type{Main: shaderir.Float})
+			cs.ir.ImplicitTime = true
+			return []shaderir.Expr{
+				{
+					Type:  shaderir.UniformVariable,
+					Index: len(cs.ir.Uniforms) - 1,
+				},
+			}, []shaderir.Type{{Main: shaderir.Float}}, nil, true
+		}
+		for _, a := range cs.attributes {
+			if a.name == e.Name {
+				cs.addError(e.Pos(), fmt.Sprintf("attribute %s is available only in the vertex entry point", e.Name))
+				return nil, nil, nil, false
+			}
+		}
 		cs.addError(e.Pos(), fmt.Sprintf("unexpected identifier: %s", e.Name))
 
 	case *ast.ParenExpr:
@@ -736,6 +1193,31 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 			cs.addError(e.Pos(), fmt.Sprintf("multiple-value context is not available at a selector: %s", e.X))
 			return nil, nil, nil, false
 		}
+		// A type or a function name has no type.
+		if len(types) != 1 {
+			cs.addError(e.Pos(), fmt.Sprintf("invalid selector: the operand of .%s is not a value", e.Sel.Name))
+			return nil, nil, nil, false
+		}
+
+		if types[0].Main == shaderir.Struct {
+			idx, ok := structFieldIndex(&types[0], e.Sel.Name)
+			if !ok {
+				cs.addError(e.Sel.Pos(), fmt.Sprintf("%s undefined (type %s has no field %s)", e.Sel.Name, types[0].String(), e.Sel.Name))
+				return nil, nil, nil, false
+			}
+			return []shaderir.Expr{
+				{
+					Type: shaderir.FieldSelector,
+					Exprs: []shaderir.Expr{
+						exprs[0],
+						{
+							Type:  shaderir.StructMember,
+							Index: idx,
+						},
+					},
+				},
+			}, []shaderir.Type{types[0].Sub[idx]}, stmts, true
+		}
 
 		if !isValidSwizzling(e.Sel.Name, types[0]) {
 			cs.addError(e.Pos(), fmt.Sprintf("unexpected swizzling: %s", e.Sel.Name))
@@ -766,6 +1248,12 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 			case 4:
 				t.Main = shaderir.IVec4
 			}
+		case shaderir.BVec2, shaderir.BVec3, shaderir.BVec4:
+			if len(e.Sel.Name) == 1 {
+				t.Main = shaderir.Bool
+			} else {
+				t = boolVectorType(len(e.Sel.Name))
+			}
 		}
 		if t.Equal(&shaderir.Type{}) {
 			cs.addError(e.Pos(), fmt.Sprintf("unexpected swizzling: %s", e.Sel.Name))
@@ -789,13 +1277,46 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 		if !ok {
 			return nil, nil, nil, false
 		}
-		if len(exprs) != 1 {
+		if len(exprs) != 1 || len(ts) != 1 {
 			cs.addError(e.Pos(), fmt.Sprintf("multiple-value context is not available at a unary operator: %s", e.X))
 			return nil, nil, nil, false
 		}
 
+		switch e.Op {
+		case token.ADD, token.SUB:
+			t := ts[0]
+			if t.Main == shaderir.None && exprs[0].Const != nil {
+				t = toDefaultType(exprs[0].Const)
+			}
+			if t.Main != shaderir.Int && t.Main != shaderir.Float && !t.IsFloatVector() && !t.IsIntVector() && !t.IsMatrix() {
+				cs.addError(e.Pos(), fmt.Sprintf("invalid operation: operator %s not defined on %s", e.Op, t.String()))
+				return nil, nil, nil, false
+			}
+		case token.NOT:
+			t := ts[0]
+			if t.Main == shaderir.None && exprs[0].Const != nil {
+				t = toDefaultType(exprs[0].Const)
+			}
+			if t.IsBoolVector() {
+				// ! on a boolean vector is component-wise. Lower this to not, as GLSL's ! is only for bool.
+				return []shaderir.Expr{builtinCall(shaderir.Not, exprs[0])}, ts[:1], stmts, true
+			}
+			if t.Main != shaderir.Bool {
+				cs.addError(e.Pos(), fmt.Sprintf("invalid operation: operator ! not defined on %s", t.String()))
+				return nil, nil, nil, false
+			}
+		default:
+			cs.addError(e.Pos(), fmt.Sprintf("unexpected operator: %s", e.Op))
+			return nil, nil, nil, false
+		}
+
 		if exprs[0].Const != nil {
 			v := gconstant.UnaryOp(e.Op, exprs[0].Const, 0)
+			// The negation of a typed int constant can overflow, e.g. -c where c is math.MinInt32.
+			if ts[0].Main == shaderir.Int && !canRepresentAsInt32(v) {
+				cs.addError(e.Pos(), fmt.Sprintf("constant %s overflows int", v.String()))
+				return nil, nil, nil, false
+			}
 			// Use the original type as it is.
 			// Keep the type untyped if the original expression is untyped (#2705).
 			return []shaderir.Expr{
@@ -806,6 +1327,27 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 			}, ts[:1], stmts, true
 		}
 
+		// Unary plus is a no-op.
+		if e.Op == token.ADD {
+			return exprs, ts[:1], stmts, true
+		}
+
+		// Fold the negation of a constant vector or matrix like -vec3(1, 2, 3).
+		if e.Op == token.SUB && isConstantConstructorCall(&exprs[0]) {
+			expr := exprs[0]
+			expr.Exprs = make([]shaderir.Expr, len(exprs[0].Exprs))
+			copy(expr.Exprs, exprs[0].Exprs)
+			for i := 1; i < len(expr.Exprs); i++ {
+				v := gconstant.UnaryOp(token.SUB, expr.Exprs[i].Const, 0)
+				if ts[0].IsIntVector() && !canRepresentAsInt32(v) {
+					cs.addError(e.Pos(), fmt.Sprintf("constant %s overflows int", v.String()))
+					return nil, nil, nil, false
+				}
+				expr.Exprs[i].Const = v
+			}
+			return []shaderir.Expr{expr}, ts[:1], stmts, true
+		}
+
 		var op shaderir.Op
 		switch e.Op {
 		case token.ADD:
@@ -831,14 +1373,25 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 		if !ok {
 			return nil, nil, nil, false
 		}
+		if t.Main == shaderir.Struct {
+			return cs.parseStructLiteral(block, fname, e, t, markLocalVariableUsed)
+		}
 		if t.Main != shaderir.Array {
 			cs.addError(e.Pos(), fmt.Sprintf("invalid composite literal type %s", t.String()))
 			return nil, nil, nil, false
 		}
 		if t.Main == shaderir.Array && t.Length == -1 {
+			if len(e.Elts) == 0 {
+				cs.addError(e.Pos(), "length of array must be positive but 0")
+				return nil, nil, nil, false
+			}
 			t.Length = len(e.Elts)
 		}
-
+		if len(e.Elts) > t.Length {
+			cs.addError(e.Elts[t.Length].Pos(), fmt.Sprintf("array index %d out of bounds [0:%d]", t.Length, t.Length))
+			return nil, nil, nil, false
+		}
+
 		idx := block.totalLocalVariableCount()
 		block.vars = append(block.vars, variable{
 			typ: t,
@@ -846,11 +1399,15 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 
 		var stmts []shaderir.Stmt
 		for i, e := range e.Elts {
-			exprs, _, ss, ok := cs.parseExpr(block, fname, e, markLocalVariableUsed)
+			if _, ok := e.(*ast.KeyValueExpr); ok {
+				cs.addError(e.Pos(), "keyed elements are not available in an array literal")
+				return nil, nil, nil, false
+			}
+			exprs, ts, ss, ok := cs.parseExpr(block, fname, e, markLocalVariableUsed)
 			if !ok {
 				return nil, nil, nil, false
 			}
-			if len(exprs) != 1 {
+			if len(exprs) != 1 || len(ts) != 1 {
 				cs.addError(e.Pos(), "multiple-value context is not available at a composite literal")
 				return nil, nil, nil, false
 			}
@@ -861,6 +1418,7 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 				case shaderir.Bool:
 					if expr.Const.Kind() != gconstant.Bool {
 						cs.addError(e.Pos(), fmt.Sprintf("cannot %s to type bool", expr.Const.String()))
+						return nil, nil, nil, false
 					}
 				case shaderir.Int:
 					if !canTruncateToInteger(expr.Const) {
@@ -878,6 +1436,9 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 					cs.addError(e.Pos(), fmt.Sprintf("constant %s cannot be used for the array type %s", expr.Const.String(), t.String()))
 					return nil, nil, nil, false
 				}
+			} else if !ts[0].Equal(&t.Sub[0]) {
+				cs.addError(e.Pos(), fmt.Sprintf("cannot use %s as %s value in array literal", ts[0].String(), t.Sub[0].String()))
+				return nil, nil, nil, false
 			}
 
 			stmts = append(stmts, ss...)
@@ -913,7 +1474,7 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 		var stmts []shaderir.Stmt
 
 		// Parse the index first
-		exprs, _, ss, ok := cs.parseExpr(block, fname, e.Index, true)
+		exprs, its, ss, ok := cs.parseExpr(block, fname, e.Index, true)
 		if !ok {
 			return nil, nil, nil, false
 		}
@@ -923,7 +1484,17 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 			cs.addError(e.Pos(), "multiple-value context is not available at an index expression")
 			return nil, nil, nil, false
 		}
+		// A type or a function name has no type.
+		if len(its) != 1 {
+			cs.addError(e.Pos(), "invalid index")
+			return nil, nil, nil, false
+		}
 		idx := exprs[0]
+		// An untyped constant index has no type.
+		if its[0].Main != shaderir.None && its[0].Main != shaderir.Int {
+			cs.addError(e.Pos(), fmt.Sprintf("invalid argument: index of type %s must be integer", its[0].String()))
+			return nil, nil, nil, false
+		}
 		if idx.Const != nil {
 			if !canTruncateToInteger(idx.Const) {
 				cs.addError(e.Pos(), fmt.Sprintf("constant %s truncated to integer", idx.Const.String()))
@@ -940,28 +1511,50 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 			cs.addError(e.Pos(), "multiple-value context is not available at an index expression")
 			return nil, nil, nil, false
 		}
+		if len(ts) != 1 {
+			cs.addError(e.Pos(), fmt.Sprintf("index operator cannot be applied to %s", e.X))
+			return nil, nil, nil, false
+		}
 		x := exprs[0]
 		t := ts[0]
 
 		var typ shaderir.Type
+		var length int
 		switch t.Main {
 		case shaderir.Vec2, shaderir.Vec3, shaderir.Vec4:
 			typ = shaderir.Type{Main: shaderir.Float}
+			length = t.VectorElementCount()
 		case shaderir.IVec2, shaderir.IVec3, shaderir.IVec4:
 			typ = shaderir.Type{Main: shaderir.Int}
+			length = t.VectorElementCount()
+		case shaderir.BVec2, shaderir.BVec3, shaderir.BVec4:
+			typ = shaderir.Type{Main: shaderir.Bool}
+			length = t.VectorElementCount()
 		case shaderir.Mat2:
 			typ = shaderir.Type{Main: shaderir.Vec2}
+			length = 2
 		case shaderir.Mat3:
 			typ = shaderir.Type{Main: shaderir.Vec3}
+			length = 3
 		case shaderir.Mat4:
 			typ = shaderir.Type{Main: shaderir.Vec4}
+			length = 4
 		case shaderir.Array:
 			typ = t.Sub[0]
+			length = t.Length
 		default:
 			cs.addError(e.Pos(), fmt.Sprintf("index operator cannot be applied to the type %s", t.String()))
 			return nil, nil, nil, false
 		}
 
+		if idx.Const != nil {
+			v, ok := gconstant.Int64Val(gconstant.ToInt(idx.Const))
+			if !ok || v < 0 || v >= int64(length) {
+				cs.addError(e.Pos(), fmt.Sprintf("invalid argument: index %s out of bounds [0:%d]", idx.Const.String(), length))
+				return nil, nil, nil, false
+			}
+		}
+
 		return []shaderir.Expr{
 			{
 				Type: shaderir.Index,
@@ -972,23 +1565,1029 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 			},
 		}, []shaderir.Type{typ}, stmts, true
 
+	case *ast.BadExpr:
+		// The parser already reported the syntax error. This is reached only in the recovery mode.
+		cs.badNodes++
+
 	default:
 		cs.addError(e.Pos(), fmt.Sprintf("expression not implemented: %#v", e))
 	}
 	return nil, nil, nil, false
 }
 
+// parseStructLiteral parses a composite literal of the struct type t.
+// Like an array literal, the literal is stored to a new local variable, and the members are assigned one by one.
+// The members not specified in a keyed literal are zero values.
+func (cs *compileState) parseStructLiteral(block *block, fname string, e *ast.CompositeLit, t shaderir.Type, markLocalVariableUsed bool) ([]shaderir.Expr, []shaderir.Type, []shaderir.Stmt, bool) {
+	var keyed bool
+	if len(e.Elts) > 0 {
+		_, keyed = e.Elts[0].(*ast.KeyValueExpr)
+	}
+	if !keyed && len(e.Elts) > 0 && len(e.Elts) < len(t.Sub) {
+		cs.addError(e.Rbrace, "too few values in struct literal")
+		return nil, nil, nil, false
+	}
+	if !keyed && len(e.Elts) > len(t.Sub) {
+		cs.addError(e.Elts[len(t.Sub)].Pos(), "too many values in struct literal")
+		return nil, nil, nil, false
+	}
+
+	idx := block.totalLocalVariableCount()
+	block.vars = append(block.vars, variable{
+		typ: t,
+	})
+
+	var stmts []shaderir.Stmt
+	assigned := map[int]struct{}{}
+	for i, elt := range e.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if ok != keyed {
+			cs.addError(elt.Pos(), "mixture of field:value and value elements in struct literal")
+			return nil, nil, nil, false
+		}
+
+		fieldIdx := i
+		value := elt
+		if keyed {
+			key, ok := kv.Key.(*ast.Ident)
+			if !ok {
+				cs.addError(kv.Key.Pos(), fmt.Sprintf("invalid field name %s in struct literal", kv.Key))
+				return nil, nil, nil, false
+			}
+			fieldIdx, ok = structFieldIndex(&t, key.Name)
+			if !ok {
+				cs.addError(key.Pos(), fmt.Sprintf("unknown field %s in struct literal", key.Name))
+				return nil, nil, nil, false
+			}
+			if _, ok := assigned[fieldIdx]; ok {
+				cs.addError(key.Pos(), fmt.Sprintf("duplicate field name %s in struct literal", key.Name))
+				return nil, nil, nil, false
+			}
+			assigned[fieldIdx] = struct{}{}
+			value = kv.Value
+		}
+
+		exprs, ts, ss, ok := cs.parseExpr(block, fname, value, markLocalVariableUsed)
+		if !ok {
+			return nil, nil, nil, false
+		}
+		if len(exprs) != 1 || len(ts) != 1 {
+			cs.addError(value.Pos(), "multiple-value context is not available at a composite literal")
+			return nil, nil, nil, false
+		}
+
+		expr := exprs[0]
+		ft := t.Sub[fieldIdx]
+		if !canAssign(&ft, &ts[0], expr.Const) {
+			vt := ts[0].String()
+			if expr.Const != nil && ts[0].Main == shaderir.None {
+				vt = expr.Const.String()
+			}
+			cs.addError(value.Pos(), fmt.Sprintf("cannot use %s as %s value in struct literal", vt, ft.String()))
+			return nil, nil, nil, false
+		}
+		if expr.Const != nil {
+			switch ft.Main {
+			case shaderir.Int:
+				expr.Const = gconstant.ToInt(expr.Const)
+			case shaderir.Float:
+				expr.Const = gconstant.ToFloat(expr.Const)
+			}
+		}
+
+		stmts = append(stmts, ss...)
+		stmts = append(stmts, shaderir.Stmt{
+			Type: shaderir.Assign,
+			Exprs: []shaderir.Expr{
+				{
+					Type: shaderir.FieldSelector,
+					Exprs: []shaderir.Expr{
+						{
+							Type:  shaderir.LocalVariable,
+							Index: idx,
+						},
+						{
+							Type:  shaderir.StructMember,
+							Index: fieldIdx,
+						},
+					},
+				},
+				expr,
+			},
+		})
+	}
+
+	return []shaderir.Expr{
+		{
+			Type:  shaderir.LocalVariable,
+			Index: idx,
+		},
+	}, []shaderir.Type{t}, stmts, true
+}
+
+// evaluateOnce returns an expression that can be referred to multiple times without evaluating expr again.
+// If expr is not a constant or a variable, evaluateOnce stores expr to a new local variable by appending a statement to stmts.
+func (cs *compileState) evaluateOnce(block *block, expr shaderir.Expr, t shaderir.Type, stmts []shaderir.Stmt) (shaderir.Expr, []shaderir.Stmt) {
+	switch expr.Type {
+	case shaderir.NumberExpr, shaderir.LocalVariable, shaderir.UniformVariable:
+		return expr, stmts
+	}
+	return cs.storeToLocalVariable(block, expr, t, stmts)
+}
+
+// storeToLocalVariable stores expr to a new local variable by appending a statement to stmts, and returns the variable.
+func (cs *compileState) storeToLocalVariable(block *block, expr shaderir.Expr, t shaderir.Type, stmts []shaderir.Stmt) (shaderir.Expr, []shaderir.Stmt) {
+	idx := block.totalLocalVariableCount()
+	block.vars = append(block.vars, variable{
+		typ: t,
+	})
+	stmts = append(stmts, shaderir.Stmt{
+		Type: shaderir.Assign,
+		Exprs: []shaderir.Expr{
+			{
+				Type:  shaderir.LocalVariable,
+				Index: idx,
+			},
+			expr,
+		},
+	})
+	return shaderir.Expr{
+		Type:  shaderir.LocalVariable,
+		Index: idx,
+	}, stmts
+}
+
+// convolve3x3Expr returns an expression of the weighted sum of the 3x3 pixels around a position.
+// The arguments are a function to read a pixel like imageSrc0At, the center position, and a [9]float kernel in row-major order.
+func (cs *compileState) convolve3x3Expr(block *block, pos token.Pos, args []shaderir.Expr, argts []shaderir.Type, stmts []shaderir.Stmt) ([]shaderir.Expr, []shaderir.Type, []shaderir.Stmt, bool) {
+	if len(args) != 3 {
+		cs.addError(pos, fmt.Sprintf("number of %s's arguments must be 3 but %d", shaderir.Convolve3x3, len(args)))
+		return nil, nil, nil, false
+	}
+	if args[0].Type != shaderir.FunctionExpr {
+		cs.addError(pos, fmt.Sprintf("cannot use %s as func(vec2) vec4 value in argument to %s", argts[0].String(), shaderir.Convolve3x3))
+		return nil, nil, nil, false
+	}
+	if f := cs.funcs[args[0].Index].ir; len(f.InParams) != 1 || f.InParams[0].Main != shaderir.Vec2 || len(f.OutParams) != 0 || f.Return.Main != shaderir.Vec4 {
+		cs.addError(pos, fmt.Sprintf("cannot use %s as func(vec2) vec4 value in argument to %s", cs.funcs[args[0].Index].name, shaderir.Convolve3x3))
+		return nil, nil, nil, false
+	}
+	if argts[1].Main != shaderir.Vec2 {
+		cs.addError(pos, fmt.Sprintf("cannot use %s as vec2 value in argument to %s", argts[1].String(), shaderir.Convolve3x3))
+		return nil, nil, nil, false
+	}
+	if argts[2].Main != shaderir.Array || argts[2].Length != 9 || argts[2].Sub[0].Main != shaderir.Float {
+		cs.addError(pos, fmt.Sprintf("cannot use %s as [9]float value in argument to %s", argts[2].String(), shaderir.Convolve3x3))
+		return nil, nil, nil, false
+	}
+	// With the texel mode, the size of a pixel is unknown here.
+	if cs.unit != shaderir.Pixels {
+		cs.addError(pos, fmt.Sprintf("%s is available only in the pixel-unit mode", shaderir.Convolve3x3))
+		return nil, nil, nil, false
+	}
+
+	f := args[0]
+	center, stmts := cs.evaluateOnce(block, args[1], argts[1], stmts)
+	kernel, stmts := cs.evaluateOnce(block, args[2], argts[2], stmts)
+
+	var sum shaderir.Expr
+	for i := 0; i < 9; i++ {
+		dx, dy := i%3-1, i/3-1
+		p := binaryExpr(shaderir.Add, center, builtinCall(shaderir.Vec2F, floatExpr(float64(dx)), floatExpr(float64(dy))))
+		k := shaderir.Expr{
+			Type: shaderir.Index,
+			Exprs: []shaderir.Expr{
+				kernel,
+				{
+					Type:  shaderir.NumberExpr,
+					Const: gconstant.MakeInt64(int64(i)),
+				},
+			},
+		}
+		term := binaryExpr(shaderir.ComponentWiseMul, shaderir.Expr{
+			Type:  shaderir.Call,
+			Exprs: []shaderir.Expr{f, p},
+		}, k)
+		if i == 0 {
+			sum = term
+			continue
+		}
+		sum = binaryExpr(shaderir.Add, sum, term)
+	}
+	return []shaderir.Expr{sum}, []shaderir.Type{{Main: shaderir.Vec4}}, stmts, true
+}
+
+// bilinearSampleExpr returns an expression of the bilinear interpolation of the 4 pixels around a position.
+// The arguments are a function to read a pixel like imageSrc0UnsafeAt and the position.
+func (cs *compileState) bilinearSampleExpr(block *block, pos token.Pos, args []shaderir.Expr, argts []shaderir.Type, stmts []shaderir.Stmt) ([]shaderir.Expr, []shaderir.Type, []shaderir.Stmt, bool) {
+	if len(args) != 2 {
+		cs.addError(pos, fmt.Sprintf("number of %s's arguments must be 2 but %d", shaderir.BilinearSample, len(args)))
+		return nil, nil, nil, false
+	}
+	if args[0].Type != shaderir.FunctionExpr {
+		cs.addError(pos, fmt.Sprintf("cannot use %s as func(vec2) vec4 value in argument to %s", argts[0].String(), shaderir.BilinearSample))
+		return nil, nil, nil, false
+	}
+	if f := cs.funcs[args[0].Index].ir; len(f.InParams) != 1 || f.InParams[0].Main != shaderir.Vec2 || len(f.OutParams) != 0 || f.Return.Main != shaderir.Vec4 {
+		cs.addError(pos, fmt.Sprintf("cannot use %s as func(vec2) vec4 value in argument to %s", cs.funcs[args[0].Index].name, shaderir.BilinearSample))
+		return nil, nil, nil, false
+	}
+	if argts[1].Main != shaderir.Vec2 {
+		cs.addError(pos, fmt.Sprintf("cannot use %s as vec2 value in argument to %s", argts[1].String(), shaderir.BilinearSample))
+		return nil, nil, nil, false
+	}
+	// With the pixel-unit mode, the texel size is 1. With the texel mode, the size of a texel is unknown here.
+	if cs.unit != shaderir.Pixels {
+		cs.addError(pos, fmt.Sprintf("%s is available only in the pixel-unit mode", shaderir.BilinearSample))
+		return nil, nil, nil, false
+	}
+
+	f := args[0]
+	t := shaderir.Type{Main: shaderir.Vec2}
+
+	// Pixel centers are at half-integer positions. p is the position relative to the pixel centers.
+	p, stmts := cs.evaluateOnce(block, binaryExpr(shaderir.Sub, args[1], builtinCall(shaderir.Vec2F, floatExpr(0.5))), t, stmts)
+	base, stmts := cs.evaluateOnce(block, binaryExpr(shaderir.Add, builtinCall(shaderir.Floor, p), builtinCall(shaderir.Vec2F, floatExpr(0.5))), t, stmts)
+	rate, stmts := cs.evaluateOnce(block, builtinCall(shaderir.Fract, p), t, stmts)
+
+	at := func(dx, dy float64) shaderir.Expr {
+		p := base
+		if dx != 0 || dy != 0 {
+			p = binaryExpr(shaderir.Add, base, builtinCall(shaderir.Vec2F, floatExpr(dx), floatExpr(dy)))
+		}
+		return shaderir.Expr{
+			Type:  shaderir.Call,
+			Exprs: []shaderir.Expr{f, p},
+		}
+	}
+	component := func(s string) shaderir.Expr {
+		return shaderir.Expr{
+			Type:  shaderir.FieldSelector,
+			Exprs: []shaderir.Expr{rate, {Type: shaderir.SwizzlingExpr, Swizzling: s}},
+		}
+	}
+
+	top := builtinCall(shaderir.Mix, at(0, 0), at(1, 0), component("x"))
+	bottom := builtinCall(shaderir.Mix, at(0, 1), at(1, 1), component("x"))
+	return []shaderir.Expr{builtinCall(shaderir.Mix, top, bottom, component("y"))}, []shaderir.Type{{Main: shaderir.Vec4}}, stmts, true
+}
+
+// aastepExpr returns an expression of an anti-aliased step function.
+// Like step(threshold, value), the result is 0 when value is less than threshold, and 1 otherwise.
+// The transition is smoothed over the width of one pixel, which is calculated by fwidth.
+// Then, the width of the transition in value scales with the gradient of value.
+func (cs *compileState) aastepExpr(block *block, pos token.Pos, args []shaderir.Expr, argts []shaderir.Type, stmts []shaderir.Stmt) ([]shaderir.Expr, []shaderir.Type, []shaderir.Stmt, bool) {
+	if len(args) != 2 {
+		cs.addError(pos, fmt.Sprintf("number of %s's arguments must be 2 but %d", shaderir.AAStep, len(args)))
+		return nil, nil, nil, false
+	}
+	for i := range args {
+		// If the argument is a non-typed constant value, treat this as a float value (#1874).
+		if args[i].Const != nil && argts[i].Main == shaderir.None && gconstant.ToFloat(args[i].Const).Kind() != gconstant.Unknown {
+			args[i].Const = gconstant.ToFloat(args[i].Const)
+			argts[i] = shaderir.Type{Main: shaderir.Float}
+		}
+		if argts[i].Main != shaderir.Float && !argts[i].IsFloatVector() {
+			cs.addError(pos, fmt.Sprintf("cannot use %s as float, vec2, vec3, or vec4 value in argument to %s", argts[i].String(), shaderir.AAStep))
+			return nil, nil, nil, false
+		}
+	}
+	if !argts[0].Equal(&argts[1]) && argts[0].Main != shaderir.Float {
+		cs.addError(pos, fmt.Sprintf("the first argument for %s must equal to the second argument %s or float but %s", shaderir.AAStep, argts[1].String(), argts[0].String()))
+		return nil, nil, nil, false
+	}
+
+	t := argts[1]
+	threshold, stmts := cs.evaluateOnce(block, args[0], argts[0], stmts)
+	value, stmts := cs.evaluateOnce(block, args[1], t, stmts)
+	// smoothstep is undefined when the edges are the same. Keep the width positive even when value is uniform.
+	width, stmts := cs.evaluateOnce(block, builtinCall(shaderir.Max, binaryExpr(shaderir.ComponentWiseMul, builtinCall(shaderir.Fwidth, value), floatExpr(0.5)), floatExpr(1e-6)), t, stmts)
+
+	lo := binaryExpr(shaderir.Sub, threshold, width)
+	hi := binaryExpr(shaderir.Add, threshold, width)
+	return []shaderir.Expr{builtinCall(shaderir.Smoothstep, lo, hi, value)}, []shaderir.Type{t}, stmts, true
+}
+
+// conversionPrecision returns the precision of a local variable declared with the value expr of type t.
+// Only a precision conversion like mediump(x) gives a precision. The precision is never inferred from other expressions like x * 2.
+func conversionPrecision(expr ast.Expr, t *shaderir.Type) shaderir.Precision {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return shaderir.PrecisionDefault
+	}
+	if ident, ok := call.Fun.(*ast.Ident); !ok || ident.Name != string(shaderir.LowpF) && ident.Name != string(shaderir.MediumpF) && ident.Name != string(shaderir.HighpF) {
+		return shaderir.PrecisionDefault
+	}
+	return t.Precision
+}
+
+// convertUntypedConstsToInt converts the untyped constants in args to int constants.
+// The constants must already be checked to be representable as integers, like 1.0.
+func convertUntypedConstsToInt(args []shaderir.Expr, argts []shaderir.Type) {
+	for i := range args {
+		if args[i].Const == nil || argts[i].Main != shaderir.None {
+			continue
+		}
+		args[i].Const = gconstant.ToInt(args[i].Const)
+		argts[i] = shaderir.Type{Main: shaderir.Int}
+	}
+}
+
+// selectExpr returns an expression of mix(x, y, a) with a bool a, that is y if a is true, or x otherwise.
+func (cs *compileState) selectExpr(pos token.Pos, args []shaderir.Expr, argts []shaderir.Type, stmts []shaderir.Stmt) ([]shaderir.Expr, []shaderir.Type, []shaderir.Stmt, bool) {
+	// A type or a function name has no type and is not a constant.
+	for i := 0; i < 2; i++ {
+		if argts[i].Main == shaderir.None && args[i].Const == nil {
+			cs.addError(pos, fmt.Sprintf("cannot use %s as value in argument to %s", argts[i].String(), shaderir.Mix))
+			return nil, nil, nil, false
+		}
+	}
+
+	// Resolve the types of untyped constants.
+	switch {
+	case argts[0].Main == shaderir.None && argts[1].Main == shaderir.None:
+		x, y, ok := shaderir.ResolveUntypedConstsForBinaryOp(args[0].Const, args[1].Const, argts[0], argts[1])
+		if !ok || x.Kind() != y.Kind() {
+			cs.addError(pos, fmt.Sprintf("%s and %s don't match in argument to %s", args[0].Const.String(), args[1].Const.String(), shaderir.Mix))
+			return nil, nil, nil, false
+		}
+		args[0].Const, args[1].Const = x, y
+		argts[0] = toDefaultType(x)
+		argts[1] = argts[0]
+	case argts[0].Main == shaderir.None:
+		if !canAssign(&argts[1], &argts[0], args[0].Const) {
+			cs.addError(pos, fmt.Sprintf("cannot use %s as %s value in argument to %s", args[0].Const.String(), argts[1].String(), shaderir.Mix))
+			return nil, nil, nil, false
+		}
+		argts[0] = argts[1]
+	case argts[1].Main == shaderir.None:
+		if !canAssign(&argts[0], &argts[1], args[1].Const) {
+			cs.addError(pos, fmt.Sprintf("cannot use %s as %s value in argument to %s", args[1].Const.String(), argts[0].String(), shaderir.Mix))
+			return nil, nil, nil, false
+		}
+		argts[1] = argts[0]
+	}
+	if !argts[0].Equal(&argts[1]) {
+		cs.addError(pos, fmt.Sprintf("%s and %s don't match in argument to %s", argts[0].String(), argts[1].String(), shaderir.Mix))
+		return nil, nil, nil, false
+	}
+	t := argts[0]
+	if t.Main == shaderir.Texture || t.Main == shaderir.Array || t.Main == shaderir.Struct {
+		cs.addError(pos, fmt.Sprintf("cannot use %s as a value to select in argument to %s", t.String(), shaderir.Mix))
+		return nil, nil, nil, false
+	}
+
+	// Fold the selection with a constant condition.
+	if args[2].Const != nil {
+		if gconstant.BoolVal(args[2].Const) {
+			return []shaderir.Expr{args[1]}, []shaderir.Type{t}, stmts, true
+		}
+		return []shaderir.Expr{args[0]}, []shaderir.Type{t}, stmts, true
+	}
+
+	return []shaderir.Expr{
+		{
+			Type:  shaderir.Selection,
+			Exprs: []shaderir.Expr{args[2], args[1], args[0]},
+		},
+	}, []shaderir.Type{t}, stmts, true
+}
+
+// selectVectorExpr returns an expression of mix(x, y, a) with a bool vector a.
+// Each component is the component of y if the component of a is true, or the component of x otherwise.
+func (cs *compileState) selectVectorExpr(block *block, pos token.Pos, args []shaderir.Expr, argts []shaderir.Type, stmts []shaderir.Stmt) ([]shaderir.Expr, []shaderir.Type, []shaderir.Stmt, bool) {
+	if !argts[0].Equal(&argts[1]) {
+		cs.addError(pos, fmt.Sprintf("%s and %s don't match in argument to %s", argts[0].String(), argts[1].String(), shaderir.Mix))
+		return nil, nil, nil, false
+	}
+	t := argts[0]
+	n := argts[2].VectorElementCount()
+	if t.VectorElementCount() != n {
+		cs.addError(pos, fmt.Sprintf("cannot use %s to select %s values in argument to %s", argts[2].String(), t.String(), shaderir.Mix))
+		return nil, nil, nil, false
+	}
+
+	var f shaderir.BuiltinFunc
+	switch t.Main {
+	case shaderir.Vec2:
+		f = shaderir.Vec2F
+	case shaderir.Vec3:
+		f = shaderir.Vec3F
+	case shaderir.Vec4:
+		f = shaderir.Vec4F
+	case shaderir.IVec2:
+		f = shaderir.IVec2F
+	case shaderir.IVec3:
+		f = shaderir.IVec3F
+	case shaderir.IVec4:
+		f = shaderir.IVec4F
+	case shaderir.BVec2:
+		f = shaderir.BVec2F
+	case shaderir.BVec3:
+		f = shaderir.BVec3F
+	case shaderir.BVec4:
+		f = shaderir.BVec4F
+	}
+
+	x, stmts := cs.evaluateOnce(block, args[0], t, stmts)
+	y, stmts := cs.evaluateOnce(block, args[1], t, stmts)
+	cond, stmts := cs.evaluateOnce(block, args[2], argts[2], stmts)
+	component := func(e shaderir.Expr, i int) shaderir.Expr {
+		return shaderir.Expr{
+			Type:  shaderir.FieldSelector,
+			Exprs: []shaderir.Expr{e, {Type: shaderir.SwizzlingExpr, Swizzling: "xyzw"[i : i+1]}},
+		}
+	}
+
+	// Select each component with a scalar condition, as the backends don't have a common way to select with a vector condition.
+	components := make([]shaderir.Expr, n)
+	for i := range components {
+		components[i] = shaderir.Expr{
+			Type:  shaderir.Selection,
+			Exprs: []shaderir.Expr{component(cond, i), component(y, i), component(x, i)},
+		}
+	}
+	return []shaderir.Expr{builtinCall(f, components...)}, []shaderir.Type{t}, stmts, true
+}
+
+// broadcastFloatArgs broadcasts the float arguments of the built-in function f to the float vector type of the other arguments.
+// The float arguments at scalarIndices are kept as they are only when all of them are floats, as GLSL has such overloads.
+// broadcastFloatArgs reports an error and returns false when the vector arguments have different types.
+func (cs *compileState) broadcastFloatArgs(pos token.Pos, f shaderir.BuiltinFunc, args []shaderir.Expr, argts []shaderir.Type, scalarIndices ...int) bool {
+	var vt shaderir.Type
+	for i := range argts {
+		if !argts[i].IsFloatVector() {
+			continue
+		}
+		if vt.Main == shaderir.None {
+			vt = argts[i]
+			continue
+		}
+		if !vt.Equal(&argts[i]) {
+			cs.addError(pos, fmt.Sprintf("%s and %s don't match in argument to %s", vt.String(), argts[i].String(), f))
+			return false
+		}
+	}
+	if vt.Main == shaderir.None {
+		return true
+	}
+
+	keepScalars := true
+	isScalarIndex := make([]bool, len(args))
+	for _, i := range scalarIndices {
+		isScalarIndex[i] = true
+		if argts[i].Main != shaderir.Float {
+			keepScalars = false
+		}
+	}
+	for i := range args {
+		if argts[i].Main != shaderir.Float {
+			continue
+		}
+		if keepScalars && isScalarIndex[i] {
+			continue
+		}
+		args[i] = broadcastFloat(args[i], vt)
+		argts[i] = vt
+	}
+	return true
+}
+
+// broadcastFloat returns an expression of the float value e broadcast to the float vector type t, like vec3(e).
+func broadcastFloat(e shaderir.Expr, t shaderir.Type) shaderir.Expr {
+	var f shaderir.BuiltinFunc
+	switch t.Main {
+	case shaderir.Vec2:
+		f = shaderir.Vec2F
+	case shaderir.Vec3:
+		f = shaderir.Vec3F
+	case shaderir.Vec4:
+		f = shaderir.Vec4F
+	}
+	return builtinCall(f, e)
+}
+
+func builtinCall(f shaderir.BuiltinFunc, args ...shaderir.Expr) shaderir.Expr {
+	return shaderir.Expr{
+		Type: shaderir.Call,
+		Exprs: append([]shaderir.Expr{
+			{
+				Type:        shaderir.BuiltinFuncExpr,
+				BuiltinFunc: f,
+			},
+		}, args...),
+	}
+}
+
+func binaryExpr(op shaderir.Op, lhs, rhs shaderir.Expr) shaderir.Expr {
+	return shaderir.Expr{
+		Type:  shaderir.Binary,
+		Op:    op,
+		Exprs: []shaderir.Expr{lhs, rhs},
+	}
+}
+
+func floatExpr(v float64) shaderir.Expr {
+	return shaderir.Expr{
+		Type:  shaderir.NumberExpr,
+		Const: gconstant.MakeFloat64(v),
+	}
+}
+
+// randExpr returns an expression of a pseudo-random value in [0, 1) for the given vec2 or vec3 value.
+// This is the common hash fract(sin(dot(p, seed)) * 43758.5453), and returns the same values for the same p.
+func (cs *compileState) randExpr(pos token.Pos, arg shaderir.Expr, argt shaderir.Type, stmts []shaderir.Stmt) ([]shaderir.Expr, []shaderir.Type, []shaderir.Stmt, bool) {
+	var seed shaderir.Expr
+	switch argt.Main {
+	case shaderir.Vec2:
+		seed = builtinCall(shaderir.Vec2F, floatExpr(12.9898), floatExpr(78.233))
+	case shaderir.Vec3:
+		seed = builtinCall(shaderir.Vec3F, floatExpr(12.9898), floatExpr(78.233), floatExpr(37.719))
+	default:
+		cs.addError(pos, fmt.Sprintf("cannot use %s as vec2 or vec3 value in argument to %s", argt.String(), shaderir.Rand))
+		return nil, nil, nil, false
+	}
+
+	sin := builtinCall(shaderir.Sin, builtinCall(shaderir.Dot, arg, seed))
+	return []shaderir.Expr{
+		builtinCall(shaderir.Fract, binaryExpr(shaderir.ComponentWiseMul, sin, floatExpr(43758.5453))),
+	}, []shaderir.Type{{Main: shaderir.Float}}, stmts, true
+}
+
+func linearToSRGB(c float64) float64 {
+	if c <= 0.0031308 {
+		return c * 12.92
+	}
+	return 1.055*math.Pow(c, 1/2.4) - 0.055
+}
+
+func srgbToLinear(c float64) float64 {
+	if c <= 0.04045 {
+		return c / 12.92
+	}
+	return math.Pow((c+0.055)/1.055, 2.4)
+}
+
+// colorSpaceExpr returns an expression converting the given vec3 or vec4 color between linear and sRGB.
+// The alpha value of a vec4 color is kept as it is.
+func (cs *compileState) colorSpaceExpr(block *block, pos token.Pos, f shaderir.BuiltinFunc, arg shaderir.Expr, argt shaderir.Type, stmts []shaderir.Stmt) ([]shaderir.Expr, []shaderir.Type, []shaderir.Stmt, bool) {
+	if argt.Main != shaderir.Vec3 && argt.Main != shaderir.Vec4 {
+		cs.addError(pos, fmt.Sprintf("cannot use %s as vec3 or vec4 value in argument to %s", argt.String(), f))
+		return nil, nil, nil, false
+	}
+
+	// Fold a constant color.
+	if isConstantConstructorCall(&arg) && len(arg.Exprs) == argt.VectorElementCount()+1 {
+		expr := arg
+		expr.Exprs = make([]shaderir.Expr, len(arg.Exprs))
+		copy(expr.Exprs, arg.Exprs)
+		for i := 1; i <= 3; i++ {
+			v, _ := gconstant.Float64Val(gconstant.ToFloat(expr.Exprs[i].Const))
+			if f == shaderir.LinearToSRGB {
+				v = linearToSRGB(v)
+			} else {
+				v = srgbToLinear(v)
+			}
+			expr.Exprs[i] = floatExpr(v)
+		}
+		return []shaderir.Expr{expr}, []shaderir.Type{argt}, stmts, true
+	}
+
+	// The argument is referred to multiple times. Evaluate it only once.
+	arg, stmts = cs.evaluateOnce(block, arg, argt, stmts)
+
+	c := arg
+	if argt.Main == shaderir.Vec4 {
+		c = shaderir.Expr{
+			Type:  shaderir.FieldSelector,
+			Exprs: []shaderir.Expr{arg, {Type: shaderir.SwizzlingExpr, Swizzling: "rgb"}},
+		}
+	}
+
+	var lo, hi, threshold shaderir.Expr
+	if f == shaderir.LinearToSRGB {
+		// c <= 0.0031308 ? c * 12.92 : 1.055 * pow(c, 1/2.4) - 0.055
+		lo = binaryExpr(shaderir.ComponentWiseMul, c, floatExpr(12.92))
+		pow := builtinCall(shaderir.Pow, builtinCall(shaderir.Max, c, builtinCall(shaderir.Vec3F, floatExpr(0))), builtinCall(shaderir.Vec3F, floatExpr(1/2.4)))
+		hi = binaryExpr(shaderir.Sub, binaryExpr(shaderir.ComponentWiseMul, floatExpr(1.055), pow), floatExpr(0.055))
+		threshold = floatExpr(0.0031308)
+	} else {
+		// c <= 0.04045 ? c / 12.92 : pow((c + 0.055) / 1.055, 2.4)
+		lo = binaryExpr(shaderir.Div, c, floatExpr(12.92))
+		base := binaryExpr(shaderir.Div, binaryExpr(shaderir.Add, builtinCall(shaderir.Max, c, builtinCall(shaderir.Vec3F, floatExpr(0))), floatExpr(0.055)), floatExpr(1.055))
+		hi = builtinCall(shaderir.Pow, base, builtinCall(shaderir.Vec3F, floatExpr(2.4)))
+		threshold = floatExpr(0.04045)
+	}
+	// step(c, threshold) is 1 when c <= threshold, and 0 otherwise.
+	rgb := builtinCall(shaderir.Mix, hi, lo, builtinCall(shaderir.Step, c, builtinCall(shaderir.Vec3F, threshold)))
+
+	if argt.Main == shaderir.Vec4 {
+		a := shaderir.Expr{
+			Type:  shaderir.FieldSelector,
+			Exprs: []shaderir.Expr{arg, {Type: shaderir.SwizzlingExpr, Swizzling: "a"}},
+		}
+		return []shaderir.Expr{builtinCall(shaderir.Vec4F, rgb, a)}, []shaderir.Type{argt}, stmts, true
+	}
+	return []shaderir.Expr{rgb}, []shaderir.Type{argt}, stmts, true
+}
+
+// simplifyIdentity returns a simplified expression for a binary operation with an identity or absorbing element,
+// like x*1, x+0, x-0, and x/1. t is the type of the result.
+// x*0 is simplified to 0 only for integers unless FastMath is specified, as x*0 is NaN when x is NaN or infinity.
+func (cs *compileState) simplifyIdentity(op shaderir.Op, lhs, rhs *shaderir.Expr, lhst, rhst, t *shaderir.Type) (shaderir.Expr, bool) {
+	isConst := func(expr *shaderir.Expr, v int64) bool {
+		if expr.Const == nil || expr.Const.Kind() == gconstant.Bool {
+			return false
+		}
+		return gconstant.Compare(expr.Const, token.EQL, gconstant.MakeInt64(v))
+	}
+
+	switch op {
+	case shaderir.Add:
+		if isConst(rhs, 0) && lhst.Equal(t) {
+			return *lhs, true
+		}
+		if isConst(lhs, 0) && rhst.Equal(t) {
+			return *rhs, true
+		}
+	case shaderir.Sub:
+		if isConst(rhs, 0) && lhst.Equal(t) {
+			return *lhs, true
+		}
+	case shaderir.ComponentWiseMul, shaderir.MatrixMul:
+		if isConst(rhs, 1) && lhst.Equal(t) {
+			return *lhs, true
+		}
+		if isConst(lhs, 1) && rhst.Equal(t) {
+			return *rhs, true
+		}
+		// The other operand is dropped, so keep it when it might have side effects.
+		if isConst(lhs, 0) && !hasSideEffects(rhs) || isConst(rhs, 0) && !hasSideEffects(lhs) {
+			isInt := t.Main == shaderir.Int || t.IsIntVector()
+			if isInt || cs.options.FastMath {
+				return zeroExpr(t), true
+			}
+		}
+	case shaderir.Div:
+		if isConst(rhs, 1) && lhst.Equal(t) {
+			return *lhs, true
+		}
+	}
+	return shaderir.Expr{}, false
+}
+
+// hasSideEffects reports whether evaluating expr might have side effects.
+// A user-defined function might assign variables or discard the fragment, while built-in functions don't.
+func hasSideEffects(expr *shaderir.Expr) bool {
+	if expr.Type == shaderir.Call && expr.Exprs[0].Type == shaderir.FunctionExpr {
+		return true
+	}
+	for i := range expr.Exprs {
+		if hasSideEffects(&expr.Exprs[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// zeroExpr returns an expression of the zero value of the numeric type t.
+func zeroExpr(t *shaderir.Type) shaderir.Expr {
+	var f shaderir.BuiltinFunc
+	switch t.Main {
+	case shaderir.Int:
+		return shaderir.Expr{
+			Type:  shaderir.NumberExpr,
+			Const: gconstant.MakeInt64(0),
+		}
+	case shaderir.Float:
+		return floatExpr(0)
+	case shaderir.Vec2:
+		f = shaderir.Vec2F
+	case shaderir.Vec3:
+		f = shaderir.Vec3F
+	case shaderir.Vec4:
+		f = shaderir.Vec4F
+	case shaderir.IVec2:
+		f = shaderir.IVec2F
+	case shaderir.IVec3:
+		f = shaderir.IVec3F
+	case shaderir.IVec4:
+		f = shaderir.IVec4F
+	case shaderir.Mat2:
+		f = shaderir.Mat2F
+	case shaderir.Mat3:
+		f = shaderir.Mat3F
+	case shaderir.Mat4:
+		f = shaderir.Mat4F
+	default:
+		panic(fmt.Sprintf("shader: unexpected type for a zero value: %s", t.String()))
+	}
+	if t.IsIntVector() {
+		return builtinCall(f, shaderir.Expr{
+			Type:  shaderir.NumberExpr,
+			Const: gconstant.MakeInt64(0),
+		})
+	}
+	return builtinCall(f, floatExpr(0))
+}
+
+// bitwiseComplement returns an expression of the bitwise complement of the integer expression expr.
+// The complement is represented as expr ^ -1 since there is no unary operator for this in IR.
+func bitwiseComplement(expr shaderir.Expr) shaderir.Expr {
+	if expr.Const != nil {
+		return shaderir.Expr{
+			Type:  shaderir.NumberExpr,
+			Const: gconstant.UnaryOp(token.XOR, expr.Const, 0),
+		}
+	}
+	return binaryExpr(shaderir.Xor, expr, shaderir.Expr{
+		Type:  shaderir.NumberExpr,
+		Const: gconstant.MakeInt64(-1),
+	})
+}
+
+// isConstantZero reports whether expr is a constant zero or a vector constructor call with only constant zeros.
+func isConstantZero(expr *shaderir.Expr) bool {
+	if expr.Const != nil {
+		return gconstant.Sign(expr.Const) == 0
+	}
+	if !isConstantConstructorCall(expr) {
+		return false
+	}
+	for _, arg := range expr.Exprs[1:] {
+		if gconstant.Sign(arg.Const) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// sameDimensionArgCounts is the number of the leading arguments that must have the same dimension for a built-in function.
+var sameDimensionArgCounts = map[shaderir.BuiltinFunc]int{
+	shaderir.Distance:    2,
+	shaderir.Dot:         2,
+	shaderir.Reflect:     2,
+	shaderir.Refract:     2,
+	shaderir.Faceforward: 3,
+}
+
+// checkArgDimensions returns an error if the leading arguments of the built-in function f have different dimensions.
+func checkArgDimensions(f shaderir.BuiltinFunc, argts []shaderir.Type) error {
+	n := sameDimensionArgCounts[f]
+	for i := 1; i < n && i < len(argts); i++ {
+		if !argts[0].Equal(&argts[i]) {
+			return fmt.Errorf("%s requires matching dimensions, got %s and %s", f, argts[0].String(), argts[i].String())
+		}
+	}
+	return nil
+}
+
+// isTransposeCall reports whether expr is a call of transpose.
+func isTransposeCall(expr *shaderir.Expr) bool {
+	if expr.Type != shaderir.Call || len(expr.Exprs) != 2 {
+		return false
+	}
+	return expr.Exprs[0].Type == shaderir.BuiltinFuncExpr && expr.Exprs[0].BuiltinFunc == shaderir.Transpose
+}
+
+// constantFloatArgs returns the values of args if all of them are float constants.
+func constantFloatArgs(args []shaderir.Expr, argts []shaderir.Type) ([]float64, bool) {
+	vs := make([]float64, len(args))
+	for i := range args {
+		if args[i].Type != shaderir.NumberExpr || args[i].Const == nil || argts[i].Main != shaderir.Float {
+			return nil, false
+		}
+		v, _ := gconstant.Float64Val(gconstant.ToFloat(args[i].Const))
+		vs[i] = v
+	}
+	return vs, true
+}
+
+// foldBuiltinCall evaluates the built-in function f with the constant arguments args at compile time.
+// foldBuiltinCall returns false if f cannot be evaluated at compile time,
+// or if the result cannot be represented exactly as a float32 value, e.g. sin(0.1) or exp(100.0).
+// Such a result is left to the GPU so that the folded value never differs from the evaluated one.
+// foldBuiltinCall returns an error if the result is undefined, e.g. sqrt of a negative value.
+func foldBuiltinCall(f shaderir.BuiltinFunc, args []float64) (float64, bool, error) {
+	undefined := func() (float64, bool, error) {
+		strs := make([]string, len(args))
+		for i, a := range args {
+			strs[i] = strconv.FormatFloat(a, 'g', -1, 64)
+		}
+		return 0, false, fmt.Errorf("%s(%s) is undefined", f, strings.Join(strs, ", "))
+	}
+
+	var v float64
+	switch f {
+	case shaderir.Radians:
+		v = args[0] * math.Pi / 180
+	case shaderir.Degrees:
+		v = args[0] * 180 / math.Pi
+	case shaderir.Sin:
+		v = math.Sin(args[0])
+	case shaderir.Cos:
+		v = math.Cos(args[0])
+	case shaderir.Tan:
+		v = math.Tan(args[0])
+	case shaderir.Asin:
+		if math.Abs(args[0]) > 1 {
+			return undefined()
+		}
+		v = math.Asin(args[0])
+	case shaderir.Acos:
+		if math.Abs(args[0]) > 1 {
+			return undefined()
+		}
+		v = math.Acos(args[0])
+	case shaderir.Atan:
+		v = math.Atan(args[0])
+	case shaderir.Atan2:
+		if args[0] == 0 && args[1] == 0 {
+			return undefined()
+		}
+		v = math.Atan2(args[0], args[1])
+	case shaderir.Pow:
+		if args[0] < 0 || (args[0] == 0 && args[1] <= 0) {
+			return undefined()
+		}
+		v = math.Pow(args[0], args[1])
+	case shaderir.Exp:
+		v = math.Exp(args[0])
+	case shaderir.Log:
+		if args[0] <= 0 {
+			return undefined()
+		}
+		v = math.Log(args[0])
+	case shaderir.Exp2:
+		v = math.Exp2(args[0])
+	case shaderir.Log2:
+		if args[0] <= 0 {
+			return undefined()
+		}
+		v = math.Log2(args[0])
+	case shaderir.Sqrt:
+		if args[0] < 0 {
+			return undefined()
+		}
+		v = math.Sqrt(args[0])
+	case shaderir.Inversesqrt:
+		if args[0] <= 0 {
+			return undefined()
+		}
+		v = 1 / math.Sqrt(args[0])
+	case shaderir.Abs:
+		v = math.Abs(args[0])
+	case shaderir.Sign:
+		switch {
+		case args[0] > 0:
+			v = 1
+		case args[0] < 0:
+			v = -1
+		}
+	case shaderir.Floor:
+		v = math.Floor(args[0])
+	case shaderir.Ceil:
+		v = math.Ceil(args[0])
+	case shaderir.Fract:
+		v = args[0] - math.Floor(args[0])
+	case shaderir.Mod:
+		if args[1] == 0 {
+			return undefined()
+		}
+		v = args[0] - args[1]*math.Floor(args[0]/args[1])
+	case shaderir.Min:
+		v = math.Min(args[0], args[1])
+	case shaderir.Max:
+		v = math.Max(args[0], args[1])
+	case shaderir.Clamp:
+		if args[1] > args[2] {
+			return undefined()
+		}
+		v = math.Min(math.Max(args[0], args[1]), args[2])
+	case shaderir.Step:
+		if args[1] >= args[0] {
+			v = 1
+		}
+	default:
+		return 0, false, nil
+	}
+	if math.IsInf(v, 0) || float64(float32(v)) != v {
+		return 0, false, nil
+	}
+	return v, true, nil
+}
+
+// hasConstantZero reports whether expr is a constant zero, or a constant vector with a zero component like vec2(1, 0).
+func hasConstantZero(expr *shaderir.Expr) bool {
+	if expr.Type == shaderir.NumberExpr {
+		return expr.Const != nil && expr.Const.Kind() != gconstant.Bool && gconstant.Sign(expr.Const) == 0
+	}
+	if !isConstantConstructorCall(expr) {
+		return false
+	}
+	for i := 1; i < len(expr.Exprs); i++ {
+		if hasConstantZero(&expr.Exprs[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// isConstantConstructorCall reports whether expr is a call of a vector or matrix constructor with only constant arguments.
+func isConstantConstructorCall(expr *shaderir.Expr) bool {
+	if expr.Type != shaderir.Call {
+		return false
+	}
+	if expr.Exprs[0].Type != shaderir.BuiltinFuncExpr {
+		return false
+	}
+	switch expr.Exprs[0].BuiltinFunc {
+	case shaderir.Vec2F, shaderir.Vec3F, shaderir.Vec4F, shaderir.IVec2F, shaderir.IVec3F, shaderir.IVec4F, shaderir.Mat2F, shaderir.Mat3F, shaderir.Mat4F:
+	default:
+		return false
+	}
+	for _, arg := range expr.Exprs[1:] {
+		if arg.Type != shaderir.NumberExpr {
+			return false
+		}
+	}
+	return true
+}
+
+// boolVectorType returns the boolean vector type with n components.
+func boolVectorType(n int) shaderir.Type {
+	switch n {
+	case 2:
+		return shaderir.Type{Main: shaderir.BVec2}
+	case 3:
+		return shaderir.Type{Main: shaderir.BVec3}
+	case 4:
+		return shaderir.Type{Main: shaderir.BVec4}
+	}
+	return shaderir.Type{}
+}
+
+// narrowVector returns the expression and the type of the first n components of the vector expr of type t.
+func narrowVector(expr shaderir.Expr, t shaderir.Type, n int) (shaderir.Expr, shaderir.Type) {
+	var nt shaderir.Type
+	switch {
+	case t.IsFloatVector():
+		nt.Main = []shaderir.BasicType{shaderir.Vec2, shaderir.Vec3}[n-2]
+	case t.IsIntVector():
+		nt.Main = []shaderir.BasicType{shaderir.IVec2, shaderir.IVec3}[n-2]
+	}
+	return shaderir.Expr{
+		Type:  shaderir.FieldSelector,
+		Exprs: []shaderir.Expr{expr, {Type: shaderir.SwizzlingExpr, Swizzling: "xyzw"[:n]}},
+	}, nt
+}
+
+// isBoolOperand reports whether an operand of type t and constant value c is a boolean.
+func isBoolOperand(t shaderir.Type, c gconstant.Value) bool {
+	if t.Main == shaderir.Bool {
+		return true
+	}
+	return t.Main == shaderir.None && c != nil && c.Kind() == gconstant.Bool
+}
+
+// operandTypeString returns the type name of an operand for error messages.
+// For an untyped constant, this returns a name like "untyped int constant".
+// isIntOperand reports whether t is an integer scalar or an integer vector.
+func isIntOperand(t shaderir.Type) bool {
+	return t.Main == shaderir.Int || t.IsIntVector()
+}
+
+// isFloatOperand reports whether t is a float scalar, a float vector, or a matrix.
+func isFloatOperand(t shaderir.Type) bool {
+	return t.Main == shaderir.Float || t.IsFloatVector() || t.IsMatrix()
+}
+
+func operandTypeString(t shaderir.Type, c gconstant.Value) string {
+	if t.Main != shaderir.None || c == nil {
+		return t.String()
+	}
+	switch c.Kind() {
+	case gconstant.Bool:
+		return "untyped bool constant"
+	case gconstant.Int:
+		return "untyped int constant"
+	case gconstant.Float:
+		return "untyped float constant"
+	}
+	return t.String()
+}
+
 func isValidSwizzling(swizzling string, t shaderir.Type) bool {
 	if !shaderir.IsValidSwizzling(swizzling) {
 		return false
 	}
 
 	switch t.Main {
-	case shaderir.Vec2, shaderir.IVec2:
+	case shaderir.Vec2, shaderir.IVec2, shaderir.BVec2:
 		return !strings.ContainsAny(swizzling, "zwbapq")
-	case shaderir.Vec3, shaderir.IVec3:
+	case shaderir.Vec3, shaderir.IVec3, shaderir.BVec3:
 		return !strings.ContainsAny(swizzling, "waq")
-	case shaderir.Vec4, shaderir.IVec4:
+	case shaderir.Vec4, shaderir.IVec4, shaderir.BVec4:
 		return true
 	default:
 		return false
@@ -21,6 +21,7 @@ import (
 	"go/ast"
 	gconstant "go/constant"
 	"go/parser"
+	"go/scanner"
 	"go/token"
 	"regexp"
 	"strings"
@@ -32,6 +33,14 @@ type variable struct {
 	name           string
 	typ            shaderir.Type
 	forLoopCounter bool
+	pos            token.Pos
+
+	// discarded reports whether the variable is explicitly discarded with the blank identifier, e.g. _ = x.
+	discarded bool
+
+	// invalid reports whether the variable is a placeholder for a variable whose declaration failed.
+	// The error is already reported at the declaration, so a use of the variable fails without another error.
+	invalid bool
 }
 
 type constant struct {
@@ -40,6 +49,12 @@ type constant struct {
 	value gconstant.Value
 }
 
+// fragmentOnlyFeature is a use of a feature available only for fragments, like discard.
+type fragmentOnlyFeature struct {
+	name string
+	pos  token.Pos
+}
+
 type function struct {
 	name string
 
@@ -55,13 +70,46 @@ type compileState struct {
 
 	ir shaderir.Program
 
+	// uniformPositions is the positions of the declared uniform variables by their names.
+	uniformPositions map[string]token.Pos
+
+	// attributes is the vertex attributes declared by //kage:attribute directives.
+	// These are available in the vertex entry point after its parameters.
+	attributes []variable
+
 	funcs []function
 
 	global block
 
 	varyingParsed bool
 
-	errs []string
+	// fragmentVaryings is the parameters of the fragment entry point for the varyings.
+	fragmentVaryings []variable
+
+	// vertexAttributes is the parameters of the vertex entry point and the declared attributes.
+	vertexAttributes []variable
+
+	// iota is the value of iota in the constant declaration being parsed.
+	// iota is available only when inConstDecl is true.
+	iota        int64
+	inConstDecl bool
+
+	// loops is the stack of the for-loops enclosing the statement being parsed.
+	loops []loop
+
+	// blockVars is the local variables declared in each block, in the same order as the block's LocalVars.
+	blockVars map[*shaderir.Block][]variable
+
+	// fragmentOnlyFeatures is the first use of a fragment-only feature like discard in each function other than the entry points.
+	fragmentOnlyFeatures map[string]fragmentOnlyFeature
+
+	errs []Error
+
+	// badNodes is the number of the nodes with syntax errors skipped in the recovery mode.
+	// The parser already reported their errors.
+	badNodes int
+
+	options *CompileOptions
 }
 
 func (cs *compileState) findFunction(name string) (int, bool) {
@@ -98,6 +146,43 @@ type block struct {
 	ir *shaderir.Block
 }
 
+// warnUnusedVaryings reports the varyings that the vertex entry point passes but the fragment entry point never uses.
+func (cs *compileState) warnUnusedVaryings() {
+	// Without the vertex entry point in the source, the varyings are the ones the default vertex shader passes.
+	if cs.ir.VertexFunc.Block == nil || cs.ir.FragmentFunc.Block == nil {
+		return
+	}
+
+	for _, idx := range cs.ir.UnusedVaryings() {
+		v := cs.fragmentVaryings[idx]
+		if v.name == "_" {
+			continue
+		}
+		cs.addWarning(v.pos, fmt.Sprintf("varying %s is passed from the vertex entry point but not used", v.name))
+	}
+}
+
+// warnUnusedAttributes reports the vertex attributes that the vertex entry point never uses.
+// An attribute can be kept unused by naming the parameter _.
+func (cs *compileState) warnUnusedAttributes() {
+	for _, idx := range cs.ir.UnusedAttributes() {
+		v := cs.vertexAttributes[idx]
+		if v.name == "_" {
+			continue
+		}
+		cs.addWarning(v.pos, fmt.Sprintf("attribute %s is not used", v.name))
+	}
+}
+
+// isValidVaryingType reports whether t can be passed from the vertex entry point to the fragment entry point.
+func isValidVaryingType(t shaderir.Type) bool {
+	switch t.Main {
+	case shaderir.Float, shaderir.Vec2, shaderir.Vec3, shaderir.Vec4:
+		return true
+	}
+	return false
+}
+
 func (b *block) totalLocalVariableCount() int {
 	c := len(b.vars)
 	if b.outer != nil {
@@ -110,6 +195,7 @@ func (b *block) addNamedLocalVariable(name string, typ shaderir.Type, pos token.
 	b.vars = append(b.vars, variable{
 		name: name,
 		typ:  typ,
+		pos:  pos,
 	})
 	if name == "_" {
 		return
@@ -121,6 +207,26 @@ func (b *block) addNamedLocalVariable(name string, typ shaderir.Type, pos token.
 	b.unusedVars[idx] = pos
 }
 
+// discardLocalVariables marks the local variables that e refers to as discarded.
+func (b *block) discardLocalVariables(e *shaderir.Expr) {
+	for i := range e.Exprs {
+		b.discardLocalVariables(&e.Exprs[i])
+	}
+	if e.Type != shaderir.LocalVariable {
+		return
+	}
+	for b := b; b != nil; b = b.outer {
+		offset := 0
+		for outer := b.outer; outer != nil; outer = outer.outer {
+			offset += len(outer.vars)
+		}
+		if idx := e.Index - offset; idx >= 0 && idx < len(b.vars) {
+			b.vars[idx].discarded = true
+			return
+		}
+	}
+}
+
 func (b *block) findLocalVariable(name string, markLocalVariableUsed bool) (int, shaderir.Type, bool) {
 	if name == "" || name == "_" {
 		panic("shader: variable name must be non-empty and non-underscore")
@@ -144,6 +250,61 @@ func (b *block) findLocalVariable(name string, markLocalVariableUsed bool) (int,
 	return 0, shaderir.Type{}, false
 }
 
+// isInvalidLocalVariable reports whether the local variable name is a placeholder for a failed declaration.
+func (b *block) isInvalidLocalVariable(name string) bool {
+	for _, v := range b.vars {
+		if v.name == name {
+			return v.invalid
+		}
+	}
+	if b.outer != nil {
+		return b.outer.isInvalidLocalVariable(name)
+	}
+	return false
+}
+
+// addInvalidLocalVariables adds placeholders for the local variables that the failed statement stmt declares.
+// The variables already declared in b are kept as they are.
+func (b *block) addInvalidLocalVariables(stmt ast.Stmt) {
+	var names []*ast.Ident
+	switch stmt := stmt.(type) {
+	case *ast.AssignStmt:
+		if stmt.Tok != token.DEFINE {
+			return
+		}
+		for _, e := range stmt.Lhs {
+			if ident, ok := e.(*ast.Ident); ok {
+				names = append(names, ident)
+			}
+		}
+	case *ast.DeclStmt:
+		d, ok := stmt.Decl.(*ast.GenDecl)
+		if !ok || d.Tok != token.VAR {
+			return
+		}
+		for _, s := range d.Specs {
+			names = append(names, s.(*ast.ValueSpec).Names...)
+		}
+	}
+
+names:
+	for _, n := range names {
+		if n.Name == "_" {
+			continue
+		}
+		for _, v := range b.vars {
+			if v.name == n.Name {
+				continue names
+			}
+		}
+		b.vars = append(b.vars, variable{
+			name:    n.Name,
+			pos:     n.Pos(),
+			invalid: true,
+		})
+	}
+}
+
 func (b *block) findLocalVariableByIndex(idx int) (shaderir.Type, bool) {
 	bs := []*block{b}
 	for outer := b.outer; outer != nil; outer = outer.outer {
@@ -176,24 +337,154 @@ func (b *block) findConstant(name string) (constant, bool) {
 	return constant{}, false
 }
 
+func (b *block) findType(name string) (shaderir.Type, bool) {
+	for _, t := range b.types {
+		if t.name == name {
+			return t.ir, true
+		}
+	}
+	if b.outer != nil {
+		return b.outer.findType(name)
+	}
+
+	return shaderir.Type{}, false
+}
+
+// Error is an error at a position in a shader program.
+type Error struct {
+	// Line and Column are the 1-based position of the error.
+	// Line and Column are 0 when the position is unknown.
+	Line   int
+	Column int
+
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Line == 0 {
+		return e.Message
+	}
+	if e.Column == 0 {
+		return fmt.Sprintf("%d: %s", e.Line, e.Message)
+	}
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Message)
+}
+
+func newError(pos token.Position, msg string) Error {
+	if !pos.IsValid() {
+		return Error{Message: msg}
+	}
+	return Error{
+		Line:    pos.Line,
+		Column:  pos.Column,
+		Message: msg,
+	}
+}
+
+// ParseError is the errors in a shader program.
 type ParseError struct {
-	errs []string
+	errs []Error
 }
 
 func (p *ParseError) Error() string {
-	return strings.Join(p.errs, "\n")
+	strs := make([]string, 0, len(p.errs))
+	for _, e := range p.errs {
+		strs = append(strs, e.Error())
+	}
+	return strings.Join(strs, "\n")
+}
+
+// Errors returns the errors with their positions, e.g. to show them as diagnostics in editors.
+func (p *ParseError) Errors() []Error {
+	return append([]Error(nil), p.errs...)
+}
+
+// Unwrap returns the errors as *Error values.
+// With Go 1.20 or later, errors.As can find an *Error in a ParseError.
+func (p *ParseError) Unwrap() []error {
+	errs := make([]error, 0, len(p.errs))
+	for i := range p.errs {
+		errs = append(errs, &p.errs[i])
+	}
+	return errs
+}
+
+// CompileOptions represents options for CompileWithOptions.
+type CompileOptions struct {
+	// Warn is called for each warning the compiler reports.
+	// Warnings don't make the compilation fail.
+	// If Warn is nil, warnings are ignored.
+	Warn func(msg string)
+
+	// DisableAutoTime disables the implicit declaration of the time uniform variable shaderir.TimeUniformName.
+	DisableAutoTime bool
+
+	// Optimize enables optimizations that rewrite expressions into equivalent but cheaper ones.
+	Optimize bool
+
+	// FastMath enables optimizations that are not valid for NaN or infinity, e.g. x*0 to 0.
+	// FastMath is effective only when Optimize is true.
+	FastMath bool
+
+	// UnrollLoops is the maximum number of iterations of a for-loop with constant bounds to be unrolled.
+	// A loop with more iterations, or a loop with break or continue, is kept as a loop.
+	// If UnrollLoops is 0, no loops are unrolled.
+	UnrollLoops int
+
+	// Recover makes the compiler continue after errors as far as possible, e.g. for editor tooling.
+	// With Recover, CompileWithOptions returns a best-effort partial program along with the errors.
+	// The statements and declarations with errors are omitted from the partial program.
+	// A partial program must not be passed to any backends.
+	Recover bool
+
+	// AllowedUnusedUniforms is the names of uniform variables that are not reported as unused.
+	// This is useful for uniform variables declared only to keep the layout of uniform variables stable.
+	AllowedUnusedUniforms []string
+
+	// Flags is the set of flags for //kage:if directives.
+	// The lines between //kage:if name and //kage:endif are compiled only when Flags[name] is true.
+	Flags map[string]bool
 }
 
 func Compile(src []byte, vertexEntry, fragmentEntry string, textureCount int) (*shaderir.Program, error) {
+	return CompileWithOptions(src, vertexEntry, fragmentEntry, textureCount, nil)
+}
+
+func CompileWithOptions(src []byte, vertexEntry, fragmentEntry string, textureCount int, options *CompileOptions) (*shaderir.Program, error) {
+	if options == nil {
+		options = &CompileOptions{}
+	}
+
+	src, err := preprocess(src, options.Flags)
+	if err != nil {
+		return nil, err
+	}
+
 	unit, err := ParseCompilerDirectives(src)
 	if err != nil {
 		return nil, err
 	}
+	features, err := parseRequiredFeatures(src)
+	if err != nil {
+		return nil, err
+	}
+	floatPrecision, intPrecision, err := parsePrecisions(src)
+	if err != nil {
+		return nil, err
+	}
+	attributes, err := parseAttributes(src)
+	if err != nil {
+		return nil, err
+	}
 
 	fs := token.NewFileSet()
 	f, err := parser.ParseFile(fs, "", src, parser.AllErrors)
 	if err != nil {
-		return nil, err
+		err = adjustParserError(err)
+		// The parser returns a partial AST with errors. Continue with it in the recovery mode.
+		if !options.Recover || f == nil {
+			return nil, &ParseError{parserErrors(err)}
+		}
 	}
 
 	s := &compileState{
@@ -201,12 +492,14 @@ func Compile(src []byte, vertexEntry, fragmentEntry string, textureCount int) (*
 		vertexEntry:   vertexEntry,
 		fragmentEntry: fragmentEntry,
 		unit:          unit,
+		attributes:    attributes,
+		options:       options,
 	}
 	s.global.ir = &shaderir.Block{}
 	s.parse(f)
 
-	if len(s.errs) > 0 {
-		return nil, &ParseError{s.errs}
+	if len(attributes) > 0 && s.ir.VertexFunc.Block == nil && len(s.errs) == 0 {
+		return nil, fmt.Errorf("shader: //kage:attribute requires the vertex entry point %s", vertexEntry)
 	}
 
 	// TODO: Resolve identifiers?
@@ -215,9 +508,162 @@ func Compile(src []byte, vertexEntry, fragmentEntry string, textureCount int) (*
 	// TODO: Make a call graph and reorder the elements.
 
 	s.ir.TextureCount = textureCount
+	s.ir.RequiredFeatures = features
+	s.ir.FloatPrecision = floatPrecision
+	s.ir.IntPrecision = intPrecision
+
+	if err != nil || len(s.errs) > 0 {
+		var errs []Error
+		if err != nil {
+			errs = append(errs, parserErrors(err)...)
+		}
+		errs = append(errs, s.errs...)
+		if options.Recover {
+			return &s.ir, &ParseError{errs}
+		}
+		return nil, &ParseError{errs}
+	}
+
+	s.warnUnusedUniformVariables()
+	s.warnUnusedAttributes()
+	s.warnUnusedVaryings()
+
 	return &s.ir, nil
 }
 
+// warnUnusedUniformVariables reports the uniform variables that are used in neither entry point.
+func (cs *compileState) warnUnusedUniformVariables() {
+	// Without entry points, nothing can use uniform variables.
+	if cs.ir.VertexFunc.Block == nil && cs.ir.FragmentFunc.Block == nil {
+		return
+	}
+
+	for _, idx := range cs.ir.UnusedUniformVariables() {
+		name := cs.ir.UniformNames[idx]
+		// Internal uniform variables like the ones added by Ebitengine might not be used.
+		if strings.HasPrefix(name, "__") {
+			continue
+		}
+		var allowed bool
+		for _, n := range cs.options.AllowedUnusedUniforms {
+			if n == name {
+				allowed = true
+				break
+			}
+		}
+		if allowed {
+			continue
+		}
+		// An implicit uniform variable like Time is not declared.
+		pos, ok := cs.uniformPositions[name]
+		if !ok {
+			continue
+		}
+		cs.addWarning(pos, fmt.Sprintf("uniform variable %s is not used", name))
+	}
+}
+
+// preprocess processes //kage:if, //kage:else, and //kage:endif directives.
+// The lines excluded by the directives are replaced with empty lines so that the positions in error messages are kept.
+func preprocess(src []byte, flags map[string]bool) ([]byte, error) {
+	if !bytes.Contains(src, []byte("//kage:")) {
+		return src, nil
+	}
+
+	reIf := regexp.MustCompile(`^[ \t]*//kage:if\s+(!?)([A-Za-z_][A-Za-z0-9_]*)[ \t\r]*$`)
+	reElse := regexp.MustCompile(`^[ \t]*//kage:else[ \t\r]*$`)
+	reEndif := regexp.MustCompile(`^[ \t]*//kage:endif[ \t\r]*$`)
+
+	type cond struct {
+		enabled bool
+		hasElse bool
+		line    int
+	}
+	var conds []cond
+	enabled := func() bool {
+		for _, c := range conds {
+			if !c.enabled {
+				return false
+			}
+		}
+		return true
+	}
+
+	lines := bytes.Split(src, []byte("\n"))
+	for i, l := range lines {
+		if m := reIf.FindSubmatch(l); m != nil {
+			v := flags[string(m[2])]
+			if len(m[1]) > 0 {
+				v = !v
+			}
+			conds = append(conds, cond{
+				enabled: v,
+				line:    i + 1,
+			})
+			lines[i] = nil
+			continue
+		}
+		if reElse.Match(l) {
+			if len(conds) == 0 {
+				return nil, fmt.Errorf("shader: %d: //kage:else without //kage:if", i+1)
+			}
+			c := &conds[len(conds)-1]
+			if c.hasElse {
+				return nil, fmt.Errorf("shader: %d: duplicated //kage:else", i+1)
+			}
+			c.enabled = !c.enabled
+			c.hasElse = true
+			lines[i] = nil
+			continue
+		}
+		if reEndif.Match(l) {
+			if len(conds) == 0 {
+				return nil, fmt.Errorf("shader: %d: //kage:endif without //kage:if", i+1)
+			}
+			conds = conds[:len(conds)-1]
+			lines[i] = nil
+			continue
+		}
+		if !enabled() {
+			lines[i] = nil
+		}
+	}
+	if len(conds) > 0 {
+		return nil, fmt.Errorf("shader: %d: //kage:if without //kage:endif", conds[len(conds)-1].line)
+	}
+
+	return bytes.Join(lines, []byte("\n")), nil
+}
+
+var reTopLevelStatement = regexp.MustCompile(`^expected declaration, found ('(for|if|switch|return|break|continue|{)'|[A-Za-z_][A-Za-z0-9_]*)$`)
+
+// adjustParserError replaces confusing error messages from the Go parser with ones for shader programs.
+func adjustParserError(err error) error {
+	errs, ok := err.(scanner.ErrorList)
+	if !ok {
+		return err
+	}
+	for _, e := range errs {
+		if reTopLevelStatement.MatchString(e.Msg) {
+			e.Msg = "statements must be inside a function"
+		}
+	}
+	return errs
+}
+
+// parserErrors converts an error from the Go parser to errors with positions.
+func parserErrors(err error) []Error {
+	errList, ok := err.(scanner.ErrorList)
+	if !ok {
+		return []Error{{Message: err.Error()}}
+	}
+	errs := make([]Error, 0, len(errList))
+	for _, e := range errList {
+		errs = append(errs, newError(e.Pos, e.Msg))
+	}
+	return errs
+}
+
 func ParseCompilerDirectives(src []byte) (shaderir.Unit, error) {
 	// TODO: Change the unit to pixels in v3 (#2645).
 	unit := shaderir.Texels
@@ -251,19 +697,162 @@ func ParseCompilerDirectives(src []byte) (shaderir.Unit, error) {
 	return unit, nil
 }
 
+// parseRequiredFeatures parses //kage:require directives.
+func parseRequiredFeatures(src []byte) ([]shaderir.Feature, error) {
+	reRequire := regexp.MustCompile(`^[ \t\r\n]*//kage:require\s+([^ \t\r\n]+)[ \t\r\n]*$`)
+	var features []shaderir.Feature
+
+	buf := bytes.NewBuffer(src)
+	s := bufio.NewScanner(buf)
+	for s.Scan() {
+		m := reRequire.FindStringSubmatch(s.Text())
+		if m == nil {
+			continue
+		}
+		f := shaderir.Feature(m[1])
+		switch f {
+		case shaderir.FeatureDerivatives, shaderir.FeatureIntUniforms:
+		default:
+			return nil, fmt.Errorf("shader: invalid value for //kage:require: %s", m[1])
+		}
+		var found bool
+		for _, f2 := range features {
+			if f2 == f {
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+		features = append(features, f)
+	}
+
+	return features, nil
+}
+
+// parseAttributes parses //kage:attribute directives like //kage:attribute normal vec3.
+func parseAttributes(src []byte) ([]variable, error) {
+	reAttribute := regexp.MustCompile(`^[ \t\r\n]*//kage:attribute\s+([^ \t\r\n]+)\s+([^ \t\r\n]+)[ \t\r\n]*$`)
+	reName := regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	var attributes []variable
+
+	buf := bytes.NewBuffer(src)
+	s := bufio.NewScanner(buf)
+	for s.Scan() {
+		m := reAttribute.FindStringSubmatch(s.Text())
+		if m == nil {
+			continue
+		}
+		name := m[1]
+		if !reName.MatchString(name) || name == "_" {
+			return nil, fmt.Errorf("shader: invalid name for //kage:attribute: %s", name)
+		}
+		for _, a := range attributes {
+			if a.name == name {
+				return nil, fmt.Errorf("shader: duplicated //kage:attribute: %s", name)
+			}
+		}
+		var t shaderir.Type
+		switch m[2] {
+		case "float":
+			t = shaderir.Type{Main: shaderir.Float}
+		case "vec2":
+			t = shaderir.Type{Main: shaderir.Vec2}
+		case "vec3":
+			t = shaderir.Type{Main: shaderir.Vec3}
+		case "vec4":
+			t = shaderir.Type{Main: shaderir.Vec4}
+		default:
+			return nil, fmt.Errorf("shader: attribute %s must be float, vec2, vec3, or vec4 but %s", name, m[2])
+		}
+		attributes = append(attributes, variable{
+			name: name,
+			typ:  t,
+		})
+	}
+
+	return attributes, nil
+}
+
+// parsePrecisions parses //kage:precision directives like //kage:precision mediump float.
+// parsePrecisions returns the default precisions of floats and ints.
+func parsePrecisions(src []byte) (floatPrecision, intPrecision shaderir.Precision, err error) {
+	rePrecision := regexp.MustCompile(`^[ \t\r\n]*//kage:precision\s+([^ \t\r\n]+)\s+([^ \t\r\n]+)[ \t\r\n]*$`)
+
+	buf := bytes.NewBuffer(src)
+	s := bufio.NewScanner(buf)
+	for s.Scan() {
+		m := rePrecision.FindStringSubmatch(s.Text())
+		if m == nil {
+			continue
+		}
+		var precision shaderir.Precision
+		switch m[1] {
+		case "lowp":
+			precision = shaderir.PrecisionLow
+		case "mediump":
+			precision = shaderir.PrecisionMedium
+		case "highp":
+			precision = shaderir.PrecisionHigh
+		default:
+			return 0, 0, fmt.Errorf("shader: invalid precision for //kage:precision: %s", m[1])
+		}
+		var target *shaderir.Precision
+		switch m[2] {
+		case "float":
+			target = &floatPrecision
+		case "int":
+			target = &intPrecision
+		default:
+			return 0, 0, fmt.Errorf("shader: invalid type for //kage:precision: %s", m[2])
+		}
+		if *target != shaderir.PrecisionDefault {
+			return 0, 0, fmt.Errorf("shader: at most one //kage:precision for %s can exist in a shader", m[2])
+		}
+		*target = precision
+	}
+
+	return floatPrecision, intPrecision, nil
+}
+
 func (s *compileState) addError(pos token.Pos, str string) {
+	err := newError(s.fs.Position(pos), str)
+	// The same node might be checked more than once, e.g. a function signature.
+	for _, e := range s.errs {
+		if e == err {
+			return
+		}
+	}
+	s.errs = append(s.errs, err)
+}
+
+func (s *compileState) addWarning(pos token.Pos, str string) {
+	if s.options.Warn == nil {
+		return
+	}
 	p := s.fs.Position(pos)
-	s.errs = append(s.errs, fmt.Sprintf("%s: %s", p, str))
+	s.options.Warn(fmt.Sprintf("%s: %s", p, str))
 }
 
 func (cs *compileState) parse(f *ast.File) {
 	cs.ir.Unit = cs.unit
 
 	// Parse GenDecl for global variables, and then parse functions.
-	for _, d := range f.Decls {
-		if _, ok := d.(*ast.FuncDecl); !ok {
+	// Constants and types are parsed before uniform variables so that a uniform variable's type can refer to a constant declared after it.
+	for _, varDecl := range []bool{false, true} {
+		for _, d := range f.Decls {
+			if _, ok := d.(*ast.FuncDecl); ok {
+				continue
+			}
+			if g, ok := d.(*ast.GenDecl); (ok && g.Tok == token.VAR) != varDecl {
+				continue
+			}
 			ss, ok := cs.parseDecl(&cs.global, "", d)
 			if !ok {
+				if cs.options.Recover {
+					continue
+				}
 				return
 			}
 			cs.global.ir.Stmts = append(cs.global.ir.Stmts, ss...)
@@ -311,7 +900,13 @@ func (cs *compileState) parse(f *ast.File) {
 			}
 		}
 
-		inParams, outParams, ret := cs.parseFuncParams(&cs.global, n, fd)
+		inParams, outParams, ret, ok := cs.parseFuncParams(&cs.global, n, fd)
+		if !ok {
+			if cs.options.Recover {
+				continue
+			}
+			return
+		}
 		var inT, outT []shaderir.Type
 		for _, v := range inParams {
 			inT = append(inT, v.typ)
@@ -337,19 +932,30 @@ func (cs *compileState) parse(f *ast.File) {
 		if f, ok := d.(*ast.FuncDecl); ok {
 			ss, ok := cs.parseDecl(&cs.global, f.Name.Name, d)
 			if !ok {
-				return
+				// Continue parsing the other functions so that their errors are reported too.
+				continue
 			}
 			cs.global.ir.Stmts = append(cs.global.ir.Stmts, ss...)
 		}
 	}
 
-	if len(cs.errs) > 0 {
+	if len(cs.errs) > 0 && !cs.options.Recover {
 		return
 	}
 
 	for _, f := range cs.funcs {
 		cs.ir.Funcs = append(cs.ir.Funcs, f.ir)
 	}
+
+	// A function with a fragment-only feature like discard can be called only from the fragment entry point.
+	if len(cs.fragmentOnlyFeatures) > 0 && cs.ir.VertexFunc.Block != nil {
+		for _, f := range cs.ir.ReachableFuncsFromBlock(cs.ir.VertexFunc.Block) {
+			n := cs.funcs[f.Index].name
+			if u, ok := cs.fragmentOnlyFeatures[n]; ok {
+				cs.addError(u.pos, fmt.Sprintf("%s is not available in %s called from %s", u.name, n, cs.vertexEntry))
+			}
+		}
+	}
 }
 
 func (cs *compileState) parseDecl(b *block, fname string, d ast.Decl) ([]shaderir.Stmt, bool) {
@@ -362,6 +968,10 @@ func (cs *compileState) parseDecl(b *block, fname string, d ast.Decl) ([]shaderi
 			// TODO: Parse other types
 			for _, s := range d.Specs {
 				s := s.(*ast.TypeSpec)
+				if refersToType(s.Type, s.Name.Name) {
+					cs.addError(s.Pos(), fmt.Sprintf("invalid recursive type %s", s.Name.Name))
+					return nil, false
+				}
 				t, ok := cs.parseType(b, fname, s.Type)
 				if !ok {
 					return nil, false
@@ -379,13 +989,27 @@ func (cs *compileState) parseDecl(b *block, fname string, d ast.Decl) ([]shaderi
 				})
 			}
 		case token.CONST:
-			for _, s := range d.Specs {
+			var last *ast.ValueSpec
+			for i, s := range d.Specs {
 				s := s.(*ast.ValueSpec)
-				cs, ok := cs.parseConstant(b, fname, s)
+				// Like Go, an omitted type and expression list repeat the previous ones.
+				if s.Type == nil && len(s.Values) == 0 && last != nil {
+					s = &ast.ValueSpec{
+						Names:  s.Names,
+						Type:   last.Type,
+						Values: last.Values,
+					}
+				} else {
+					last = s
+				}
+				cs.iota = int64(i)
+				cs.inConstDecl = true
+				consts, ok := cs.parseConstant(b, fname, s)
+				cs.inConstDecl = false
 				if !ok {
 					return nil, false
 				}
-				b.consts = append(b.consts, cs...)
+				b.consts = append(b.consts, consts...)
 			}
 		case token.VAR:
 			for _, s := range d.Specs {
@@ -415,8 +1039,16 @@ func (cs *compileState) parseDecl(b *block, fname string, d ast.Decl) ([]shaderi
 								return nil, false
 							}
 						}
+						if v.typ.Main == shaderir.Struct {
+							cs.addError(s.Names[i].Pos(), fmt.Sprintf("a uniform variable cannot be a struct: %s", v.name))
+							return nil, false
+						}
 						cs.ir.UniformNames = append(cs.ir.UniformNames, v.name)
 						cs.ir.Uniforms = append(cs.ir.Uniforms, v.typ)
+						if cs.uniformPositions == nil {
+							cs.uniformPositions = map[string]token.Pos{}
+						}
+						cs.uniformPositions[v.name] = s.Names[i].Pos()
 					}
 					continue
 				}
@@ -547,26 +1179,39 @@ func (s *compileState) parseVariable(block *block, fname string, vs *ast.ValueSp
 			if !ok {
 				return nil, nil, nil, false
 			}
+			// A type or a function name has no type, and a call of a function might have no value.
+			if len(rts) != 1 {
+				s.addError(vs.Pos(), "the numbers of lhs and rhs don't match")
+				return nil, nil, nil, false
+			}
 
 			if t.Main == shaderir.None {
 				ts, ok := s.functionReturnTypes(block, init)
 				if !ok {
 					ts = rts
 				}
-				if len(ts) > 1 {
+				if len(ts) != 1 {
 					s.addError(vs.Pos(), "the numbers of lhs and rhs don't match")
+					return nil, nil, nil, false
 				}
 				t = ts[0]
 				if t.Main == shaderir.None {
 					t = toDefaultType(es[0].Const)
 				}
 			}
+			t.Precision = conversionPrecision(init, &rts[0])
 
 			for i, rt := range rts {
 				if !canAssign(&t, &rt, es[i].Const) {
-					s.addError(vs.Pos(), fmt.Sprintf("cannot use type %s as type %s in variable declaration", rt.String(), t.String()))
+					s.addError(vs.Pos(), fmt.Sprintf("cannot use type %s as type %s in variable declaration%s", rt.String(), t.String(), scalarBroadcastHint(&t, &rt, es[i].Const)))
 				}
 			}
+			if t.Main == shaderir.Float && len(es) == 1 {
+				s.warnIntegerDivisionInFloatContext(init, es[0], rts[0])
+			}
+			for i := range es {
+				convertConstant(&es[i], &t)
+			}
 
 			inits = append(inits, es...)
 			stmts = append(stmts, ss...)
@@ -584,6 +1229,14 @@ func (s *compileState) parseVariable(block *block, fname string, vs *ast.ValueSp
 				if !ok {
 					return nil, nil, nil, false
 				}
+				if ts, ok := s.functionReturnTypes(block, init); ok && len(ts) != len(vs.Names) {
+					s.addError(vs.Pos(), fmt.Sprintf("assignment mismatch: %d variables but %s() returns %d %s", len(vs.Names), init.(*ast.CallExpr).Fun, len(ts), pluralValues(len(ts))))
+					return nil, nil, nil, false
+				}
+				if len(initexprs) != len(vs.Names) || len(inittypes) != len(vs.Names) {
+					s.addError(vs.Pos(), "the numbers of lhs and rhs don't match")
+					return nil, nil, nil, false
+				}
 				stmts = append(stmts, ss...)
 
 				if t.Main == shaderir.None {
@@ -593,7 +1246,7 @@ func (s *compileState) parseVariable(block *block, fname string, vs *ast.ValueSp
 					}
 					if len(ts) != len(vs.Names) {
 						s.addError(vs.Pos(), "the numbers of lhs and rhs don't match")
-						continue
+						return nil, nil, nil, false
 					}
 				}
 			}
@@ -604,10 +1257,11 @@ func (s *compileState) parseVariable(block *block, fname string, vs *ast.ValueSp
 				if t.Main == shaderir.None {
 					t = toDefaultType(initexprs[i].Const)
 				}
+				t.Precision = shaderir.PrecisionDefault
 			}
 
 			if !canAssign(&t, &inittypes[i], initexprs[i].Const) {
-				s.addError(vs.Pos(), fmt.Sprintf("cannot use type %s as type %s in variable declaration", inittypes[i].String(), t.String()))
+				s.addError(vs.Pos(), fmt.Sprintf("cannot use type %s as type %s in variable declaration%s", inittypes[i].String(), t.String(), scalarBroadcastHint(&t, &inittypes[i], initexprs[i].Const)))
 			}
 
 			// Add the same initexprs for each variable.
@@ -646,6 +1300,15 @@ func (s *compileState) parseConstant(block *block, fname string, vs *ast.ValueSp
 		}
 	}
 
+	if len(vs.Values) < len(vs.Names) {
+		s.addError(vs.Pos(), "missing init expr for const declaration")
+		return nil, false
+	}
+	if len(vs.Values) > len(vs.Names) {
+		s.addError(vs.Pos(), "extra init expr")
+		return nil, false
+	}
+
 	var cs []constant
 	for i, n := range vs.Names {
 		name := n.Name
@@ -675,7 +1338,7 @@ func (s *compileState) parseConstant(block *block, fname string, vs *ast.ValueSp
 			return nil, false
 		}
 		if es[0].Type != shaderir.NumberExpr {
-			s.addError(vs.Pos(), fmt.Sprintf("constant expression must be a number but not: %s", n))
+			s.addError(vs.Values[i].Pos(), fmt.Sprintf("the value of constant %s is not constant (value of type %s)", n, ts[0].String()))
 			return nil, false
 		}
 
@@ -702,28 +1365,29 @@ func (s *compileState) parseConstant(block *block, fname string, vs *ast.ValueSp
 	return cs, true
 }
 
-func (cs *compileState) parseFuncParams(block *block, fname string, d *ast.FuncDecl) (in, out []variable, ret shaderir.Type) {
+func (cs *compileState) parseFuncParams(block *block, fname string, d *ast.FuncDecl) (in, out []variable, ret shaderir.Type, ok bool) {
 	for _, f := range d.Type.Params.List {
 		t, ok := cs.parseType(block, fname, f.Type)
 		if !ok {
-			return
+			return nil, nil, shaderir.Type{}, false
 		}
 		for _, n := range f.Names {
 			in = append(in, variable{
 				name: n.Name,
 				typ:  t,
+				pos:  n.Pos(),
 			})
 		}
 	}
 
 	if d.Type.Results == nil {
-		return
+		return in, nil, shaderir.Type{}, true
 	}
 
 	for _, f := range d.Type.Results.List {
 		t, ok := cs.parseType(block, fname, f.Type)
 		if !ok {
-			return
+			return nil, nil, shaderir.Type{}, false
 		}
 		if len(f.Names) == 0 {
 			out = append(out, variable{
@@ -745,7 +1409,7 @@ func (cs *compileState) parseFuncParams(block *block, fname string, d *ast.FuncD
 		out = nil
 	}
 
-	return
+	return in, out, ret, true
 }
 
 func (cs *compileState) parseFunc(block *block, d *ast.FuncDecl) (function, bool) {
@@ -762,7 +1426,10 @@ func (cs *compileState) parseFunc(block *block, d *ast.FuncDecl) (function, bool
 		return function{}, false
 	}
 
-	inParams, outParams, returnType := cs.parseFuncParams(block, d.Name.Name, d)
+	inParams, outParams, returnType, ok := cs.parseFuncParams(block, d.Name.Name, d)
+	if !ok {
+		return function{}, false
+	}
 
 	checkVaryings := func(vs []variable) {
 		if len(cs.ir.Varyings) != len(vs) {
@@ -779,10 +1446,36 @@ func (cs *compileState) parseFunc(block *block, d *ast.FuncDecl) (function, bool
 	if block == &cs.global {
 		switch d.Name.Name {
 		case cs.vertexEntry:
+			// The parameters are the vertex attributes.
 			for _, v := range inParams {
+				switch v.typ.Main {
+				case shaderir.Float, shaderir.Vec2, shaderir.Vec3, shaderir.Vec4:
+				default:
+					cs.addError(d.Pos(), fmt.Sprintf("vertex entry point's parameter must be float, vec2, vec3, or vec4 but %s", v.typ.String()))
+					return function{}, false
+				}
 				cs.ir.Attributes = append(cs.ir.Attributes, v.typ)
 			}
 
+			// The declared attributes follow the parameters.
+			for _, a := range cs.attributes {
+				for _, v := range inParams {
+					if v.name == a.name {
+						cs.addError(d.Pos(), fmt.Sprintf("attribute %s is redeclared as a parameter of the vertex entry point", a.name))
+						return function{}, false
+					}
+				}
+				if _, ok := cs.findUniformVariable(a.name); ok {
+					cs.addError(d.Pos(), fmt.Sprintf("attribute %s is redeclared as a uniform variable", a.name))
+					return function{}, false
+				}
+				// A declared attribute has no position in the source. Point at the vertex entry point instead.
+				a.pos = d.Pos()
+				inParams = append(inParams, a)
+				cs.ir.Attributes = append(cs.ir.Attributes, a.typ)
+			}
+			cs.vertexAttributes = inParams
+
 			// For the vertex entry, a parameter (variable) is used as a returning value.
 			// For example, GLSL doesn't treat gl_Position as a returning value.
 			// TODO: This can be resolved by having an indirect function like what the fragment entry already does.
@@ -799,11 +1492,17 @@ func (cs *compileState) parseFunc(block *block, d *ast.FuncDecl) (function, bool
 				return function{}, false
 			}
 
+			for _, v := range outParams[1:] {
+				if !isValidVaryingType(v.typ) {
+					cs.addError(d.Pos(), fmt.Sprintf("vertex entry point's returning value for a varying must be float, vec2, vec3, or vec4 but %s", v.typ.String()))
+					return function{}, false
+				}
+			}
+
 			if cs.varyingParsed {
 				checkVaryings(outParams[1:])
 			} else {
 				for _, v := range outParams[1:] {
-					// TODO: Check that these params are not arrays or structs
 					cs.ir.Varyings = append(cs.ir.Varyings, v.typ)
 				}
 			}
@@ -823,6 +1522,13 @@ func (cs *compileState) parseFunc(block *block, d *ast.FuncDecl) (function, bool
 				return function{}, false
 			}
 
+			for _, v := range inParams[1:] {
+				if !isValidVaryingType(v.typ) {
+					cs.addError(d.Pos(), fmt.Sprintf("fragment entry point's parameter for a varying must be float, vec2, vec3, or vec4 but %s", v.typ.String()))
+					return function{}, false
+				}
+			}
+
 			if cs.varyingParsed {
 				checkVaryings(inParams[1:])
 			} else {
@@ -831,15 +1537,23 @@ func (cs *compileState) parseFunc(block *block, d *ast.FuncDecl) (function, bool
 				}
 			}
 			cs.varyingParsed = true
+			cs.fragmentVaryings = inParams[1:]
 		}
 	}
 
+	errCount := len(cs.errs) + cs.badNodes
 	b, ok := cs.parseBlock(block, d.Name.Name, d.Body.List, inParams, outParams, returnType, true)
 	if !ok {
 		return function{}, false
 	}
+	hasErrors := len(cs.errs)+cs.badNodes != errCount
+	if !hasErrors {
+		cs.warnUnreadLocalVariables(b.ir)
+		cs.warnUninitializedLocalVariables(b.ir)
+	}
 
-	if len(outParams) > 0 || returnType.Main != shaderir.None {
+	// In the recovery mode, a return statement might have been skipped as an invalid statement.
+	if !hasErrors && (len(outParams) > 0 || returnType.Main != shaderir.None) {
 		var hasReturn func(stmts []shaderir.Stmt) bool
 		hasReturn = func(stmts []shaderir.Stmt) bool {
 			for _, stmt := range stmts {
@@ -880,6 +1594,303 @@ func (cs *compileState) parseFunc(block *block, d *ast.FuncDecl) (function, bool
 	}, true
 }
 
+// warnUnreadLocalVariables reports the local variables in the function body that are declared but whose values are never read.
+// Assigning to a variable, or to its element, field, or swizzling, is not a read.
+func (cs *compileState) warnUnreadLocalVariables(body *shaderir.Block) {
+	if cs.options.Warn == nil {
+		return
+	}
+
+	type scope struct {
+		block *shaderir.Block
+		reads []int
+	}
+	var scopes []*scope
+
+	read := func(idx int) {
+		for i := len(scopes) - 1; i >= 0; i-- {
+			s := scopes[i]
+			if offset := s.block.LocalVarIndexOffset; offset <= idx && idx < offset+len(s.block.LocalVars) {
+				s.reads[idx-offset]++
+				return
+			}
+		}
+	}
+
+	var walkExpr func(e *shaderir.Expr)
+	walkExpr = func(e *shaderir.Expr) {
+		if e.Type == shaderir.LocalVariable {
+			read(e.Index)
+		}
+		for i := range e.Exprs {
+			walkExpr(&e.Exprs[i])
+		}
+	}
+
+	// walkLhs visits the expressions read to determine the assigned variable, e.g. indices.
+	var walkLhs func(e *shaderir.Expr)
+	walkLhs = func(e *shaderir.Expr) {
+		switch e.Type {
+		case shaderir.FieldSelector:
+			walkLhs(&e.Exprs[0])
+		case shaderir.Index:
+			walkLhs(&e.Exprs[0])
+			walkExpr(&e.Exprs[1])
+		case shaderir.LocalVariable:
+		default:
+			walkExpr(e)
+		}
+	}
+
+	var walkBlock func(b *shaderir.Block)
+	walkBlock = func(b *shaderir.Block) {
+		if b == nil {
+			return
+		}
+		s := &scope{
+			block: b,
+			reads: make([]int, len(b.LocalVars)),
+		}
+		scopes = append(scopes, s)
+		for i := range b.Stmts {
+			stmt := &b.Stmts[i]
+			if stmt.Type == shaderir.Assign {
+				walkLhs(&stmt.Exprs[0])
+				walkExpr(&stmt.Exprs[1])
+			} else {
+				for j := range stmt.Exprs {
+					walkExpr(&stmt.Exprs[j])
+				}
+			}
+			for _, b := range stmt.Blocks {
+				walkBlock(b)
+			}
+		}
+		scopes = scopes[:len(scopes)-1]
+
+		vars := cs.blockVars[b]
+		for i, n := range s.reads {
+			if n > 0 || i >= len(vars) {
+				continue
+			}
+			v := vars[i]
+			if v.name == "" || v.name == "_" || v.forLoopCounter || v.discarded {
+				continue
+			}
+			cs.addWarning(v.pos, fmt.Sprintf("the value of local variable %s is never read", v.name))
+		}
+	}
+	walkBlock(body)
+}
+
+// warnUninitializedLocalVariables reports the local variables in the function body that are read after a value is assigned only on some paths,
+// e.g., a variable assigned only in one branch of an if statement.
+// Such a variable has the zero value on the other paths like Go, but the read is likely a mistake.
+// A variable read before any assignment is not reported, as reading the zero value is common, e.g., an accumulator declared with var.
+func (cs *compileState) warnUninitializedLocalVariables(body *shaderir.Block) {
+	if cs.options.Warn == nil {
+		return
+	}
+
+	// vars is the checked local variables in the visited blocks by the local variable index.
+	vars := map[int]variable{}
+	reported := map[int]bool{}
+
+	// assigned has the variables assigned on some paths. The value reports whether the variable is assigned on all the paths.
+	read := func(idx int, assigned map[int]bool) {
+		v, ok := vars[idx]
+		if !ok || reported[idx] {
+			return
+		}
+		if all, ok := assigned[idx]; !ok || all {
+			return
+		}
+		reported[idx] = true
+		cs.addWarning(v.pos, fmt.Sprintf("local variable %s is assigned only on some paths before it is read", v.name))
+	}
+
+	var walkExpr func(e *shaderir.Expr, assigned map[int]bool)
+
+	// walkLhs visits the expressions read to determine the assigned variable, e.g. indices, and returns the variable.
+	// Assigning to an element, a field, or a swizzling of a variable is treated as assigning to the variable.
+	var walkLhs func(e *shaderir.Expr, assigned map[int]bool) int
+	walkLhs = func(e *shaderir.Expr, assigned map[int]bool) int {
+		switch e.Type {
+		case shaderir.FieldSelector:
+			return walkLhs(&e.Exprs[0], assigned)
+		case shaderir.Index:
+			walkExpr(&e.Exprs[1], assigned)
+			return walkLhs(&e.Exprs[0], assigned)
+		case shaderir.LocalVariable:
+			return e.Index
+		}
+		walkExpr(e, assigned)
+		return -1
+	}
+
+	walkExpr = func(e *shaderir.Expr, assigned map[int]bool) {
+		switch e.Type {
+		case shaderir.LocalVariable:
+			read(e.Index, assigned)
+			return
+		case shaderir.Call:
+			// The arguments for the output parameters of a function are assigned by the call.
+			if callee := &e.Exprs[0]; callee.Type == shaderir.FunctionExpr {
+				n := len(cs.funcs[callee.Index].ir.InParams)
+				var outs []int
+				for i := range e.Exprs[1:] {
+					if i < n {
+						walkExpr(&e.Exprs[i+1], assigned)
+						continue
+					}
+					if idx := walkLhs(&e.Exprs[i+1], assigned); idx >= 0 {
+						outs = append(outs, idx)
+					}
+				}
+				for _, idx := range outs {
+					assigned[idx] = true
+				}
+				return
+			}
+		}
+		for i := range e.Exprs {
+			walkExpr(&e.Exprs[i], assigned)
+		}
+	}
+
+	clone := func(assigned map[int]bool) map[int]bool {
+		m := make(map[int]bool, len(assigned))
+		for idx, all := range assigned {
+			m[idx] = all
+		}
+		return m
+	}
+
+	// walkBlock visits the block with the variables assigned before the block, and returns the variables assigned after the block.
+	// walkBlock also reports whether the block always ends with a jump like return or break, after which no variables matter.
+	var walkBlock func(b *shaderir.Block, assigned map[int]bool) (map[int]bool, bool)
+	walkBlock = func(b *shaderir.Block, assigned map[int]bool) (map[int]bool, bool) {
+		// The indices of the variables in a block might be reused in its sibling blocks.
+		for i, v := range cs.blockVars[b] {
+			idx := b.LocalVarIndexOffset + i
+			delete(vars, idx)
+			delete(assigned, idx)
+			delete(reported, idx)
+			if v.name == "" || v.name == "_" || v.forLoopCounter || v.invalid {
+				continue
+			}
+			vars[idx] = v
+		}
+
+		for i := range b.Stmts {
+			stmt := &b.Stmts[i]
+			switch stmt.Type {
+			case shaderir.ExprStmt:
+				walkExpr(&stmt.Exprs[0], assigned)
+			case shaderir.BlockStmt:
+				var jumped bool
+				assigned, jumped = walkBlock(stmt.Blocks[0], assigned)
+				if jumped {
+					return assigned, true
+				}
+			case shaderir.Assign:
+				walkExpr(&stmt.Exprs[1], assigned)
+				if idx := walkLhs(&stmt.Exprs[0], assigned); idx >= 0 {
+					assigned[idx] = true
+				}
+			case shaderir.Init:
+				assigned[stmt.InitIndex] = true
+			case shaderir.If:
+				walkExpr(&stmt.Exprs[0], assigned)
+				thenAssigned, thenJumped := walkBlock(stmt.Blocks[0], clone(assigned))
+				elseAssigned, elseJumped := assigned, false
+				if len(stmt.Blocks) > 1 && stmt.Blocks[1] != nil {
+					elseAssigned, elseJumped = walkBlock(stmt.Blocks[1], clone(assigned))
+				}
+				switch {
+				case thenJumped && elseJumped:
+					return assigned, true
+				case thenJumped:
+					assigned = elseAssigned
+				case elseJumped:
+					assigned = thenAssigned
+				default:
+					assigned = map[int]bool{}
+					for idx, all := range thenAssigned {
+						assigned[idx] = all && elseAssigned[idx]
+					}
+					for idx := range elseAssigned {
+						if _, ok := thenAssigned[idx]; !ok {
+							assigned[idx] = false
+						}
+					}
+				}
+			case shaderir.For:
+				bodyAssigned, _ := walkBlock(stmt.Blocks[0], clone(assigned))
+				// The body is executed at least once when the condition is satisfied at the start.
+				// Without a break or a continue, the variables assigned in the body are assigned after the loop.
+				if tk, ok := comparisonToken(stmt.ForOp); ok && gconstant.Compare(stmt.ForInit, tk, stmt.ForEnd) && !hasLoopJump(stmt.Blocks[0]) {
+					assigned = bodyAssigned
+				}
+			case shaderir.While:
+				walkExpr(&stmt.Exprs[0], assigned)
+				walkBlock(stmt.Blocks[0], clone(assigned))
+			case shaderir.Return:
+				for j := range stmt.Exprs {
+					walkExpr(&stmt.Exprs[j], assigned)
+				}
+				return assigned, true
+			case shaderir.Discard, shaderir.Break, shaderir.Continue:
+				return assigned, true
+			}
+		}
+		return assigned, false
+	}
+	walkBlock(body, map[int]bool{})
+}
+
+// hasLoopJump reports whether the loop body b has a break or a continue for the loop.
+// The breaks and continues in inner loops are not counted.
+func hasLoopJump(b *shaderir.Block) bool {
+	for _, s := range b.Stmts {
+		switch s.Type {
+		case shaderir.Break, shaderir.Continue:
+			return true
+		case shaderir.For, shaderir.While:
+			continue
+		}
+		for _, b := range s.Blocks {
+			if b != nil && hasLoopJump(b) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isTerminatingStmt reports whether the statement s always transfers the control elsewhere, that is return, break, or continue.
+// The statements after a terminating statement in the same block are unreachable.
+// discard is not terminating, as a function with discard still needs a return statement.
+// An if-else statement is terminating when both of its branches are terminating.
+func isTerminatingStmt(s *shaderir.Stmt) bool {
+	switch s.Type {
+	case shaderir.Return, shaderir.Break, shaderir.Continue:
+		return true
+	case shaderir.BlockStmt:
+		return isTerminatingBlock(s.Blocks[0])
+	case shaderir.If:
+		return len(s.Blocks) == 2 && isTerminatingBlock(s.Blocks[0]) && isTerminatingBlock(s.Blocks[1])
+	}
+	return false
+}
+
+func isTerminatingBlock(b *shaderir.Block) bool {
+	if b == nil || len(b.Stmts) == 0 {
+		return false
+	}
+	return isTerminatingStmt(&b.Stmts[len(b.Stmts)-1])
+}
+
 func (cs *compileState) parseBlock(outer *block, fname string, stmts []ast.Stmt, inParams, outParams []variable, returnType shaderir.Type, checkLocalVariableUsage bool) (*block, bool) {
 	var vars []variable
 	if outer == &cs.global {
@@ -916,6 +1927,10 @@ func (cs *compileState) parseBlock(outer *block, fname string, stmts []ast.Stmt,
 			}
 			block.ir.LocalVars = append(block.ir.LocalVars, v.typ)
 		}
+		if cs.blockVars == nil {
+			cs.blockVars = map[*shaderir.Block][]variable{}
+		}
+		cs.blockVars[block.ir] = block.vars[offset:]
 	}()
 
 	if outer.outer == nil && len(outParams) > 0 && outParams[0].name != "" {
@@ -927,19 +1942,49 @@ func (cs *compileState) parseBlock(outer *block, fname string, stmts []ast.Stmt,
 		}
 	}
 
-	for _, stmt := range stmts {
+	// Skip an invalid statement and continue so that the errors in the following statements are reported too.
+	var failed bool
+	var terminated bool
+	for i, stmt := range stmts {
 		ss, ok := cs.parseStmt(block, fname, stmt, inParams, outParams, returnType)
 		if !ok {
-			return nil, false
+			failed = true
+			// Declare the variables of a failed declaration so that their uses don't cause cascading errors.
+			block.addInvalidLocalVariables(stmt)
+			continue
+		}
+		// The statements after a terminating statement are still parsed for errors, but are not emitted.
+		if terminated {
+			continue
+		}
+		// The statements in nested blocks already have their own lines.
+		line := cs.fs.Position(stmt.Pos()).Line
+		for j := range ss {
+			if ss[j].Line == 0 {
+				ss[j].Line = line
+			}
 		}
 		block.ir.Stmts = append(block.ir.Stmts, ss...)
+		if len(ss) > 0 && isTerminatingStmt(&ss[len(ss)-1]) {
+			terminated = true
+			if i < len(stmts)-1 {
+				cs.addWarning(stmts[i+1].Pos(), "unreachable code")
+			}
+		}
 	}
 
-	if checkLocalVariableUsage && len(block.unusedVars) > 0 {
+	if failed && !cs.options.Recover {
+		return nil, false
+	}
+
+	// A variable might be used only in an invalid statement. Don't report it as unused.
+	if checkLocalVariableUsage && !failed && len(block.unusedVars) > 0 {
 		for idx, pos := range block.unusedVars {
 			cs.addError(pos, fmt.Sprintf("local variable %s is not used", block.vars[idx].name))
 		}
-		return nil, false
+		if !cs.options.Recover {
+			return nil, false
+		}
 	}
 
 	return block, true
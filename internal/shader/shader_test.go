@@ -15,6 +15,7 @@
 package shader_test
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -27,6 +28,8 @@ import (
 	"github.com/hajimehoshi/ebiten/v2/internal/shaderir/glsl"
 	"github.com/hajimehoshi/ebiten/v2/internal/shaderir/hlsl"
 	"github.com/hajimehoshi/ebiten/v2/internal/shaderir/msl"
+	"github.com/hajimehoshi/ebiten/v2/internal/shaderir/spirv"
+	"github.com/hajimehoshi/ebiten/v2/internal/shaderir/wgsl"
 )
 
 func glslVertexNormalize(str string) string {
@@ -45,9 +48,10 @@ func glslFragmentNormalize(str string) string {
 	return strings.TrimSpace(str)
 }
 
-func hlslNormalize(str string) string {
-	if strings.HasPrefix(str, hlsl.Prelude) {
-		str = str[len(hlsl.Prelude):]
+func hlslNormalize(str string, varyings []shaderir.Type) string {
+	prelude := hlsl.Prelude(varyings)
+	if strings.HasPrefix(str, prelude) {
+		str = str[len(prelude):]
 	}
 	return strings.TrimSpace(str)
 }
@@ -60,6 +64,14 @@ func metalNormalize(str string) string {
 	return strings.TrimSpace(str)
 }
 
+func wgslNormalize(str string) string {
+	prelude := wgsl.Prelude(shaderir.Texels)
+	if strings.HasPrefix(str, prelude) {
+		str = str[len(prelude):]
+	}
+	return strings.TrimSpace(str)
+}
+
 func compare(t *testing.T, title, got, want string) {
 	var msg string
 	gotlines := strings.Split(got, "\n")
@@ -98,6 +110,8 @@ func TestCompile(t *testing.T) {
 		FS    []byte
 		HLSL  []byte
 		Metal []byte
+		WGSL  []byte
+		SPIRV []byte
 	}
 
 	fnames := map[string]struct{}{}
@@ -165,6 +179,24 @@ func TestCompile(t *testing.T) {
 			tc.Metal = metal
 		}
 
+		wgsln := name + ".expected.wgsl"
+		if _, ok := fnames[wgsln]; ok {
+			w, err := os.ReadFile(filepath.Join("testdata", wgsln))
+			if err != nil {
+				t.Fatal(err)
+			}
+			tc.WGSL = w
+		}
+
+		spirvn := name + ".expected.spvasm"
+		if _, ok := fnames[spirvn]; ok {
+			d, err := os.ReadFile(filepath.Join("testdata", spirvn))
+			if err != nil {
+				t.Fatal(err)
+			}
+			tc.SPIRV = d
+		}
+
 		tests = append(tests, tc)
 	}
 
@@ -189,7 +221,7 @@ func TestCompile(t *testing.T) {
 
 			if tc.HLSL != nil {
 				vs, _, _ := hlsl.Compile(s)
-				if got, want := hlslNormalize(vs), hlslNormalize(string(tc.HLSL)); got != want {
+				if got, want := hlslNormalize(vs, s.Varyings), hlslNormalize(string(tc.HLSL), s.Varyings); got != want {
 					compare(t, "HLSL", got, want)
 				}
 			}
@@ -201,9 +233,986 @@ func TestCompile(t *testing.T) {
 				}
 			}
 
+			if tc.WGSL != nil {
+				w := wgsl.Compile(s, "Vertex", "Fragment")
+				if got, want := wgslNormalize(w), wgslNormalize(string(tc.WGSL)); got != want {
+					compare(t, "WGSL", got, want)
+				}
+			}
+
+			// SPIR-V is tested for all the test cases, as the compilation might fail.
+			module, err := spirv.Compile(s, "Vertex", "Fragment")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			d, err := spirv.Disassemble(module)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if tc.SPIRV != nil {
+				if got, want := d, string(tc.SPIRV); got != want {
+					compare(t, "SPIR-V", got, want)
+				}
+			}
+
 			// Just check that Compile doesn't cause panic.
 			// TODO: Should the results be tested?
 			msl.Compile(s, "Vertex", "Fragmentp")
 		})
 	}
 }
+
+func TestCompileNoComments(t *testing.T) {
+	src := []byte(`//kage:unit pixels
+
+// Package main is a shader with comments.
+package main
+
+/* A uniform variable. */
+var Foo float // Foo is a uniform variable.
+
+// Fragment is the entry point.
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	// Return the color as it is.
+	return color * Foo /* scale */
+}
+`)
+	s, err := shader.Compile(src, "Vertex", "Fragment", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vs, fs := glsl.Compile(s, glsl.GLSLVersionDefault)
+	vsES, fsES := glsl.Compile(s, glsl.GLSLVersionES300)
+	hlslVS, hlslPS, _ := hlsl.Compile(s)
+	m := msl.Compile(s, "Vertex", "Fragment")
+	w := wgsl.Compile(s, "Vertex", "Fragment")
+	for name, out := range map[string]string{
+		"GLSL Vertex":           vs,
+		"GLSL Fragment":         fs,
+		"GLSL ES 3.00 Vertex":   vsES,
+		"GLSL ES 3.00 Fragment": fsES,
+		"HLSL Vertex":           hlslVS,
+		"HLSL Pixel":            hlslPS,
+		"Metal":                 m,
+		"WGSL":                  w,
+	} {
+		if strings.Contains(out, "//") || strings.Contains(out, "/*") {
+			t.Errorf("%s must not include comments but does:\n%s", name, out)
+		}
+	}
+
+	// SPIR-V has no comments, but the source text could be embedded with OpSource or OpString.
+	bs, err := spirv.Compile(s, "Vertex", "Fragment")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []string{
+		"Package main is a shader with comments.",
+		"A uniform variable.",
+		"Foo is a uniform variable.",
+		"Fragment is the entry point.",
+		"Return the color as it is.",
+		"scale",
+	} {
+		if bytes.Contains(bs, []byte(c)) {
+			t.Errorf("SPIR-V must not include the comment %q but does", c)
+		}
+	}
+}
+
+func TestCompileLargeFloatLiteral(t *testing.T) {
+	s, err := shader.Compile([]byte(`package main
+
+func Foo() float {
+	return 1e19
+}
+`), "Vertex", "Fragment", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vs, _ := glsl.Compile(s, glsl.GLSLVersionDefault)
+	hlslVS, _, _ := hlsl.Compile(s)
+	for name, out := range map[string]string{
+		"GLSL":  vs,
+		"HLSL":  hlslVS,
+		"Metal": msl.Compile(s, "Vertex", "Fragment"),
+		"WGSL":  wgsl.Compile(s, "Vertex", "Fragment"),
+	} {
+		if want := "return 1e+19;"; !strings.Contains(out, want) {
+			t.Errorf("%s: the output must include %q but does not:\n%s", name, want, out)
+		}
+	}
+}
+
+func TestCompileOptimizeTransposeMul(t *testing.T) {
+	src := []byte(`package main
+
+func Foo(m mat3, v vec3) vec3 {
+	return transpose(m) * v
+}
+`)
+	for _, optimize := range []bool{false, true} {
+		s, err := shader.CompileWithOptions(src, "Vertex", "Fragment", 0, &shader.CompileOptions{
+			Optimize: optimize,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		vs, _ := glsl.Compile(s, glsl.GLSLVersionDefault)
+		want := "return (transpose(l0)) * (l1);"
+		if optimize {
+			want = "return (l1) * (l0);"
+		}
+		if !strings.Contains(vs, want) {
+			t.Errorf("optimize: %t: the output must include %q but does not:\n%s", optimize, want, vs)
+		}
+	}
+}
+
+func TestCompileOptimizeIdentity(t *testing.T) {
+	cases := []struct {
+		expr     string
+		typ      string
+		want     string
+		fastMath bool
+	}{
+		{expr: "x * 1", typ: "float", want: "return l0;"},
+		{expr: "1 * x", typ: "float", want: "return l0;"},
+		{expr: "x + 0", typ: "float", want: "return l0;"},
+		{expr: "0 + x", typ: "float", want: "return l0;"},
+		{expr: "x - 0", typ: "float", want: "return l0;"},
+		{expr: "x / 1", typ: "float", want: "return l0;"},
+		{expr: "x * 1.0", typ: "vec3", want: "return l0;"},
+		{expr: "x * 1", typ: "mat2", want: "return l0;"},
+		{expr: "x / 1", typ: "int", want: "return l0;"},
+		{expr: "x * 0", typ: "int", want: "return 0;"},
+		{expr: "x * 0", typ: "ivec2", want: "return ivec2(0);"},
+		{expr: "x * 0", typ: "float", want: "return (l0) * (0.0);"},
+		{expr: "x * 0", typ: "float", want: "return 0.0;", fastMath: true},
+		{expr: "0 * x", typ: "vec4", want: "return vec4(0.0);", fastMath: true},
+		{expr: "0 - x", typ: "float", want: "return (0.0) - (l0);"},
+		{expr: "x * 2", typ: "float", want: "return (l0) * (2.0);"},
+	}
+
+	for _, c := range cases {
+		src := []byte(fmt.Sprintf(`package main
+
+func Foo(x %[1]s) %[1]s {
+	return %[2]s
+}
+`, c.typ, c.expr))
+		s, err := shader.CompileWithOptions(src, "Vertex", "Fragment", 0, &shader.CompileOptions{
+			Optimize: true,
+			FastMath: c.fastMath,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		vs, _ := glsl.Compile(s, glsl.GLSLVersionDefault)
+		if !strings.Contains(vs, c.want) {
+			t.Errorf("%s (%s): the output must include %q but does not:\n%s", c.expr, c.typ, c.want, vs)
+		}
+	}
+
+	// The operand multiplied by zero is kept when it might have side effects.
+	s, err := shader.CompileWithOptions([]byte(`package main
+
+func Foo(x int) int {
+	return Bar(x) * 0
+}
+
+func Bar(x int) int {
+	if x < 0 {
+		discard()
+	}
+	return x
+}
+`), "Vertex", "Fragment", 0, &shader.CompileOptions{
+		Optimize: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vs, _ := glsl.Compile(s, glsl.GLSLVersionDefault)
+	if want := "return (F1(l0)) * (0);"; !strings.Contains(vs, want) {
+		t.Errorf("the output must include %q but does not:\n%s", want, vs)
+	}
+
+	// Without Optimize, the identities are kept as they are.
+	s, err = shader.Compile([]byte(`package main
+
+func Foo(x float) float {
+	return x * 1
+}
+`), "Vertex", "Fragment", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vs, _ = glsl.Compile(s, glsl.GLSLVersionDefault)
+	if want := "return (l0) * (1.0);"; !strings.Contains(vs, want) {
+		t.Errorf("the output must include %q but does not:\n%s", want, vs)
+	}
+}
+
+func TestCompileUnrollLoops(t *testing.T) {
+	src := []byte(`package main
+
+func Foo(x float) float {
+	sum := 0.0
+	for i := 0; i < 4; i++ {
+		sum += x * float(i)
+	}
+	return sum
+}
+`)
+	cases := []struct {
+		unrollLoops int
+		want        string
+	}{
+		{
+			unrollLoops: 0,
+			want: `float F0(in float l0) {
+	float l1 = float(0);
+	l1 = 0.0;
+	for (int l2 = 0; l2 < 4; l2++) {
+		l1 = (l1) + ((l0) * (float(l2)));
+	}
+	return l1;
+}`,
+		},
+		{
+			unrollLoops: 4,
+			want: `float F0(in float l0) {
+	float l1 = float(0);
+	l1 = 0.0;
+	{
+		l1 = (l1) + ((l0) * (float(0)));
+	}
+	{
+		l1 = (l1) + ((l0) * (float(1)));
+	}
+	{
+		l1 = (l1) + ((l0) * (float(2)));
+	}
+	{
+		l1 = (l1) + ((l0) * (float(3)));
+	}
+	return l1;
+}`,
+		},
+		{
+			// The iteration count exceeds the budget.
+			unrollLoops: 3,
+			want: `float F0(in float l0) {
+	float l1 = float(0);
+	l1 = 0.0;
+	for (int l2 = 0; l2 < 4; l2++) {
+		l1 = (l1) + ((l0) * (float(l2)));
+	}
+	return l1;
+}`,
+		},
+	}
+	for _, c := range cases {
+		s, err := shader.CompileWithOptions(src, "Vertex", "Fragment", 0, &shader.CompileOptions{
+			UnrollLoops: c.unrollLoops,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		vs, _ := glsl.Compile(s, glsl.GLSLVersionDefault)
+		if !strings.Contains(vs, c.want) {
+			t.Errorf("unrollLoops: %d: the output must include %q but does not:\n%s", c.unrollLoops, c.want, vs)
+		}
+	}
+
+	// A loop with break is not unrolled.
+	s, err := shader.CompileWithOptions([]byte(`package main
+
+func Foo(x float) float {
+	for i := 0; i < 4; i++ {
+		if x > float(i) {
+			break
+		}
+		x += 1
+	}
+	return x
+}
+`), "Vertex", "Fragment", 0, &shader.CompileOptions{
+		UnrollLoops: 4,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vs, _ := glsl.Compile(s, glsl.GLSLVersionDefault); !strings.Contains(vs, "for (") {
+		t.Errorf("a loop with break must not be unrolled:\n%s", vs)
+	}
+}
+
+func TestCompileTextureLod(t *testing.T) {
+	cases := []struct {
+		unit  string
+		glsl  string
+		hlsl  string
+		metal string
+		wgsl  string
+	}{
+		{
+			unit:  "texels",
+			glsl:  "textureLod(T0, l0, 2.0)",
+			hlsl:  "T0.SampleLevel(samp, l0, 2.0)",
+			metal: "T0.sample(texture_sampler, l0, level(2.0))",
+			wgsl:  "textureSampleLevel(T0, texture_sampler, l0, 2.0)",
+		},
+		{
+			// In pixels, the position is scaled to the level.
+			unit:  "pixels",
+			glsl:  "texelFetch(T0, ivec2((l0) / (4.0)), int(2.0))",
+			hlsl:  "T0.Load(int3((l0) / (4.0), int(2.0)))",
+			metal: "T0.read(static_cast<uint2>((l0) / (4.0)), static_cast<uint>(2.0))",
+			wgsl:  "textureLoad(T0, vec2<i32>((l0) / (4.0)), i32(2.0))",
+		},
+	}
+	for _, c := range cases {
+		src := []byte(fmt.Sprintf(`//kage:unit %s
+
+package main
+
+func Sample(pos vec2) vec4 {
+	return __textureLod(__t0, pos, 2)
+}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return Sample(srcPos)
+}
+`, c.unit))
+		s, err := shader.Compile(src, "Vertex", "Fragment", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, fs := glsl.Compile(s, glsl.GLSLVersionES300); !strings.Contains(fs, c.glsl) {
+			t.Errorf("unit: %s: the GLSL output must include %q but does not:\n%s", c.unit, c.glsl, fs)
+		}
+		if _, ps, _ := hlsl.Compile(s); !strings.Contains(ps, c.hlsl) {
+			t.Errorf("unit: %s: the HLSL output must include %q but does not:\n%s", c.unit, c.hlsl, ps)
+		}
+		if m := msl.Compile(s, "Vertex", "Fragment"); !strings.Contains(m, c.metal) {
+			t.Errorf("unit: %s: the Metal output must include %q but does not:\n%s", c.unit, c.metal, m)
+		}
+		if w := wgsl.Compile(s, "Vertex", "Fragment"); !strings.Contains(w, c.wgsl) {
+			t.Errorf("unit: %s: the WGSL output must include %q but does not:\n%s", c.unit, c.wgsl, w)
+		}
+	}
+
+	for _, expr := range []string{
+		"__textureLod(__t0, srcPos)",
+		"__textureLod(srcPos, srcPos, 0)",
+		"__textureLod(__t0, 1.0, 0)",
+		"__textureLod(__t0, srcPos, vec2(0))",
+		"__textureLod(__t0, srcPos, int(0))",
+		"__textureLod(__t0, srcPos, true)",
+	} {
+		src := []byte(fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return %s
+}
+`, expr))
+		if _, err := shader.Compile(src, "Vertex", "Fragment", 1); err == nil {
+			t.Errorf("%s must return an error but does not", expr)
+		}
+	}
+}
+
+func TestCompileMathBuiltins(t *testing.T) {
+	cases := []struct {
+		expr  string
+		glsl  string
+		hlsl  string
+		metal string
+	}{
+		{expr: "sin(x)", glsl: "sin(l0)", hlsl: "sin(l0)", metal: "sin(l0)"},
+		{expr: "cos(x)", glsl: "cos(l0)", hlsl: "cos(l0)", metal: "cos(l0)"},
+		{expr: "tan(x)", glsl: "tan(l0)", hlsl: "tan(l0)", metal: "tan(l0)"},
+		{expr: "asin(x)", glsl: "asin(l0)", hlsl: "asin(l0)", metal: "asin(l0)"},
+		{expr: "acos(x)", glsl: "acos(l0)", hlsl: "acos(l0)", metal: "acos(l0)"},
+		{expr: "atan(x)", glsl: "atan(l0)", hlsl: "atan(l0)", metal: "atan(l0)"},
+		{expr: "atan2(x, y)", glsl: "atan(l0, l1)", hlsl: "atan2(l0, l1)", metal: "atan2(l0, l1)"},
+		{expr: "pow(x, y)", glsl: "pow(l0, l1)", hlsl: "pow(l0, l1)", metal: "pow(l0, l1)"},
+		{expr: "exp(x)", glsl: "exp(l0)", hlsl: "exp(l0)", metal: "exp(l0)"},
+		{expr: "exp2(x)", glsl: "exp2(l0)", hlsl: "exp2(l0)", metal: "exp2(l0)"},
+		{expr: "log(x)", glsl: "log(l0)", hlsl: "log(l0)", metal: "log(l0)"},
+		{expr: "log2(x)", glsl: "log2(l0)", hlsl: "log2(l0)", metal: "log2(l0)"},
+		{expr: "sqrt(x)", glsl: "sqrt(l0)", hlsl: "sqrt(l0)", metal: "sqrt(l0)"},
+		{expr: "inversesqrt(x)", glsl: "inversesqrt(l0)", hlsl: "rsqrt(l0)", metal: "rsqrt(l0)"},
+	}
+	for _, c := range cases {
+		for _, typ := range []string{"float", "vec3"} {
+			src := []byte(fmt.Sprintf(`package main
+
+func Foo(x, y %[1]s) %[1]s {
+	return %[2]s
+}
+`, typ, c.expr))
+			s, err := shader.Compile(src, "Vertex", "Fragment", 0)
+			if err != nil {
+				t.Errorf("%s with %s: %v", c.expr, typ, err)
+				continue
+			}
+			want := "return " + c.glsl + ";"
+			if vs, _ := glsl.Compile(s, glsl.GLSLVersionDefault); !strings.Contains(vs, want) {
+				t.Errorf("%s with %s: the GLSL output must include %q but does not:\n%s", c.expr, typ, want, vs)
+			}
+			want = "return " + c.hlsl + ";"
+			if vs, _, _ := hlsl.Compile(s); !strings.Contains(vs, want) {
+				t.Errorf("%s with %s: the HLSL output must include %q but does not:\n%s", c.expr, typ, want, vs)
+			}
+			want = "return " + c.metal + ";"
+			if m := msl.Compile(s, "Vertex", "Fragment"); !strings.Contains(m, want) {
+				t.Errorf("%s with %s: the Metal output must include %q but does not:\n%s", c.expr, typ, want, m)
+			}
+		}
+	}
+}
+
+func TestCompileRequiredFeatures(t *testing.T) {
+	src := []byte(`//kage:require derivatives
+
+package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return vec4(dfdx(srcPos.x), dfdy(srcPos.y), 0, 1)
+}
+`)
+	s, err := shader.Compile(src, "Vertex", "Fragment", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s.RequiredFeatures, []shaderir.Feature{shaderir.FeatureDerivatives}; len(got) != len(want) || got[0] != want[0] {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+
+	for _, features := range [][]shaderir.Feature{
+		glsl.Features(glsl.GLSLVersionDefault),
+		glsl.Features(glsl.GLSLVersionES300),
+		hlsl.Features(),
+		msl.Features(),
+	} {
+		if err := s.CheckFeatures(features); err != nil {
+			t.Errorf("CheckFeatures(%v) must not return an error but returned %v", features, err)
+		}
+	}
+
+	// A target without derivatives.
+	if err := s.CheckFeatures([]shaderir.Feature{shaderir.FeatureIntUniforms}); err == nil {
+		t.Errorf("CheckFeatures must return an error but does not")
+	}
+
+	// An unknown GLSL version supports no features.
+	if err := s.CheckFeatures(glsl.Features(glsl.GLSLVersion(-1))); err == nil {
+		t.Errorf("CheckFeatures with an unknown GLSL version must return an error but does not")
+	}
+
+	if _, err := shader.Compile([]byte(`//kage:require foo
+
+package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return color
+}
+`), "Vertex", "Fragment", 0); err == nil {
+		t.Errorf("an unknown required feature must return an error but does not")
+	}
+}
+
+func TestCompilePrecision(t *testing.T) {
+	const body = `package main
+
+var Scale float
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return color * Scale
+}
+`
+	s, err := shader.Compile([]byte(body), "Vertex", "Fragment", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vs, fs := glsl.Compile(s, glsl.GLSLVersionES300)
+	if !strings.Contains(fs, "precision highp float;") || !strings.Contains(fs, "precision highp int;") {
+		t.Errorf("the fragment shader must use highp by default:\n%s", fs)
+	}
+	if strings.Contains(vs, "precision ") {
+		t.Errorf("the vertex shader must not have precision statements by default:\n%s", vs)
+	}
+
+	s, err = shader.Compile([]byte("//kage:precision mediump float\n\n"+body), "Vertex", "Fragment", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s.FloatPrecision, shaderir.PrecisionMedium; got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+	vs, fs = glsl.Compile(s, glsl.GLSLVersionES300)
+	// The precisions must be the same in both shaders as the uniform variables are shared.
+	for _, src := range []string{vs, fs} {
+		if !strings.Contains(src, "precision mediump float;") || !strings.Contains(src, "precision highp int;") {
+			t.Errorf("the shader must use mediump for floats and highp for ints:\n%s", src)
+		}
+	}
+
+	for _, directive := range []string{
+		"//kage:precision foo float",
+		"//kage:precision highp bool",
+		"//kage:precision highp float\n//kage:precision lowp float",
+	} {
+		if _, err := shader.Compile([]byte(directive+"\n\n"+body), "Vertex", "Fragment", 0); err == nil {
+			t.Errorf("%q must return an error but does not", directive)
+		}
+	}
+}
+
+func TestCompileAttributes(t *testing.T) {
+	s, err := shader.Compile([]byte(`//kage:attribute Normal vec3
+
+package main
+
+func Vertex(dstPos vec2, srcPos vec2, color vec4) (vec4, vec2, vec4) {
+	return vec4(dstPos, 0, 1), srcPos, vec4(Normal, color.a)
+}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return color
+}
+`), "Vertex", "Fragment", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(s.Attributes), 4; got != want {
+		t.Fatalf("len(s.Attributes): got: %d, want: %d", got, want)
+	}
+	if got, want := s.Attributes[3].Main, shaderir.Vec3; got != want {
+		t.Errorf("s.Attributes[3].Main: got: %v, want: %v", got, want)
+	}
+
+	cases := []struct {
+		name string
+		src  string
+	}{
+		{
+			name: "invalid type",
+			src: `//kage:attribute Normal mat3
+
+package main
+
+func Vertex(dstPos vec2) vec4 {
+	return vec4(dstPos, 0, 1)
+}`,
+		},
+		{
+			name: "duplicated",
+			src: `//kage:attribute Normal vec3
+//kage:attribute Normal vec3
+
+package main
+
+func Vertex(dstPos vec2) vec4 {
+	return vec4(dstPos, 0, 1)
+}`,
+		},
+		{
+			name: "parameter",
+			src: `//kage:attribute dstPos vec3
+
+package main
+
+func Vertex(dstPos vec2) vec4 {
+	return vec4(dstPos, 0, 1)
+}`,
+		},
+		{
+			name: "fragment",
+			src: `//kage:attribute Normal vec3
+
+package main
+
+func Vertex(dstPos vec2) vec4 {
+	return vec4(dstPos, 0, 1)
+}
+
+func Fragment(dstPos vec4) vec4 {
+	return vec4(Normal, 1)
+}`,
+		},
+		{
+			name: "function",
+			src: `//kage:attribute Normal vec3
+
+package main
+
+func Foo() vec3 {
+	return Normal
+}
+
+func Vertex(dstPos vec2) vec4 {
+	return vec4(Foo(), 1)
+}`,
+		},
+		{
+			name: "no vertex entry point",
+			src: `//kage:attribute Normal vec3
+
+package main
+
+func Fragment(dstPos vec4) vec4 {
+	return dstPos
+}`,
+		},
+	}
+	for _, c := range cases {
+		if _, err := shader.Compile([]byte(c.src), "Vertex", "Fragment", 0); err == nil {
+			t.Errorf("%s: Compile must return an error but does not", c.name)
+		}
+	}
+}
+
+func TestCompileVaryingsHLSL(t *testing.T) {
+	src, err := os.ReadFile(filepath.Join("testdata", "varyings.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := shader.Compile(src, "Vertex", "Fragment", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vs, ps, _ := hlsl.Compile(s)
+	// The default varyings keep their semantics, and the additional ones use TEXCOORD1 and later.
+	for _, member := range []string{
+		"float2 M0 : TEXCOORD0;",
+		"float4 M1 : COLOR;",
+		"float M2 : TEXCOORD1;",
+		"float3 M3 : TEXCOORD2;",
+	} {
+		if !strings.Contains(vs, member) || !strings.Contains(ps, member) {
+			t.Errorf("the shaders must have a member %q in Varyings", member)
+		}
+	}
+}
+
+func TestCompileRecover(t *testing.T) {
+	cases := []struct {
+		name  string
+		src   string
+		funcs int
+	}{
+		{
+			name: "syntax error",
+			src: `package main
+
+func Foo() vec4 {
+	return vec4(1)
+}
+
+func Bar() vec4 {
+	x := 1 +
+	return vec4(0)
+}
+
+var Color vec4
+`,
+			funcs: 2,
+		},
+		{
+			name: "type error",
+			src: `package main
+
+func Foo() vec4 {
+	var x float = vec2(1)
+	y := 1.0
+	return vec4(y)
+}
+
+func Bar() vec4 {
+	return Color * undefined
+}
+
+var Color vec4
+`,
+			funcs: 2,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			p, err := shader.CompileWithOptions([]byte(c.src), "Vertex", "Fragment", 0, &shader.CompileOptions{
+				Recover: true,
+			})
+			if err == nil {
+				t.Fatal("CompileWithOptions must return an error but does not")
+			}
+			if p == nil {
+				t.Fatal("CompileWithOptions must return a partial program but does not")
+			}
+			if got, want := len(p.Funcs), c.funcs; got != want {
+				t.Errorf("len(p.Funcs): got: %d, want: %d", got, want)
+			}
+			if got, want := p.UniformNames, []string{"Color"}; len(got) != len(want) || got[0] != want[0] {
+				t.Errorf("p.UniformNames: got: %v, want: %v", got, want)
+			}
+			if len(p.Funcs) > 0 && len(p.Funcs[0].Block.Stmts) == 0 {
+				t.Errorf("the first function must have statements but does not")
+			}
+
+			// Without Recover, no program is returned.
+			p, err = shader.CompileWithOptions([]byte(c.src), "Vertex", "Fragment", 0, nil)
+			if err == nil {
+				t.Errorf("CompileWithOptions must return an error but does not")
+			}
+			if p != nil {
+				t.Errorf("CompileWithOptions without Recover must not return a program but does")
+			}
+		})
+	}
+}
+
+func TestCompileRecoverErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "error in a function signature",
+			src: `package main
+
+func Foo(x Foo) vec4 {
+	return vec4(1)
+}
+`,
+			want: "3:12: unexpected type: Foo",
+		},
+		{
+			name: "syntax error",
+			src: `package main
+
+func Foo() vec4 {
+	x := (
+	return vec4(0)
+}
+`,
+			want: `5:2: expected ')', found 'return'
+5:2: expected operand, found 'return'
+5:9: expected ';', found vec4
+6:3: expected ';', found 'EOF'
+6:3: expected '}', found 'EOF'`,
+		},
+		{
+			name: "type name as value",
+			src: `package main
+
+func Foo() vec4 {
+	x := 1.0
+	x = vec3
+	v := vec3(0)
+	v += vec3
+	w := ivec3(0)
+	w &^= ivec3
+	_ = vec2.xy
+	return vec4(x)
+}
+`,
+			want: `5:6: vec3 is not a value
+7:7: vec3 is not a value
+9:8: ivec3 is not a value
+10:6: invalid selector: the operand of .xy is not a value`,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			_, err := shader.CompileWithOptions([]byte(c.src), "Vertex", "Fragment", 0, &shader.CompileOptions{
+				Recover: true,
+			})
+			if err == nil {
+				t.Fatal("CompileWithOptions must return an error but does not")
+			}
+			if got, want := err.Error(), c.want; got != want {
+				t.Errorf("Error(): got: %q, want: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestCompileErrorPositions(t *testing.T) {
+	cases := []struct {
+		name string
+		src  string
+		want []shader.Error
+	}{
+		{
+			name: "syntax error",
+			src: `package main
+
+func Foo() vec4 {
+	return vec4(1))
+}
+`,
+			want: []shader.Error{
+				{Line: 4, Column: 16, Message: "expected statement, found ')'"},
+				{Line: 5, Column: 3, Message: "expected ';', found 'EOF'"},
+				{Line: 5, Column: 3, Message: "expected '}', found 'EOF'"},
+			},
+		},
+		{
+			name: "type errors",
+			src: `package main
+
+func Foo() vec4 {
+	var x float = vec2(1)
+	return vec4(x)
+}
+
+func Bar() vec4 {
+	return Color * undefined
+}
+
+var Color vec4
+`,
+			want: []shader.Error{
+				{Line: 4, Column: 6, Message: "cannot use type vec2 as type float in variable declaration"},
+				{Line: 9, Column: 17, Message: "unexpected identifier: undefined"},
+			},
+		},
+		{
+			name: "multiple errors in a block",
+			src: `package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	var a float = vec2(1)
+	b := 1.0
+	b = undefined
+	c := vec2(1)
+	c.xx = vec2(2)
+	return vec4(a, b, c)
+}
+`,
+			want: []shader.Error{
+				{Line: 4, Column: 6, Message: "cannot use type vec2 as type float in variable declaration"},
+				{Line: 6, Column: 6, Message: "unexpected identifier: undefined"},
+				{Line: 8, Column: 2, Message: "cannot assign to swizzling with duplicated components: xx"},
+			},
+		},
+		{
+			name: "no cascading errors from a failed declaration",
+			src: `package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	a := undefined
+	b := a * 2
+	var c = b + a
+	d := vec4(c)
+	return d
+}
+`,
+			want: []shader.Error{
+				{Line: 4, Column: 7, Message: "unexpected identifier: undefined"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			_, err := shader.Compile([]byte(c.src), "Vertex", "Fragment", 0)
+			perr, ok := err.(*shader.ParseError)
+			if !ok {
+				t.Fatalf("CompileWithOptions must return a *shader.ParseError but %T: %v", err, err)
+			}
+			got := perr.Errors()
+			if len(got) != len(c.want) {
+				t.Fatalf("Errors(): got: %v, want: %v", got, c.want)
+			}
+			var strs []string
+			for i := range got {
+				if got[i] != c.want[i] {
+					t.Errorf("Errors()[%d]: got: %+v, want: %+v", i, got[i], c.want[i])
+				}
+				strs = append(strs, fmt.Sprintf("%d:%d: %s", c.want[i].Line, c.want[i].Column, c.want[i].Message))
+			}
+			if got, want := err.Error(), strings.Join(strs, "\n"); got != want {
+				t.Errorf("Error(): got: %q, want: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestCompileSourceMap(t *testing.T) {
+	src := []byte(`package main
+
+func Foo(x float) float {
+	y := x * 2
+	if y > 1 {
+		y = 1
+	} else {
+		for i := 0; i < 4; i++ {
+			y += 0.5
+		}
+	}
+	return y
+}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return vec4(Foo(srcPos.x))
+}
+`)
+	s, err := shader.Compile(src, "Vertex", "Fragment", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vs0, fs0 := glsl.Compile(s, glsl.GLSLVersionDefault)
+	vs, fs, vsMap, fsMap := glsl.CompileWithSourceMap(s, glsl.GLSLVersionDefault)
+	if vs != vs0 || fs != fs0 {
+		t.Errorf("the shaders must be the same as the ones without a source map")
+	}
+	if got, want := len(vsMap), strings.Count(vs, "\n"); got != want {
+		t.Errorf("len(vertexSourceMap): got: %d, want: %d", got, want)
+	}
+
+	lines := strings.Split(fs, "\n")
+	if got, want := len(fsMap), len(lines)-1; got != want {
+		t.Fatalf("len(fragmentSourceMap): got: %d, want: %d", got, want)
+	}
+	want := map[string]int{
+		"l1 = (l0) * (2.0);":               4,
+		"if ((l1) > (1.0)) {":              5,
+		"l1 = 1.0;":                        6,
+		"} else {":                         5,
+		"for (int l2 = 0; l2 < 4; l2++) {": 8,
+		"l1 = (l1) + (5.0000000000e-01);":  9,
+		"return l1;":                       12,
+		"return vec4(F0((l1).x));":         16,
+	}
+	for i, l := range lines[:len(fsMap)] {
+		l = strings.TrimSpace(l)
+		if w, ok := want[l]; ok {
+			if got := fsMap[i]; got != w {
+				t.Errorf("line %d %q: got: %d, want: %d", i+1, l, got, w)
+			}
+			delete(want, l)
+			continue
+		}
+		if l == "float l1 = float(0);" && fsMap[i] != 0 {
+			t.Errorf("line %d %q: got: %d, want: 0", i+1, l, fsMap[i])
+		}
+	}
+	for l := range want {
+		t.Errorf("line %q is not found:\n%s", l, fs)
+	}
+}
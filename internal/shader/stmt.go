@@ -29,6 +29,10 @@ func (cs *compileState) forceToInt(node ast.Node, expr *shaderir.Expr) bool {
 		cs.addError(node.Pos(), fmt.Sprintf("constant %s truncated to integer", expr.Const.String()))
 		return false
 	}
+	if !canRepresentAsInt32(expr.Const) {
+		cs.addError(node.Pos(), fmt.Sprintf("constant %s overflows int", expr.Const.String()))
+		return false
+	}
 	expr.Const = gconstant.ToInt(expr.Const)
 	return true
 }
@@ -60,23 +64,35 @@ func (cs *compileState) parseStmt(block *block, fname string, stmt ast.Stmt, inP
 				return nil, false
 			}
 			stmts = append(stmts, ss...)
-		case token.ADD_ASSIGN, token.SUB_ASSIGN, token.MUL_ASSIGN, token.QUO_ASSIGN, token.REM_ASSIGN, token.AND_ASSIGN, token.OR_ASSIGN, token.XOR_ASSIGN, token.AND_NOT_ASSIGN:
+		case token.ADD_ASSIGN, token.SUB_ASSIGN, token.MUL_ASSIGN, token.QUO_ASSIGN, token.REM_ASSIGN, token.AND_ASSIGN, token.OR_ASSIGN, token.XOR_ASSIGN, token.AND_NOT_ASSIGN, token.SHL_ASSIGN, token.SHR_ASSIGN:
 			rhs, rts, ss, ok := cs.parseExpr(block, fname, stmt.Rhs[0], true)
 			if !ok {
 				return nil, false
 			}
 			stmts = append(stmts, ss...)
+			if !cs.checkSingleValue(stmt.Rhs[0], rhs, rts) {
+				return nil, false
+			}
 
 			lhs, lts, ss, ok := cs.parseExpr(block, fname, stmt.Lhs[0], true)
 			if !ok {
 				return nil, false
 			}
 			stmts = append(stmts, ss...)
+			if !cs.checkSingleValue(stmt.Lhs[0], lhs, lts) {
+				return nil, false
+			}
 
 			if lhs[0].Type == shaderir.UniformVariable {
 				cs.addError(stmt.Pos(), "a uniform variable cannot be assigned")
 				return nil, false
 			}
+			if s, ok := duplicatedSwizzling(&lhs[0]); ok {
+				cs.addError(stmt.Pos(), fmt.Sprintf("cannot assign to swizzling with duplicated components: %s", s))
+				return nil, false
+			}
+			// The LHS is both read and written. Evaluate its indices only once.
+			stmts = append(stmts, cs.bindIndicesToTemporaryVariables(block, &lhs[0])...)
 
 			var op shaderir.Op
 			switch stmt.Tok {
@@ -100,6 +116,13 @@ func (cs *compileState) parseStmt(block *block, fname string, stmt ast.Stmt, inP
 				op = shaderir.Or
 			case token.XOR_ASSIGN:
 				op = shaderir.Xor
+			case token.AND_NOT_ASSIGN:
+				// x &^= y is lowered to x &= ^y below.
+				op = shaderir.And
+			case token.SHL_ASSIGN:
+				op = shaderir.LeftShift
+			case token.SHR_ASSIGN:
+				op = shaderir.RightShift
 			default:
 				cs.addError(stmt.Pos(), fmt.Sprintf("unexpected token: %s", stmt.Tok))
 				return nil, false
@@ -110,14 +133,15 @@ func (cs *compileState) parseStmt(block *block, fname string, stmt ast.Stmt, inP
 					cs.addError(stmt.Pos(), fmt.Sprintf("invalid operation: operator / not defined on %s", rts[0].String()))
 					return nil, false
 				}
-				if op == shaderir.And || op == shaderir.Or || op == shaderir.Xor {
+				if isBitwiseOp(op) {
 					if lts[0].Main != shaderir.Int && !lts[0].IsIntVector() {
 						cs.addError(stmt.Pos(), fmt.Sprintf("invalid operation: operator %s not defined on %s", stmt.Tok, lts[0].String()))
+						return nil, false
 					}
 					if rts[0].Main != shaderir.Int && !rts[0].IsIntVector() {
 						cs.addError(stmt.Pos(), fmt.Sprintf("invalid operation: operator %s not defined on %s", stmt.Tok, rts[0].String()))
+						return nil, false
 					}
-					return nil, false
 				}
 				if lts[0].Main == shaderir.Int && rhs[0].Const != nil {
 					if !cs.forceToInt(stmt, &rhs[0]) {
@@ -137,8 +161,9 @@ func (cs *compileState) parseStmt(block *block, fname string, stmt ast.Stmt, inP
 						}
 					}
 				case shaderir.Float:
-					if op == shaderir.And || op == shaderir.Or || op == shaderir.Xor {
+					if isBitwiseOp(op) {
 						cs.addError(stmt.Pos(), fmt.Sprintf("invalid operation: operator %s not defined on %s", stmt.Tok, lts[0].String()))
+						return nil, false
 					} else if rhs[0].Const != nil &&
 						(rts[0].Main == shaderir.None || rts[0].Main == shaderir.Float) &&
 						gconstant.ToFloat(rhs[0].Const).Kind() != gconstant.Unknown {
@@ -148,8 +173,9 @@ func (cs *compileState) parseStmt(block *block, fname string, stmt ast.Stmt, inP
 						return nil, false
 					}
 				case shaderir.Vec2, shaderir.Vec3, shaderir.Vec4, shaderir.Mat2, shaderir.Mat3, shaderir.Mat4:
-					if op == shaderir.And || op == shaderir.Or || op == shaderir.Xor {
+					if isBitwiseOp(op) {
 						cs.addError(stmt.Pos(), fmt.Sprintf("invalid operation: operator %s not defined on %s", stmt.Tok, lts[0].String()))
+						return nil, false
 					} else if (op == shaderir.MatrixMul || op == shaderir.Div) &&
 						(rts[0].Main == shaderir.Float ||
 							(rhs[0].Const != nil &&
@@ -180,11 +206,34 @@ func (cs *compileState) parseStmt(block *block, fname string, stmt ast.Stmt, inP
 				}
 			}
 
-			if op == shaderir.ModOp && lts[0].Main != shaderir.Int && lts[0].Main != shaderir.IVec2 && lts[0].Main != shaderir.IVec3 && lts[0].Main != shaderir.IVec4 {
+			if (op == shaderir.LeftShift || op == shaderir.RightShift) && rhs[0].Const != nil && gconstant.Sign(rhs[0].Const) < 0 {
+				cs.addError(stmt.Pos(), fmt.Sprintf("invalid shift count: %s", rhs[0].Const.String()))
+				return nil, false
+			}
+
+			if (op == shaderir.Div || op == shaderir.ModOp) && hasConstantZero(&rhs[0]) {
+				cs.addError(stmt.Pos(), "invalid operation: division by zero")
+				return nil, false
+			}
+
+			if op == shaderir.ModOp && lts[0].Main != shaderir.Int && !lts[0].IsIntVector() && lts[0].Main != shaderir.Float && !lts[0].IsFloatVector() {
 				cs.addError(stmt.Pos(), fmt.Sprintf("invalid operation: operator %% not defined on %s", lts[0].String()))
 				return nil, false
 			}
 
+			if stmt.Tok == token.AND_NOT_ASSIGN {
+				rhs[0] = bitwiseComplement(rhs[0])
+			}
+
+			// % is available only for integers in backends like GLSL. Use mod for floats.
+			if op == shaderir.ModOp && (lts[0].Main == shaderir.Float || lts[0].IsFloatVector()) {
+				stmts = append(stmts, shaderir.Stmt{
+					Type:  shaderir.Assign,
+					Exprs: []shaderir.Expr{lhs[0], builtinCall(shaderir.Mod, lhs[0], rhs[0])},
+				})
+				break
+			}
+
 			stmts = append(stmts, shaderir.Stmt{
 				Type: shaderir.Assign,
 				Exprs: []shaderir.Expr{
@@ -221,7 +270,26 @@ func (cs *compileState) parseStmt(block *block, fname string, stmt ast.Stmt, inP
 		stmts = append(stmts, ss...)
 
 	case *ast.ForStmt:
-		ss, ok := cs.parseFor(block, fname, stmt, inParams, outParams, returnType, true)
+		ss, ok := cs.parseFor(block, fname, stmt, "", inParams, outParams, returnType, true)
+		if !ok {
+			return nil, false
+		}
+		stmts = append(stmts, ss...)
+
+	case *ast.LabeledStmt:
+		f, ok := stmt.Stmt.(*ast.ForStmt)
+		if !ok {
+			cs.addError(stmt.Pos(), "a label is available only for a for-statement")
+			return nil, false
+		}
+		ss, ok := cs.parseFor(block, fname, f, stmt.Label.Name, inParams, outParams, returnType, true)
+		if !ok {
+			return nil, false
+		}
+		stmts = append(stmts, ss...)
+
+	case *ast.SwitchStmt:
+		ss, ok := cs.parseSwitch(block, fname, stmt, inParams, outParams, returnType)
 		if !ok {
 			return nil, false
 		}
@@ -229,9 +297,11 @@ func (cs *compileState) parseStmt(block *block, fname string, stmt ast.Stmt, inP
 
 	case *ast.IfStmt:
 		if stmt.Init != nil {
-			init := stmt.Init
-			stmt.Init = nil
-			b, ok := cs.parseBlock(block, fname, []ast.Stmt{init, stmt}, inParams, outParams, returnType, true)
+			// Parse the statement without the init statement in a new block after the init statement.
+			// Copy the statement not to modify the AST.
+			s := *stmt
+			s.Init = nil
+			b, ok := cs.parseBlock(block, fname, []ast.Stmt{stmt.Init, &s}, inParams, outParams, returnType, true)
 			if !ok {
 				return nil, false
 			}
@@ -288,11 +358,21 @@ func (cs *compileState) parseStmt(block *block, fname string, stmt ast.Stmt, inP
 		})
 
 	case *ast.IncDecStmt:
-		exprs, _, ss, ok := cs.parseExpr(block, fname, stmt.X, true)
+		exprs, ts, ss, ok := cs.parseExpr(block, fname, stmt.X, true)
 		if !ok {
 			return nil, false
 		}
 		stmts = append(stmts, ss...)
+		if !cs.checkSingleValue(stmt.X, exprs, ts) {
+			return nil, false
+		}
+		if s, ok := duplicatedSwizzling(&exprs[0]); ok {
+			cs.addError(stmt.Pos(), fmt.Sprintf("cannot assign to swizzling with duplicated components: %s", s))
+			return nil, false
+		}
+		// The operand is both read and written. Evaluate its indices only once.
+		stmts = append(stmts, cs.bindIndicesToTemporaryVariables(block, &exprs[0])...)
+
 		var op shaderir.Op
 		switch stmt.Tok {
 		case token.INC:
@@ -394,6 +474,9 @@ func (cs *compileState) parseStmt(block *block, fname string, stmt ast.Stmt, inP
 				cs.addError(stmt.Pos(), fmt.Sprintf("cannot use type %s as type %s in return argument", t.String(), &outT))
 				return nil, false
 			}
+			if outT.Main == shaderir.Float && len(stmt.Results) == len(exprs) {
+				cs.warnIntegerDivisionInFloatContext(stmt.Results[i], exprs[i], types[i])
+			}
 
 			if len(outParams) > 0 {
 				stmts = append(stmts, shaderir.Stmt{
@@ -423,12 +506,23 @@ func (cs *compileState) parseStmt(block *block, fname string, stmt ast.Stmt, inP
 		}
 
 	case *ast.BranchStmt:
+		if stmt.Label != nil {
+			ss, ok := cs.parseLabeledBranch(stmt)
+			if !ok {
+				return nil, false
+			}
+			stmts = append(stmts, ss...)
+			break
+		}
 		switch stmt.Tok {
 		case token.BREAK:
 			stmts = append(stmts, shaderir.Stmt{
 				Type: shaderir.Break,
 			})
 		case token.CONTINUE:
+			if len(cs.loops) > 0 {
+				stmts = append(stmts, cs.loops[len(cs.loops)-1].post...)
+			}
 			stmts = append(stmts, shaderir.Stmt{
 				Type: shaderir.Continue,
 			})
@@ -465,6 +559,11 @@ func (cs *compileState) parseStmt(block *block, fname string, stmt ast.Stmt, inP
 			})
 		}
 
+	case *ast.BadStmt:
+		// The parser already reported the syntax error. This is reached only in the recovery mode.
+		cs.badNodes++
+		return nil, false
+
 	default:
 		cs.addError(stmt.Pos(), fmt.Sprintf("unexpected statement: %#v", stmt))
 		return nil, false
@@ -478,14 +577,39 @@ func (cs *compileState) assign(block *block, fname string, pos token.Pos, lhs, r
 	var rhsTypes []shaderir.Type
 	allblank := true
 
-	for i, e := range lhs {
-		if len(lhs) == len(rhs) {
-			// Prase RHS first for the order of the statements.
-			r, rts, ss, ok := cs.parseExpr(block, fname, rhs[i], true)
+	if define {
+		// The parser reports this, but the AST is still available in the recovery mode.
+		for _, e := range lhs {
+			if _, ok := e.(*ast.Ident); !ok {
+				cs.addError(e.Pos(), fmt.Sprintf("non-name %s on left side of :=", e))
+				return nil, false
+			}
+		}
+	}
+
+	// Parse all the RHS expressions first, as they must be evaluated before any assignments.
+	// For example, a, b = b, a must swap the values, and x, y := 1, x must refer to the outer x.
+	var rs [][]shaderir.Expr
+	var rtss [][]shaderir.Type
+	if len(lhs) == len(rhs) {
+		for _, e := range rhs {
+			r, rts, ss, ok := cs.parseExpr(block, fname, e, true)
 			if !ok {
 				return nil, false
 			}
 			stmts = append(stmts, ss...)
+			rs = append(rs, r)
+			rtss = append(rtss, rts)
+		}
+	}
+
+	// The assignments from the temporary variables in multiple assignments.
+	// These must be done after all the RHS values are stored in the temporary variables.
+	var tmpAssigns []shaderir.Stmt
+
+	for i, e := range lhs {
+		if len(lhs) == len(rhs) {
+			r, rts := rs[i], rtss[i]
 
 			if define {
 				name := e.(*ast.Ident).Name
@@ -501,7 +625,7 @@ func (cs *compileState) assign(block *block, fname string, pos token.Pos, lhs, r
 				if !ok {
 					ts = rts
 				}
-				if len(ts) > 1 {
+				if len(ts) != 1 {
 					cs.addError(pos, "single-value context and multiple-value context cannot be mixed")
 					return nil, false
 				}
@@ -509,7 +633,11 @@ func (cs *compileState) assign(block *block, fname string, pos token.Pos, lhs, r
 				if t.Main == shaderir.None {
 					t = toDefaultType(r[0].Const)
 				}
-				block.addNamedLocalVariable(name, t, e.Pos())
+				t.Precision = conversionPrecision(rhs[i], &t)
+				// A blank identifier doesn't declare a variable. The RHS is still evaluated for its side effects.
+				if name != "_" {
+					block.addNamedLocalVariable(name, t, e.Pos())
+				}
 			}
 
 			if len(r) > 1 {
@@ -531,8 +659,16 @@ func (cs *compileState) assign(block *block, fname string, pos token.Pos, lhs, r
 				}
 				return nil, false
 			}
+			if !cs.checkSingleValue(rhs[i], r, rts) {
+				return nil, false
+			}
+			if l[0].Type != shaderir.Blank && !cs.checkSingleValue(lhs[i], l, lts) {
+				return nil, false
+			}
 
 			if l[0].Type == shaderir.Blank {
+				block.discardLocalVariables(&r[0])
+				stmts = cs.evaluateBlankValue(block, r[0], rts[0], stmts)
 				continue
 			}
 
@@ -557,14 +693,22 @@ func (cs *compileState) assign(block *block, fname string, pos token.Pos, lhs, r
 				cs.addError(pos, "a uniform variable cannot be assigned")
 				return nil, false
 			}
+			if s, ok := duplicatedSwizzling(&l[0]); ok {
+				cs.addError(pos, fmt.Sprintf("cannot assign to swizzling with duplicated components: %s", s))
+				return nil, false
+			}
 			allblank = false
 
 			for i := range lts {
 				if !canAssign(&lts[i], &rts[i], r[i].Const) {
-					cs.addError(pos, fmt.Sprintf("cannot use type %s as type %s in variable declaration", rts[i].String(), lts[i].String()))
+					cs.addError(pos, fmt.Sprintf("cannot use type %s as type %s in variable declaration%s", rts[i].String(), lts[i].String(), scalarBroadcastHint(&lts[i], &rts[i], r[i].Const)))
 					return nil, false
 				}
 			}
+			if lts[0].Main == shaderir.Float {
+				cs.warnIntegerDivisionInFloatContext(rhs[i], r[0], rts[0])
+			}
+			convertConstant(&r[0], &lts[0])
 
 			if len(lhs) == 1 {
 				stmts = append(stmts, shaderir.Stmt{
@@ -581,27 +725,26 @@ func (cs *compileState) assign(block *block, fname string, pos token.Pos, lhs, r
 					typ: t,
 				})
 				idx := block.totalLocalVariableCount() - 1
-				stmts = append(stmts,
-					shaderir.Stmt{
-						Type: shaderir.Assign,
-						Exprs: []shaderir.Expr{
-							{
-								Type:  shaderir.LocalVariable,
-								Index: idx,
-							},
-							r[0],
+				stmts = append(stmts, shaderir.Stmt{
+					Type: shaderir.Assign,
+					Exprs: []shaderir.Expr{
+						{
+							Type:  shaderir.LocalVariable,
+							Index: idx,
 						},
+						r[0],
 					},
-					shaderir.Stmt{
-						Type: shaderir.Assign,
-						Exprs: []shaderir.Expr{
-							l[0],
-							{
-								Type:  shaderir.LocalVariable,
-								Index: idx,
-							},
+				})
+				tmpAssigns = append(tmpAssigns, shaderir.Stmt{
+					Type: shaderir.Assign,
+					Exprs: []shaderir.Expr{
+						l[0],
+						{
+							Type:  shaderir.LocalVariable,
+							Index: idx,
 						},
-					})
+					},
+				})
 			}
 		} else {
 			if i == 0 {
@@ -611,8 +754,17 @@ func (cs *compileState) assign(block *block, fname string, pos token.Pos, lhs, r
 				if !ok {
 					return nil, false
 				}
-				if len(rhsExprs) != len(lhs) {
+				if ts, ok := cs.functionReturnTypes(block, rhs[0]); ok {
+					if len(ts) != len(lhs) {
+						cs.addError(pos, fmt.Sprintf("assignment mismatch: %d variables but %s() returns %d %s", len(lhs), rhs[0].(*ast.CallExpr).Fun, len(ts), pluralValues(len(ts))))
+						return nil, false
+					}
+					// Use the original returning value types rather than the types for IR.
+					rhsTypes = ts
+				}
+				if len(rhsExprs) != len(lhs) || len(rhsTypes) != len(lhs) {
 					cs.addError(pos, "single-value context and multiple-value context cannot be mixed")
+					return nil, false
 				}
 				stmts = append(stmts, ss...)
 			}
@@ -633,7 +785,10 @@ func (cs *compileState) assign(block *block, fname string, pos token.Pos, lhs, r
 					// but there are no actual cases when len(lhs) != len(rhs). Is this correct?
 					t = toDefaultType(rhsExprs[i].Const)
 				}
-				block.addNamedLocalVariable(name, t, e.Pos())
+				t.Precision = shaderir.PrecisionDefault
+				if name != "_" {
+					block.addNamedLocalVariable(name, t, e.Pos())
+				}
 			}
 
 			l, lts, ss, ok := cs.parseExpr(block, fname, lhs[i], false)
@@ -652,12 +807,14 @@ func (cs *compileState) assign(block *block, fname string, pos token.Pos, lhs, r
 			}
 
 			if l[0].Type == shaderir.Blank {
+				block.discardLocalVariables(&rhsExprs[i])
+				stmts = cs.evaluateBlankValue(block, rhsExprs[i], rhsTypes[i], stmts)
 				continue
 			}
 			allblank = false
 
 			if !canAssign(&lts[0], &rhsTypes[i], rhsExprs[i].Const) {
-				cs.addError(pos, fmt.Sprintf("cannot use type %s as type %s in variable declaration", rhsTypes[i].String(), lts[0].String()))
+				cs.addError(pos, fmt.Sprintf("cannot use type %s as type %s in variable declaration%s", rhsTypes[i].String(), lts[0].String(), scalarBroadcastHint(&lts[0], &rhsTypes[i], rhsExprs[i].Const)))
 				return nil, false
 			}
 
@@ -673,9 +830,52 @@ func (cs *compileState) assign(block *block, fname string, pos token.Pos, lhs, r
 		return nil, false
 	}
 
+	stmts = append(stmts, tmpAssigns...)
 	return stmts, true
 }
 
+// checkSingleValue reports whether the parsed expression e is a single value with a type.
+// A type or a function name has no type. checkSingleValue reports an error if e is not a single value.
+func (cs *compileState) checkSingleValue(e ast.Expr, exprs []shaderir.Expr, ts []shaderir.Type) bool {
+	if len(exprs) == 1 && len(ts) == 1 {
+		return true
+	}
+	if len(exprs) > 1 {
+		cs.addError(e.Pos(), "single-value context and multiple-value context cannot be mixed")
+		return false
+	}
+	if ident, ok := e.(*ast.Ident); ok {
+		cs.addError(e.Pos(), fmt.Sprintf("%s is not a value", ident.Name))
+		return false
+	}
+	cs.addError(e.Pos(), "the expression is not a value")
+	return false
+}
+
+// evaluateBlankValue appends statements to evaluate expr assigned to a blank identifier.
+// expr is evaluated only when it might have side effects, e.g., a call of a function that discards the fragment.
+func (cs *compileState) evaluateBlankValue(block *block, expr shaderir.Expr, t shaderir.Type, stmts []shaderir.Stmt) []shaderir.Stmt {
+	if !hasSideEffects(&expr) {
+		return stmts
+	}
+	if expr.Type == shaderir.Call {
+		return append(stmts, shaderir.Stmt{
+			Type:  shaderir.ExprStmt,
+			Exprs: []shaderir.Expr{expr},
+		})
+	}
+	_, stmts = cs.evaluateOnce(block, expr, t, stmts)
+	return stmts
+}
+
+// pluralValues returns "value" or "values" for an error message about n values.
+func pluralValues(n int) string {
+	if n == 1 {
+		return "value"
+	}
+	return "values"
+}
+
 func toDefaultType(v gconstant.Value) shaderir.Type {
 	switch v.Kind() {
 	case gconstant.Bool:
@@ -689,6 +889,96 @@ func toDefaultType(v gconstant.Value) shaderir.Type {
 	return shaderir.Type{}
 }
 
+// isBitwiseOp reports whether op is a bitwise operator, which is defined only on integer types.
+func isBitwiseOp(op shaderir.Op) bool {
+	switch op {
+	case shaderir.And, shaderir.Or, shaderir.Xor, shaderir.LeftShift, shaderir.RightShift:
+		return true
+	}
+	return false
+}
+
+// vectorComparisonFunc returns the built-in function comparing vectors component-wise for the relational operator op.
+func vectorComparisonFunc(op shaderir.Op) (shaderir.BuiltinFunc, bool) {
+	switch op {
+	case shaderir.LessThanOp:
+		return shaderir.LessThan, true
+	case shaderir.LessThanEqualOp:
+		return shaderir.LessThanEqual, true
+	case shaderir.GreaterThanOp:
+		return shaderir.GreaterThan, true
+	case shaderir.GreaterThanEqualOp:
+		return shaderir.GreaterThanEqual, true
+	}
+	return "", false
+}
+
+// isVector reports whether t is a vector type of any component type.
+func isVector(t shaderir.Type) bool {
+	return t.IsFloatVector() || t.IsIntVector() || t.IsBoolVector()
+}
+
+// duplicatedSwizzling reports whether the assigned expression e has a swizzling with duplicated components like v.xx.
+// Such a swizzling is not assignable as the same component would be written twice.
+func duplicatedSwizzling(e *shaderir.Expr) (string, bool) {
+	for e.Type == shaderir.FieldSelector || e.Type == shaderir.Index {
+		if e.Type == shaderir.FieldSelector && e.Exprs[1].Type == shaderir.SwizzlingExpr {
+			s := e.Exprs[1].Swizzling
+			for i := 0; i < len(s); i++ {
+				if strings.IndexByte(s[i+1:], s[i]) >= 0 {
+					return s, true
+				}
+			}
+		}
+		e = &e.Exprs[0]
+	}
+	return "", false
+}
+
+// bindIndicesToTemporaryVariables replaces the non-trivial index expressions in the assignment target e with temporary variables.
+// This is used for compound assignments, where e is both read and written, so that the indices are evaluated only once.
+func (cs *compileState) bindIndicesToTemporaryVariables(block *block, e *shaderir.Expr) []shaderir.Stmt {
+	var stmts []shaderir.Stmt
+	for e.Type == shaderir.FieldSelector || e.Type == shaderir.Index {
+		if e.Type == shaderir.Index {
+			switch idx := e.Exprs[1]; idx.Type {
+			case shaderir.NumberExpr, shaderir.LocalVariable, shaderir.UniformVariable:
+			default:
+				block.vars = append(block.vars, variable{
+					typ: shaderir.Type{Main: shaderir.Int},
+				})
+				tmp := shaderir.Expr{
+					Type:  shaderir.LocalVariable,
+					Index: block.totalLocalVariableCount() - 1,
+				}
+				stmts = append(stmts, shaderir.Stmt{
+					Type:  shaderir.Assign,
+					Exprs: []shaderir.Expr{tmp, idx},
+				})
+				e.Exprs[1] = tmp
+			}
+		}
+		e = &e.Exprs[0]
+	}
+	// Evaluate the indices in the source order.
+	for i, j := 0, len(stmts)-1; i < j; i, j = i+1, j-1 {
+		stmts[i], stmts[j] = stmts[j], stmts[i]
+	}
+	return stmts
+}
+
+// scalarBroadcastHint returns a hint for an error message when a scalar value is assigned to a vector.
+// A scalar value is never broadcast implicitly, and the hint suggests a vector constructor instead.
+func scalarBroadcastHint(lt *shaderir.Type, rt *shaderir.Type, rc gconstant.Value) string {
+	if !lt.IsFloatVector() && !lt.IsIntVector() {
+		return ""
+	}
+	if rt.Main != shaderir.Int && rt.Main != shaderir.Float && (rt.Main != shaderir.None || rc == nil || rc.Kind() == gconstant.Bool) {
+		return ""
+	}
+	return fmt.Sprintf("; use %s(...) to set all the components", lt.String())
+}
+
 func canAssign(lt *shaderir.Type, rt *shaderir.Type, rc gconstant.Value) bool {
 	if lt.Equal(rt) {
 		return true
@@ -706,27 +996,71 @@ func canAssign(lt *shaderir.Type, rt *shaderir.Type, rc gconstant.Value) bool {
 	case shaderir.Bool:
 		return rc.Kind() == gconstant.Bool
 	case shaderir.Int:
-		return gconstant.ToInt(rc).Kind() != gconstant.Unknown
+		return gconstant.ToInt(rc).Kind() != gconstant.Unknown && canRepresentAsInt32(rc)
 	case shaderir.Float:
-		return gconstant.ToFloat(rc).Kind() != gconstant.Unknown
+		return gconstant.ToFloat(rc).Kind() != gconstant.Unknown && canRepresentAsFloat32(rc)
 	}
 
 	return false
 }
 
-func (cs *compileState) parseFor(block *block, fname string, stmt *ast.ForStmt, inParams, outParams []variable, returnType shaderir.Type, checkLocalVariableUsage bool) ([]shaderir.Stmt, bool) {
-	msg := "for-statement must follow this format: for (varname) := (constant); (varname) (op) (constant); (varname) (op) (constant) { ..."
-	if stmt.Init == nil {
-		cs.addError(stmt.Pos(), msg)
-		return nil, false
+// convertConstant converts the constant expr to the type t so that the literal has the type, e.g. 1000 for 1e3 as an int.
+func convertConstant(expr *shaderir.Expr, t *shaderir.Type) {
+	if expr.Type != shaderir.NumberExpr || expr.Const == nil {
+		return
 	}
-	if stmt.Cond == nil {
-		cs.addError(stmt.Pos(), msg)
-		return nil, false
+	switch t.Main {
+	case shaderir.Int:
+		if v := gconstant.ToInt(expr.Const); v.Kind() == gconstant.Int {
+			expr.Const = v
+		}
+	case shaderir.Float:
+		if v := gconstant.ToFloat(expr.Const); v.Kind() == gconstant.Float {
+			expr.Const = v
+		}
 	}
-	if stmt.Post == nil {
-		cs.addError(stmt.Pos(), msg)
-		return nil, false
+}
+
+// parseFor parses a for-statement. label is the label of the for-statement, or empty if the for-statement is not labeled.
+//
+// A for-statement in the canonical form is lowered to shaderir.For, which is available for any backends.
+// Otherwise, e.g. a for-statement only with a condition, the for-statement is lowered to shaderir.While.
+func (cs *compileState) parseFor(block *block, fname string, stmt *ast.ForStmt, label string, inParams, outParams []variable, returnType shaderir.Type, checkLocalVariableUsage bool) ([]shaderir.Stmt, bool) {
+	l := loop{
+		label:   label,
+		flagVar: -1,
+	}
+	if label != "" {
+		for _, outer := range cs.loops {
+			if outer.label == label {
+				cs.addError(stmt.Pos(), fmt.Sprintf("label %s already defined", label))
+				return nil, false
+			}
+		}
+		// A labeled branch in an inner loop needs a flag variable to exit the inner loops one by one.
+		// The flag variable must be added before the pseudo block to keep the local variable indices.
+		if hasLabeledBranchInInnerLoop(stmt.Body, label) {
+			block.vars = append(block.vars, variable{
+				typ: shaderir.Type{Main: shaderir.Int},
+			})
+			l.flagVar = block.totalLocalVariableCount() - 1
+		}
+	}
+
+	nerrs := len(cs.errs)
+	stmts, canonical, ok := cs.parseCanonicalFor(block, fname, stmt, l, inParams, outParams, returnType)
+	if canonical {
+		return stmts, ok
+	}
+	cs.errs = cs.errs[:nerrs]
+	return cs.parseWhile(block, fname, stmt, l, inParams, outParams, returnType)
+}
+
+// parseCanonicalFor parses a for-statement in the canonical form with constant bounds, and lowers it to shaderir.For.
+// canonical is false if the for-statement is not in the canonical form. In this case, the added errors should be discarded.
+func (cs *compileState) parseCanonicalFor(block *block, fname string, stmt *ast.ForStmt, l loop, inParams, outParams []variable, returnType shaderir.Type) (stmts []shaderir.Stmt, canonical bool, ok bool) {
+	if stmt.Init == nil || stmt.Cond == nil || stmt.Post == nil {
+		return nil, false, false
 	}
 
 	// Create a new pseudo block for the initial statement, so that the counter variable belongs to the
@@ -734,31 +1068,26 @@ func (cs *compileState) parseFor(block *block, fname string, stmt *ast.ForStmt,
 	// for-loops confuses the parser.
 	pseudoBlock, ok := cs.parseBlock(block, fname, []ast.Stmt{stmt.Init}, inParams, outParams, returnType, false)
 	if !ok {
-		return nil, false
+		return nil, false, false
 	}
 	ss := pseudoBlock.ir.Stmts
 
 	if len(ss) != 1 {
-		cs.addError(stmt.Pos(), msg)
-		return nil, false
+		return nil, false, false
 	}
 	if ss[0].Type != shaderir.Assign {
-		cs.addError(stmt.Pos(), msg)
-		return nil, false
+		return nil, false, false
 	}
 	if ss[0].Exprs[0].Type != shaderir.LocalVariable {
-		cs.addError(stmt.Pos(), msg)
-		return nil, false
+		return nil, false, false
 	}
 	varidx := ss[0].Exprs[0].Index
 	if ss[0].Exprs[1].Const == nil {
-		cs.addError(stmt.Pos(), msg)
-		return nil, false
+		return nil, false, false
 	}
 
 	if len(pseudoBlock.vars) != 1 {
-		cs.addError(stmt.Pos(), msg)
-		return nil, false
+		return nil, false, false
 	}
 
 	vartype := pseudoBlock.vars[0].typ
@@ -766,78 +1095,62 @@ func (cs *compileState) parseFor(block *block, fname string, stmt *ast.ForStmt,
 
 	exprs, ts, ss, ok := cs.parseExpr(pseudoBlock, fname, stmt.Cond, true)
 	if !ok {
-		return nil, false
+		return nil, false, false
 	}
 	if len(exprs) != 1 {
-		cs.addError(stmt.Pos(), msg)
-		return nil, false
+		return nil, false, false
 	}
 	if len(ts) != 1 || ts[0].Main != shaderir.Bool {
-		cs.addError(stmt.Pos(), "for-statement's condition must be bool")
-		return nil, false
+		return nil, false, false
 	}
 	if len(ss) != 0 {
-		cs.addError(stmt.Pos(), msg)
-		return nil, false
+		return nil, false, false
 	}
 	if exprs[0].Type != shaderir.Binary {
-		cs.addError(stmt.Pos(), msg)
-		return nil, false
+		return nil, false, false
 	}
 	op := exprs[0].Op
 	if op != shaderir.LessThanOp && op != shaderir.LessThanEqualOp && op != shaderir.GreaterThanOp && op != shaderir.GreaterThanEqualOp && op != shaderir.EqualOp && op != shaderir.NotEqualOp {
-		cs.addError(stmt.Pos(), "for-statement's condition must have one of these operators: <, <=, >, >=, ==, !=")
-		return nil, false
+		return nil, false, false
 	}
 	if exprs[0].Exprs[0].Type != shaderir.LocalVariable {
-		cs.addError(stmt.Pos(), msg)
-		return nil, false
+		return nil, false, false
 	}
 	if exprs[0].Exprs[0].Index != varidx {
-		cs.addError(stmt.Pos(), msg)
-		return nil, false
+		return nil, false, false
 	}
 	if exprs[0].Exprs[1].Const == nil {
-		cs.addError(stmt.Pos(), msg)
-		return nil, false
+		return nil, false, false
 	}
 	end := exprs[0].Exprs[1].Const
 
 	postSs, ok := cs.parseStmt(pseudoBlock, fname, stmt.Post, inParams, outParams, returnType)
 	if !ok {
-		return nil, false
+		return nil, false, false
 	}
 	if len(postSs) != 1 {
-		cs.addError(stmt.Pos(), msg)
-		return nil, false
+		return nil, false, false
 	}
 	if postSs[0].Type != shaderir.Assign {
-		cs.addError(stmt.Pos(), msg)
-		return nil, false
+		return nil, false, false
 	}
 	if postSs[0].Exprs[0].Type != shaderir.LocalVariable {
-		cs.addError(stmt.Pos(), msg)
-		return nil, false
+		return nil, false, false
 	}
 	if postSs[0].Exprs[0].Index != varidx {
-		cs.addError(stmt.Pos(), msg)
-		return nil, false
+		return nil, false, false
 	}
 	if postSs[0].Exprs[1].Type != shaderir.Binary {
-		cs.addError(stmt.Pos(), msg)
-		return nil, false
+		return nil, false, false
 	}
 	if postSs[0].Exprs[1].Exprs[0].Type != shaderir.LocalVariable {
-		cs.addError(stmt.Pos(), msg)
-		return nil, false
+		return nil, false, false
 	}
 	if postSs[0].Exprs[1].Exprs[0].Index != varidx {
-		cs.addError(stmt.Pos(), msg)
-		return nil, false
+		return nil, false, false
 	}
 	if postSs[0].Exprs[1].Exprs[1].Const == nil {
-		cs.addError(stmt.Pos(), msg)
-		return nil, false
+		return nil, false, false
 	}
 	delta := postSs[0].Exprs[1].Exprs[1].Const
 	switch postSs[0].Exprs[1].Op {
@@ -845,18 +1158,32 @@ func (cs *compileState) parseFor(block *block, fname string, stmt *ast.ForStmt,
 	case shaderir.Sub:
 		delta = gconstant.UnaryOp(token.SUB, delta, 0)
 	default:
-		cs.addError(stmt.Pos(), "for-statement's post statement must have one of these operators: +=, -=, ++, --")
-		return nil, false
+		return nil, false, false
 	}
 
+	if !forLoopTerminates(init, end, op, delta) {
+		cs.addError(stmt.Pos(), fmt.Sprintf("for-loop never terminates: the counter starts at %s and moves by %s but the condition is always satisfied", init.String(), delta.String()))
+		return nil, true, false
+	}
+
+	cs.loops = append(cs.loops, l)
 	b, ok := cs.parseBlock(pseudoBlock, fname, []ast.Stmt{stmt.Body}, inParams, outParams, returnType, true)
+	l = cs.loops[len(cs.loops)-1]
+	cs.loops = cs.loops[:len(cs.loops)-1]
 	if !ok {
-		return nil, false
+		return nil, true, false
+	}
+	if l.label != "" && !l.labelUsed {
+		cs.addError(stmt.Pos(), fmt.Sprintf("label %s defined and not used", l.label))
+		return nil, true, false
 	}
 	bodyir := b.ir
 	for len(bodyir.Stmts) == 1 && bodyir.Stmts[0].Type == shaderir.BlockStmt {
 		bodyir = bodyir.Stmts[0].Blocks[0]
 	}
+	if isEmptyLoopBody(bodyir) {
+		cs.addWarning(stmt.Pos(), "the loop body is empty or has only unreachable statements")
+	}
 
 	// As the pseudo block is not actually used, copy the variable part to the actual block.
 	// This must be done after parsing the for-loop is done, or the duplicated variables confuses the
@@ -865,16 +1192,874 @@ func (cs *compileState) parseFor(block *block, fname string, stmt *ast.ForStmt,
 	v.forLoopCounter = true
 	block.vars = append(block.vars, v)
 
-	return []shaderir.Stmt{
-		{
-			Type:        shaderir.For,
-			Blocks:      []*shaderir.Block{bodyir},
-			ForVarType:  vartype,
-			ForVarIndex: varidx,
-			ForInit:     init,
-			ForEnd:      end,
-			ForOp:       op,
-			ForDelta:    delta,
-		},
-	}, true
+	if l.flagVar < 0 && len(l.exits) == 0 {
+		if ss, ok := cs.unrollFor(bodyir, vartype, varidx, init, end, op, delta); ok {
+			stmts = append(stmts, ss...)
+			return stmts, true, true
+		}
+	}
+
+	if l.flagVar >= 0 {
+		// Reset the flag as the loop might be executed multiple times.
+		stmts = append(stmts, labeledBranchFlagAssign(l.flagVar, 0))
+	}
+	stmts = append(stmts, shaderir.Stmt{
+		Type:        shaderir.For,
+		Blocks:      []*shaderir.Block{bodyir},
+		ForVarType:  vartype,
+		ForVarIndex: varidx,
+		ForInit:     init,
+		ForEnd:      end,
+		ForOp:       op,
+		ForDelta:    delta,
+	})
+	stmts = append(stmts, cs.labeledBranchExits(block, &l)...)
+	return stmts, true, true
+}
+
+// comparisonToken returns the token for the comparison operator op.
+func comparisonToken(op shaderir.Op) (token.Token, bool) {
+	switch op {
+	case shaderir.LessThanOp:
+		return token.LSS, true
+	case shaderir.LessThanEqualOp:
+		return token.LEQ, true
+	case shaderir.GreaterThanOp:
+		return token.GTR, true
+	case shaderir.GreaterThanEqualOp:
+		return token.GEQ, true
+	case shaderir.EqualOp:
+		return token.EQL, true
+	case shaderir.NotEqualOp:
+		return token.NEQ, true
+	}
+	return 0, false
+}
+
+// forLoopTerminates reports whether the for-loop with constant bounds exits after a finite number of iterations.
+func forLoopTerminates(init, end gconstant.Value, op shaderir.Op, delta gconstant.Value) bool {
+	cmp, ok := comparisonToken(op)
+	if !ok {
+		return true
+	}
+
+	// The loop body is never executed.
+	if !gconstant.Compare(init, cmp, end) {
+		return true
+	}
+	if gconstant.Sign(delta) == 0 {
+		return false
+	}
+
+	switch cmp {
+	case token.LSS, token.LEQ:
+		return gconstant.Sign(delta) > 0
+	case token.GTR, token.GEQ:
+		return gconstant.Sign(delta) < 0
+	case token.EQL:
+		return true
+	case token.NEQ:
+		// The counter must hit the bound exactly.
+		n := gconstant.BinaryOp(gconstant.BinaryOp(end, token.SUB, init), token.QUO, delta)
+		return gconstant.Sign(n) > 0 && canTruncateToInteger(n)
+	}
+	return true
+}
+
+// unrollFor unrolls a for-loop with constant bounds into the copies of the body, where the counter is replaced with its constant values.
+// unrollFor returns false when the loop cannot be unrolled or the iteration count exceeds the option UnrollLoops.
+func (cs *compileState) unrollFor(body *shaderir.Block, vartype shaderir.Type, varidx int, init, end gconstant.Value, op shaderir.Op, delta gconstant.Value) ([]shaderir.Stmt, bool) {
+	if cs.options.UnrollLoops <= 0 {
+		return nil, false
+	}
+	if hasLoopBranch(body) || assignsLocalVariable(body, varidx) {
+		return nil, false
+	}
+
+	cmp, ok := comparisonToken(op)
+	if !ok {
+		return nil, false
+	}
+
+	var stmts []shaderir.Stmt
+	for v := init; gconstant.Compare(v, cmp, end); v = gconstant.BinaryOp(v, token.ADD, delta) {
+		if len(stmts) >= cs.options.UnrollLoops {
+			return nil, false
+		}
+		c := v
+		if vartype.Main == shaderir.Int {
+			c = gconstant.ToInt(c)
+		} else {
+			c = gconstant.ToFloat(c)
+		}
+		stmts = append(stmts, shaderir.Stmt{
+			Type:   shaderir.BlockStmt,
+			Blocks: []*shaderir.Block{replaceLocalVariableInBlock(body, varidx, c)},
+		})
+	}
+	return stmts, true
+}
+
+// hasLoopBranch reports whether the block b has break or continue statements for the loop whose body is b.
+func hasLoopBranch(b *shaderir.Block) bool {
+	for _, s := range b.Stmts {
+		switch s.Type {
+		case shaderir.Break, shaderir.Continue:
+			return true
+		case shaderir.For, shaderir.While:
+			// A branch in an inner loop is for the inner loop.
+			continue
+		}
+		for _, b := range s.Blocks {
+			if hasLoopBranch(b) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// assignsLocalVariable reports whether the block b has an assignment to the local variable at idx.
+func assignsLocalVariable(b *shaderir.Block, idx int) bool {
+	for _, s := range b.Stmts {
+		if s.Type == shaderir.Assign && s.Exprs[0].Type == shaderir.LocalVariable && s.Exprs[0].Index == idx {
+			return true
+		}
+		for _, b := range s.Blocks {
+			if assignsLocalVariable(b, idx) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// replaceLocalVariableInBlock returns a deep copy of the block b where the local variable at idx is replaced with the constant v.
+func replaceLocalVariableInBlock(b *shaderir.Block, idx int, v gconstant.Value) *shaderir.Block {
+	newB := *b
+	newB.Stmts = make([]shaderir.Stmt, len(b.Stmts))
+	for i, s := range b.Stmts {
+		s.Exprs = replaceLocalVariableInExprs(s.Exprs, idx, v)
+		if s.Blocks != nil {
+			blocks := make([]*shaderir.Block, len(s.Blocks))
+			for j, b := range s.Blocks {
+				if b != nil {
+					b = replaceLocalVariableInBlock(b, idx, v)
+				}
+				blocks[j] = b
+			}
+			s.Blocks = blocks
+		}
+		newB.Stmts[i] = s
+	}
+	return &newB
+}
+
+func replaceLocalVariableInExprs(exprs []shaderir.Expr, idx int, v gconstant.Value) []shaderir.Expr {
+	if exprs == nil {
+		return nil
+	}
+	newExprs := make([]shaderir.Expr, len(exprs))
+	for i, e := range exprs {
+		if e.Type == shaderir.LocalVariable && e.Index == idx {
+			e = shaderir.Expr{
+				Type:  shaderir.NumberExpr,
+				Const: v,
+			}
+		}
+		e.Exprs = replaceLocalVariableInExprs(e.Exprs, idx, v)
+		newExprs[i] = e
+	}
+	return newExprs
+}
+
+// parseWhile parses a for-statement that is not in the canonical form, and lowers it to shaderir.While.
+//
+// The post statement is put at the end of the loop body, and also before each continue statement for the loop.
+func (cs *compileState) parseWhile(block *block, fname string, stmt *ast.ForStmt, l loop, inParams, outParams []variable, returnType shaderir.Type) ([]shaderir.Stmt, bool) {
+	// The initial statement's variables belong to a new block enclosing the loop.
+	var init []ast.Stmt
+	if stmt.Init != nil {
+		init = append(init, stmt.Init)
+	}
+	outer, ok := cs.parseBlock(block, fname, init, inParams, outParams, returnType, false)
+	if !ok {
+		return nil, false
+	}
+
+	cond := shaderir.Expr{
+		Type:  shaderir.NumberExpr,
+		Const: gconstant.MakeBool(true),
+	}
+	var condStmts []shaderir.Stmt
+	if stmt.Cond != nil {
+		exprs, ts, ss, ok := cs.parseExpr(outer, fname, stmt.Cond, true)
+		if !ok {
+			return nil, false
+		}
+		if len(exprs) != 1 || len(ts) != 1 || ts[0].Main != shaderir.Bool {
+			cs.addError(stmt.Pos(), "for-statement's condition must be bool")
+			return nil, false
+		}
+		if len(ss) > 0 {
+			// The statements to evaluate the condition must be executed at every iteration.
+			// Check the condition at the head of the loop body instead.
+			condStmts = append(ss, shaderir.Stmt{
+				Type: shaderir.If,
+				Exprs: []shaderir.Expr{
+					{
+						Type:  shaderir.Unary,
+						Op:    shaderir.NotOp,
+						Exprs: []shaderir.Expr{exprs[0]},
+					},
+				},
+				Blocks: []*shaderir.Block{
+					{
+						LocalVarIndexOffset: outer.totalLocalVariableCount(),
+						Stmts: []shaderir.Stmt{
+							{
+								Type: shaderir.Break,
+							},
+						},
+					},
+				},
+			})
+		} else {
+			cond = exprs[0]
+		}
+	}
+
+	if stmt.Post != nil {
+		ss, ok := cs.parseStmt(outer, fname, stmt.Post, inParams, outParams, returnType)
+		if !ok {
+			return nil, false
+		}
+		l.post = ss
+	}
+
+	cs.loops = append(cs.loops, l)
+	b, ok := cs.parseBlock(outer, fname, []ast.Stmt{stmt.Body}, inParams, outParams, returnType, true)
+	l = cs.loops[len(cs.loops)-1]
+	cs.loops = cs.loops[:len(cs.loops)-1]
+	if !ok {
+		return nil, false
+	}
+	if l.label != "" && !l.labelUsed {
+		cs.addError(stmt.Pos(), fmt.Sprintf("label %s defined and not used", l.label))
+		return nil, false
+	}
+	bodyir := b.ir
+	for len(bodyir.Stmts) == 1 && bodyir.Stmts[0].Type == shaderir.BlockStmt {
+		bodyir = bodyir.Stmts[0].Blocks[0]
+	}
+	if isEmptyLoopBody(bodyir) {
+		cs.addWarning(stmt.Pos(), "the loop body is empty or has only unreachable statements")
+	}
+	// A loop without a condition never terminates unless the body exits the loop.
+	if len(condStmts) == 0 && cond.Const != nil && gconstant.BoolVal(cond.Const) && !canExitLoop(bodyir, true) {
+		cs.addError(stmt.Pos(), "for-statement without a condition must have a break or a return")
+		return nil, false
+	}
+	bodyir.Stmts = append(condStmts, bodyir.Stmts...)
+	bodyir.Stmts = append(bodyir.Stmts, l.post...)
+
+	w := shaderir.Stmt{
+		Type:   shaderir.While,
+		Exprs:  []shaderir.Expr{cond},
+		Blocks: []*shaderir.Block{bodyir},
+	}
+
+	var stmts []shaderir.Stmt
+	if l.flagVar >= 0 {
+		// Reset the flag as the loop might be executed multiple times.
+		stmts = append(stmts, labeledBranchFlagAssign(l.flagVar, 0))
+	}
+	if stmt.Init == nil {
+		// The enclosing block is not needed without an initial statement.
+		stmts = append(stmts, w)
+	} else {
+		outer.ir.Stmts = append(outer.ir.Stmts, w)
+		stmts = append(stmts, shaderir.Stmt{
+			Type:   shaderir.BlockStmt,
+			Blocks: []*shaderir.Block{outer.ir},
+		})
+	}
+	stmts = append(stmts, cs.labeledBranchExits(block, &l)...)
+	return stmts, true
+}
+
+// loop represents a for-loop enclosing the statement being parsed.
+type loop struct {
+	// label is the label of the loop, or empty if the loop is not labeled.
+	label     string
+	labelUsed bool
+
+	// flagVar is the index of the local variable to tell inner loops to exit for a labeled break or continue.
+	// flagVar is -1 if no inner loops branch to this loop by the label.
+	flagVar int
+
+	labeledBreak    bool
+	labeledContinue bool
+
+	// exits is the indices in compileState.loops of the outer loops that this loop exits to by labeled branches.
+	exits []int
+
+	// post is the post statement to be executed before continuing the loop.
+	post []shaderir.Stmt
+}
+
+const (
+	labeledBreakFlag    = 1
+	labeledContinueFlag = 2
+)
+
+// hasLabeledBranchInInnerLoop reports whether body has a break or continue statement with the label in an inner loop.
+func hasLabeledBranchInInnerLoop(body *ast.BlockStmt, label string) bool {
+	var found bool
+	ast.Inspect(body, func(n ast.Node) bool {
+		f, ok := n.(*ast.ForStmt)
+		if !ok {
+			return !found
+		}
+		ast.Inspect(f.Body, func(n ast.Node) bool {
+			if b, ok := n.(*ast.BranchStmt); ok && b.Label != nil && b.Label.Name == label {
+				found = true
+			}
+			return !found
+		})
+		return false
+	})
+	return found
+}
+
+func labeledBranchFlagAssign(flagVar int, value int64) shaderir.Stmt {
+	return shaderir.Stmt{
+		Type: shaderir.Assign,
+		Exprs: []shaderir.Expr{
+			{
+				Type:  shaderir.LocalVariable,
+				Index: flagVar,
+			},
+			{
+				Type:  shaderir.NumberExpr,
+				Const: gconstant.MakeInt64(value),
+			},
+		},
+	}
+}
+
+// parseLabeledBranch parses a break or continue statement with a label.
+//
+// As the backends don't support labeled branches, a labeled branch to an outer loop is lowered to a flag assignment
+// and a break. The inner loops then check the flag after they finish. See also labeledBranchExits.
+func (cs *compileState) parseLabeledBranch(stmt *ast.BranchStmt) ([]shaderir.Stmt, bool) {
+	if stmt.Tok != token.BREAK && stmt.Tok != token.CONTINUE {
+		cs.addError(stmt.Pos(), fmt.Sprintf("invalid token: %s", stmt.Tok))
+		return nil, false
+	}
+
+	name := stmt.Label.Name
+	target := -1
+	for i := len(cs.loops) - 1; i >= 0; i-- {
+		if cs.loops[i].label == name {
+			target = i
+			break
+		}
+	}
+	if target < 0 {
+		cs.addError(stmt.Pos(), fmt.Sprintf("invalid %s label %s: no enclosing for-statement has the label", stmt.Tok, name))
+		return nil, false
+	}
+	cs.loops[target].labelUsed = true
+
+	typ := shaderir.Break
+	if stmt.Tok == token.CONTINUE {
+		typ = shaderir.Continue
+	}
+	if target == len(cs.loops)-1 {
+		var stmts []shaderir.Stmt
+		if typ == shaderir.Continue {
+			stmts = append(stmts, cs.loops[target].post...)
+		}
+		stmts = append(stmts, shaderir.Stmt{
+			Type: typ,
+		})
+		return stmts, true
+	}
+
+	flag := int64(labeledBreakFlag)
+	if stmt.Tok == token.CONTINUE {
+		cs.loops[target].labeledContinue = true
+		flag = labeledContinueFlag
+	} else {
+		cs.loops[target].labeledBreak = true
+	}
+	for i := target + 1; i < len(cs.loops); i++ {
+		var found bool
+		for _, e := range cs.loops[i].exits {
+			if e == target {
+				found = true
+				break
+			}
+		}
+		if !found {
+			cs.loops[i].exits = append(cs.loops[i].exits, target)
+		}
+	}
+
+	return []shaderir.Stmt{
+		labeledBranchFlagAssign(cs.loops[target].flagVar, flag),
+		{
+			Type: shaderir.Break,
+		},
+	}, true
+}
+
+// labeledBranchExits returns the statements to be put after the loop l to propagate labeled branches to the outer loops.
+func (cs *compileState) labeledBranchExits(block *block, l *loop) []shaderir.Stmt {
+	newIf := func(flagVar int, op shaderir.Op, value int64, stmts ...shaderir.Stmt) shaderir.Stmt {
+		return shaderir.Stmt{
+			Type: shaderir.If,
+			Exprs: []shaderir.Expr{
+				{
+					Type: shaderir.Binary,
+					Op:   op,
+					Exprs: []shaderir.Expr{
+						{
+							Type:  shaderir.LocalVariable,
+							Index: flagVar,
+						},
+						{
+							Type:  shaderir.NumberExpr,
+							Const: gconstant.MakeInt64(value),
+						},
+					},
+				},
+			},
+			Blocks: []*shaderir.Block{
+				{
+					LocalVarIndexOffset: block.totalLocalVariableCount(),
+					Stmts:               stmts,
+				},
+			},
+		}
+	}
+
+	var stmts []shaderir.Stmt
+	for _, target := range l.exits {
+		outer := &cs.loops[target]
+		if target < len(cs.loops)-1 {
+			// The target is not the immediately enclosing loop. Exit the enclosing loop too.
+			stmts = append(stmts, newIf(outer.flagVar, shaderir.NotEqualOp, 0, shaderir.Stmt{
+				Type: shaderir.Break,
+			}))
+			continue
+		}
+		if outer.labeledBreak {
+			stmts = append(stmts, newIf(outer.flagVar, shaderir.EqualOp, labeledBreakFlag, shaderir.Stmt{
+				Type: shaderir.Break,
+			}))
+		}
+		if outer.labeledContinue {
+			ss := []shaderir.Stmt{labeledBranchFlagAssign(outer.flagVar, 0)}
+			ss = append(ss, outer.post...)
+			ss = append(ss, shaderir.Stmt{
+				Type: shaderir.Continue,
+			})
+			stmts = append(stmts, newIf(outer.flagVar, shaderir.EqualOp, labeledContinueFlag, ss...))
+		}
+	}
+	return stmts
+}
+
+// isEmptyLoopBody reports whether the loop body b has no statements to be executed.
+// b must be the body after the unreachable statements are dropped.
+// Empty blocks, continue, and if statements without side effects whose branches are empty are not counted.
+func isEmptyLoopBody(b *shaderir.Block) bool {
+	if b == nil {
+		return true
+	}
+	for _, s := range b.Stmts {
+		switch s.Type {
+		case shaderir.Continue:
+		case shaderir.BlockStmt:
+			if !isEmptyLoopBody(s.Blocks[0]) {
+				return false
+			}
+		case shaderir.If:
+			if hasSideEffects(&s.Exprs[0]) {
+				return false
+			}
+			for _, b := range s.Blocks {
+				if !isEmptyLoopBody(b) {
+					return false
+				}
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// canExitLoop reports whether the block b has a statement to exit the loop, that is a break or a return.
+// outermost reports whether b belongs to the loop itself. The breaks in inner loops are not counted.
+func canExitLoop(b *shaderir.Block, outermost bool) bool {
+	for _, s := range b.Stmts {
+		switch s.Type {
+		case shaderir.Return:
+			return true
+		case shaderir.Break:
+			if outermost {
+				return true
+			}
+		}
+		inner := outermost && s.Type != shaderir.For && s.Type != shaderir.While
+		for _, b := range s.Blocks {
+			if canExitLoop(b, inner) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+var (
+	falseExpr = shaderir.Expr{
+		Type:  shaderir.NumberExpr,
+		Const: gconstant.MakeBool(false),
+	}
+	trueExpr = shaderir.Expr{
+		Type:  shaderir.NumberExpr,
+		Const: gconstant.MakeBool(true),
+	}
+)
+
+// caseClause is a case clause of a switch statement lowered to an if statement.
+type caseClause struct {
+	cond shaderir.Expr
+	body *shaderir.Block
+}
+
+// parseSwitch parses a switch statement and lowers it to an if-else chain, or to a sequence of if statements with fallthrough.
+func (cs *compileState) parseSwitch(block *block, fname string, stmt *ast.SwitchStmt, inParams, outParams []variable, returnType shaderir.Type) ([]shaderir.Stmt, bool) {
+	if stmt.Init != nil {
+		// Copy the statement not to modify the AST.
+		s := *stmt
+		s.Init = nil
+		b, ok := cs.parseBlock(block, fname, []ast.Stmt{stmt.Init, &s}, inParams, outParams, returnType, true)
+		if !ok {
+			return nil, false
+		}
+		return []shaderir.Stmt{
+			{
+				Type:   shaderir.BlockStmt,
+				Blocks: []*shaderir.Block{b.ir},
+			},
+		}, true
+	}
+
+	if stmt.Tag == nil {
+		cs.addError(stmt.Pos(), "switch without a tag is not implemented")
+		return nil, false
+	}
+
+	var stmts []shaderir.Stmt
+
+	exprs, ts, ss, ok := cs.parseExpr(block, fname, stmt.Tag, true)
+	if !ok {
+		return nil, false
+	}
+	if len(exprs) != 1 || len(ts) != 1 {
+		cs.addError(stmt.Tag.Pos(), "multiple-value context is not available at a switch tag")
+		return nil, false
+	}
+	stmts = append(stmts, ss...)
+
+	tag := exprs[0]
+	if tag.Const != nil {
+		if !canTruncateToInteger(tag.Const) {
+			cs.addError(stmt.Tag.Pos(), fmt.Sprintf("constant %s truncated to integer", tag.Const.String()))
+			return nil, false
+		}
+		tag.Const = gconstant.ToInt(tag.Const)
+	} else if ts[0].Main != shaderir.Int {
+		cs.addError(stmt.Tag.Pos(), fmt.Sprintf("switch tag must be int but %s", ts[0].String()))
+		return nil, false
+	}
+
+	var ccs []*ast.CaseClause
+	for _, s := range stmt.Body.List {
+		ccs = append(ccs, s.(*ast.CaseClause))
+	}
+
+	bodies := make([][]ast.Stmt, len(ccs))
+	fallthroughs := make([]bool, len(ccs))
+	var hasFallthrough bool
+	for i := range ccs {
+		body, ft, ok := cs.switchClauseBody(ccs, i)
+		if !ok {
+			return nil, false
+		}
+		bodies[i] = body
+		fallthroughs[i] = ft
+		if ft {
+			hasFallthrough = true
+		}
+	}
+
+	// The tag is compared with each case. Evaluate the tag only once.
+	var flag shaderir.Expr
+	if hasFallthrough {
+		// With fallthrough, the conditions are evaluated after the bodies of the previous clauses.
+		// Copy the tag so that the bodies don't affect the conditions.
+		tag, stmts = cs.storeToLocalVariable(block, tag, shaderir.Type{Main: shaderir.Int}, stmts)
+		// The flag must be allocated before the bodies are parsed, as the bodies' local variables follow it.
+		flag, stmts = cs.storeToLocalVariable(block, falseExpr, shaderir.Type{Main: shaderir.Bool}, stmts)
+	} else {
+		tag, stmts = cs.evaluateOnce(block, tag, shaderir.Type{Main: shaderir.Int}, stmts)
+	}
+
+	var clauses []caseClause
+	var defaultBody *shaderir.Block
+	values := map[int64]struct{}{}
+
+	// caseConds are the conditions of all the case clauses, used for the default clause with fallthrough.
+	var caseConds []shaderir.Expr
+	// defaultIndex is the index of the default clause in clauses with fallthrough.
+	defaultIndex := -1
+
+	for ci, cc := range ccs {
+		var cond shaderir.Expr
+		for i, e := range cc.List {
+			es, _, ss, ok := cs.parseExpr(block, fname, e, true)
+			if !ok {
+				return nil, false
+			}
+			if len(es) != 1 || es[0].Const == nil || !canTruncateToInteger(es[0].Const) {
+				cs.addError(e.Pos(), "case expression must be an integer constant")
+				return nil, false
+			}
+			stmts = append(stmts, ss...)
+
+			v, ok := gconstant.Int64Val(gconstant.ToInt(es[0].Const))
+			if !ok {
+				cs.addError(e.Pos(), fmt.Sprintf("constant %s overflows int", es[0].Const.String()))
+				return nil, false
+			}
+			if _, ok := values[v]; ok {
+				cs.addError(e.Pos(), fmt.Sprintf("duplicate case %d in expression switch", v))
+				return nil, false
+			}
+			values[v] = struct{}{}
+
+			eq := shaderir.Expr{
+				Type: shaderir.Binary,
+				Op:   shaderir.EqualOp,
+				Exprs: []shaderir.Expr{
+					tag,
+					{
+						Type:  shaderir.NumberExpr,
+						Const: gconstant.MakeInt64(v),
+					},
+				},
+			}
+			if i == 0 {
+				cond = eq
+				continue
+			}
+			cond = shaderir.Expr{
+				Type:  shaderir.Binary,
+				Op:    shaderir.OrOr,
+				Exprs: []shaderir.Expr{cond, eq},
+			}
+		}
+
+		b, ok := cs.parseBlock(block, fname, bodies[ci], inParams, outParams, returnType, true)
+		if !ok {
+			return nil, false
+		}
+
+		if hasFallthrough {
+			if cc.List == nil {
+				defaultIndex = len(clauses)
+			} else {
+				caseConds = append(caseConds, cond)
+			}
+			clauses = append(clauses, caseClause{
+				cond: cond,
+				body: b.ir,
+			})
+			continue
+		}
+
+		if cc.List == nil {
+			defaultBody = b.ir
+			continue
+		}
+		clauses = append(clauses, caseClause{
+			cond: cond,
+			body: b.ir,
+		})
+	}
+
+	if hasFallthrough {
+		return lowerSwitchWithFallthrough(clauses, caseConds, defaultIndex, fallthroughs, flag, stmts), true
+	}
+
+	if len(clauses) == 0 {
+		if defaultBody != nil {
+			stmts = append(stmts, shaderir.Stmt{
+				Type:   shaderir.BlockStmt,
+				Blocks: []*shaderir.Block{defaultBody},
+			})
+		}
+		return stmts, true
+	}
+
+	// Build the if-else chain from the last case. The default clause is the last else-block regardless of its position.
+	elseBody := defaultBody
+	for i := len(clauses) - 1; i >= 0; i-- {
+		s := shaderir.Stmt{
+			Type:   shaderir.If,
+			Exprs:  []shaderir.Expr{clauses[i].cond},
+			Blocks: []*shaderir.Block{clauses[i].body},
+		}
+		if elseBody != nil {
+			s.Blocks = append(s.Blocks, elseBody)
+		}
+		if i == 0 {
+			stmts = append(stmts, s)
+			break
+		}
+		elseBody = &shaderir.Block{
+			LocalVarIndexOffset: block.totalLocalVariableCount(),
+			Stmts:               []shaderir.Stmt{s},
+		}
+	}
+
+	return stmts, true
+}
+
+// switchClauseBody returns the statements of the i-th case clause to be lowered into an if-else block.
+// switchClauseBody also reports whether the clause ends with a fallthrough statement.
+func (cs *compileState) switchClauseBody(ccs []*ast.CaseClause, i int) ([]ast.Stmt, bool, bool) {
+	body := ccs[i].Body
+
+	var fallthroughPos token.Pos
+	if n := len(body); n > 0 {
+		if b, ok := body[n-1].(*ast.BranchStmt); ok {
+			switch {
+			case b.Tok == token.BREAK && b.Label == nil:
+				// A break at the end of a case clause is a no-op.
+				body = body[:n-1]
+			case b.Tok == token.FALLTHROUGH:
+				fallthroughPos = b.Pos()
+				body = body[:n-1]
+			}
+		}
+	}
+
+	for _, s := range body {
+		var breakPos, nestedFallthroughPos token.Pos
+		ast.Inspect(s, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.ForStmt, *ast.SwitchStmt:
+				// A break in these statements doesn't refer to this switch statement.
+				return false
+			case *ast.BranchStmt:
+				// A labeled break refers to an enclosing for-statement.
+				if n.Tok == token.BREAK && n.Label == nil && breakPos == token.NoPos {
+					breakPos = n.Pos()
+				}
+				if n.Tok == token.FALLTHROUGH && nestedFallthroughPos == token.NoPos {
+					nestedFallthroughPos = n.Pos()
+				}
+			}
+			return true
+		})
+		if breakPos != token.NoPos {
+			cs.addError(breakPos, "break in a switch statement is available only at the end of a case clause")
+			return nil, false, false
+		}
+		if nestedFallthroughPos != token.NoPos {
+			cs.addError(nestedFallthroughPos, "fallthrough statement out of place")
+			return nil, false, false
+		}
+	}
+
+	if fallthroughPos == token.NoPos {
+		return body, false, true
+	}
+	if i == len(ccs)-1 {
+		cs.addError(fallthroughPos, "cannot fallthrough final case in switch")
+		return nil, false, false
+	}
+	return body, true, true
+}
+
+// lowerSwitchWithFallthrough lowers the case clauses of a switch statement with fallthrough to a sequence of if statements.
+// clauses are in the source order, and the condition of the default clause at defaultIndex is ignored.
+// caseConds are the conditions of all the clauses except for the default clause.
+//
+// The bool variable flag is set at the end of a clause with fallthrough, and the next clause is executed if the flag is set.
+// The conditions are exclusive as duplicated case values are rejected, so at most one clause is executed without the flag.
+func lowerSwitchWithFallthrough(clauses []caseClause, caseConds []shaderir.Expr, defaultIndex int, fallthroughs []bool, flag shaderir.Expr, stmts []shaderir.Stmt) []shaderir.Stmt {
+
+	for i, c := range clauses {
+		cond := c.cond
+		if i == defaultIndex {
+			// The default clause is executed when no case matches, regardless of its position.
+			var matched shaderir.Expr
+			for j, e := range caseConds {
+				if j == 0 {
+					matched = e
+					continue
+				}
+				matched = shaderir.Expr{
+					Type:  shaderir.Binary,
+					Op:    shaderir.OrOr,
+					Exprs: []shaderir.Expr{matched, e},
+				}
+			}
+			if len(caseConds) == 0 {
+				cond = trueExpr
+			} else {
+				cond = shaderir.Expr{
+					Type:  shaderir.Unary,
+					Op:    shaderir.NotOp,
+					Exprs: []shaderir.Expr{matched},
+				}
+			}
+		}
+
+		body := c.body
+		if i > 0 && fallthroughs[i-1] {
+			cond = shaderir.Expr{
+				Type:  shaderir.Binary,
+				Op:    shaderir.OrOr,
+				Exprs: []shaderir.Expr{flag, cond},
+			}
+			// Reset the flag so that it doesn't affect the later clauses.
+			body.Stmts = append([]shaderir.Stmt{
+				{
+					Type:  shaderir.Assign,
+					Exprs: []shaderir.Expr{flag, falseExpr},
+				},
+			}, body.Stmts...)
+		}
+		if fallthroughs[i] && !isTerminatingBlock(body) {
+			body.Stmts = append(body.Stmts, shaderir.Stmt{
+				Type:  shaderir.Assign,
+				Exprs: []shaderir.Expr{flag, trueExpr},
+			})
+		}
+
+		stmts = append(stmts, shaderir.Stmt{
+			Type:   shaderir.If,
+			Exprs:  []shaderir.Expr{cond},
+			Blocks: []*shaderir.Block{body},
+		})
+	}
+	return stmts
 }
@@ -16,17 +16,30 @@ package shader_test
 
 import (
 	"fmt"
+	gconstant "go/constant"
+	"math"
 	"strings"
 	"testing"
 
 	"github.com/hajimehoshi/ebiten/v2/internal/shader"
 	"github.com/hajimehoshi/ebiten/v2/internal/shaderir"
+	"github.com/hajimehoshi/ebiten/v2/internal/shaderir/glsl"
 )
 
 func compileToIR(src []byte) (*shaderir.Program, error) {
 	return shader.Compile(src, "Vertex", "Fragment", 0)
 }
 
+func compileToIRWithWarnings(src []byte) (*shaderir.Program, []string, error) {
+	var warnings []string
+	p, err := shader.CompileWithOptions(src, "Vertex", "Fragment", 0, &shader.CompileOptions{
+		Warn: func(msg string) {
+			warnings = append(warnings, msg)
+		},
+	})
+	return p, warnings, err
+}
+
 func TestSyntaxShadowing(t *testing.T) {
 	if _, err := compileToIR([]byte(`package main
 
@@ -608,6 +621,55 @@ func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
 	}
 }
 
+func TestSyntaxBlankDefine(t *testing.T) {
+	cases := []struct {
+		stmt      string
+		err       bool
+		localVars int
+	}{
+		// The two local variables for the out parameters of Foo are always allocated.
+		{stmt: "x, _ := Foo(); _ = x", err: false, localVars: 3},
+		{stmt: "_, y := Foo(); _ = y", err: false, localVars: 3},
+		{stmt: "var x int; x, _ = Foo(); _ = x", err: false, localVars: 3},
+		{stmt: "_, _ = Foo()", err: false, localVars: 2},
+		{stmt: "x, _ := 1, 2.0; _ = x", err: false, localVars: 2},
+		{stmt: "_ = Bar(1, 2)", err: false, localVars: 0},
+		{stmt: "_, _ := Foo()", err: true},
+		{stmt: "_ := 1", err: true},
+		{stmt: "x, _ := Foo(); _ = _", err: true},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Foo() (int, float) {
+	return 1, 2
+}
+
+func Bar(_ int, b int) int {
+	return b
+}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		p, err := compileToIR([]byte(src))
+		if err == nil && c.err {
+			t.Errorf("%s must return an error but does not", stmt)
+		} else if err != nil && !c.err {
+			t.Errorf("%s must not return nil but returned %v", stmt, err)
+		}
+		if err != nil || c.err {
+			continue
+		}
+		if got, want := len(p.FragmentFunc.Block.LocalVars), c.localVars; got != want {
+			t.Errorf("%s: len(LocalVars): got: %d, want: %d", stmt, got, want)
+		}
+	}
+}
+
 func TestSyntaxDuplicatedVarsAndConstants(t *testing.T) {
 	if _, err := compileToIR([]byte(`package main
 
@@ -780,6 +842,17 @@ func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
 }`)); err == nil {
 		t.Errorf("error must be non-nil but was nil")
 	}
+	if _, err := compileToIR([]byte(`package main
+
+const c = 5.5 % 2.0
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return vec4(c)
+}`)); err == nil {
+		t.Errorf("error must be non-nil but was nil")
+	} else if got, want := err.Error(), "3:11: invalid operation: operator % not defined on untyped float constant; use mod(x, y) for floats"; got != want {
+		t.Errorf("got: %q, want: %q", got, want)
+	}
 
 	if _, err := compileToIR([]byte(`package main
 
@@ -808,8 +881,8 @@ func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
 	b := 0.5
 	_ = a % b
 	return vec4(0)
-}`)); err == nil {
-		t.Errorf("error must be non-nil but was nil")
+}`)); err != nil {
+		t.Error(err)
 	}
 
 	if _, err := compileToIR([]byte(`package main
@@ -927,8 +1000,8 @@ func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
 	a %= 1
 	_ = a
 	return vec4(0)
-}`)); err == nil {
-		t.Errorf("error must be non-nil but was nil")
+}`)); err != nil {
+		t.Error(err)
 	}
 }
 
@@ -1074,8 +1147,8 @@ func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
 func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
 	a := vec2(1) % 2
 	return a.xxyy
-}`)); err == nil {
-		t.Errorf("error must be non-nil but was nil")
+}`)); err != nil {
+		t.Error(err)
 	}
 	if _, err := compileToIR([]byte(`package main
 
@@ -1091,8 +1164,8 @@ func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
 func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
 	a := vec2(1) % 2.1
 	return a.xxyy
-}`)); err == nil {
-		t.Errorf("error must be non-nil but was nil")
+}`)); err != nil {
+		t.Error(err)
 	}
 	if _, err := compileToIR([]byte(`package main
 
@@ -1147,8 +1220,8 @@ func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
 	a := vec2(1)
 	a %= 2
 	return a.xxyy
-}`)); err == nil {
-		t.Errorf("error must be non-nil but was nil")
+}`)); err != nil {
+		t.Error(err)
 	}
 	if _, err := compileToIR([]byte(`package main
 
@@ -1166,8 +1239,8 @@ func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
 	a := vec2(1)
 	a %= 2.1
 	return a.xxyy
-}`)); err == nil {
-		t.Errorf("error must be non-nil but was nil")
+}`)); err != nil {
+		t.Error(err)
 	}
 	if _, err := compileToIR([]byte(`package main
 
@@ -1525,6 +1598,36 @@ func TestSyntaxBitwiseOperatorAssign(t *testing.T) {
 		{stmt: "a := vec2(1); a ^= vec2(2)", err: true},
 		{stmt: "a := mat2(1); a ^= 2", err: true},
 		{stmt: "a := mat2(1); a ^= mat2(2)", err: true},
+
+		{stmt: "a := 1; a <<= 2", err: false},
+		{stmt: "a := 1; a <<= 2.0", err: false},
+		{stmt: "const c = 2; a := 1; a <<= c", err: false},
+		{stmt: "const c float = 2; a := 1; a <<= c", err: true},
+		{stmt: "a := 1; a <<= -1", err: true},
+		{stmt: "a := 1; a <<= int(2)", err: false},
+		{stmt: "a := 1; a <<= vec2(2)", err: true},
+		{stmt: "a := 1; a <<= ivec2(2)", err: true},
+		{stmt: "a := 1.0; a <<= 2", err: true},
+		{stmt: "a := ivec2(1); a <<= 2", err: false},
+		{stmt: "a := ivec2(1); a <<= ivec2(1)", err: false},
+		{stmt: "a := ivec2(1); a <<= ivec3(1)", err: true},
+		{stmt: "a := vec2(1); a <<= 2", err: true},
+		{stmt: "a := mat2(1); a <<= 2", err: true},
+
+		{stmt: "a := 1; a >>= 2", err: false},
+		{stmt: "a := 1; a >>= 2.0", err: false},
+		{stmt: "const c = 2; a := 1; a >>= c", err: false},
+		{stmt: "const c float = 2; a := 1; a >>= c", err: true},
+		{stmt: "a := 1; a >>= -1", err: true},
+		{stmt: "a := 1; a >>= int(2)", err: false},
+		{stmt: "a := 1; a >>= vec2(2)", err: true},
+		{stmt: "a := 1; a >>= ivec2(2)", err: true},
+		{stmt: "a := 1.0; a >>= 2", err: true},
+		{stmt: "a := ivec2(1); a >>= 2", err: false},
+		{stmt: "a := ivec2(1); a >>= ivec2(1)", err: false},
+		{stmt: "a := ivec2(1); a >>= ivec3(1)", err: true},
+		{stmt: "a := vec2(1); a >>= 2", err: true},
+		{stmt: "a := mat2(1); a >>= 2", err: true},
 	}
 
 	for _, c := range cases {
@@ -1777,9 +1880,9 @@ func TestSyntaxConstructorFuncType(t *testing.T) {
 		{stmt: "i := 1; a := vec2(i); _ = a", err: true},
 		{stmt: "i := 1.0; a := vec2(i); _ = a", err: false},
 		{stmt: "a := vec2(vec2(1)); _ = a", err: false},
-		{stmt: "a := vec2(vec3(1)); _ = a", err: true},
+		{stmt: "a := vec2(vec3(1)); _ = a", err: false},
 		{stmt: "a := vec2(ivec2(1)); _ = a", err: false},
-		{stmt: "a := vec2(ivec3(1)); _ = a", err: true},
+		{stmt: "a := vec2(ivec3(1)); _ = a", err: false},
 
 		{stmt: "a := vec2(1, 1); _ = a", err: false},
 		{stmt: "a := vec2(1.0, 1.0); _ = a", err: false},
@@ -1798,10 +1901,10 @@ func TestSyntaxConstructorFuncType(t *testing.T) {
 		{stmt: "i := 1.0; a := vec3(i); _ = a", err: false},
 		{stmt: "a := vec3(vec3(1)); _ = a", err: false},
 		{stmt: "a := vec3(vec2(1)); _ = a", err: true},
-		{stmt: "a := vec3(vec4(1)); _ = a", err: true},
+		{stmt: "a := vec3(vec4(1)); _ = a", err: false},
 		{stmt: "a := vec3(ivec3(1)); _ = a", err: false},
 		{stmt: "a := vec3(ivec2(1)); _ = a", err: true},
-		{stmt: "a := vec3(ivec4(1)); _ = a", err: true},
+		{stmt: "a := vec3(ivec4(1)); _ = a", err: false},
 
 		{stmt: "a := vec3(1, 1, 1); _ = a", err: false},
 		{stmt: "a := vec3(1.0, 1.0, 1.0); _ = a", err: false},
@@ -1854,9 +1957,9 @@ func TestSyntaxConstructorFuncType(t *testing.T) {
 		{stmt: "i := 1; a := ivec2(i); _ = a", err: false},
 		{stmt: "i := 1.0; a := ivec2(i); _ = a", err: true},
 		{stmt: "a := ivec2(vec2(1)); _ = a", err: false},
-		{stmt: "a := ivec2(vec3(1)); _ = a", err: true},
+		{stmt: "a := ivec2(vec3(1)); _ = a", err: false},
 		{stmt: "a := ivec2(ivec2(1)); _ = a", err: false},
-		{stmt: "a := ivec2(ivec3(1)); _ = a", err: true},
+		{stmt: "a := ivec2(ivec3(1)); _ = a", err: false},
 
 		{stmt: "a := ivec2(1, 1); _ = a", err: false},
 		{stmt: "a := ivec2(1.0, 1.0); _ = a", err: false},
@@ -1876,10 +1979,10 @@ func TestSyntaxConstructorFuncType(t *testing.T) {
 		{stmt: "i := 1.0; a := ivec3(i); _ = a", err: true},
 		{stmt: "a := ivec3(vec3(1)); _ = a", err: false},
 		{stmt: "a := ivec3(vec2(1)); _ = a", err: true},
-		{stmt: "a := ivec3(vec4(1)); _ = a", err: true},
+		{stmt: "a := ivec3(vec4(1)); _ = a", err: false},
 		{stmt: "a := ivec3(ivec3(1)); _ = a", err: false},
 		{stmt: "a := ivec3(ivec2(1)); _ = a", err: true},
-		{stmt: "a := ivec3(ivec4(1)); _ = a", err: true},
+		{stmt: "a := ivec3(ivec4(1)); _ = a", err: false},
 
 		{stmt: "a := ivec3(1, 1, 1); _ = a", err: false},
 		{stmt: "a := ivec3(1.0, 1.0, 1.0); _ = a", err: false},
@@ -2062,9 +2165,95 @@ func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
 	foo()
 	return vec4(0)
 }
+`)); err != nil {
+		t.Error(err)
+	}
+	if _, err := compileToIR([]byte(`package main
+
+func Vertex(dstPos vec2, srcPos vec2, color vec4) (vec4, vec2, vec4) {
+	discard()
+	return vec4(0), srcPos, color
+}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return vec4(0)
+}
 `)); err == nil {
 		t.Errorf("error must be non-nil but was nil")
 	}
+	if _, err := compileToIR([]byte(`package main
+
+func foo() {
+	discard()
+}
+
+func bar() {
+	foo()
+}
+
+func Vertex(dstPos vec2, srcPos vec2, color vec4) (vec4, vec2, vec4) {
+	bar()
+	return vec4(0), srcPos, color
+}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	foo()
+	return vec4(0)
+}
+`)); err == nil || !strings.Contains(err.Error(), "4:2: discard is not available in foo called from Vertex") {
+		t.Errorf("error must be about discard in foo but was %v", err)
+	}
+}
+
+func TestSyntaxDerivatives(t *testing.T) {
+	for _, f := range []string{"dfdx", "dfdy", "fwidth"} {
+		if _, err := compileToIR([]byte(fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	var w vec2 = %s(srcPos)
+	return vec4(w, 0, 1)
+}
+`, f))); err != nil {
+			t.Errorf("%s: %v", f, err)
+		}
+		if _, err := compileToIR([]byte(fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	var w float = %s(srcPos)
+	return vec4(w)
+}
+`, f))); err == nil {
+			t.Errorf("%s: error must be non-nil but was nil", f)
+		}
+		if _, err := compileToIR([]byte(fmt.Sprintf(`package main
+
+func Vertex(dstPos vec2, srcPos vec2, color vec4) (vec4, vec2, vec4) {
+	return vec4(%s(dstPos), 0, 1), srcPos, color
+}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return color
+}
+`, f))); err == nil || !strings.Contains(err.Error(), fmt.Sprintf("%s is available only in Fragment", f)) {
+			t.Errorf("%s: error must be about the vertex entry point but was %v", f, err)
+		}
+		if _, err := compileToIR([]byte(fmt.Sprintf(`package main
+
+func foo(x vec2) vec2 {
+	return %s(x)
+}
+
+func Vertex(dstPos vec2, srcPos vec2, color vec4) (vec4, vec2, vec4) {
+	return vec4(dstPos, 0, 1), foo(srcPos), color
+}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return vec4(foo(srcPos), 0, 1)
+}
+`, f))); err == nil || !strings.Contains(err.Error(), fmt.Sprintf("4:9: %s is not available in foo called from Vertex", f)) {
+			t.Errorf("%s: error must be about foo but was %v", f, err)
+		}
+	}
 }
 
 // Issue #2184
@@ -2169,7 +2358,6 @@ func TestSyntaxBuiltinFuncDoubleArgsType(t *testing.T) {
 
 	funcs := []string{
 		"atan2",
-		"pow",
 		"distance",
 		"dot",
 		"reflect",
@@ -2232,6 +2420,64 @@ func TestSyntaxBuiltinFuncDoubleArgsType2(t *testing.T) {
 
 	funcs := []string{
 		"mod",
+		"pow",
+	}
+	for _, c := range cases {
+		for _, f := range funcs {
+			stmt := strings.ReplaceAll(c.stmt, "{{.Func}}", f)
+			src := fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+			_, err := compileToIR([]byte(src))
+			if err == nil && c.err {
+				t.Errorf("%s must return an error but does not", stmt)
+			} else if err != nil && !c.err {
+				t.Errorf("%s must not return nil but returned %v", stmt, err)
+			}
+		}
+	}
+}
+
+func TestSyntaxBuiltinFuncMinMaxType(t *testing.T) {
+	cases := []struct {
+		stmt string
+		err  bool
+	}{
+		{stmt: "a := {{.Func}}(); _ = a", err: true},
+		{stmt: "a := {{.Func}}(1); _ = a", err: true},
+		{stmt: "a := {{.Func}}(false, false); _ = a", err: true},
+		{stmt: "a := {{.Func}}(1, 1); _ = a", err: false},
+		{stmt: "a := {{.Func}}(1.0, 1); _ = a", err: false},
+		{stmt: "a := {{.Func}}(1, 1.0); _ = a", err: false},
+		{stmt: "a := {{.Func}}(int(1), int(1)); _ = a", err: true},
+		{stmt: "a := {{.Func}}(1, vec2(1)); _ = a", err: false}, // The first argument is broadcast.
+		{stmt: "a := {{.Func}}(1, vec3(1)); _ = a", err: false}, // The first argument is broadcast.
+		{stmt: "a := {{.Func}}(1, vec4(1)); _ = a", err: false}, // The first argument is broadcast.
+		{stmt: "a := {{.Func}}(1, ivec2(1)); _ = a", err: true},
+		{stmt: "a := {{.Func}}(1, ivec3(1)); _ = a", err: true},
+		{stmt: "a := {{.Func}}(1, ivec4(1)); _ = a", err: true},
+		{stmt: "a := {{.Func}}(vec2(1), 1); _ = a", err: false}, // The second argument can be a scalar.
+		{stmt: "a := {{.Func}}(vec2(1), vec2(1)); _ = a", err: false},
+		{stmt: "a := {{.Func}}(vec2(1), vec3(1)); _ = a", err: true},
+		{stmt: "a := {{.Func}}(vec2(1), vec4(1)); _ = a", err: true},
+		{stmt: "a := {{.Func}}(vec3(1), 1); _ = a", err: false}, // The second argument can be a scalar.
+		{stmt: "a := {{.Func}}(vec3(1), vec2(1)); _ = a", err: true},
+		{stmt: "a := {{.Func}}(vec3(1), vec3(1)); _ = a", err: false},
+		{stmt: "a := {{.Func}}(vec3(1), vec4(1)); _ = a", err: true},
+		{stmt: "a := {{.Func}}(vec4(1), 1); _ = a", err: false}, // The second argument can be a scalar.
+		{stmt: "a := {{.Func}}(vec4(1), vec2(1)); _ = a", err: true},
+		{stmt: "a := {{.Func}}(vec4(1), vec3(1)); _ = a", err: true},
+		{stmt: "a := {{.Func}}(vec4(1), vec4(1)); _ = a", err: false},
+		{stmt: "a := {{.Func}}(mat2(1), mat2(1)); _ = a", err: true},
+		{stmt: "a := {{.Func}}(ivec2(1), 1); _ = a", err: true},
+		{stmt: "a := {{.Func}}(ivec2(1), ivec2(1)); _ = a", err: true},
+		{stmt: "a := {{.Func}}(1, 1, 1); _ = a", err: true},
+	}
+
+	funcs := []string{
 		"min",
 		"max",
 	}
@@ -2273,7 +2519,7 @@ func TestSyntaxBuiltinFuncStepType(t *testing.T) {
 		{stmt: "a := step(1, ivec2(1)); _ = a", err: true},
 		{stmt: "a := step(1, ivec3(1)); _ = a", err: true},
 		{stmt: "a := step(1, ivec4(1)); _ = a", err: true},
-		{stmt: "a := step(vec2(1), 1); _ = a", err: true},
+		{stmt: "a := step(vec2(1), 1); _ = a", err: true}, // The result type follows the second argument x.
 		{stmt: "a := step(vec2(1), vec2(1)); _ = a", err: false},
 		{stmt: "a := step(vec2(1), vec3(1)); _ = a", err: true},
 		{stmt: "a := step(vec2(1), vec4(1)); _ = a", err: true},
@@ -2288,6 +2534,9 @@ func TestSyntaxBuiltinFuncStepType(t *testing.T) {
 		{stmt: "a := step(mat2(1), mat2(1)); _ = a", err: true},
 		{stmt: "a := step(ivec2(1), ivec2(1)); _ = a", err: true},
 		{stmt: "a := step(1, 1, 1); _ = a", err: true},
+		{stmt: "var a vec2 = step(1, vec2(1)); _ = a", err: false},
+		{stmt: "var a float = step(1, vec2(1)); _ = a", err: true},
+		{stmt: "var a vec3 = step(vec3(1), vec3(1)); _ = a", err: false},
 	}
 
 	for _, c := range cases {
@@ -2378,21 +2627,23 @@ func TestSyntaxBuiltinFuncClampType(t *testing.T) {
 		{stmt: "a := clamp(1.0, 1, 1); _ = a", err: false},
 		{stmt: "a := clamp(1, 1.0, 1); _ = a", err: false},
 		{stmt: "a := clamp(1, 1, 1.0); _ = a", err: false},
-		{stmt: "a := clamp(1, vec2(1), 1); _ = a", err: true},
-		{stmt: "a := clamp(1, 1, vec2(1)); _ = a", err: true},
-		{stmt: "a := clamp(1, vec2(1), vec2(1)); _ = a", err: true},
+		{stmt: "a := clamp(1, vec2(1), 1); _ = a", err: false},
+		{stmt: "a := clamp(1, 1, vec2(1)); _ = a", err: false},
+		{stmt: "a := clamp(1, vec2(1), vec2(1)); _ = a", err: false},
 		{stmt: "a := clamp(vec2(1), 1, 1); _ = a", err: false},
-		{stmt: "a := clamp(vec2(1), 1, vec2(1)); _ = a", err: true},
-		{stmt: "a := clamp(vec2(1), vec2(1), 1); _ = a", err: true},
+		{stmt: "a := clamp(vec2(1), 1, vec2(1)); _ = a", err: false},
+		{stmt: "a := clamp(vec2(1), vec2(1), 1); _ = a", err: false},
 		{stmt: "a := clamp(vec2(1), vec2(1), vec2(1)); _ = a", err: false},
 		{stmt: "a := clamp(vec2(1), vec2(1), vec3(1)); _ = a", err: true},
+		{stmt: "a := clamp(1, vec2(1), vec3(1)); _ = a", err: true},
+		{stmt: "a := clamp(vec3(1), vec2(1), 1); _ = a", err: true},
 		{stmt: "a := clamp(vec3(1), 1, 1); _ = a", err: false},
-		{stmt: "a := clamp(vec3(1), 1, vec3(1)); _ = a", err: true},
-		{stmt: "a := clamp(vec3(1), vec3(1), 1); _ = a", err: true},
+		{stmt: "a := clamp(vec3(1), 1, vec3(1)); _ = a", err: false},
+		{stmt: "a := clamp(vec3(1), vec3(1), 1); _ = a", err: false},
 		{stmt: "a := clamp(vec3(1), vec3(1), vec3(1)); _ = a", err: false},
 		{stmt: "a := clamp(vec4(1), 1, 1); _ = a", err: false},
-		{stmt: "a := clamp(vec4(1), 1, vec4(1)); _ = a", err: true},
-		{stmt: "a := clamp(vec4(1), vec4(1), 1); _ = a", err: true},
+		{stmt: "a := clamp(vec4(1), 1, vec4(1)); _ = a", err: false},
+		{stmt: "a := clamp(vec4(1), vec4(1), 1); _ = a", err: false},
 		{stmt: "a := clamp(vec4(1), vec4(1), vec4(1)); _ = a", err: false},
 		{stmt: "a := clamp(ivec2(1), 1, 1); _ = a", err: true},
 		{stmt: "a := clamp(1, 1, 1, 1); _ = a", err: true},
@@ -2425,7 +2676,8 @@ func TestSyntaxBuiltinFuncMixType(t *testing.T) {
 		{stmt: "a := mix(1); _ = a", err: true},
 		{stmt: "a := mix(false, false); _ = a", err: true},
 		{stmt: "a := mix(1, 1); _ = a", err: true},
-		{stmt: "a := mix(false, false, false); _ = a", err: true},
+		{stmt: "a := mix(false, false, 1); _ = a", err: true},
+		{stmt: "a := mix(false, false, false); _ = a", err: false}, // A bool value selects one of the values.
 		{stmt: "a := mix(1, 1, 1); _ = a", err: false},
 		{stmt: "a := mix(1.0, 1, 1); _ = a", err: false},
 		{stmt: "a := mix(1, 1.0, 1); _ = a", err: false},
@@ -2448,6 +2700,13 @@ func TestSyntaxBuiltinFuncMixType(t *testing.T) {
 		{stmt: "a := mix(vec4(1), vec4(1), vec4(1)); _ = a", err: false},
 		{stmt: "a := mix(ivec2(1), ivec2(1), 1); _ = a", err: true},
 		{stmt: "a := mix(ivec2(1), ivec2(1), ivec2(1)); _ = a", err: true},
+		{stmt: "a := mix(vec2(1), vec2(1), bvec2(true)); _ = a", err: false}, // A bool vector selects each component.
+		{stmt: "a := mix(vec3(1), vec3(1), bvec3(true)); _ = a", err: false},
+		{stmt: "a := mix(ivec4(1), ivec4(1), bvec4(true)); _ = a", err: false},
+		{stmt: "a := mix(bvec2(false), bvec2(true), bvec2(true)); _ = a", err: false},
+		{stmt: "a := mix(vec2(1), vec2(1), bvec3(true)); _ = a", err: true},
+		{stmt: "a := mix(vec2(1), ivec2(1), bvec2(true)); _ = a", err: true},
+		{stmt: "a := mix(1, 1, bvec2(true)); _ = a", err: true},
 		{stmt: "a := mix(1, 1, 1, 1); _ = a", err: true},
 	}
 
@@ -2487,24 +2746,26 @@ func TestSyntaxBuiltinFuncSmoothstepType(t *testing.T) {
 		{stmt: "a := smoothstep(1, 1, vec2(1)); _ = a", err: false},
 		{stmt: "a := smoothstep(1, 1, vec3(1)); _ = a", err: false},
 		{stmt: "a := smoothstep(1, 1, vec4(1)); _ = a", err: false},
-		{stmt: "a := smoothstep(1, vec2(1), vec2(1)); _ = a", err: true},
+		{stmt: "a := smoothstep(1, vec2(1), vec2(1)); _ = a", err: false},
 		{stmt: "a := smoothstep(vec2(1), 1, 1); _ = a", err: true},
-		{stmt: "a := smoothstep(vec2(1), 1, vec2(1)); _ = a", err: true},
+		{stmt: "a := smoothstep(vec2(1), 1, vec2(1)); _ = a", err: false},
 		{stmt: "a := smoothstep(vec2(1), vec2(1), 1); _ = a", err: true},
 		{stmt: "a := smoothstep(vec2(1), vec2(1), vec2(1)); _ = a", err: false},
 		{stmt: "a := smoothstep(vec2(1), vec2(1), vec3(1)); _ = a", err: true},
 		{stmt: "a := smoothstep(vec3(1), 1, 1); _ = a", err: true},
-		{stmt: "a := smoothstep(vec3(1), 1, vec3(1)); _ = a", err: true},
+		{stmt: "a := smoothstep(vec3(1), 1, vec3(1)); _ = a", err: false},
 		{stmt: "a := smoothstep(vec3(1), vec3(1), 1); _ = a", err: true},
 		{stmt: "a := smoothstep(vec3(1), vec3(1), vec3(1)); _ = a", err: false},
 		{stmt: "a := smoothstep(vec4(1), 1, 1); _ = a", err: true},
-		{stmt: "a := smoothstep(vec4(1), 1, vec4(1)); _ = a", err: true},
+		{stmt: "a := smoothstep(vec4(1), 1, vec4(1)); _ = a", err: false},
 		{stmt: "a := smoothstep(vec4(1), vec4(1), 1); _ = a", err: true},
 		{stmt: "a := smoothstep(vec4(1), vec4(1), vec4(1)); _ = a", err: false},
 		{stmt: "a := smoothstep(ivec2(1), 1, 1); _ = a", err: true},
 		{stmt: "a := smoothstep(1, ivec2(1), 1); _ = a", err: true},
 		{stmt: "a := smoothstep(1, 1, ivec2(1)); _ = a", err: true},
 		{stmt: "a := smoothstep(1, 1, 1, 1); _ = a", err: true},
+		{stmt: "var a vec2 = smoothstep(1, vec2(1), vec2(1)); _ = a", err: false},
+		{stmt: "var a float = smoothstep(1, 1, vec2(1)); _ = a", err: true},
 	}
 
 	for _, c := range cases {
@@ -3543,6 +3804,19 @@ func TestSyntaxCast(t *testing.T) {
 		{stmt: "a := 1; _ = float(a)", err: false},
 		{stmt: "a := 1.0; _ = float(a)", err: false},
 		{stmt: "a := 1.1; _ = float(a)", err: false},
+		{stmt: "a := 1; b := int(float(a)); _ = b", err: false},
+		{stmt: "a := 1.5; b := float(int(a)); _ = b", err: false},
+		{stmt: "a := vec2(1); b := vec3(a, 1); _ = b", err: false},
+		{stmt: "a := vec2(1); b := vec4(a, a); _ = b", err: false},
+		{stmt: "a := vec4(1); b := vec2(a); _ = b", err: false},
+		{stmt: "a := vec4(1); b := ivec3(a); _ = b", err: false},
+		{stmt: "a := ivec4(1); b := vec2(vec3(a)); _ = b", err: false},
+		{stmt: "a := vec2(1); b := vec3(a); _ = b", err: true},
+		{stmt: "a := vec3(1); b := int(a); _ = b", err: true},
+		{stmt: "a := vec3(1); b := float(a); _ = b", err: true},
+		{stmt: "a := mat2(1); b := float(a); _ = b", err: true},
+		{stmt: "a := true; b := int(a); _ = b", err: true},
+		{stmt: "a := mat4(1); b := vec4(a); _ = b", err: true},
 	}
 
 	for _, c := range cases {
@@ -3562,6 +3836,128 @@ func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
 	}
 }
 
+func TestSyntaxVectorNarrowing(t *testing.T) {
+	src := `package main
+
+func Foo(v vec4) vec2 {
+	return vec2(v)
+}
+`
+	p, err := compileToIR([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// vec2(v) is lowered to vec2(v.xy).
+	e := p.Funcs[0].Block.Stmts[0].Exprs[0]
+	if e.Type != shaderir.Call || e.Exprs[0].BuiltinFunc != shaderir.Vec2F || len(e.Exprs) != 2 {
+		t.Fatalf("the expression must be a call of vec2 with one argument: %v", e)
+	}
+	if arg := e.Exprs[1]; arg.Type != shaderir.FieldSelector || arg.Exprs[1].Swizzling != "xy" {
+		t.Errorf("the argument must be a swizzling xy: %v", arg)
+	}
+}
+
+func TestSyntaxVaryings(t *testing.T) {
+	cases := []struct {
+		name   string
+		vertex string
+		frag   string
+		err    bool
+		warn   bool
+	}{
+		{
+			name:   "matched",
+			vertex: "func Vertex(pos vec2) (vec4, float, vec3) { return vec4(pos, 0, 1), pos.x, vec3(pos, 1) }",
+			frag:   "func Fragment(pos vec4, depth float, n vec3) vec4 { return vec4(n, depth) }",
+		},
+		{
+			name:   "unused",
+			vertex: "func Vertex(pos vec2) (vec4, float, vec3) { return vec4(pos, 0, 1), pos.x, vec3(pos, 1) }",
+			frag:   "func Fragment(pos vec4, depth float, n vec3) vec4 { return vec4(depth) }",
+			warn:   true,
+		},
+		{
+			name:   "blank",
+			vertex: "func Vertex(pos vec2) (vec4, float, vec3) { return vec4(pos, 0, 1), pos.x, vec3(pos, 1) }",
+			frag:   "func Fragment(pos vec4, depth float, _ vec3) vec4 { return vec4(depth) }",
+		},
+		{
+			name:   "count mismatch",
+			vertex: "func Vertex(pos vec2) (vec4, float, vec3) { return vec4(pos, 0, 1), pos.x, vec3(pos, 1) }",
+			frag:   "func Fragment(pos vec4, depth float) vec4 { return vec4(depth) }",
+			err:    true,
+		},
+		{
+			name:   "type mismatch",
+			vertex: "func Vertex(pos vec2) (vec4, float, vec3) { return vec4(pos, 0, 1), pos.x, vec3(pos, 1) }",
+			frag:   "func Fragment(pos vec4, depth vec2, n vec3) vec4 { return vec4(n, depth.x) }",
+			err:    true,
+		},
+		{
+			name:   "int",
+			vertex: "func Vertex(pos vec2) (vec4, int) { return vec4(pos, 0, 1), 1 }",
+			frag:   "func Fragment(pos vec4, i int) vec4 { return vec4(float(i)) }",
+			err:    true,
+		},
+		{
+			name:   "matrix",
+			vertex: "func Vertex(pos vec2) (vec4, mat2) { return vec4(pos, 0, 1), mat2(1) }",
+			frag:   "func Fragment(pos vec4, m mat2) vec4 { return vec4(m[0], m[1]) }",
+			err:    true,
+		},
+	}
+
+	for _, c := range cases {
+		src := fmt.Sprintf(`package main
+
+%s
+
+%s
+`, c.vertex, c.frag)
+		_, warnings, err := compileToIRWithWarnings([]byte(src))
+		if err == nil && c.err {
+			t.Errorf("%s: must return an error but does not", c.name)
+		} else if err != nil && !c.err {
+			t.Errorf("%s: must not return nil but returned %v", c.name, err)
+		}
+		if c.err {
+			continue
+		}
+		if got := len(warnings) > 0; got != c.warn {
+			t.Errorf("%s: warned: got: %v, want: %v (%v)", c.name, got, c.warn, warnings)
+		}
+	}
+}
+
+func TestSyntaxVectorRelationalOp(t *testing.T) {
+	cases := []struct {
+		stmt string
+		err  string
+	}{
+		{stmt: "_ = vec2(0) < vec2(1)", err: "operator < not defined on vectors; use lessThan(x, y)"},
+		{stmt: "_ = vec3(0) <= vec3(1)", err: "operator <= not defined on vectors; use lessThanEqual(x, y)"},
+		{stmt: "_ = ivec4(0) > ivec4(1)", err: "operator > not defined on vectors; use greaterThan(x, y)"},
+		{stmt: "_ = vec2(0) >= 1", err: "operator >= not defined on vectors; use greaterThanEqual(x, y)"},
+		{stmt: "_ = 1.0 < vec4(0)", err: "operator < not defined on vectors; use lessThan(x, y)"},
+	}
+
+	for _, c := range cases {
+		_, err := compileToIR([]byte(fmt.Sprintf(`package main
+
+func Foo() {
+	%s
+}`, c.stmt)))
+		if err == nil {
+			t.Errorf("%s must return an error but does not", c.stmt)
+			continue
+		}
+		if !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%s: got: %v, want: an error containing %q", c.stmt, err, c.err)
+		}
+	}
+}
+
 // Issue #2718
 func TestSyntaxCompare(t *testing.T) {
 	cases := []struct {
@@ -3666,23 +4062,45 @@ func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
 
 // Issue #2680
 func TestSyntaxForWithLocalVariable(t *testing.T) {
-	if _, err := compileToIR([]byte(`package main
+	// These for-statements are not in the canonical form, and must not be lowered to shaderir.For.
+	for _, src := range []string{`package main
 
 func foo() {
 	i := 0
 	for i = 0; i < 1; i++ {
 	}
-}`)); err == nil {
-		t.Error("compileToIR must return an error but did not")
-	}
-	if _, err := compileToIR([]byte(`package main
+}`, `package main
 
 func foo() {
 	for i, j := 0, 0; i < 1; i++ {
 		_ = j
 	}
-}`)); err == nil {
-		t.Error("compileToIR must return an error but did not")
+}`} {
+		p, err := compileToIR([]byte(src))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		var hasWhile func(b *shaderir.Block) bool
+		hasWhile = func(b *shaderir.Block) bool {
+			for _, s := range b.Stmts {
+				if s.Type == shaderir.For {
+					return false
+				}
+				if s.Type == shaderir.While {
+					return true
+				}
+				for _, b := range s.Blocks {
+					if hasWhile(b) {
+						return true
+					}
+				}
+			}
+			return false
+		}
+		if !hasWhile(p.Funcs[0].Block) {
+			t.Errorf("the for-statement must be lowered to shaderir.While:\n%s", src)
+		}
 	}
 }
 
@@ -3800,6 +4218,36 @@ func TestSyntaxBitwiseOperator(t *testing.T) {
 		{stmt: "_ = mat2(0) ^ mat2(1)", err: true},
 		{stmt: "_ = mat3(0) ^ mat3(1)", err: true},
 		{stmt: "_ = mat4(0) ^ mat4(1)", err: true},
+
+		{stmt: "_ = false << true", err: true},
+		{stmt: "_ = int(0) << int(1)", err: false},
+		{stmt: "_ = int(0) << 1.0", err: false},
+		{stmt: "_ = int(0) << 1.5", err: true},
+		{stmt: "_ = int(0) << -1", err: true},
+		{stmt: "_ = float(0) << int(1)", err: true},
+		{stmt: "_ = vec2(0) << int(1)", err: true},
+		{stmt: "_ = ivec2(0) << ivec2(1)", err: false},
+		{stmt: "_ = ivec3(0) << ivec3(1)", err: false},
+		{stmt: "_ = ivec4(0) << ivec4(1)", err: false},
+		{stmt: "_ = ivec2(0) << int(1)", err: false},
+		{stmt: "_ = ivec2(0) << ivec3(1)", err: true},
+		{stmt: "_ = int(0) << ivec2(1)", err: true},
+		{stmt: "_ = mat2(0) << int(1)", err: true},
+
+		{stmt: "_ = false >> true", err: true},
+		{stmt: "_ = int(0) >> int(1)", err: false},
+		{stmt: "_ = int(0) >> 1.0", err: false},
+		{stmt: "_ = int(0) >> 1.5", err: true},
+		{stmt: "_ = int(0) >> -1", err: true},
+		{stmt: "_ = float(0) >> int(1)", err: true},
+		{stmt: "_ = vec2(0) >> int(1)", err: true},
+		{stmt: "_ = ivec2(0) >> ivec2(1)", err: false},
+		{stmt: "_ = ivec3(0) >> ivec3(1)", err: false},
+		{stmt: "_ = ivec4(0) >> ivec4(1)", err: false},
+		{stmt: "_ = ivec2(0) >> int(1)", err: false},
+		{stmt: "_ = ivec2(0) >> ivec3(1)", err: true},
+		{stmt: "_ = int(0) >> ivec2(1)", err: true},
+		{stmt: "_ = mat2(0) >> int(1)", err: true},
 	}
 
 	for _, c := range cases {
@@ -3818,3 +4266,2425 @@ func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
 		}
 	}
 }
+
+func TestSyntaxIntegerDivision(t *testing.T) {
+	cases := []struct {
+		stmt string
+		err  bool
+		warn bool
+	}{
+		{stmt: "i, j := 5, 2; var a int = i / j; _ = a", err: false, warn: false},
+		{stmt: "x, y := 5.0, 2.0; var a float = x / y; _ = a", err: false, warn: false},
+		{stmt: "i, j := 5, 2; var a float = i / j; _ = a", err: true},
+		{stmt: "i, j := 5, 2; a := float(i) / float(j); _ = a", err: false, warn: false},
+		{stmt: "i, j := 5, 2; a := float(i / j); _ = a", err: false, warn: true},
+		{stmt: "i, j := 5, 2; a := vec2(float(i / j)); _ = a", err: false, warn: true},
+		{stmt: "var a float = 1 / 2; _ = a", err: false, warn: true},
+		{stmt: "var a float = 1.0 / 2; _ = a", err: false, warn: false},
+		{stmt: "a := vec2(1 / 2); _ = a", err: false, warn: true},
+		{stmt: "a := 1 / 2; _ = a", err: false, warn: false},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		_, warnings, err := compileToIRWithWarnings([]byte(src))
+		if err == nil && c.err {
+			t.Errorf("%s must return an error but does not", stmt)
+		} else if err != nil && !c.err {
+			t.Errorf("%s must not return nil but returned %v", stmt, err)
+		}
+		if c.err {
+			continue
+		}
+		if got := len(warnings) > 0; got != c.warn {
+			t.Errorf("%s: warned: got: %v, want: %v (%v)", stmt, got, c.warn, warnings)
+		}
+	}
+
+	// The constant division of integers is an integer division.
+	p, err := compileToIR([]byte(`package main
+
+func Foo() (int, float) {
+	return 5 / 2, 5.0 / 2.0
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmts := p.Funcs[0].Block.Stmts
+	if got, want := stmts[0].Exprs[1].Const.String(), "2"; got != want {
+		t.Errorf("got: %s, want: %s", got, want)
+	}
+	if got, want := stmts[1].Exprs[1].Const.String(), "2.5"; got != want {
+		t.Errorf("got: %s, want: %s", got, want)
+	}
+}
+
+func TestSyntaxMixedIntFloatOperands(t *testing.T) {
+	cases := []struct {
+		stmt string
+		err  string
+	}{
+		{stmt: "i, x := 5, 2.0; _ = i / x", err: "mismatched types int and float"},
+		{stmt: "i, x := 5, 2.0; _ = x * i", err: "mismatched types float and int"},
+		{stmt: "_ = vec2(1) + ivec2(1)", err: "mismatched types vec2 and ivec2"},
+		{stmt: "const c int = 5; const d float = 2; _ = c / d", err: "mismatched types int and float"},
+		{stmt: "i := 5; _ = i / 2.5", err: "constant 2.5 truncated to integer"},
+		{stmt: "_ = 2.5 * ivec2(1)", err: "constant 2.5 truncated to integer"},
+		{stmt: "i := 5; _ = i / 2.0", err: ""},
+		{stmt: "i, x := 5, 2.0; _ = float(i) / x", err: ""},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		_, err := compileToIR([]byte(src))
+		if c.err == "" {
+			if err != nil {
+				t.Errorf("%s must not return nil but returned %v", stmt, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%s must return an error but does not", stmt)
+			continue
+		}
+		if !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%s: got: %v, want: an error containing %q", stmt, err, c.err)
+		}
+	}
+}
+
+func TestSyntaxDivisionByZero(t *testing.T) {
+	cases := []struct {
+		stmt string
+		err  bool
+	}{
+		{stmt: "x := 1; _ = x / 0", err: true},
+		{stmt: "x := 1; _ = x % 0", err: true},
+		{stmt: "x := 1.0; _ = x / 0", err: true},
+		{stmt: "x := 1.0; _ = x / 0.0", err: true},
+		{stmt: "x := 1.0; _ = x % 0.0", err: true},
+		{stmt: "x := 1.0; _ = x / float(0)", err: true},
+		{stmt: "const z = 0; x := 1; _ = x / z", err: true},
+		{stmt: "x := vec2(1); _ = x / 0", err: true},
+		{stmt: "x := vec2(1); _ = x / vec2(1, 0)", err: true},
+		{stmt: "x := ivec2(1); _ = x % ivec2(0)", err: true},
+		{stmt: "x := 1; x /= 0", err: true},
+		{stmt: "x := 1; x %= 0", err: true},
+		{stmt: "x := 1.0; x /= 0.0", err: true},
+		{stmt: "x := 1.0; x %= 0", err: true},
+		{stmt: "x := vec3(1); x /= vec3(1, 2, 0)", err: true},
+		{stmt: "x := 1; _ = x / 2", err: false},
+		{stmt: "x := 1.0; _ = x / 0.5", err: false},
+		{stmt: "x := 0.0; _ = 1 / x", err: false},
+		{stmt: "x := vec2(1); x /= vec2(1, 2)", err: false},
+		{stmt: "_ = 0 / 1", err: false},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		_, err := compileToIR([]byte(src))
+		if err == nil && c.err {
+			t.Errorf("%s must return an error but does not", stmt)
+		} else if err != nil && !c.err {
+			t.Errorf("%s must not return nil but returned %v", stmt, err)
+		}
+		if err != nil && c.err && !strings.Contains(err.Error(), "division by zero") {
+			t.Errorf("%s: got: %v, want: division by zero", stmt, err)
+		}
+	}
+}
+
+func TestSyntaxLossyConstantConversion(t *testing.T) {
+	cases := []struct {
+		stmt string
+		warn bool
+	}{
+		{stmt: "a := int(1.0); _ = a", warn: false},
+		{stmt: "a := int(2147483647); _ = a", warn: false},
+		{stmt: "a := int(1e10); _ = a", warn: true},
+		{stmt: "a := int(-3e9); _ = a", warn: true},
+		{stmt: "a := float(1); _ = a", warn: false},
+		{stmt: "a := float(16777216); _ = a", warn: false},
+		{stmt: "a := float(16777217); _ = a", warn: true},
+		{stmt: "a := float(0.1); _ = a", warn: false},
+		{stmt: "i := 16777217; a := float(i); _ = a", warn: false},
+		{stmt: "f := 1e10; a := int(f); _ = a", warn: false},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		_, warnings, err := compileToIRWithWarnings([]byte(src))
+		if err != nil {
+			t.Errorf("%s must not return nil but returned %v", stmt, err)
+			continue
+		}
+		if got := len(warnings) > 0; got != c.warn {
+			t.Errorf("%s: warned: got: %v, want: %v (%v)", stmt, got, c.warn, warnings)
+		}
+	}
+}
+
+func TestSyntaxImplicitTime(t *testing.T) {
+	const src = `package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return color * sin(Time)
+}
+`
+	p, err := compileToIR([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.ImplicitTime {
+		t.Errorf("ImplicitTime must be true but not")
+	}
+	if got, want := len(p.UniformNames), 1; got != want {
+		t.Fatalf("len(p.UniformNames): got: %d, want: %d", got, want)
+	}
+	if got, want := p.UniformNames[0], shaderir.TimeUniformName; got != want {
+		t.Errorf("p.UniformNames[0]: got: %s, want: %s", got, want)
+	}
+	if got, want := p.Uniforms[0], (shaderir.Type{Main: shaderir.Float}); !got.Equal(&want) {
+		t.Errorf("p.Uniforms[0]: got: %s, want: %s", got.String(), want.String())
+	}
+
+	// The time uniform variable is an ordinary uniform variable, and its value can be set.
+	uniforms := []uint32{1}
+	p.FilterUniformVariables(uniforms)
+	if got, want := uniforms[0], uint32(1); got != want {
+		t.Errorf("uniforms[0]: got: %d, want: %d", got, want)
+	}
+
+	// An explicit declaration is respected.
+	p, err = compileToIR([]byte(`package main
+
+var Time float
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return color * sin(Time)
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.ImplicitTime {
+		t.Errorf("ImplicitTime must be false but not")
+	}
+	if got, want := len(p.UniformNames), 1; got != want {
+		t.Errorf("len(p.UniformNames): got: %d, want: %d", got, want)
+	}
+
+	// Time is not available when the auto-time is disabled.
+	if _, err := shader.CompileWithOptions([]byte(src), "Vertex", "Fragment", 0, &shader.CompileOptions{
+		DisableAutoTime: true,
+	}); err == nil {
+		t.Errorf("error must be non-nil but was nil")
+	}
+}
+
+func TestSyntaxUnaryMinus(t *testing.T) {
+	cases := []struct {
+		stmt string
+		err  bool
+	}{
+		{stmt: "a := -1; _ = a", err: false},
+		{stmt: "a := -1.0; _ = a", err: false},
+		{stmt: "x := 1; a := -x; var b int = a; _ = b", err: false},
+		{stmt: "x := 1.0; a := -x; var b float = a; _ = b", err: false},
+		{stmt: "x := vec2(1); a := -x; var b vec2 = a; _ = b", err: false},
+		{stmt: "x := vec3(1); a := -x; var b vec3 = a; _ = b", err: false},
+		{stmt: "x := vec4(1); a := -x; var b vec4 = a; _ = b", err: false},
+		{stmt: "x := ivec2(1); a := -x; var b ivec2 = a; _ = b", err: false},
+		{stmt: "x := ivec3(1); a := -x; var b ivec3 = a; _ = b", err: false},
+		{stmt: "x := ivec4(1); a := -x; var b ivec4 = a; _ = b", err: false},
+		{stmt: "x := mat2(1); a := -x; var b mat2 = a; _ = b", err: false},
+		{stmt: "x := mat3(1); a := -x; var b mat3 = a; _ = b", err: false},
+		{stmt: "x := mat4(1); a := -x; var b mat4 = a; _ = b", err: false},
+		{stmt: "a := -vec3(1, 2, 3); var b vec3 = a; _ = b", err: false},
+		{stmt: "a := -true; _ = a", err: true},
+		{stmt: "x := true; a := -x; _ = a", err: true},
+		{stmt: "x := [2]float{}; a := -x; _ = a", err: true},
+		{stmt: "const c int = -2147483647; a := -c; _ = a", err: false},
+		{stmt: "const c int = -2147483648; a := -c; _ = a", err: true},
+		{stmt: "a := -ivec2(1, -2147483648); _ = a", err: true},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		_, err := compileToIR([]byte(src))
+		if err == nil && c.err {
+			t.Errorf("%s must return an error but does not", stmt)
+		} else if err != nil && !c.err {
+			t.Errorf("%s must not return nil but returned %v", stmt, err)
+		}
+	}
+}
+
+func TestSyntaxUnaryPlusAndNot(t *testing.T) {
+	cases := []struct {
+		stmt string
+		err  bool
+	}{
+		{stmt: "a := +1; _ = a", err: false},
+		{stmt: "x := 1.0; a := +x; var b float = a; _ = b", err: false},
+		{stmt: "x := vec3(1); a := +x; var b vec3 = a; _ = b", err: false},
+		{stmt: "x := mat2(1); a := +x; var b mat2 = a; _ = b", err: false},
+		{stmt: "a := +true; _ = a", err: true},
+		{stmt: "a := !true; var b bool = a; _ = b", err: false},
+		{stmt: "x := true; a := !x; var b bool = a; _ = b", err: false},
+		{stmt: "x := 1.0; a := !(x > 0); var b bool = a; _ = b", err: false},
+		{stmt: "a := !1; _ = a", err: true},
+		{stmt: "x := 1.0; a := !x; _ = a", err: true},
+		{stmt: "x := vec2(1); a := !x; _ = a", err: true},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		_, err := compileToIR([]byte(src))
+		if err == nil && c.err {
+			t.Errorf("%s must return an error but does not", stmt)
+		} else if err != nil && !c.err {
+			t.Errorf("%s must not return nil but returned %v", stmt, err)
+		}
+	}
+}
+
+func TestSyntaxUnaryFolding(t *testing.T) {
+	p, err := compileToIR([]byte(`package main
+
+func Foo(x float) (vec3, bool, bool) {
+	return -vec3(1, 2, 3), !(x > 0), !(1 > 0)
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmts := p.Funcs[0].Block.Stmts
+
+	// -vec3(1, 2, 3) is folded to vec3(-1, -2, -3).
+	e := stmts[0].Exprs[1]
+	if e.Type != shaderir.Call || len(e.Exprs) != 4 {
+		t.Fatalf("the expression must be a call of vec3 with three arguments: %v", e)
+	}
+	for i, want := range []string{"-1", "-2", "-3"} {
+		if got := e.Exprs[i+1].Const; got == nil || got.String() != want {
+			t.Errorf("argument %d: got: %v, want: %s", i, got, want)
+		}
+	}
+
+	// !(x > 0) is not a constant.
+	if e := stmts[1].Exprs[1]; e.Type != shaderir.Unary || e.Op != shaderir.NotOp {
+		t.Errorf("the expression must be a unary not: %v", e)
+	}
+
+	// !(1 > 0) is folded to false.
+	if e := stmts[2].Exprs[1]; e.Type != shaderir.NumberExpr || e.Const.String() != "false" {
+		t.Errorf("the expression must be the constant false: %v", e)
+	}
+}
+
+func TestSyntaxBuiltinFuncFolding(t *testing.T) {
+	cases := []struct {
+		expr   string
+		folded bool
+		value  float64
+		err    bool
+	}{
+		{expr: "sqrt(4.0)", folded: true, value: 2},
+		{expr: "sqrt(4)", folded: true, value: 2},
+		{expr: "pow(2.0, 3.0)", folded: true, value: 8},
+		{expr: "abs(-1.0)", folded: true, value: 1},
+		{expr: "max(1.0, 2.0)", folded: true, value: 2},
+		{expr: "clamp(3.0, 0.0, 1.0)", folded: true, value: 1},
+		{expr: "mod(-1.0, 3.0)", folded: true, value: 2},
+		{expr: "step(0.5, 1.0)", folded: true, value: 1},
+		{expr: "sqrt(x)", folded: false},
+		{expr: "pow(x, 2.0)", folded: false},
+		{expr: "length(vec2(3, 4))", folded: false},
+		{expr: "sin(0.1)", folded: false},
+		{expr: "sqrt(2.0)", folded: false},
+		{expr: "pow(10.0, 20.0)", folded: false},
+		{expr: "exp(100.0)", folded: false},
+		{expr: "exp(1000.0)", folded: false},
+		{expr: "sqrt(-1.0)", err: true},
+		{expr: "inversesqrt(0.0)", err: true},
+		{expr: "log(0.0)", err: true},
+		{expr: "log2(-1.0)", err: true},
+		{expr: "pow(-2.0, 0.5)", err: true},
+		{expr: "asin(2.0)", err: true},
+		{expr: "mod(1.0, 0.0)", err: true},
+	}
+
+	for _, c := range cases {
+		src := fmt.Sprintf(`package main
+
+func Foo(x float) float {
+	return %s
+}
+`, c.expr)
+		p, err := compileToIR([]byte(src))
+		if err == nil && c.err {
+			t.Errorf("%s must return an error but does not", c.expr)
+			continue
+		} else if err != nil && !c.err {
+			t.Errorf("%s must not return nil but returned %v", c.expr, err)
+			continue
+		}
+		if c.err {
+			continue
+		}
+
+		e := p.Funcs[0].Block.Stmts[0].Exprs[0]
+		if !c.folded {
+			if e.Type != shaderir.Call {
+				t.Errorf("%s must not be folded: %v", c.expr, e)
+			}
+			continue
+		}
+		if e.Type != shaderir.NumberExpr {
+			t.Errorf("%s must be folded: %v", c.expr, e)
+			continue
+		}
+		if got, _ := gconstant.Float64Val(e.Const); got != c.value {
+			t.Errorf("%s: got: %v, want: %v", c.expr, got, c.value)
+		}
+	}
+}
+
+func TestSyntaxArrayElementSwizzle(t *testing.T) {
+	cases := []struct {
+		stmt string
+		err  bool
+	}{
+		{stmt: "var a [3]vec3; b := a[1].xy; var c vec2 = b; _ = c", err: false},
+		{stmt: "var a [3]vec3; b := a[2].zyx; var c vec3 = b; _ = c", err: false},
+		{stmt: "var a [3]vec3; a[0].xy = vec2(1)", err: false},
+		{stmt: "var a [3]vec3; b := a[3].xy; _ = b", err: true},
+		{stmt: "var a [3]vec3; b := a[-1].xy; _ = b", err: true},
+		{stmt: "var a [3]vec3; b := a[1].w; _ = b", err: true},
+		{stmt: "var a [3]float; b := a[1].x; _ = b", err: true},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		_, err := compileToIR([]byte(src))
+		if err == nil && c.err {
+			t.Errorf("%s must return an error but does not", stmt)
+		} else if err != nil && !c.err {
+			t.Errorf("%s must not return nil but returned %v", stmt, err)
+		}
+	}
+}
+
+func TestSyntaxZeroVectorWarning(t *testing.T) {
+	cases := []struct {
+		stmt string
+		warn bool
+	}{
+		{stmt: "a := normalize(vec3(0.0)); _ = a", warn: true},
+		{stmt: "a := normalize(vec2(0, 0)); _ = a", warn: true},
+		{stmt: "a := normalize(0.0); _ = a", warn: true},
+		{stmt: "a := normalize(vec3(0, 1, 0)); _ = a", warn: false},
+		{stmt: "a := normalize(dstPos); _ = a", warn: false},
+		{stmt: "a := reflect(vec2(1), vec2(0)); _ = a", warn: true},
+		{stmt: "a := reflect(vec2(0), vec2(1)); _ = a", warn: false},
+		{stmt: "a := refract(vec2(1), vec2(0), 1.0); _ = a", warn: true},
+		{stmt: "a := refract(vec2(1), vec2(1), 0.0); _ = a", warn: false},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		_, warnings, err := compileToIRWithWarnings([]byte(src))
+		if err != nil {
+			t.Errorf("%s must not return nil but returned %v", stmt, err)
+			continue
+		}
+		if got := len(warnings) > 0; got != c.warn {
+			t.Errorf("%s: warned: got: %v, want: %v (%v)", stmt, got, c.warn, warnings)
+		}
+	}
+}
+
+func TestSyntaxBuiltinFuncRand(t *testing.T) {
+	cases := []struct {
+		stmt string
+		err  bool
+	}{
+		{stmt: "a := rand(vec2(1)); var b float = a; _ = b", err: false},
+		{stmt: "a := rand(vec3(1)); var b float = a; _ = b", err: false},
+		{stmt: "a := rand(srcPos); var b float = a; _ = b", err: false},
+		{stmt: "a := rand(); _ = a", err: true},
+		{stmt: "a := rand(1.0); _ = a", err: true},
+		{stmt: "a := rand(vec4(1)); _ = a", err: true},
+		{stmt: "a := rand(ivec2(1)); _ = a", err: true},
+		{stmt: "a := rand(vec2(1), vec2(1)); _ = a", err: true},
+		{stmt: "a := rand(vec2(1)); var b vec2 = a; _ = b", err: true},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		_, err := compileToIR([]byte(src))
+		if err == nil && c.err {
+			t.Errorf("%s must return an error but does not", stmt)
+		} else if err != nil && !c.err {
+			t.Errorf("%s must not return nil but returned %v", stmt, err)
+		}
+	}
+}
+
+func TestSyntaxSwitch(t *testing.T) {
+	cases := []struct {
+		stmt string
+		err  bool
+	}{
+		{stmt: "x := 1; switch x { case 0: x = 2; case 1: x = 3; default: x = 4 }", err: false},
+		{stmt: "x := 1; switch x { case 1, 2, 3: x = 0 }", err: false},
+		{stmt: "x := 1; switch x { default: x = 0; case 1, 2: x = 1 }", err: false},
+		{stmt: "x := 1; switch x { default: x = 0 }", err: false},
+		{stmt: "x := 1; switch x {}", err: false},
+		{stmt: "x := 1; switch y := x + 1; y { case 2: x = 0 }", err: false},
+		{stmt: "x := 1; switch x * 2 { case 2: x = 0 }", err: false},
+		{stmt: "x := 1; switch 1 { case 1: x = 0 }; _ = x", err: false},
+		{stmt: "x := 1; switch x { case 1.0: x = 0 }", err: false},
+		{stmt: "x := 1; switch x { case 1: x = 0; break }", err: false},
+		{stmt: "x := 1; switch x { case 1: for i := 0; i < 4; i++ { break } }", err: false},
+		{stmt: "x := 1; switch x { case 1: switch x { case 1: break } }", err: false},
+		{stmt: "x := 1; switch x { case 1: if x == 1 { break }; x = 0 }", err: true},
+		{stmt: "x := 1; switch x { case 1: x = 0; fallthrough; case 2: x = 1 }", err: false},
+		{stmt: "x := 1; switch x { case 1: fallthrough; default: x = 1; fallthrough; case 2: x = 2 }", err: false},
+		{stmt: "x := 1; switch x { case 1: y := 0; x = y; fallthrough; case 2: y := 1; x = y }", err: false},
+		{stmt: "x := 1; switch x { case 1: switch x { case 1: fallthrough; case 2: x = 0 }; fallthrough; case 2: x = 1 }", err: false},
+		{stmt: "x := 1; switch x { case 1: fallthrough; case 2: if y := 1; y == 1 { x = y } }", err: false},
+		{stmt: "x := 1; switch x { case 1: fallthrough; case 2: switch y := 1; y { case 1: x = y } }", err: false},
+		{stmt: "x := 1; switch x { case 1: x = 2; fallthrough; case 2: x = 3; default: x = 4 }", err: false},
+		{stmt: "x := 1; switch x { case 1: x = 0; fallthrough }", err: true},
+		{stmt: "x := 1; switch x { case 1: fallthrough; x = 0; case 2: x = 1 }", err: true},
+		{stmt: "x := 1; switch x { case 1: if x == 1 { fallthrough }; case 2: x = 1 }", err: true},
+		{stmt: "x := 1; switch x { case 1: switch x { case 1: fallthrough }; case 2: x = 1 }", err: true},
+		{stmt: "x := 1; switch x { case 1, 1: x = 0 }", err: true},
+		{stmt: "x := 1; switch x { case 1: x = 0; case 1: x = 1 }", err: true},
+		{stmt: "x := 1; y := 2; switch x { case y: x = 0 }", err: true},
+		{stmt: "x := 1; switch x { case 1.5: x = 0 }", err: true},
+		{stmt: "x := 1; switch x { case true: x = 0 }", err: true},
+		{stmt: "x := 1.0; switch x { case 1: x = 0 }", err: true},
+		{stmt: "x := vec2(1); switch x { case 1: x = vec2(0) }", err: true},
+		{stmt: "x := 1; switch { case x == 1: x = 0 }", err: true},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		_, err := compileToIR([]byte(src))
+		if err == nil && c.err {
+			t.Errorf("%s must return an error but does not", stmt)
+		} else if err != nil && !c.err {
+			t.Errorf("%s must not return nil but returned %v", stmt, err)
+		}
+	}
+
+	// The body of a clause is parsed only once even when the previous clause falls through.
+	_, warnings, err := compileToIRWithWarnings([]byte(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	x := 1
+	y := 0.0
+	switch x {
+	case 1:
+		fallthrough
+	case 2:
+		y = float(x / 2)
+	}
+	return vec4(y)
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("warnings: got: %v, want: 1 warning", warnings)
+	}
+}
+
+func TestSyntaxTypeAlias(t *testing.T) {
+	cases := []struct {
+		stmt string
+		warn bool
+	}{
+		{stmt: "var a int32 = 1; var b int = a; _ = b", warn: false},
+		{stmt: "var a float32 = 1; var b float = a; _ = b", warn: false},
+		{stmt: "var a int64 = 1; var b int = a; _ = b", warn: true},
+		{stmt: "var a float64 = 1; var b float = a; _ = b", warn: true},
+		{stmt: "var a [2]float32; var b [2]float = a; _ = b", warn: false},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		_, warnings, err := compileToIRWithWarnings([]byte(src))
+		if err != nil {
+			t.Errorf("%s must not return nil but returned %v", stmt, err)
+			continue
+		}
+		if got := len(warnings) > 0; got != c.warn {
+			t.Errorf("%s: warned: got: %v, want: %v (%v)", stmt, got, c.warn, warnings)
+		}
+	}
+
+	if _, err := compileToIR([]byte(`package main
+
+func Foo(x float32) float64 {
+	return x
+}
+`)); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := compileToIR([]byte(`package main
+
+func Foo(x uint) int {
+	return 0
+}
+`)); err == nil {
+		t.Errorf("uint must return an error but does not")
+	}
+}
+
+func TestSyntaxBuiltinFuncColorSpace(t *testing.T) {
+	cases := []struct {
+		stmt string
+		err  bool
+	}{
+		{stmt: "a := linearToSRGB(vec3(1)); var b vec3 = a; _ = b", err: false},
+		{stmt: "a := linearToSRGB(color); var b vec4 = a; _ = b", err: false},
+		{stmt: "a := srgbToLinear(color.rgb); var b vec3 = a; _ = b", err: false},
+		{stmt: "a := srgbToLinear(color * 2); var b vec4 = a; _ = b", err: false},
+		{stmt: "a := linearToSRGB(); _ = a", err: true},
+		{stmt: "a := linearToSRGB(1.0); _ = a", err: true},
+		{stmt: "a := linearToSRGB(vec2(1)); _ = a", err: true},
+		{stmt: "a := srgbToLinear(ivec3(1)); _ = a", err: true},
+		{stmt: "a := srgbToLinear(vec3(1), vec3(1)); _ = a", err: true},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		_, err := compileToIR([]byte(src))
+		if err == nil && c.err {
+			t.Errorf("%s must return an error but does not", stmt)
+		} else if err != nil && !c.err {
+			t.Errorf("%s must not return nil but returned %v", stmt, err)
+		}
+	}
+}
+
+func TestSyntaxBuiltinFuncColorSpaceRoundTrip(t *testing.T) {
+	// The last value is the alpha value and is kept as it is.
+	want := []float64{0, 0.001, 0.2, 0.5}
+	for _, f := range []string{"srgbToLinear(linearToSRGB(%s))", "linearToSRGB(srgbToLinear(%s))"} {
+		src := fmt.Sprintf(`package main
+
+func Foo() vec4 {
+	return %s
+}`, fmt.Sprintf(f, "vec4(0, 0.001, 0.2, 0.5)"))
+		p, err := compileToIR([]byte(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		stmts := p.Funcs[0].Block.Stmts
+		expr := stmts[len(stmts)-1].Exprs[0]
+		if expr.Type != shaderir.Call || len(expr.Exprs) != 5 {
+			t.Fatalf("%s: the result must be folded to a constant vector but not", f)
+		}
+		for i, e := range expr.Exprs[1:] {
+			got, _ := gconstant.Float64Val(e.Const)
+			if math.Abs(got-want[i]) > 1e-6 {
+				t.Errorf("%s: component %d: got: %f, want: %f", f, i, got, want[i])
+			}
+		}
+	}
+}
+
+func TestSyntaxScalarToVectorAssignment(t *testing.T) {
+	cases := []struct {
+		stmt string
+		hint string
+	}{
+		{stmt: "v := vec3(0); v = 1.0; _ = v", hint: "use vec3(...)"},
+		{stmt: "v := vec2(0); f := 1.0; v = f; _ = v", hint: "use vec2(...)"},
+		{stmt: "v := ivec4(0); v = 1; _ = v", hint: "use ivec4(...)"},
+		{stmt: "var v vec3 = 1.0; _ = v", hint: "use vec3(...)"},
+		{stmt: "var v vec3 = true; _ = v", hint: ""},
+		{stmt: "var v vec3 = vec2(1); _ = v", hint: ""},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		_, err := compileToIR([]byte(src))
+		if err == nil {
+			t.Errorf("%s must return an error but does not", stmt)
+			continue
+		}
+		if got := strings.Contains(err.Error(), "(...)"); got != (c.hint != "") {
+			t.Errorf("%s: the error must include a hint %q: %v", stmt, c.hint, err)
+			continue
+		}
+		if c.hint != "" && !strings.Contains(err.Error(), c.hint) {
+			t.Errorf("%s: the error must include a hint %q: %v", stmt, c.hint, err)
+		}
+	}
+}
+
+func TestSyntaxTopLevelStatement(t *testing.T) {
+	cases := []string{
+		`for i := 0; i < 3; i++ {
+}`,
+		`if true {
+}`,
+		`x := 1`,
+		`switch 1 {
+}`,
+	}
+
+	for _, c := range cases {
+		src := fmt.Sprintf(`package main
+
+%s
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return dstPos
+}`, c)
+		_, err := compileToIR([]byte(src))
+		if err == nil {
+			t.Errorf("%s must return an error but does not", c)
+			continue
+		}
+		if !strings.Contains(err.Error(), "3:1: statements must be inside a function") {
+			t.Errorf("%s: unexpected error: %v", c, err)
+		}
+	}
+}
+
+func TestSyntaxConditionalCompilation(t *testing.T) {
+	const src = `package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	c := color
+	//kage:if highQuality
+	c = c * 0.5
+	//kage:if !fast
+	c = c * 0.5
+	//kage:endif
+	//kage:else
+	c = c * 2
+	//kage:endif
+	return c
+}
+`
+	cases := []struct {
+		flags map[string]bool
+		stmts int
+	}{
+		{flags: nil, stmts: 3},
+		{flags: map[string]bool{"highQuality": true}, stmts: 4},
+		{flags: map[string]bool{"highQuality": true, "fast": true}, stmts: 3},
+	}
+	for _, c := range cases {
+		p, err := shader.CompileWithOptions([]byte(src), "Vertex", "Fragment", 0, &shader.CompileOptions{
+			Flags: c.flags,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := len(p.FragmentFunc.Block.Stmts), c.stmts; got != want {
+			t.Errorf("flags: %v: len(stmts): got: %d, want: %d", c.flags, got, want)
+		}
+	}
+
+	for _, src := range []string{
+		"//kage:if foo\npackage main\n",
+		"package main\n//kage:endif\n",
+		"package main\n//kage:else\n",
+		"//kage:if foo\n//kage:else\n//kage:else\n//kage:endif\npackage main\n",
+	} {
+		if _, err := compileToIR([]byte(src)); err == nil {
+			t.Errorf("%q must return an error but does not", src)
+		}
+	}
+
+	// The line numbers are kept.
+	_, err := shader.CompileWithOptions([]byte(`package main
+
+//kage:if foo
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return 1
+}
+//kage:endif
+`), "Vertex", "Fragment", 0, &shader.CompileOptions{
+		Flags: map[string]bool{"foo": true},
+	})
+	if err == nil || !strings.HasPrefix(err.Error(), "5:") {
+		t.Errorf("the error must be at line 5 but: %v", err)
+	}
+}
+
+func TestSyntaxBuiltinFuncConvolve3x3(t *testing.T) {
+	cases := []struct {
+		stmt string
+		err  bool
+	}{
+		{stmt: "var k [9]float; a := convolve3x3(At, srcPos, k); var b vec4 = a; _ = b", err: false},
+		{stmt: "a := convolve3x3(At, srcPos*2, [9]float{1, 1, 1, 1, 1, 1, 1, 1, 1}); _ = a", err: false},
+		{stmt: "var k [9]float; a := convolve3x3(At, srcPos); _ = a; _ = k", err: true},
+		{stmt: "var k [8]float; a := convolve3x3(At, srcPos, k); _ = a", err: true},
+		{stmt: "var k [9]int; a := convolve3x3(At, srcPos, k); _ = a", err: true},
+		{stmt: "var k [9]float; a := convolve3x3(At, 1.0, k); _ = a", err: true},
+		{stmt: "var k [9]float; a := convolve3x3(Bad, srcPos, k); _ = a", err: true},
+		{stmt: "var k [9]float; a := convolve3x3(color, srcPos, k); _ = a", err: true},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`//kage:unit pixels
+
+package main
+
+func At(pos vec2) vec4 {
+	return vec4(pos, 0, 1)
+}
+
+func Bad(pos vec2) float {
+	return pos.x
+}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		_, err := compileToIR([]byte(src))
+		if err == nil && c.err {
+			t.Errorf("%s must return an error but does not", stmt)
+		} else if err != nil && !c.err {
+			t.Errorf("%s must not return nil but returned %v", stmt, err)
+		}
+	}
+
+	// convolve3x3 is not available in the texel-unit mode.
+	if _, err := compileToIR([]byte(`package main
+
+func At(pos vec2) vec4 {
+	return vec4(pos, 0, 1)
+}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	var k [9]float
+	return convolve3x3(At, srcPos, k)
+}`)); err == nil {
+		t.Errorf("convolve3x3 in the texel-unit mode must return an error but does not")
+	}
+}
+
+func TestSyntaxBuiltinFuncPrecision(t *testing.T) {
+	cases := []struct {
+		stmt string
+		err  bool
+	}{
+		{stmt: "a := mediump(1.0); var b float = a; _ = b", err: false},
+		{stmt: "a := lowp(color); var b vec4 = a; _ = b", err: false},
+		{stmt: "a := highp(1); var b int = a; _ = b", err: false},
+		{stmt: "a := mediump(mat2(1)); var b mat2 = a; _ = b", err: false},
+		{stmt: "var a float = mediump(1.0); _ = a", err: false},
+		{stmt: "a := mediump(); _ = a", err: true},
+		{stmt: "a := mediump(1.0, 2.0); _ = a", err: true},
+		{stmt: "a := mediump(true); _ = a", err: true},
+		{stmt: "a := highp([2]float{}); _ = a", err: true},
+		{stmt: "var a float = mediump; _ = a", err: true},
+		{stmt: "var a float = vec2; _ = a", err: true},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		_, err := compileToIR([]byte(src))
+		if err == nil && c.err {
+			t.Errorf("%s must return an error but does not", stmt)
+		} else if err != nil && !c.err {
+			t.Errorf("%s must not return nil but returned %v", stmt, err)
+		}
+	}
+}
+
+func TestSyntaxBuiltinFuncDimensionMismatch(t *testing.T) {
+	cases := []struct {
+		stmt string
+		err  string
+	}{
+		{stmt: "_ = dot(vec2(1), vec3(1))", err: "4:6: dot requires matching dimensions, got vec2 and vec3"},
+		{stmt: "_ = distance(vec4(1), vec2(1))", err: "4:6: distance requires matching dimensions, got vec4 and vec2"},
+		{stmt: "_ = reflect(vec3(1), vec2(1))", err: "4:6: reflect requires matching dimensions, got vec3 and vec2"},
+		{stmt: "_ = refract(vec2(1), vec3(1), 0.5)", err: "4:6: refract requires matching dimensions, got vec2 and vec3"},
+		{stmt: "_ = faceforward(vec3(1), vec3(1), vec2(1))", err: "4:6: faceforward requires matching dimensions, got vec3 and vec2"},
+		{stmt: "_ = dot(vec3(1), vec3(1))", err: ""},
+		{stmt: "_ = reflect(vec2(1), vec2(0, 1))", err: ""},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		_, err := compileToIR([]byte(src))
+		if c.err == "" {
+			if err != nil {
+				t.Errorf("%s must not return an error but does: %v", stmt, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%s must return an error but does not", stmt)
+			continue
+		}
+		if !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%s: the error must include %q: %v", stmt, c.err, err)
+		}
+	}
+}
+
+func TestSyntaxCompoundAssignmentToElement(t *testing.T) {
+	cases := []struct {
+		stmt string
+		err  bool
+	}{
+		{stmt: "var a [3]vec4; a[1] += vec4(1)", err: false},
+		{stmt: "var a [3]vec4; i := 2; a[i] *= 2", err: false},
+		{stmt: "var a [3]float; a[0] -= 1", err: false},
+		{stmt: "var m mat3; m[1][2] *= 2", err: false},
+		{stmt: "var m mat3; m[0] += vec3(1)", err: false},
+		{stmt: "var a [3]vec4; a[3] += vec4(1)", err: true},
+		{stmt: "var a [3]vec4; a[-1] += vec4(1)", err: true},
+		{stmt: "var m mat3; m[3][0] *= 2", err: true},
+		{stmt: "var m mat3; m[0][3] *= 2", err: true},
+		{stmt: "var a [3]vec4; a[0] += vec3(1)", err: true},
+		{stmt: "var m mat3; m[0][0] += vec3(1)", err: true},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		_, err := compileToIR([]byte(src))
+		if err == nil && c.err {
+			t.Errorf("%s must return an error but does not", stmt)
+		} else if err != nil && !c.err {
+			t.Errorf("%s must not return nil but returned %v", stmt, err)
+		}
+	}
+}
+
+func TestSyntaxBuiltinFuncBilinearSample(t *testing.T) {
+	cases := []struct {
+		stmt string
+		err  bool
+	}{
+		{stmt: "a := bilinearSample(At, srcPos); var b vec4 = a; _ = b", err: false},
+		{stmt: "a := bilinearSample(At, srcPos*0.5+1); _ = a", err: false},
+		{stmt: "a := bilinearSample(At); _ = a", err: true},
+		{stmt: "a := bilinearSample(At, srcPos, srcPos); _ = a", err: true},
+		{stmt: "a := bilinearSample(At, 1.0); _ = a", err: true},
+		{stmt: "a := bilinearSample(At, vec3(1)); _ = a", err: true},
+		{stmt: "a := bilinearSample(Bad, srcPos); _ = a", err: true},
+		{stmt: "a := bilinearSample(color, srcPos); _ = a", err: true},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`//kage:unit pixels
+
+package main
+
+func At(pos vec2) vec4 {
+	return vec4(pos, 0, 1)
+}
+
+func Bad(pos vec2) float {
+	return pos.x
+}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		_, err := compileToIR([]byte(src))
+		if err == nil && c.err {
+			t.Errorf("%s must return an error but does not", stmt)
+		} else if err != nil && !c.err {
+			t.Errorf("%s must not return nil but returned %v", stmt, err)
+		}
+	}
+
+	// bilinearSample is not available in the texel-unit mode.
+	if _, err := compileToIR([]byte(`package main
+
+func At(pos vec2) vec4 {
+	return vec4(pos, 0, 1)
+}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return bilinearSample(At, srcPos)
+}`)); err == nil {
+		t.Errorf("bilinearSample in the texel-unit mode must return an error but does not")
+	}
+}
+
+func TestSyntaxConstantGroup(t *testing.T) {
+	cases := []struct {
+		stmt string
+		err  bool
+	}{
+		{stmt: "const (A = 0; B = 1); x := 1; switch x { case A: x = 2; case B: x = 3 }", err: false},
+		{stmt: "const (A = iota; B; C); var x [C]float; _ = x", err: false},
+		{stmt: "const (A = iota * 2; B; C); var x [C]float; _ = x; _ = A; _ = B", err: false},
+		{stmt: "const (A float = iota; B); var x float = B; _ = x; _ = A", err: false},
+		{stmt: "const (A, B = iota, iota + 10; C, D); var x [D]float; _ = x; _ = A; _ = B; _ = C", err: false},
+		{stmt: "const (A = 0; B = 0); x := 1; switch x { case A: x = 2; case B: x = 3 }", err: true},
+		{stmt: "const (A, B = 0); _ = A", err: true},
+		{stmt: "const (A = 0, 1); _ = A", err: true},
+		{stmt: "x := iota; _ = x", err: true},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		_, err := compileToIR([]byte(src))
+		if err == nil && c.err {
+			t.Errorf("%s must return an error but does not", stmt)
+		} else if err != nil && !c.err {
+			t.Errorf("%s must not return nil but returned %v", stmt, err)
+		}
+	}
+}
+
+func TestSyntaxIota(t *testing.T) {
+	cases := []struct {
+		stmt string
+		expr string
+		want int64
+	}{
+		{stmt: "const (A = iota; B; C)", expr: "C", want: 2},
+		{stmt: "const (_ = iota; A; B)", expr: "B", want: 2},
+		{stmt: "const (A = 1 << iota; B; C; D)", expr: "D", want: 8},
+		{stmt: "const (A = iota * 10; B)", expr: "B", want: 10},
+		{stmt: "const (A = 3; B = iota; C)", expr: "C", want: 2},
+		{stmt: "", expr: "1 << 4", want: 16},
+		{stmt: "", expr: "256 >> 2", want: 64},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Foo() int {
+	%s
+	return %s
+}`, stmt, c.expr)
+		p, err := compileToIR([]byte(src))
+		if err != nil {
+			t.Errorf("%s; %s must not return nil but returned %v", stmt, c.expr, err)
+			continue
+		}
+		got := p.Funcs[0].Block.Stmts[0].Exprs[0]
+		if got.Const == nil {
+			t.Errorf("%s; %s: the result must be a constant but not", stmt, c.expr)
+			continue
+		}
+		if v, _ := gconstant.Int64Val(got.Const); v != c.want {
+			t.Errorf("%s; %s: got: %d, want: %d", stmt, c.expr, v, c.want)
+		}
+	}
+
+	for _, stmt := range []string{
+		"x := 1.5 << 1; _ = x",
+		"x := 1 << -1; _ = x",
+	} {
+		src := fmt.Sprintf(`package main
+
+func Foo() {
+	%s
+}`, stmt)
+		if _, err := compileToIR([]byte(src)); err == nil {
+			t.Errorf("%s must return an error but does not", stmt)
+		}
+	}
+}
+
+func TestSyntaxAndNot(t *testing.T) {
+	cases := []struct {
+		stmt string
+		err  bool
+	}{
+		{stmt: "a := 7; b := a &^ 2; _ = b", err: false},
+		{stmt: "a := 7; b := 1; a &^= b", err: false},
+		{stmt: "a := 7; b := 1; a &= b; a |= b; a ^= b", err: false},
+		{stmt: "a := ivec2(7); a &^= ivec2(1, 2)", err: false},
+		{stmt: "a := ivec2(7); a &^= 1", err: false},
+		{stmt: "const a = 7 &^ 2; var b [a]float; _ = b", err: false},
+		{stmt: "a := 7.0; b := a &^ 2; _ = b", err: true},
+		{stmt: "a := 7.0; a &^= 2", err: true},
+		{stmt: "a := vec2(7); a &^= vec2(1)", err: true},
+		{stmt: "a := 1.0; b := 2.0; a &= b", err: true},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		_, err := compileToIR([]byte(src))
+		if err == nil && c.err {
+			t.Errorf("%s must return an error but does not", stmt)
+		} else if err != nil && !c.err {
+			t.Errorf("%s must not return nil but returned %v", stmt, err)
+		}
+	}
+}
+
+func TestSyntaxEmptyLoopWarning(t *testing.T) {
+	cases := []struct {
+		stmt string
+		warn bool
+	}{
+		{stmt: "for i := 0; i < 4; i++ {}", warn: true},
+		{stmt: "for i := 0; i < 4; i++ { continue }", warn: true},
+		{stmt: "x := 0; for i := 0; i < 4; i++ { continue; x += i }; _ = x", warn: true},
+		{stmt: "x := 0; for i := 0; i < 4; i++ { x += i }; _ = x", warn: false},
+		{stmt: "x := 0; for i := 0; i < 4; i++ { x += i; continue }; _ = x", warn: false},
+		{stmt: "x := 0; for i := 0; i < 4; i++ { if i == 2 { continue }; x += i }; _ = x", warn: false},
+		// The bodies become empty after the unreachable statements are dropped.
+		{stmt: "x := 0; for i := 0; i < 4; i++ { { continue }; x += i }; _ = x", warn: true},
+		{stmt: "x := 0; for i := 0; i < 4; i++ { if i == 2 { continue } else { continue }; x += i }; _ = x", warn: true},
+		{stmt: "x := 0; for x < 4 { if x == 2 { continue } else { continue }; x++ }", warn: true},
+		{stmt: "x := 0; for i := 0; i < 4; i++ { if i == 2 { x = 1; continue } else { continue }; x += i }; _ = x", warn: false},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		_, warnings, err := compileToIRWithWarnings([]byte(src))
+		if err != nil {
+			t.Errorf("%s must not return nil but returned %v", stmt, err)
+			continue
+		}
+		var loopWarning string
+		for _, w := range warnings {
+			if strings.HasSuffix(w, "the loop body is empty or has only unreachable statements") {
+				loopWarning = w
+			}
+		}
+		if got := loopWarning != ""; got != c.warn {
+			t.Errorf("%s: warned: got: %v, want: %v (%v)", stmt, got, c.warn, warnings)
+		}
+		if c.warn && !strings.HasPrefix(loopWarning, "4:") {
+			t.Errorf("%s: the warning must point at the loop: %v", stmt, warnings)
+		}
+	}
+}
+
+func TestSyntaxUnreadLocalVariableWarning(t *testing.T) {
+	cases := []struct {
+		stmt string
+		warn string
+	}{
+		{stmt: "tmp := dstPos; tmp.x = 1", warn: "4:2: the value of local variable tmp is never read"},
+		{stmt: "var tmp [2]float; tmp[0] = 1; _ = tmp[1]", warn: ""},
+		{stmt: "tmp := vec2(1); { tmp := tmp; tmp.x = 2 }", warn: "4:20: the value of local variable tmp is never read"},
+		{stmt: "c := color; tmp := vec2(1); if dstPos.x > 0 { tmp.x = 2 }; c.xy = tmp; _ = c", warn: ""},
+		{stmt: "tmp := 1.0; _ = tmp", warn: ""},
+		{stmt: "i := 0; var tmp mat2; tmp[i].x = 1", warn: "4:10: the value of local variable tmp is never read"},
+		{stmt: "for i := 0; i < 4; i++ { tmp := dstPos; tmp.y = 0 }", warn: "4:27: the value of local variable tmp is never read"},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		_, warnings, err := compileToIRWithWarnings([]byte(src))
+		if err != nil {
+			t.Errorf("%s must not return nil but returned %v", stmt, err)
+			continue
+		}
+		if c.warn == "" {
+			if len(warnings) > 0 {
+				t.Errorf("%s: got: %v, want: no warnings", stmt, warnings)
+			}
+			continue
+		}
+		if len(warnings) != 1 || warnings[0] != c.warn {
+			t.Errorf("%s: got: %v, want: %q", stmt, warnings, c.warn)
+		}
+	}
+}
+
+func TestSyntaxUninitializedLocalVariableWarning(t *testing.T) {
+	cases := []struct {
+		stmt string
+		warn string
+	}{
+		{stmt: "var x float; if dstPos.x > 0 { x = 1 }; dstPos.x += x", warn: "4:2: local variable x is assigned only on some paths before it is read"},
+		{stmt: "var x float; if dstPos.x > 0 { x = 1 } else { x = 2 }; dstPos.x += x", warn: ""},
+		{stmt: "var x float; if dstPos.x > 0 { x = 1 } else { discard() }; dstPos.x += x", warn: ""},
+		{stmt: "var x float; x += 1; dstPos.x += x", warn: ""},
+		{stmt: "var x float; dstPos.x += x; if dstPos.x > 0 { x = 1 }", warn: ""},
+		{stmt: "var x vec2; x.x = 1; dstPos.xy += x", warn: ""},
+		{stmt: "x := 1.0; dstPos.x += x", warn: ""},
+		{stmt: "var x float; for i := 0; i < 100; i++ { x = float(i) }; dstPos.x += x", warn: ""},
+		{stmt: "var x float; for i := 0; i < 4; i++ { x += 1.0 }; dstPos.x += x", warn: ""},
+		{stmt: "var x float; for i := 0; i < 100; i++ { dstPos.x += x; x = float(i) }", warn: ""},
+		{stmt: "var x float; for i := 0; i < 100; i++ { if i == 1 { x = 1 }; dstPos.x += x }", warn: "4:2: local variable x is assigned only on some paths before it is read"},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		_, warnings, err := compileToIRWithWarnings([]byte(src))
+		if err != nil {
+			t.Errorf("%s must not return nil but returned %v", stmt, err)
+			continue
+		}
+		if c.warn == "" {
+			if len(warnings) > 0 {
+				t.Errorf("%s: got: %v, want: no warnings", stmt, warnings)
+			}
+			continue
+		}
+		if len(warnings) != 1 || warnings[0] != c.warn {
+			t.Errorf("%s: got: %v, want: %q", stmt, warnings, c.warn)
+		}
+	}
+}
+
+func TestSyntaxUnusedUniformWarning(t *testing.T) {
+	src := []byte(`package main
+
+var Used vec4
+var Unused float
+var UsedInFunc vec2
+var Kept [4]float
+var __Internal float
+
+func Foo() vec2 {
+	return UsedInFunc
+}
+
+func Bar() float {
+	return Unused
+}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return Used + vec4(Foo(), 0, 0)
+}
+`)
+
+	var warnings []string
+	if _, err := shader.CompileWithOptions(src, "Vertex", "Fragment", 0, &shader.CompileOptions{
+		Warn: func(msg string) {
+			warnings = append(warnings, msg)
+		},
+		AllowedUnusedUniforms: []string{"Kept"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Unused is used only in a function that is not reachable from the entry points.
+	want := []string{"4:5: uniform variable Unused is not used"}
+	if len(warnings) != len(want) || warnings[0] != want[0] {
+		t.Errorf("got: %v, want: %v", warnings, want)
+	}
+}
+
+func TestSyntaxUnusedAttributeWarning(t *testing.T) {
+	src := []byte(`//kage:attribute normal vec3
+//kage:attribute weight float
+
+package main
+
+func Vertex(pos vec2, _ vec2, color vec4) vec4 {
+	return vec4(pos, normal.z, 1)
+}
+`)
+
+	var warnings []string
+	if _, err := shader.CompileWithOptions(src, "Vertex", "Fragment", 0, &shader.CompileOptions{
+		Warn: func(msg string) {
+			warnings = append(warnings, msg)
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A parameter named _ is not reported.
+	want := []string{
+		"6:31: attribute color is not used",
+		"6:1: attribute weight is not used",
+	}
+	if len(warnings) != len(want) || warnings[0] != want[0] || warnings[1] != want[1] {
+		t.Errorf("got: %v, want: %v", warnings, want)
+	}
+}
+
+func TestSyntaxVertexAttributeTypes(t *testing.T) {
+	cases := []struct {
+		params string
+		err    bool
+	}{
+		{params: "pos vec2", err: false},
+		{params: "pos vec2, srcPos vec2, color vec4", err: false},
+		{params: "x float, pos vec3", err: false},
+		{params: "pos ivec2", err: true},
+		{params: "pos [2]float", err: true},
+		{params: "pos mat2", err: true},
+		{params: "pos vec2, b bool", err: true},
+	}
+
+	for _, c := range cases {
+		src := fmt.Sprintf(`package main
+
+func Vertex(%s) vec4 {
+	return vec4(0)
+}`, c.params)
+		_, err := compileToIR([]byte(src))
+		if err == nil && c.err {
+			t.Errorf("%s must return an error but does not", c.params)
+		} else if err != nil && !c.err {
+			t.Errorf("%s must not return nil but returned %v", c.params, err)
+		}
+	}
+}
+
+func TestSyntaxBuiltinFuncAAStep(t *testing.T) {
+	cases := []struct {
+		stmt string
+		err  bool
+	}{
+		{stmt: "a := aastep(0.5, srcPos.x); var b float = a; _ = b", err: false},
+		{stmt: "a := aastep(0.5, srcPos); var b vec2 = a; _ = b", err: false},
+		{stmt: "a := aastep(vec2(0.5), srcPos); var b vec2 = a; _ = b", err: false},
+		{stmt: "a := aastep(1, 2); var b float = a; _ = b", err: false},
+		{stmt: "a := aastep(0.5); _ = a", err: true},
+		{stmt: "a := aastep(0.5, srcPos, srcPos); _ = a", err: true},
+		{stmt: "a := aastep(vec3(0.5), srcPos); _ = a", err: true},
+		{stmt: "a := aastep(srcPos, 0.5); _ = a", err: true},
+		{stmt: "a := aastep(0.5, ivec2(1)); _ = a", err: true},
+		{stmt: "a := aastep(0.5, true); _ = a", err: true},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		_, err := compileToIR([]byte(src))
+		if err == nil && c.err {
+			t.Errorf("%s must return an error but does not", stmt)
+		} else if err != nil && !c.err {
+			t.Errorf("%s must not return nil but returned %v", stmt, err)
+		}
+	}
+}
+
+func TestSyntaxInvalidOperands(t *testing.T) {
+	cases := []struct {
+		stmt string
+		err  bool
+	}{
+		{stmt: "a := 1 / 2; _ = a", err: false},
+		{stmt: "a := 1 / 0; _ = a", err: true},
+		{stmt: "a := 1.0 / 0; _ = a", err: true},
+		{stmt: "a := 1 % 0; _ = a", err: true},
+		{stmt: "a := 1 * mod; _ = a", err: true},
+		{stmt: "a := mod * 1; _ = a", err: true},
+		{stmt: "a := -mod; _ = a", err: true},
+		{stmt: "a := &srcPos; _ = a", err: true},
+		{stmt: "a := mod; _ = a", err: true},
+		{stmt: "var a = mod; _ = a", err: true},
+		{stmt: "var a, b float = srcPos.x; _, _ = a, b", err: true},
+		{stmt: "switch mod {}", err: true},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		_, err := compileToIR([]byte(src))
+		if err == nil && c.err {
+			t.Errorf("%s must return an error but does not", stmt)
+		} else if err != nil && !c.err {
+			t.Errorf("%s must not return nil but returned %v", stmt, err)
+		}
+	}
+}
+
+func TestSyntaxLabeledBranch(t *testing.T) {
+	cases := []struct {
+		stmt string
+		err  bool
+	}{
+		{stmt: "L: for i := 0; i < 4; i++ { break L }", err: false},
+		{stmt: "L: for i := 0; i < 4; i++ { continue L }", err: false},
+		{stmt: "L: for i := 0; i < 4; i++ { for j := 0; j < 4; j++ { break L } }", err: false},
+		{stmt: "L: for i := 0; i < 4; i++ { for j := 0; j < 4; j++ { continue L } }", err: false},
+		{stmt: "L: for i := 0; i < 4; i++ { M: for j := 0; j < 4; j++ { for k := 0; k < 4; k++ { if k == 0 { continue M }; break L } } }", err: false},
+		{stmt: "L: for i := 0; i < 4; i++ { switch i { case 0: break L; case 1: continue L } }", err: false},
+		{stmt: "L: for i := 0; i < 4; i++ { }", err: true},
+		{stmt: "L: for i := 0; i < 4; i++ { L: for j := 0; j < 4; j++ { break L } }", err: true},
+		{stmt: "L: for i := 0; i < 4; i++ { break L }; for j := 0; j < 4; j++ { break L }", err: true},
+		{stmt: "for i := 0; i < 4; i++ { break L }", err: true},
+		{stmt: "x := 0; L: switch x { case 0: break L }", err: true},
+		{stmt: "x := 0; L: x = 1; _ = x", err: true},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		_, err := compileToIR([]byte(src))
+		if err == nil && c.err {
+			t.Errorf("%s must return an error but does not", stmt)
+		} else if err != nil && !c.err {
+			t.Errorf("%s must not return nil but returned %v", stmt, err)
+		}
+	}
+}
+
+func TestSyntaxForWithoutConstantBounds(t *testing.T) {
+	cases := []struct {
+		stmt string
+		err  bool
+	}{
+		{stmt: "i := 0; for i < 4 { i++ }", err: false},
+		{stmt: "i := 0; for ; i < 4; i++ { }", err: false},
+		{stmt: "for i := 0; i < 4; { i++ }", err: false},
+		{stmt: "for i := 0; i < int(srcPos.x); i++ { }", err: false},
+		{stmt: "for i := 0.0; i < srcPos.x; i += srcPos.y { }", err: false},
+		{stmt: "for i := 1; i < 64; i *= 2 { }", err: false},
+		{stmt: "for { break }", err: false},
+		{stmt: "for { if srcPos.x > 0 { break } }", err: false},
+		{stmt: "for { return dstPos }", err: false},
+		{stmt: "for { for { return dstPos } }", err: false},
+		{stmt: "for { }", err: true},
+		{stmt: "x := 0; for { x++ }; _ = x", err: true},
+		{stmt: "for true { }", err: true},
+		{stmt: "for { for { break } }", err: true},
+		{stmt: "for { for i := 0; i < 4; i++ { break } }", err: true},
+		{stmt: "L: for i := 0; i < int(srcPos.x); i++ { for j := 0; j < 4; j++ { continue L } }", err: false},
+		{stmt: "for i := 0; i; i++ { }", err: true},
+		{stmt: "for i := 0; i < x; i++ { }", err: true},
+		{stmt: "for i := 0; i < int(srcPos.x); i++ { x := 1 }", err: true},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		_, err := compileToIR([]byte(src))
+		if err == nil && c.err {
+			t.Errorf("%s must return an error but does not", stmt)
+		} else if err != nil && !c.err {
+			t.Errorf("%s must not return nil but returned %v", stmt, err)
+		}
+	}
+}
+
+func TestSyntaxBuiltinFuncMixBool(t *testing.T) {
+	cases := []struct {
+		stmt string
+		err  bool
+	}{
+		{stmt: "a := mix(1.0, 2.0, srcPos.x > 0); var b float = a; _ = b", err: false},
+		{stmt: "a := mix(1, 2, srcPos.x > 0); var b int = a; _ = b", err: false},
+		{stmt: "a := mix(1, 2.0, srcPos.x > 0); var b float = a; _ = b", err: false},
+		{stmt: "a := mix(srcPos, vec2(0), srcPos.x > 0); var b vec2 = a; _ = b", err: false},
+		{stmt: "a := mix(ivec3(1), ivec3(2), srcPos.x > 0); var b ivec3 = a; _ = b", err: false},
+		{stmt: "a := mix(srcPos.x, 1, srcPos.x > 0); var b float = a; _ = b", err: false},
+		{stmt: "a := mix(true, false, srcPos.x > 0); var b bool = a; _ = b", err: false},
+		{stmt: "a := mix(mat2(1), mat2(2), srcPos.x > 0); var b mat2 = a; _ = b", err: false},
+		{stmt: "a := mix(srcPos, vec2(0), true); var b vec2 = a; _ = b", err: false},
+		{stmt: "a := mix(srcPos, vec3(0), srcPos.x > 0); _ = a", err: true},
+		{stmt: "a := mix(srcPos, 1.0, srcPos.x > 0); _ = a", err: true},
+		{stmt: "a := mix(1, true, srcPos.x > 0); _ = a", err: true},
+		{stmt: "a := mix(srcPos.x, 1.5, 1 > 0, 1); _ = a", err: true},
+		{stmt: "a := mix(int(srcPos.x), 1.5, srcPos.x > 0); _ = a", err: true},
+		{stmt: "a := mix(vec2, 1.0, true); _ = a", err: true},
+		{stmt: "a := mix(0.0, sin, true); _ = a", err: true},
+		{stmt: "a := mix(vec2, vec2, srcPos.x > 0); _ = a", err: true},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		_, err := compileToIR([]byte(src))
+		if err == nil && c.err {
+			t.Errorf("%s must return an error but does not", stmt)
+		} else if err != nil && !c.err {
+			t.Errorf("%s must not return nil but returned %v", stmt, err)
+		}
+	}
+}
+
+func TestSyntaxArrayIndex(t *testing.T) {
+	cases := []struct {
+		stmt string
+		err  bool
+	}{
+		{stmt: "var a [4]vec4; b := a[3]; _ = b", err: false},
+		{stmt: "var a [4]vec4; i := int(srcPos.x); b := a[i]; _ = b", err: false},
+		{stmt: "const n = 4; var a [n]vec4; a[n-1] = vec4(1); _ = a", err: false},
+		{stmt: "var a [4]vec4; b := a[4]; _ = b", err: true},
+		{stmt: "var a [4]vec4; b := a[-1]; _ = b", err: true},
+		{stmt: "var a [4]vec4; a[4] = vec4(0)", err: true},
+		{stmt: "var a [4]vec4; b := a[1.5]; _ = b", err: true},
+		{stmt: "var a [0]vec4; _ = a", err: true},
+		{stmt: "var a [-1]vec4; _ = a", err: true},
+		{stmt: "var a [2.0]vec4; _ = a", err: false},
+		{stmt: "const n = 2.0; var a [n]vec4; _ = a", err: false},
+		{stmt: "var a [2.5]vec4; _ = a", err: true},
+		{stmt: "var a [true]vec4; _ = a", err: true},
+		{stmt: "var a [4]vec4; _ = a[vec2]", err: true},
+		{stmt: "var a [4]vec4; _ = a[sin]", err: true},
+		{stmt: "v := vec2(0); _ = v[mediump]", err: true},
+		{stmt: "_ = vec2[0]", err: true},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		_, err := compileToIR([]byte(src))
+		if err == nil && c.err {
+			t.Errorf("%s must return an error but does not", stmt)
+		} else if err != nil && !c.err {
+			t.Errorf("%s must not return nil but returned %v", stmt, err)
+		}
+	}
+}
+
+func TestSyntaxMatrixIndex(t *testing.T) {
+	cases := []struct {
+		stmt string
+		err  bool
+	}{
+		{stmt: "m := mat3(1); var v vec3 = m[1]; _ = v", err: false},
+		{stmt: "m := mat3(1); var f float = m[1].y; _ = f", err: false},
+		{stmt: "m := mat4(1); var f float = m[3][3]; _ = f", err: false},
+		{stmt: "m := mat2(1); i := int(srcPos.x); var v vec2 = m[i]; _ = v", err: false},
+		{stmt: "m := mat2(1); m[1] = vec2(0); _ = m", err: false},
+		{stmt: "m := mat3(1); var v vec2 = m[1]; _ = v", err: true},
+		{stmt: "m := mat3(1); var v vec4 = m[1]; _ = v", err: true},
+		{stmt: "m := mat3(1); _ = m[3]", err: true},
+		{stmt: "m := mat3(1); _ = m[-1]", err: true},
+		{stmt: "m := mat2(1); _ = m[1][2]", err: true},
+		{stmt: "m := mat3(1); _ = m[1.5]", err: true},
+		{stmt: "m := mat3(1); _ = m[srcPos.x]", err: true},
+		{stmt: "m := mat3(1); _ = m[float(1)]", err: true},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		_, err := compileToIR([]byte(src))
+		if err == nil && c.err {
+			t.Errorf("%s must return an error but does not", stmt)
+		} else if err != nil && !c.err {
+			t.Errorf("%s must not return nil but returned %v", stmt, err)
+		}
+	}
+}
+
+func TestSyntaxArrayLiteral(t *testing.T) {
+	cases := []struct {
+		stmt string
+		err  bool
+	}{
+		{stmt: "a := [3]float{1, 2, 3}; b := a[2]; _ = b", err: false},
+		{stmt: "a := [3]float{1, 2}; b := a[2]; _ = b", err: false},
+		{stmt: "a := [...]float{1, 2, 3}; b := a[2]; _ = b", err: false},
+		{stmt: "a := [2]vec4{color, vec4(1)}; b := a[1]; _ = b", err: false},
+		{stmt: "a := [2]int{1, int(srcPos.x)}; b := a[1]; _ = b", err: false},
+		{stmt: "a := [...]float{1, 2, 3}; b := a[3]; _ = b", err: true},
+		{stmt: "a := [2]float{1, 2, 3}; _ = a", err: true},
+		{stmt: "a := [...]float{}; _ = a", err: true},
+		{stmt: "a := [2]float{srcPos, 1}; _ = a", err: true},
+		{stmt: "a := [2]vec4{color, srcPos}; _ = a", err: true},
+		{stmt: "a := [2]int{1, srcPos.x}; _ = a", err: true},
+		{stmt: "a := [2]bool{true, 1}; _ = a", err: true},
+		{stmt: "a := [2]float{0: 1, 1: 2}; _ = a", err: true},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		_, err := compileToIR([]byte(src))
+		if err == nil && c.err {
+			t.Errorf("%s must return an error but does not", stmt)
+		} else if err != nil && !c.err {
+			t.Errorf("%s must not return nil but returned %v", stmt, err)
+		}
+	}
+}
+
+func TestSyntaxConstInitializer(t *testing.T) {
+	cases := []struct {
+		stmt string
+		err  bool
+	}{
+		{stmt: "const pi = 3.14159; a := pi; _ = a", err: false},
+		{stmt: "const size = 8; const two = size * 2; var a [two]float; _ = a", err: false},
+		{stmt: "const size = 8; const half float = size / 2.0; var a float = half; _ = a", err: false},
+		{stmt: "const x = srcPos.x; _ = x", err: true},
+		{stmt: "x := 1; const y = x; _ = y", err: true},
+		{stmt: "const x = vec2(1); _ = x", err: true},
+		{stmt: "const x = length(srcPos); _ = x", err: true},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		_, err := compileToIR([]byte(src))
+		if err == nil && c.err {
+			t.Errorf("%s must return an error but does not", stmt)
+		} else if err != nil && !c.err {
+			t.Errorf("%s must not return nil but returned %v", stmt, err)
+		}
+	}
+
+	// The constants are substituted at the use sites, and no local variables are allocated.
+	p, err := compileToIR([]byte(`package main
+
+func Foo() float {
+	const size = 8
+	const two = size * 2
+	return two
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(p.Funcs[0].Block.LocalVars); got != 0 {
+		t.Errorf("len(LocalVars): got: %d, want: 0", got)
+	}
+}
+
+func TestSyntaxSwizzlingAssignment(t *testing.T) {
+	cases := []struct {
+		stmt string
+		err  bool
+	}{
+		{stmt: "v := vec4(0); v.x = 1; _ = v", err: false},
+		{stmt: "v := vec4(0); v.xy = vec2(1); _ = v", err: false},
+		{stmt: "v := vec4(0); v.rgb = vec3(1); _ = v", err: false},
+		{stmt: "v := vec4(0); v.wzyx = vec4(1, 2, 3, 4); _ = v", err: false},
+		{stmt: "v := vec4(0); v.xy += vec2(1); _ = v", err: false},
+		{stmt: "v := vec4(0); v.xy *= 2; _ = v", err: false},
+		{stmt: "v := vec4(0); v.x++; _ = v", err: false},
+		{stmt: "v := vec4(0); v.rgb -= v.gba; _ = v", err: false},
+		{stmt: "v := vec4(0); v.xyz *= mat3(1); _ = v", err: false},
+		{stmt: "v := [2]vec4{}; i := 0; v[i+1].zw /= vec2(2); _ = v", err: false},
+		{stmt: "v := vec4(0); v.rgb += vec2(1); _ = v", err: true},
+		{stmt: "v := ivec4(0); v.xy += vec2(1); _ = v", err: true},
+		{stmt: "v := [2]vec4{}; v[0].zw = vec2(1); _ = v", err: false},
+		{stmt: "v := vec4(0); v.xx = vec2(1); _ = v", err: true},
+		{stmt: "v := vec4(0); v.xyx = vec3(1); _ = v", err: true},
+		{stmt: "v := vec4(0); v.rr += vec2(1); _ = v", err: true},
+		{stmt: "v := vec4(0); v.xy.xx = vec2(1); _ = v", err: true},
+		{stmt: "v := vec4(0); v.xy = vec3(1); _ = v", err: true},
+		{stmt: "v := vec4(0); v.xyz = vec2(1); _ = v", err: true},
+		{stmt: "v := vec4(0); v.xy = 1.0; _ = v", err: true},
+		{stmt: "v := vec2(0); v.z = 1; _ = v", err: true},
+		{stmt: "v := ivec4(0); v.xy = vec2(1); _ = v", err: true},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		_, err := compileToIR([]byte(src))
+		if err == nil && c.err {
+			t.Errorf("%s must return an error but does not", stmt)
+		} else if err != nil && !c.err {
+			t.Errorf("%s must not return nil but returned %v", stmt, err)
+		}
+	}
+}
+
+func TestSyntaxLogicalOperatorOperands(t *testing.T) {
+	cases := []struct {
+		stmt string
+		err  string
+	}{
+		{stmt: "x := srcPos.x; if x > 0 && x < 1 { x = 2 }; _ = x", err: ""},
+		{stmt: "x := srcPos.x; if x < 0 || x > 1 { x = 2 }; _ = x", err: ""},
+		{stmt: "x := srcPos.x; if !(x > 0 && x < 1) || x == 2 { x = 2 }; _ = x", err: ""},
+		{stmt: "const c = true; x := srcPos.x; if x > 0 && c { x = 2 }; _ = x", err: ""},
+		{stmt: "x := srcPos.x; if x && x < 1 { x = 2 }; _ = x", err: "4:20: invalid operation: operator && not defined on float"},
+		{stmt: "x := srcPos.x; if x > 0 || 1 { x = 2 }; _ = x", err: "4:20: invalid operation: operator || not defined on untyped int constant"},
+		{stmt: "x := ivec2(1); if x.x > 0 && x { }", err: "4:20: invalid operation: operator && not defined on ivec2"},
+		{stmt: "_ = vec2(1) && true", err: "4:6: invalid operation: operator && not defined on vec2"},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		_, err := compileToIR([]byte(src))
+		if c.err == "" {
+			if err != nil {
+				t.Errorf("%s must not return an error but does: %v", stmt, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%s must return an error but does not", stmt)
+			continue
+		}
+		if !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%s: the error must include %q: %v", stmt, c.err, err)
+		}
+	}
+}
+
+func TestSyntaxBoolVector(t *testing.T) {
+	cases := []struct {
+		stmt string
+		err  bool
+	}{
+		{stmt: "v := vec3(0); var b bool = all(lessThan(v, vec3(1.0))); _ = b", err: false},
+		{stmt: "v := vec2(0); var b bool = any(greaterThan(v, vec2(1.0))); _ = b", err: false},
+		{stmt: "v := ivec4(0); m := lessThanEqual(v, ivec4(1)); _ = all(not(m))", err: false},
+		{stmt: "v := vec4(0); m := greaterThanEqual(v, vec4(1)); _ = m.x || m[3]", err: false},
+		{stmt: "v := vec3(0); m := equal(v, vec3(1)); _ = all(notEqual(m, m.zyx))", err: false},
+		{stmt: "v := vec3(0); m := equal(v, vec3(1)); _ = m == m", err: false},
+		{stmt: "v := vec3(0); m := equal(v, vec3(1)); m.xy = m.yx; _ = m", err: false},
+		{stmt: "v := vec3(0); m := lessThan(v, vec3(1)); m = not(m); _ = m", err: false},
+		{stmt: "v := vec3(0); var b bvec3 = lessThan(v, vec3(1)); _ = b", err: false},
+		{stmt: "m := equal(vec2(0), vec2(1)); _ = !m", err: false},
+		{stmt: "m := equal(vec2(0), vec2(1)); m = !!m; _ = m", err: false},
+		{stmt: "_ = all(!bvec2(true, false))", err: false},
+		{stmt: "_ = bvec3(true)", err: false},
+		{stmt: "_ = bvec4(lessThan(vec4(0), vec4(1)))", err: false},
+		{stmt: "var b bvec2 = bvec2(srcPos.x > 0, srcPos.y > 0); _ = b", err: false},
+
+		{stmt: "v := vec3(0); var b bool = lessThan(v, vec3(1)); _ = b", err: true},
+		{stmt: "_ = lessThan(vec2(0), vec3(1))", err: true},
+		{stmt: "_ = lessThan(vec2(0), ivec2(1))", err: true},
+		{stmt: "_ = lessThan(1.0, 2.0)", err: true},
+		{stmt: "_ = lessThan(vec2(0))", err: true},
+		{stmt: "_ = greaterThan(mat2(0), mat2(1))", err: true},
+		{stmt: "m := equal(vec2(0), vec2(1)); _ = lessThan(m, m)", err: true},
+		{stmt: "_ = all(vec2(1))", err: true},
+		{stmt: "_ = any(true)", err: true},
+		{stmt: "_ = not(false)", err: true},
+		{stmt: "m := equal(vec2(0), vec2(1)); _ = all(m, m)", err: true},
+		{stmt: "m := equal(vec2(0), vec2(1)); _ = m + m", err: true},
+		{stmt: "m := equal(vec2(0), vec2(1)); _ = m && m", err: true},
+		{stmt: "m := equal(vec2(0), vec2(1)); var b bvec3 = m; _ = b", err: true},
+		{stmt: "_ = bvec2(1, 0)", err: true},
+		{stmt: "_ = bvec2(vec2(1))", err: true},
+		{stmt: "_ = bvec3(true, false)", err: true},
+		{stmt: "_ = bvec3(bvec2(true), false)", err: true},
+		{stmt: "_ = bvec2(bvec3(true))", err: true},
+		{stmt: "_ = !vec2(1)", err: true},
+		{stmt: "m := equal(vec2(0), vec2(1)); _ = m < m", err: true},
+		{stmt: "m := equal(vec2(0), vec2(1)); _ = m == true", err: true},
+		{stmt: "m := equal(vec2(0), vec2(1)); if m { }", err: true},
+		{stmt: "m := equal(vec2(0), vec2(1)); _ = m.z", err: true},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		_, err := compileToIR([]byte(src))
+		if err == nil && c.err {
+			t.Errorf("%s must return an error but does not", stmt)
+		} else if err != nil && !c.err {
+			t.Errorf("%s must not return nil but returned %v", stmt, err)
+		}
+	}
+}
+
+func TestSyntaxConstantFolding(t *testing.T) {
+	cases := []struct {
+		typ  string
+		expr string
+		kind gconstant.Kind
+		want string
+	}{
+		{typ: "float", expr: "2.0 * 3.0", kind: gconstant.Float, want: "6"},
+		{typ: "float", expr: "1.0 / 4.0", kind: gconstant.Float, want: "0.25"},
+		{typ: "float", expr: "7.0 - 0.5 + 1.5", kind: gconstant.Float, want: "8"},
+		{typ: "float", expr: "float(3) * 0.5", kind: gconstant.Float, want: "1.5"},
+		{typ: "int", expr: "2 * 3", kind: gconstant.Int, want: "6"},
+		{typ: "int", expr: "7 / 2", kind: gconstant.Int, want: "3"},
+		{typ: "int", expr: "7 % 4", kind: gconstant.Int, want: "3"},
+		{typ: "int", expr: "(1 + 2) * (3 + 4)", kind: gconstant.Int, want: "21"},
+		{typ: "int", expr: "int(7) / 2", kind: gconstant.Int, want: "3"},
+		{typ: "bool", expr: "2 * 3 == 6", kind: gconstant.Bool, want: "true"},
+	}
+
+	for _, c := range cases {
+		src := fmt.Sprintf(`package main
+
+func Foo() %s {
+	return %s
+}`, c.typ, c.expr)
+		p, err := compileToIR([]byte(src))
+		if err != nil {
+			t.Errorf("%s must not return nil but returned %v", c.expr, err)
+			continue
+		}
+		got := p.Funcs[0].Block.Stmts[0].Exprs[0]
+		if got.Type != shaderir.NumberExpr || got.Const == nil {
+			t.Errorf("%s: the result must be a constant but not", c.expr)
+			continue
+		}
+		if got.Const.Kind() != c.kind {
+			t.Errorf("%s: kind: got: %v, want: %v", c.expr, got.Const.Kind(), c.kind)
+		}
+		if got.Const.String() != c.want {
+			t.Errorf("%s: got: %s, want: %s", c.expr, got.Const.String(), c.want)
+		}
+	}
+
+	for _, stmt := range []string{
+		"x := 1 / 0; _ = x",
+		"x := 1.0 / 0.0; _ = x",
+		"x := 1 % 0; _ = x",
+		"x := 2.0 / (1.0 - 1.0); _ = x",
+		"const c = 0; x := 1 / c; _ = x",
+	} {
+		src := fmt.Sprintf(`package main
+
+func Foo() {
+	%s
+}`, stmt)
+		if _, err := compileToIR([]byte(src)); err == nil {
+			t.Errorf("%s must return an error but does not", stmt)
+		} else if !strings.Contains(err.Error(), "division by zero") {
+			t.Errorf("%s: the error must be about division by zero but: %v", stmt, err)
+		}
+	}
+}
+
+func TestSyntaxUnreachableCode(t *testing.T) {
+	cases := []struct {
+		src   string
+		stmts int
+		warn  bool
+	}{
+		{
+			src: `var x float
+	return x`,
+			stmts: 1,
+			warn:  false,
+		},
+		{
+			src: `var x float
+	return x
+	x = 1
+	x = 2`,
+			stmts: 1,
+			warn:  true,
+		},
+		{
+			src: `var x float
+	if x > 0 {
+		return 1
+	} else {
+		return 2
+	}
+	x = 1
+	return x`,
+			stmts: 1,
+			warn:  true,
+		},
+		{
+			src: `var x float
+	if x > 0 {
+		return 1
+	} else if x < 0 {
+		return -1
+	} else {
+		return 0
+	}
+	x = 1
+	return x`,
+			stmts: 1,
+			warn:  true,
+		},
+		{
+			src: `var x float
+	if x > 0 {
+		return 1
+	}
+	x = 1
+	return x`,
+			stmts: 3,
+			warn:  false,
+		},
+		{
+			src: `var x float
+	{
+		return x
+	}
+	x = 1
+	return x`,
+			stmts: 1,
+			warn:  true,
+		},
+	}
+	for _, c := range cases {
+		src := fmt.Sprintf(`package main
+
+func Foo() float {
+	%s
+}`, c.src)
+		p, warnings, err := compileToIRWithWarnings([]byte(src))
+		if err != nil {
+			t.Errorf("%s must not return nil but returned %v", c.src, err)
+			continue
+		}
+		if got := len(p.Funcs[0].Block.Stmts); got != c.stmts {
+			t.Errorf("%s: the number of statements: got: %d, want: %d", c.src, got, c.stmts)
+		}
+		if got := len(warnings) > 0; got != c.warn {
+			t.Errorf("%s: warned: got: %v, want: %v (%v)", c.src, got, c.warn, warnings)
+		}
+	}
+
+	// The unreachable statements are still checked.
+	if _, err := compileToIR([]byte(`package main
+
+func Foo() float {
+	return 1
+	x := undefined
+	return x
+}`)); err == nil {
+		t.Errorf("an error in unreachable code must be reported but not")
+	}
+}
+
+func TestSyntaxInfiniteForLoop(t *testing.T) {
+	cases := []struct {
+		stmt string
+		err  bool
+	}{
+		{stmt: "for i := 0; i < 10; i++ {}", err: false},
+		{stmt: "for i := 0; i < 10; i -= 1 {}", err: true},
+		{stmt: "for i := 0; i <= 10; i-- {}", err: true},
+		{stmt: "for i := 10; i > 0; i-- {}", err: false},
+		{stmt: "for i := 10; i > 0; i++ {}", err: true},
+		{stmt: "for i := 10; i >= 0; i += 2 {}", err: true},
+		{stmt: "for i := 0; i < 10; i += 0 {}", err: true},
+		{stmt: "for i := 0.0; i < 1.0; i += 0.0 {}", err: true},
+		{stmt: "for i := 0; i != 9; i += 3 {}", err: false},
+		{stmt: "for i := 0; i != 10; i += 3 {}", err: true},
+		{stmt: "for i := 0; i != -9; i += 3 {}", err: true},
+		{stmt: "for i := 0.0; i != 1.0; i += 0.25 {}", err: false},
+		{stmt: "for i := 0.0; i != 1.0; i += 0.3 {}", err: true},
+		{stmt: "for i := 0; i == 0; i++ {}", err: false},
+		{stmt: "for i := 0; i == 0; i += 0 {}", err: true},
+		// The loop body is never executed.
+		{stmt: "for i := 0; i > 10; i-- {}", err: false},
+		{stmt: "for i := 0; i != 0; i += 3 {}", err: false},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Foo() {
+	%s
+}`, stmt)
+		_, err := compileToIR([]byte(src))
+		if err == nil && c.err {
+			t.Errorf("%s must return an error but does not", stmt)
+		} else if err != nil && !c.err {
+			t.Errorf("%s must not return nil but returned %v", stmt, err)
+		}
+	}
+}
+
+func TestSyntaxMultipleValueAssignment(t *testing.T) {
+	cases := []struct {
+		stmt string
+		err  string
+	}{
+		{stmt: "x, y := Foo2(); _, _ = x, y"},
+		{stmt: "x, y, z := Foo3(); _, _, _ = x, y, z"},
+		{stmt: "var x, y int; x, y = Foo2(); _, _ = x, y"},
+		{stmt: "var x int; var z vec2; x, _, z = Foo3(); _, _ = x, z"},
+		{stmt: "var x, y = Foo2(); _, _ = x, y"},
+		{stmt: "var x, y, z = Foo3(); _, _, _ = x, y, z"},
+		{stmt: "x, y := Foo3(); _, _ = x, y", err: "assignment mismatch: 2 variables but Foo3() returns 3 values"},
+		{stmt: "x, y, z := Foo2(); _, _, _ = x, y, z", err: "assignment mismatch: 3 variables but Foo2() returns 2 values"},
+		{stmt: "x, y := Foo1(); _, _ = x, y", err: "assignment mismatch: 2 variables but Foo1() returns 1 value"},
+		{stmt: "x, y := Foo0(); _, _ = x, y", err: "assignment mismatch: 2 variables but Foo0() returns 0 values"},
+		{stmt: "var x, y int; x, y = Foo3(); _, _ = x, y", err: "assignment mismatch: 2 variables but Foo3() returns 3 values"},
+		{stmt: "var x, y, z = Foo2(); _, _, _ = x, y, z", err: "assignment mismatch: 3 variables but Foo2() returns 2 values"},
+		{stmt: "var x, y vec2; x, y = Foo2(); _, _ = x, y", err: "cannot use type int as type vec2"},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Foo0() {
+}
+
+func Foo1() int {
+	return 1
+}
+
+func Foo2() (int, int) {
+	return 1, 2
+}
+
+func Foo3() (int, float, vec2) {
+	return 1, 2, vec2(3)
+}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		_, err := compileToIR([]byte(src))
+		if c.err == "" {
+			if err != nil {
+				t.Errorf("%s must not return nil but returned %v", stmt, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%s must return an error but does not", stmt)
+		} else if !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%s: the error must include %q but: %v", stmt, c.err, err)
+		}
+	}
+}
+
+func TestSyntaxGeometryBuiltinFuncReturnType(t *testing.T) {
+	cases := []struct {
+		stmt string
+		err  bool
+	}{
+		{stmt: "var x float = length(vec3(1)); _ = x", err: false},
+		{stmt: "var x vec3 = length(vec3(1)); _ = x", err: true},
+		{stmt: "var x float = distance(vec2(1), vec2(2)); _ = x", err: false},
+		{stmt: "var x vec2 = distance(vec2(1), vec2(2)); _ = x", err: true},
+		{stmt: "var x vec4 = normalize(vec4(1)); _ = x", err: false},
+		{stmt: "var x float = normalize(vec4(1)); _ = x", err: true},
+		{stmt: "var x float = dot(vec3(1), vec3(2)); _ = x", err: false},
+		{stmt: "var x float = dot(1.0, 2.0); _ = x", err: false},
+		{stmt: "var x vec3 = dot(vec3(1), vec3(2)); _ = x", err: true},
+		{stmt: "var x vec3 = cross(vec3(1), vec3(2)); _ = x", err: false},
+		{stmt: "var x float = cross(vec3(1), vec3(2)); _ = x", err: true},
+		{stmt: "var x vec2 = reflect(vec2(1), vec2(2)); _ = x", err: false},
+		{stmt: "var x vec3 = refract(vec3(1), vec3(2), 0.5); _ = x", err: false},
+		{stmt: "var x vec3 = faceforward(vec3(1), vec3(2), vec3(3)); _ = x", err: false},
+
+		// Mismatched widths.
+		{stmt: "x := cross(vec2(1), vec2(2)); _ = x", err: true},
+		{stmt: "x := cross(vec4(1), vec4(2)); _ = x", err: true},
+		{stmt: "x := cross(vec3(1), vec2(2)); _ = x", err: true},
+		{stmt: "x := dot(vec3(1), vec2(2)); _ = x", err: true},
+		{stmt: "x := distance(vec4(1), vec3(2)); _ = x", err: true},
+		{stmt: "x := reflect(vec3(1), vec2(2)); _ = x", err: true},
+		{stmt: "x := refract(vec3(1), vec2(2), 0.5); _ = x", err: true},
+		{stmt: "x := refract(vec3(1), vec3(2), vec3(0.5)); _ = x", err: true},
+		{stmt: "x := faceforward(vec3(1), vec3(2), vec2(3)); _ = x", err: true},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		_, err := compileToIR([]byte(src))
+		if err == nil && c.err {
+			t.Errorf("%s must return an error but does not", stmt)
+		} else if err != nil && !c.err {
+			t.Errorf("%s must not return nil but returned %v", stmt, err)
+		}
+	}
+}
+
+func TestSyntaxStruct(t *testing.T) {
+	cases := []struct {
+		stmt string
+		err  bool
+	}{
+		{stmt: "m := Material{Albedo: vec3(1), Rough: 0.5}; _ = m.Albedo.x + m.Rough", err: false},
+		{stmt: "m := Material{vec3(1), 1}; m.Rough = 2; m.Albedo.y = 1; _ = m", err: false},
+		{stmt: "m := Material{Rough: 1}; _ = m.Albedo", err: false},
+		{stmt: "var m Material; _ = m.Rough", err: false},
+		{stmt: "_ = Material{}.Rough", err: false},
+		{stmt: "o := Object{Material: Material{Rough: 1}, ID: 2}; _ = o.Material.Rough", err: false},
+		{stmt: "var o Object; o.Material.Albedo = vec3(1); _ = o", err: false},
+		{stmt: "type T struct { X, Y float }; t := T{1, 2}; _ = t.X + t.Y", err: false},
+		{stmt: "m := Material{vec3(1)}; _ = m", err: true},
+		{stmt: "m := Material{vec3(1), 1, 2}; _ = m", err: true},
+		{stmt: "m := Material{Albedo: vec3(1), 1}; _ = m", err: true},
+		{stmt: "m := Material{Metal: 1}; _ = m", err: true},
+		{stmt: "m := Material{Rough: 1, Rough: 2}; _ = m", err: true},
+		{stmt: "m := Material{Albedo: 1}; _ = m", err: true},
+		{stmt: "m := Material{Rough: vec3(1)}; _ = m", err: true},
+		{stmt: "o := Object{ID: 1.5}; _ = o", err: true},
+		{stmt: "var m Material; _ = m.Metal", err: true},
+		{stmt: "var m Material; _ = m.xyz", err: true},
+		{stmt: "var m Material; _ = m == m", err: true},
+		{stmt: "var m Material; _ = m + m", err: true},
+		{stmt: "var m [2]Material; _ = m", err: true},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+type Material struct {
+	Albedo vec3
+	Rough  float
+}
+
+type Object struct {
+	Material Material
+	ID       int
+}
+
+func Foo() {
+	%s
+}`, stmt)
+		_, err := compileToIR([]byte(src))
+		if err == nil && c.err {
+			t.Errorf("%s must return an error but does not", stmt)
+		} else if err != nil && !c.err {
+			t.Errorf("%s must not return nil but returned %v", stmt, err)
+		}
+	}
+}
+
+func TestSyntaxStructDeclaration(t *testing.T) {
+	cases := []struct {
+		src string
+		err string
+	}{
+		{src: "type T struct { X float }", err: ""},
+		{src: "type T struct { X, Y float; Z [2]vec2 }", err: ""},
+		{src: "type T struct {}", err: "struct must have at least one field"},
+		{src: "type T struct { X, X float }", err: "X redeclared"},
+		{src: "type T struct { T T }", err: "invalid recursive type T"},
+		{src: "type T struct { X [2]T }", err: "invalid recursive type T"},
+		{src: "type T struct { X struct { Y T } }", err: "invalid recursive type T"},
+		{src: "type T struct { X float }\n\nvar U T", err: "a uniform variable cannot be a struct: U"},
+	}
+
+	for _, c := range cases {
+		src := "package main\n\n" + c.src
+		_, err := compileToIR([]byte(src))
+		if c.err == "" {
+			if err != nil {
+				t.Errorf("%q must not return an error but returned %v", c.src, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%q must return an error but does not", c.src)
+			continue
+		}
+		if !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%q: error must contain %q but %q", c.src, c.err, err.Error())
+		}
+	}
+}
+
+func TestSyntaxConstTypeInVectorConstructor(t *testing.T) {
+	cases := []struct {
+		typ  string
+		expr string
+		kind gconstant.Kind
+	}{
+		{typ: "vec4", expr: "vec4(0, 0, 0, 1)", kind: gconstant.Float},
+		{typ: "vec3", expr: "vec3(1, 2.0, 3)", kind: gconstant.Float},
+		{typ: "ivec4", expr: "ivec4(0, 1.0, 2, 3)", kind: gconstant.Int},
+	}
+
+	for _, c := range cases {
+		src := fmt.Sprintf(`package main
+
+func Foo() %s {
+	return %s
+}`, c.typ, c.expr)
+		p, err := compileToIR([]byte(src))
+		if err != nil {
+			t.Errorf("%s must not return nil but returned %v", c.expr, err)
+			continue
+		}
+		stmts := p.Funcs[0].Block.Stmts
+		expr := stmts[len(stmts)-1].Exprs[0]
+		if expr.Type != shaderir.Call {
+			t.Errorf("%s: the result must be a call but not", c.expr)
+			continue
+		}
+		for i, e := range expr.Exprs[1:] {
+			if e.Const == nil {
+				t.Errorf("%s: argument %d must be a constant but not", c.expr, i)
+				continue
+			}
+			if got := e.Const.Kind(); got != c.kind {
+				t.Errorf("%s: argument %d: got: %s, want: %s", c.expr, i, got, c.kind)
+			}
+		}
+	}
+
+	for _, c := range []struct {
+		stmt string
+		err  bool
+	}{
+		{stmt: "x := 1.0; _ = vec3(1, 2.0, x)", err: false},
+		{stmt: "x := 1; _ = ivec3(1, 2.0, x)", err: false},
+		{stmt: "_ = ivec2(1.5, 2)", err: true},
+		{stmt: "x := 1; _ = ivec3(x, 2, 0.5)", err: true},
+		{stmt: "x := 1.0; _ = ivec2(x, 2)", err: true},
+	} {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		_, err := compileToIR([]byte(src))
+		if err == nil && c.err {
+			t.Errorf("%s must return an error but does not", stmt)
+		} else if err != nil && !c.err {
+			t.Errorf("%s must not return nil but returned %v", stmt, err)
+		}
+	}
+}
+
+func TestSyntaxNumericLiterals(t *testing.T) {
+	cases := []struct {
+		stmt string
+		err  bool
+
+		// literal is the literal emitted for the value in GLSL, if not empty.
+		literal string
+	}{
+		{stmt: "var a int = 0xFF; _ = a", err: false, literal: "255"},
+		{stmt: "a := 0xff; _ = a", err: false},
+		{stmt: "var a float = 1e-3; _ = a", err: false, literal: "1.0000000000e-03"},
+		{stmt: "var a float = 1.5e10; _ = a", err: false, literal: "15000000000.0"},
+		{stmt: "a := 1.5E10; _ = a", err: false},
+		{stmt: "var a float = 0xFF; _ = a", err: false, literal: "255.0"},
+		{stmt: "var a int = 1e3; _ = a", err: false, literal: "1000"},
+		{stmt: "var a int = 0b1010 + 0o17; _ = a", err: false, literal: "25"},
+		{stmt: "var a float = 0x1p-2; _ = a", err: false, literal: "2.5000000000e-01"},
+		{stmt: "var a int = 1_000; _ = a", err: false, literal: "1000"},
+		{stmt: "a := vec2(0xFF, 1e-3); _ = a", err: false},
+		{stmt: "a := vec4(1.5e10, .5, 0x10, 2e0); _ = a", err: false},
+		{stmt: "a := ivec2(0xFF, 1e3); _ = a", err: false},
+		{stmt: "var a int = 0x7FFFFFFF; _ = a", err: false},
+		{stmt: "var a int = -0x80000000; _ = a", err: false, literal: "-2147483648"},
+		{stmt: "var a float = 1e19; _ = a", err: false, literal: "1e+19"},
+		{stmt: "var a float = 1e30; _ = a", err: false, literal: "1e+30"},
+		{stmt: "var a float = -1e30; _ = a", err: false, literal: "-1e+30"},
+		{stmt: "var a int = 1e-3; _ = a", err: true},
+		{stmt: "a := ivec2(1.5e-1); _ = a", err: true},
+		{stmt: "var a int = 0xFFFFFFFF; _ = a", err: true},
+		{stmt: "var a int; a = 0x80000000; _ = a", err: true},
+		{stmt: "a := 1; a += 0xFFFFFFFF", err: true},
+		{stmt: "var a float = 1e39; _ = a", err: true},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := fmt.Sprintf(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	%s
+	return dstPos
+}`, stmt)
+		p, err := compileToIR([]byte(src))
+		if err == nil && c.err {
+			t.Errorf("%s must return an error but does not", stmt)
+		} else if err != nil && !c.err {
+			t.Errorf("%s must not return nil but returned %v", stmt, err)
+		}
+		if err != nil || c.literal == "" {
+			continue
+		}
+		_, fs := glsl.Compile(p, glsl.GLSLVersionDefault)
+		if want := " = " + c.literal + ";"; !strings.Contains(fs, want) {
+			t.Errorf("%s: the output must include %q but does not:\n%s", stmt, want, fs)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+vec3 F0(in float l0, in vec2 l1);
+
+vec3 F0(in float l0, in vec2 l1) {
+	float l2 = float(0);
+	float l3 = float(0);
+	vec2 l4 = vec2(0);
+	vec2 l5 = vec2(0);
+	vec2 l6 = vec2(0);
+	l2 = max((fwidth(l0)) * (5.0000000000e-01), 1.0000000000e-06);
+	l3 = smoothstep((5.0000000000e-01) - (l2), (5.0000000000e-01) + (l2), l0);
+	l4 = (l1) * (2.0);
+	l5 = max((fwidth(l4)) * (5.0000000000e-01), 1.0000000000e-06);
+	l6 = smoothstep((5.0000000000e-01) - (l5), (5.0000000000e-01) + (l5), l4);
+	return vec3(l3, l6);
+}
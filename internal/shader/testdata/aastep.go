@@ -0,0 +1,7 @@
+package main
+
+func Foo(x float, v vec2) vec3 {
+	a := aastep(0.5, x)
+	b := aastep(0.5, v*2)
+	return vec3(a, b)
+}
@@ -0,0 +1,13 @@
+ivec4 F0(in int l0, in ivec3 l1);
+
+ivec4 F0(in int l0, in ivec3 l1) {
+	int l2 = 0;
+	int l3 = 0;
+	l2 = 7;
+	l2 = (l2) & (l0);
+	l2 = (l2) & (-3);
+	l3 = (l0) & ((l2) ^ (-1));
+	l1 = (l1) & ((ivec3(1, 2, 3)) ^ (-1));
+	(l1).x = ((l1).x) & ((l2) ^ (-1));
+	return (ivec4(l2, l3, (l1).xy)) + (8);
+}
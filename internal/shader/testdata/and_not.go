@@ -0,0 +1,11 @@
+package main
+
+func Foo(x int, v ivec3) ivec4 {
+	a := 7
+	a &= x
+	a &^= 2
+	b := x &^ a
+	v &^= ivec3(1, 2, 3)
+	v.x &^= a
+	return ivec4(a, b, v.xy) + (12 &^ 4)
+}
@@ -0,0 +1,29 @@
+vec4 F0(in int l0);
+
+vec4 F0(in int l0) {
+	float l1[3];
+	l1[0] = float(0);
+	l1[1] = float(0);
+	l1[2] = float(0);
+	float l2[3];
+	l2[0] = float(0);
+	l2[1] = float(0);
+	l2[2] = float(0);
+	vec4 l3[2];
+	l3[0] = vec4(0);
+	l3[1] = vec4(0);
+	vec4 l4[2];
+	l4[0] = vec4(0);
+	l4[1] = vec4(0);
+	(l1)[0] = 2.5000000000e-01;
+	(l1)[1] = 5.0000000000e-01;
+	(l1)[2] = 2.5000000000e-01;
+	l2[0] = l1[0];
+	l2[1] = l1[1];
+	l2[2] = l1[2];
+	(l3)[0] = vec4(1.0, 0.0, 0.0, 1.0);
+	(l3)[1] = vec4(0.0, 0.0, 1.0, 1.0);
+	l4[0] = l3[0];
+	l4[1] = l3[1];
+	return ((l4)[l0]) * ((l2)[1]);
+}
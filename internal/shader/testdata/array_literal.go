@@ -0,0 +1,7 @@
+package main
+
+func Foo(i int) vec4 {
+	weights := [...]float{0.25, 0.5, 0.25}
+	colors := [2]vec4{vec4(1, 0, 0, 1), vec4(0, 0, 1, 1)}
+	return colors[i] * weights[1]
+}
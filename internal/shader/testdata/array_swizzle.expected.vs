@@ -0,0 +1,10 @@
+vec2 F0(void);
+
+vec2 F0(void) {
+	vec3 l0[3];
+	l0[0] = vec3(0);
+	l0[1] = vec3(0);
+	l0[2] = vec3(0);
+	(l0)[1] = vec3(1.0, 2.0, 3.0);
+	return ((l0)[1]).xy;
+}
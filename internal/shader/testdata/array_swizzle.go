@@ -0,0 +1,7 @@
+package main
+
+func Foo() vec2 {
+	var positions [3]vec3
+	positions[1] = vec3(1, 2, 3)
+	return positions[1].xy
+}
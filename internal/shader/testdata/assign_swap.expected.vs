@@ -0,0 +1,61 @@
+vec4 F0(void);
+vec2 F1(in vec2 l0);
+
+vec4 F0(void) {
+	float l0 = float(0);
+	float l1 = float(0);
+	float l2 = float(0);
+	float l3 = float(0);
+	float l4 = float(0);
+	float l5 = float(0);
+	vec2 l6 = vec2(0);
+	vec2 l7 = vec2(0);
+	vec2 l8 = vec2(0);
+	vec2 l9 = vec2(0);
+	vec2 l10 = vec2(0);
+	vec2 l11 = vec2(0);
+	vec2 l12 = vec2(0);
+	vec2 l13 = vec2(0);
+	vec2 l14 = vec2(0);
+	l1 = 1.0;
+	l3 = 2.0;
+	l0 = l1;
+	l2 = l3;
+	l4 = l2;
+	l5 = l0;
+	l0 = l4;
+	l2 = l5;
+	l7 = vec2(1.0);
+	l9 = vec2(2.0);
+	l11 = vec2(3.0);
+	l6 = l7;
+	l8 = l9;
+	l10 = l11;
+	l12 = l8;
+	l13 = l10;
+	l14 = l6;
+	l6 = l12;
+	l8 = l13;
+	l10 = l14;
+	return vec4(l0, l2, ((l6).x) + ((l8).x), (l10).x);
+}
+
+vec2 F1(in vec2 l0) {
+	float l1 = float(0);
+	float l2 = float(0);
+	l1 = (l0).y;
+	l2 = (l0).x;
+	(l0).x = l1;
+	(l0).y = l2;
+	{
+		vec2 l3 = vec2(0);
+		vec2 l4 = vec2(0);
+		vec2 l5 = vec2(0);
+		vec2 l6 = vec2(0);
+		l4 = vec2(0.0);
+		l6 = l0;
+		l3 = l4;
+		l5 = l6;
+		return l5;
+	}
+}
@@ -0,0 +1,18 @@
+package main
+
+func Foo() vec4 {
+	a, b := 1.0, 2.0
+	a, b = b, a
+	x, y, z := vec2(1), vec2(2), vec2(3)
+	x, y, z = y, z, x
+	return vec4(a, b, x.x+y.x, z.x)
+}
+
+func Bar(v vec2) vec2 {
+	v.x, v.y = v.y, v.x
+	{
+		v, w := vec2(0), v
+		_ = v
+		return w
+	}
+}
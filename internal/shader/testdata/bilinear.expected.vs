@@ -0,0 +1,16 @@
+vec4 F0(in vec2 l0);
+vec4 F1(in vec2 l0);
+
+vec4 F0(in vec2 l0) {
+	return vec4(l0, 0.0, 1.0);
+}
+
+vec4 F1(in vec2 l0) {
+	vec2 l1 = vec2(0);
+	vec2 l2 = vec2(0);
+	vec2 l3 = vec2(0);
+	l1 = ((l0) * (2.0)) - (vec2(5.0000000000e-01));
+	l2 = (floor(l1)) + (vec2(5.0000000000e-01));
+	l3 = fract(l1);
+	return mix(mix(F0(l2), F0((l2) + (vec2(1.0, 0.0))), (l3).x), mix(F0((l2) + (vec2(0.0, 1.0))), F0((l2) + (vec2(1.0, 1.0))), (l3).x), (l3).y);
+}
@@ -0,0 +1,11 @@
+//kage:unit pixels
+
+package main
+
+func At(pos vec2) vec4 {
+	return vec4(pos, 0, 1)
+}
+
+func Sample(pos vec2) vec4 {
+	return bilinearSample(At, pos*2)
+}
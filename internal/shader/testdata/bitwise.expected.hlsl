@@ -0,0 +1,22 @@
+void F0(in int l0, in int2 l1, out int l2, out int2 l3);
+
+void F0(in int l0, in int2 l1, out int l2, out int2 l3) {
+	int l4 = 0;
+	int l5 = 0;
+	int l6 = 0;
+	int2 l7 = 0;
+	l4 = ((l0) >> (4)) & (255);
+	l5 = ((l0) << (8)) | (l4);
+	l6 = (l5) ^ (l0);
+	l6 = (l6) & (15);
+	l6 = (l6) | (48);
+	l6 = (l6) ^ (l4);
+	l6 = (l6) << (2);
+	l6 = (l6) >> (1);
+	l7 = (l1) << (2);
+	l7 = (l7) >> (int2(1, 2));
+	l7 = (l7) & (l1);
+	l2 = l6;
+	l3 = l7;
+	return;
+}
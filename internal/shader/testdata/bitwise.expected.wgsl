@@ -0,0 +1,20 @@
+fn F0(l0: i32, l1: vec2<i32>, l2: ptr<function, i32>, l3: ptr<function, vec2<i32>>) {
+	var l4: i32;
+	var l5: i32;
+	var l6: i32;
+	var l7: vec2<i32>;
+	l4 = ((l0) >> u32(4)) & (255);
+	l5 = ((l0) << u32(8)) | (l4);
+	l6 = (l5) ^ (l0);
+	l6 = (l6) & (15);
+	l6 = (l6) | (48);
+	l6 = (l6) ^ (l4);
+	l6 = (l6) << u32(2);
+	l6 = (l6) >> u32(1);
+	l7 = (l1) << vec2<u32>(2);
+	l7 = (l7) >> vec2<u32>(vec2<i32>(1, 2));
+	l7 = (l7) & (l1);
+	(*l2) = l6;
+	(*l3) = l7;
+	return;
+}
@@ -0,0 +1,16 @@
+package main
+
+func Foo(x int, y ivec2) (int, ivec2) {
+	a := (x >> 4) & 0xff
+	b := (x << 8) | a
+	c := b ^ x
+	c &= 0x0f
+	c |= 0x30
+	c ^= a
+	c <<= 2
+	c >>= 1
+	d := y << 2
+	d >>= ivec2(1, 2)
+	d &= y
+	return c, d
+}
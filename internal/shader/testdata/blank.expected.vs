@@ -16,11 +16,9 @@ void F1(void) {
 	int l5 = 0;
 	int l6 = 0;
 	int l7 = 0;
-	int l8 = 0;
-	int l9 = 0;
 	F0(l0, l1);
 	F0(l2, l3);
 	l4 = l2;
-	F0(l6, l7);
-	l9 = l7;
+	F0(l5, l6);
+	l7 = l6;
 }
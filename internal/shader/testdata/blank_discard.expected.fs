@@ -0,0 +1,28 @@
+in vec2 V0;
+in vec4 V1;
+
+float F0(in float l0);
+vec4 F1(in vec4 l0, in vec2 l1, in vec4 l2);
+
+float F0(in float l0) {
+	if ((l0) == (0.0)) {
+		discard;
+	}
+	return l0;
+}
+
+vec4 F1(in vec4 l0, in vec2 l1, in vec4 l2) {
+	float l3 = float(0);
+	float l4 = float(0);
+	float l5 = float(0);
+	F0((l2).a);
+	F0((l2).r);
+	l4 = 1.0;
+	l3 = l4;
+	l5 = (2.0) * (F0((l2).g));
+	return vec4(l3);
+}
+
+void main(void) {
+	fragColor = F1(gl_FragCoord, V0, V1);
+}
@@ -0,0 +1,7 @@
+Varyings VSMain(float2 A0 : POSITION, float2 A1 : TEXCOORD, float4 A2 : COLOR) {
+	Varyings varyings;
+	varyings.Position = float4(A0, 0.0, 1.0);
+	varyings.M0 = A1;
+	varyings.M1 = A2;
+	return varyings;
+}
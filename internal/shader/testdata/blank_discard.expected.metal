@@ -0,0 +1,43 @@
+struct Attributes {
+	float2 M0;
+	float2 M1;
+	float4 M2;
+};
+
+struct Varyings {
+	float4 Position [[position]];
+	float2 M0;
+	float4 M1;
+};
+
+float F0(float l0);
+
+float F0(float l0) {
+	if ((l0) == (0.0)) {
+		discard_fragment();
+	}
+	return l0;
+}
+
+vertex Varyings Vertex(
+	uint vid [[vertex_id]],
+	const device Attributes* attributes [[buffer(0)]]) {
+	Varyings varyings = {};
+	varyings.Position = float4(attributes[vid].M0, 0.0, 1.0);
+	varyings.M0 = attributes[vid].M1;
+	varyings.M1 = attributes[vid].M2;
+	return varyings;
+}
+
+fragment float4 Fragment(
+	Varyings varyings [[stage_in]]) {
+	float l0 = float(0);
+	float l1 = float(0);
+	float l2 = float(0);
+	F0((varyings.M1).a);
+	F0((varyings.M1).r);
+	l1 = 1.0;
+	l0 = l1;
+	l2 = (2.0) * (F0((varyings.M1).g));
+	return float4(l0);
+}
@@ -0,0 +1,12 @@
+in vec2 A0;
+in vec2 A1;
+in vec4 A2;
+out vec2 V0;
+out vec4 V1;
+
+void main(void) {
+	gl_Position = vec4(A0, 0.0, 1.0);
+	V0 = A1;
+	V1 = A2;
+	return;
+}
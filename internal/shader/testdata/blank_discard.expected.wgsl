@@ -0,0 +1,40 @@
+struct Attributes {
+	@location(0) M0: vec2<f32>,
+	@location(1) M1: vec2<f32>,
+	@location(2) M2: vec4<f32>,
+}
+
+struct Varyings {
+	@builtin(position) Position: vec4<f32>,
+	@location(0) M0: vec2<f32>,
+	@location(1) M1: vec4<f32>,
+}
+
+fn F0(l0: f32) -> f32 {
+	if ((l0) == (0.0)) {
+		discard;
+	}
+	return l0;
+}
+
+@vertex
+fn Vertex(attributes: Attributes) -> Varyings {
+	var varyings: Varyings;
+	varyings.Position = vec4<f32>(attributes.M0, 0.0, 1.0);
+	varyings.M0 = attributes.M1;
+	varyings.M1 = attributes.M2;
+	return varyings;
+}
+
+@fragment
+fn Fragment(varyings: Varyings) -> @location(0) vec4<f32> {
+	var l0: f32;
+	var l1: f32;
+	var l2: f32;
+	F0((varyings.M1).a);
+	F0((varyings.M1).r);
+	l1 = 1.0;
+	l0 = l1;
+	l2 = (2.0) * (F0((varyings.M1).g));
+	return vec4<f32>(l0);
+}
@@ -0,0 +1,19 @@
+package main
+
+func Vertex(dstPos vec2, srcPos vec2, color vec4) (vec4, vec2, vec4) {
+	return vec4(dstPos, 0, 1), srcPos, color
+}
+
+func discardTransparent(alpha float) float {
+	if alpha == 0 {
+		discard()
+	}
+	return alpha
+}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	_ = discardTransparent(color.a)
+	_, x := discardTransparent(color.r), 1.0
+	_ = 2 * discardTransparent(color.g)
+	return vec4(x)
+}
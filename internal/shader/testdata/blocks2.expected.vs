@@ -8,5 +8,4 @@ vec2 F0(void) {
 		l1 = 0;
 		return vec2(float(l1));
 	}
-	return vec2(1.0);
 }
@@ -0,0 +1,28 @@
+int F0(int l0);
+bool F1(void);
+
+int F0(int l0) {
+	array<bool, 8> l1 = {};
+	int l3 = 0;
+	for (int l2 = 0; l2 < 8; l2++) {
+		(l1)[l2] = ((l2) % (2)) == (0);
+	}
+	(l1)[l0] = !((l1)[l0]);
+	l3 = 0;
+	for (int l4 = 0; l4 < 8; l4++) {
+		if ((l1)[l4]) {
+			l3 = (l3) + (1);
+		}
+	}
+	return l3;
+}
+
+bool F1(void) {
+	array<bool, 4> l0 = {};
+	array<bool, 4> l1 = {};
+	(l0)[0] = true;
+	(l0)[1] = false;
+	(l0)[2] = true;
+	l1 = l0;
+	return ((l1)[0]) && (!((l1)[3]));
+}
@@ -0,0 +1,47 @@
+int F0(in int l0);
+bool F1(void);
+
+int F0(in int l0) {
+	bool l1[8];
+	l1[0] = false;
+	l1[1] = false;
+	l1[2] = false;
+	l1[3] = false;
+	l1[4] = false;
+	l1[5] = false;
+	l1[6] = false;
+	l1[7] = false;
+	int l3 = 0;
+	for (int l2 = 0; l2 < 8; l2++) {
+		(l1)[l2] = (modInt((l2), (2))) == (0);
+	}
+	(l1)[l0] = !((l1)[l0]);
+	l3 = 0;
+	for (int l4 = 0; l4 < 8; l4++) {
+		if ((l1)[l4]) {
+			l3 = (l3) + (1);
+		}
+	}
+	return l3;
+}
+
+bool F1(void) {
+	bool l0[4];
+	l0[0] = false;
+	l0[1] = false;
+	l0[2] = false;
+	l0[3] = false;
+	bool l1[4];
+	l1[0] = false;
+	l1[1] = false;
+	l1[2] = false;
+	l1[3] = false;
+	(l0)[0] = true;
+	(l0)[1] = false;
+	(l0)[2] = true;
+	l1[0] = l0[0];
+	l1[1] = l0[1];
+	l1[2] = l0[2];
+	l1[3] = l0[3];
+	return ((l1)[0]) && (!((l1)[3]));
+}
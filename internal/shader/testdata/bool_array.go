@@ -0,0 +1,21 @@
+package main
+
+func Foo(x int) int {
+	var flags [8]bool
+	for i := 0; i < 8; i++ {
+		flags[i] = i%2 == 0
+	}
+	flags[x] = !flags[x]
+	n := 0
+	for i := 0; i < 8; i++ {
+		if flags[i] {
+			n++
+		}
+	}
+	return n
+}
+
+func Bar() bool {
+	mask := [4]bool{true, false, true}
+	return mask[0] && !mask[3]
+}
@@ -0,0 +1,13 @@
+vec4 F0(in float l0);
+
+vec4 F0(in float l0) {
+	float l1 = float(0);
+	float l2 = float(0);
+	float l3 = float(0);
+	float l4 = float(0);
+	l1 = 11.0;
+	l2 = 4.0;
+	l3 = (sin(1.0000000000e-01)) + (sqrt(2.0));
+	l4 = (pow(10.0, 20.0)) + (exp(100.0));
+	return (vec4(l1, l2, l3, l4)) * (l0);
+}
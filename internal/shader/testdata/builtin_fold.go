@@ -0,0 +1,11 @@
+package main
+
+func Foo(x float) vec4 {
+	// These are folded.
+	a := sqrt(4.0) + pow(2.0, 3.0) + abs(-1.0)
+	b := clamp(3.0, 0.0, 1.0) + mod(-1.0, 3.0) + step(0.5, 1.0)
+	// These are not folded, as the results are not exact or out of the float32 range.
+	c := sin(0.1) + sqrt(2.0)
+	d := pow(10.0, 20.0) + exp(100.0)
+	return vec4(a, b, c, d) * x
+}
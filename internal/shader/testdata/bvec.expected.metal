@@ -0,0 +1,23 @@
+float3 F0(float3 l0, int2 l1);
+
+float3 F0(float3 l0, int2 l1) {
+	bool3 l2 = bool3(false);
+	bool2 l3 = bool2(false);
+	bool3 l4 = bool3(false);
+	l2 = (l0) < (float3(1.0));
+	if (all(l2)) {
+		return l0;
+	}
+	l3 = !((l1) >= (int2(0)));
+	if ((any(l3)) || (((l2).x) && ((l3)[1]))) {
+		return float3(0.0);
+	}
+	l4 = (l2) == (((l0) <= (float3(2.0))).xxy);
+	if (all(((l2).xy) == (l3))) {
+		return float3(1.0);
+	}
+	if (all((l4) != (l2))) {
+		return float3(2.0);
+	}
+	return float3(3.0);
+}
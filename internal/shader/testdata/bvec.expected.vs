@@ -0,0 +1,23 @@
+vec3 F0(in vec3 l0, in ivec2 l1);
+
+vec3 F0(in vec3 l0, in ivec2 l1) {
+	bvec3 l2 = bvec3(false);
+	bvec2 l3 = bvec2(false);
+	bvec3 l4 = bvec3(false);
+	l2 = lessThan(l0, vec3(1.0));
+	if (all(l2)) {
+		return l0;
+	}
+	l3 = not(greaterThanEqual(l1, ivec2(0)));
+	if ((any(l3)) || (((l2).x) && ((l3)[1]))) {
+		return vec3(0.0);
+	}
+	l4 = equal(l2, (lessThanEqual(l0, vec3(2.0))).xxy);
+	if (((l2).xy) == (l3)) {
+		return vec3(1.0);
+	}
+	if (all(notEqual(l4, l2))) {
+		return vec3(2.0);
+	}
+	return vec3(3.0);
+}
@@ -0,0 +1,20 @@
+package main
+
+func Foo(v vec3, w ivec2) vec3 {
+	m := lessThan(v, vec3(1.0))
+	if all(m) {
+		return v
+	}
+	n := not(greaterThanEqual(w, ivec2(0)))
+	if any(n) || m.x && n[1] {
+		return vec3(0)
+	}
+	e := equal(m, lessThanEqual(v, vec3(2)).xxy)
+	if m.xy == n {
+		return vec3(1)
+	}
+	if all(notEqual(e, m)) {
+		return vec3(2)
+	}
+	return vec3(3)
+}
@@ -0,0 +1,12 @@
+bool3 F0(in float3 l0);
+
+bool3 F0(in float3 l0) {
+	bool3 l1 = false;
+	bool3 l2 = false;
+	l1 = (l0) < ((float3)(1.0));
+	l2 = !(l1);
+	if (all(!(bool2(((l0).x) > (0.0), true)))) {
+		return l2;
+	}
+	return !(bool3((l1).x, false, (l2).y));
+}
@@ -0,0 +1,12 @@
+bvec3 F0(in vec3 l0);
+
+bvec3 F0(in vec3 l0) {
+	bvec3 l1 = bvec3(false);
+	bvec3 l2 = bvec3(false);
+	l1 = lessThan(l0, vec3(1.0));
+	l2 = not(l1);
+	if (all(not(bvec2(((l0).x) > (0.0), true)))) {
+		return l2;
+	}
+	return not(bvec3((l1).x, false, (l2).y));
+}
@@ -0,0 +1,10 @@
+fn F0(l0: vec3<f32>) -> vec3<bool> {
+	var l1: vec3<bool>;
+	var l2: vec3<bool>;
+	l1 = (l0) < (vec3<f32>(1.0));
+	l2 = !(l1);
+	if (all(!(vec2<bool>(((l0).x) > (0.0), true)))) {
+		return l2;
+	}
+	return !(vec3<bool>((l1).x, false, (l2).y));
+}
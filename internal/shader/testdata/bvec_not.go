@@ -0,0 +1,10 @@
+package main
+
+func Foo(v vec3) bvec3 {
+	m := lessThan(v, vec3(1.0))
+	n := !m
+	if all(!bvec2(v.x > 0, true)) {
+		return n
+	}
+	return !bvec3(m.x, false, n.y)
+}
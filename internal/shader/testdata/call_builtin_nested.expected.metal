@@ -0,0 +1,5 @@
+float3 F0(float3 l0, float3 l1);
+
+float3 F0(float3 l0, float3 l1) {
+	return (normalize(cross(l0, l1))) * (max(dot(l0, l1), 0.0));
+}
@@ -0,0 +1,5 @@
+vec3 F0(in vec3 l0, in vec3 l1);
+
+vec3 F0(in vec3 l0, in vec3 l1) {
+	return (normalize(cross(l0, l1))) * (max(dot(l0, l1), 0.0));
+}
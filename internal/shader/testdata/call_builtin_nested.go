@@ -0,0 +1,5 @@
+package main
+
+func Foo(a, b vec3) vec3 {
+	return normalize(cross(a, b)) * max(dot(a, b), 0)
+}
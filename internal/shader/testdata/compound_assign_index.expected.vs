@@ -0,0 +1,17 @@
+vec4 F0(void);
+
+vec4 F0(void) {
+	vec4 l0[3];
+	l0[0] = vec4(0);
+	l0[1] = vec4(0);
+	l0[2] = vec4(0);
+	int l1 = 0;
+	mat4 l2 = mat4(0);
+	l1 = 1;
+	(l0)[l1] = ((l0)[l1]) + (vec4(1.0));
+	(l0)[0] = ((l0)[0]) * (2.0);
+	((l2)[1])[2] = (((l2)[1])[2]) * (3.0);
+	((l2)[l1])[2] = (((l2)[l1])[2]) + (1.0);
+	((l0)[2]).x = (((l0)[2]).x) - (1.0);
+	return ((l0)[0]) + ((l2)[0]);
+}
@@ -0,0 +1,13 @@
+package main
+
+func Foo() vec4 {
+	var a [3]vec4
+	i := 1
+	a[i] += vec4(1)
+	a[0] *= 2
+	var m mat4
+	m[1][2] *= 3.0
+	m[i][2] += 1.0
+	a[2].x -= 1.0
+	return a[0] + m[0]
+}
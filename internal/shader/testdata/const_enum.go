@@ -0,0 +1,26 @@
+package main
+
+const (
+	ModeA = 0
+	ModeB = 1
+	ModeC = 2
+)
+
+const (
+	KindX = iota
+	KindY
+	KindZ
+)
+
+func Foo(mode int) vec4 {
+	switch mode {
+	case ModeA:
+		return vec4(1, 0, 0, 1)
+	case ModeB, ModeC:
+		return vec4(0, 1, 0, 1)
+	}
+	if mode == KindZ {
+		return vec4(0, 0, 1, 1)
+	}
+	return vec4(0)
+}
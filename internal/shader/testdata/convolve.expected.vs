@@ -0,0 +1,50 @@
+vec4 F0(in vec2 l0);
+vec4 F1(in vec2 l0);
+
+vec4 F0(in vec2 l0) {
+	return vec4(l0, 0.0, 1.0);
+}
+
+vec4 F1(in vec2 l0) {
+	float l1[9];
+	l1[0] = float(0);
+	l1[1] = float(0);
+	l1[2] = float(0);
+	l1[3] = float(0);
+	l1[4] = float(0);
+	l1[5] = float(0);
+	l1[6] = float(0);
+	l1[7] = float(0);
+	l1[8] = float(0);
+	float l2[9];
+	l2[0] = float(0);
+	l2[1] = float(0);
+	l2[2] = float(0);
+	l2[3] = float(0);
+	l2[4] = float(0);
+	l2[5] = float(0);
+	l2[6] = float(0);
+	l2[7] = float(0);
+	l2[8] = float(0);
+	vec2 l3 = vec2(0);
+	(l1)[0] = 0.0;
+	(l1)[1] = -1.0;
+	(l1)[2] = 0.0;
+	(l1)[3] = -1.0;
+	(l1)[4] = 5.0;
+	(l1)[5] = -1.0;
+	(l1)[6] = 0.0;
+	(l1)[7] = -1.0;
+	(l1)[8] = 0.0;
+	l2[0] = l1[0];
+	l2[1] = l1[1];
+	l2[2] = l1[2];
+	l2[3] = l1[3];
+	l2[4] = l1[4];
+	l2[5] = l1[5];
+	l2[6] = l1[6];
+	l2[7] = l1[7];
+	l2[8] = l1[8];
+	l3 = (l0) + (5.0000000000e-01);
+	return (((((((((F0((l3) + (vec2(-1.0, -1.0)))) * ((l2)[0])) + ((F0((l3) + (vec2(0.0, -1.0)))) * ((l2)[1]))) + ((F0((l3) + (vec2(1.0, -1.0)))) * ((l2)[2]))) + ((F0((l3) + (vec2(-1.0, 0.0)))) * ((l2)[3]))) + ((F0((l3) + (vec2(0.0, 0.0)))) * ((l2)[4]))) + ((F0((l3) + (vec2(1.0, 0.0)))) * ((l2)[5]))) + ((F0((l3) + (vec2(-1.0, 1.0)))) * ((l2)[6]))) + ((F0((l3) + (vec2(0.0, 1.0)))) * ((l2)[7]))) + ((F0((l3) + (vec2(1.0, 1.0)))) * ((l2)[8]));
+}
@@ -0,0 +1,16 @@
+//kage:unit pixels
+
+package main
+
+func At(pos vec2) vec4 {
+	return vec4(pos, 0, 1)
+}
+
+func Sharpen(pos vec2) vec4 {
+	kernel := [9]float{
+		0, -1, 0,
+		-1, 5, -1,
+		0, -1, 0,
+	}
+	return convolve3x3(At, pos+0.5, kernel)
+}
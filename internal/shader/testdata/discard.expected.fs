@@ -0,0 +1,25 @@
+uniform float U0;
+in vec2 V0;
+in vec4 V1;
+
+void F0(in float l0);
+vec4 F1(in vec4 l0, in vec2 l1, in vec4 l2);
+
+void F0(in float l0) {
+	if ((l0) < (U0)) {
+		discard;
+	}
+}
+
+vec4 F1(in vec4 l0, in vec2 l1, in vec4 l2) {
+	if (((l2).a) == (0.0)) {
+		discard;
+		return vec4(0.0);
+	}
+	F0((l2).a);
+	return l2;
+}
+
+void main(void) {
+	fragColor = F1(gl_FragCoord, V0, V1);
+}
@@ -0,0 +1,41 @@
+struct Attributes {
+	float2 M0;
+	float2 M1;
+	float4 M2;
+};
+
+struct Varyings {
+	float4 Position [[position]];
+	float2 M0;
+	float4 M1;
+};
+
+void F0(constant float& U0, float l0);
+
+void F0(constant float& U0, float l0) {
+	if ((l0) < (U0)) {
+		discard_fragment();
+	}
+}
+
+vertex Varyings Vertex(
+	uint vid [[vertex_id]],
+	const device Attributes* attributes [[buffer(0)]],
+	constant float& U0 [[buffer(1)]]) {
+	Varyings varyings = {};
+	varyings.Position = float4(attributes[vid].M0, 0.0, 1.0);
+	varyings.M0 = attributes[vid].M1;
+	varyings.M1 = attributes[vid].M2;
+	return varyings;
+}
+
+fragment float4 Fragment(
+	Varyings varyings [[stage_in]],
+	constant float& U0 [[buffer(1)]]) {
+	if (((varyings.M1).a) == (0.0)) {
+		discard_fragment();
+		return float4(0.0);
+	}
+	F0(U0, (varyings.M1).a);
+	return varyings.M1;
+}
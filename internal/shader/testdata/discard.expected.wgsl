@@ -0,0 +1,42 @@
+struct Uniforms {
+	U0: f32,
+}
+
+@group(0) @binding(0) var<storage, read> uniforms: Uniforms;
+
+struct Attributes {
+	@location(0) M0: vec2<f32>,
+	@location(1) M1: vec2<f32>,
+	@location(2) M2: vec4<f32>,
+}
+
+struct Varyings {
+	@builtin(position) Position: vec4<f32>,
+	@location(0) M0: vec2<f32>,
+	@location(1) M1: vec4<f32>,
+}
+
+fn F0(l0: f32) {
+	if ((l0) < (uniforms.U0)) {
+		discard;
+	}
+}
+
+@vertex
+fn Vertex(attributes: Attributes) -> Varyings {
+	var varyings: Varyings;
+	varyings.Position = vec4<f32>(attributes.M0, 0.0, 1.0);
+	varyings.M0 = attributes.M1;
+	varyings.M1 = attributes.M2;
+	return varyings;
+}
+
+@fragment
+fn Fragment(varyings: Varyings) -> @location(0) vec4<f32> {
+	if (((varyings.M1).a) == (0.0)) {
+		discard;
+		return vec4<f32>(0.0);
+	}
+	F0((varyings.M1).a);
+	return varyings.M1;
+}
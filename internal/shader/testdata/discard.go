@@ -0,0 +1,21 @@
+package main
+
+var Threshold float
+
+func Vertex(dstPos vec2, srcPos vec2, color vec4) (vec4, vec2, vec4) {
+	return vec4(dstPos, 0, 1), srcPos, color
+}
+
+func discardTransparent(alpha float) {
+	if alpha < Threshold {
+		discard()
+	}
+}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	if color.a == 0 {
+		discard()
+	}
+	discardTransparent(color.a)
+	return color
+}
@@ -0,0 +1,15 @@
+float2 F0(void);
+
+float2 F0(void) {
+	float2 l0 = 0.0;
+	float2 l2 = 0.0;
+	l0 = (float2)(0.0);
+	for (int l1 = 0; l1 < 100; l1++) {
+		(l0).x = ((l0).x) + (float(l1));
+	}
+	l2 = (float2)(0.0);
+	for (float l3 = 10.0; l3 >= 0.0; l3 -= 2.0) {
+		(l2).x = ((l2).x) + (float(l3));
+	}
+	return l0;
+}
@@ -0,0 +1,13 @@
+fn F0() -> vec2<f32> {
+	var l0: vec2<f32>;
+	var l2: vec2<f32>;
+	l0 = vec2<f32>(0.0);
+	for (var l1: i32 = 0; l1 < 100; l1++) {
+		(l0).x = ((l0).x) + (f32(l1));
+	}
+	l2 = vec2<f32>(0.0);
+	for (var l3: f32 = 10.0; l3 >= 0.0; l3 -= 2.0) {
+		(l2).x = ((l2).x) + (f32(l3));
+	}
+	return l0;
+}
@@ -0,0 +1,19 @@
+vec2 F0(void);
+
+vec2 F0(void) {
+	vec2 l0 = vec2(0);
+	l0 = vec2(0.0);
+	for (int l1 = 0; l1 < 4; l1++) {
+		if (((l0).x) >= (2.0)) {
+			break;
+		}
+		(l0).x = ((l0).x) + (float(l1));
+	}
+	for (int l2 = 0; l2 < 4; l2++) {
+		if ((l2) == (2)) {
+			continue;
+		}
+		(l0).y = ((l0).y) + (float(l2));
+	}
+	return l0;
+}
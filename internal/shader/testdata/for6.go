@@ -0,0 +1,18 @@
+package main
+
+func Foo() vec2 {
+	v := vec2(0)
+	for i := 0; i < 4; i++ {
+		if v.x >= 2 {
+			break
+		}
+		v.x += float(i)
+	}
+	for i := 0; i < 4; i++ {
+		if i == 2 {
+			continue
+		}
+		v.y += float(i)
+	}
+	return v
+}
@@ -0,0 +1,68 @@
+int F0(void);
+int F1(void);
+
+int F0(void) {
+	int l0 = 0;
+	int l1 = 0;
+	l0 = 0;
+	l1 = 0;
+	for (int l2 = 0; l2 < 4; l2++) {
+		for (int l3 = 0; l3 < 4; l3++) {
+			if ((l3) == (2)) {
+				l1 = 2;
+				break;
+			}
+			if ((l2) == (3)) {
+				l1 = 1;
+				break;
+			}
+			l0 = (l0) + (l3);
+		}
+		if ((l1) == (1)) {
+			break;
+		}
+		if ((l1) == (2)) {
+			l1 = 0;
+			continue;
+		}
+	}
+	return l0;
+}
+
+int F1(void) {
+	int l0 = 0;
+	int l1 = 0;
+	l0 = 0;
+	l1 = 0;
+	for (int l2 = 0; l2 < 4; l2++) {
+		int l3 = 0;
+		l3 = 0;
+		for (int l4 = 0; l4 < 4; l4++) {
+			for (int l5 = 0; l5 < 4; l5++) {
+				if ((l5) == (l2)) {
+					l3 = 2;
+					break;
+				}
+				if ((l5) == (l4)) {
+					l1 = 1;
+					break;
+				}
+				l0 = (l0) + (l5);
+			}
+			if ((l3) == (2)) {
+				l3 = 0;
+				continue;
+			}
+			if ((l1) != (0)) {
+				break;
+			}
+		}
+		if ((l1) == (1)) {
+			break;
+		}
+		if ((l2) == (2)) {
+			continue;
+		}
+	}
+	return l0;
+}
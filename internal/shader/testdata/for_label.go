@@ -0,0 +1,41 @@
+package main
+
+func Foo() int {
+	sum := 0
+outer:
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			if j == 2 {
+				continue outer
+			}
+			if i == 3 {
+				break outer
+			}
+			sum += j
+		}
+	}
+	return sum
+}
+
+func Bar() int {
+	sum := 0
+outer:
+	for i := 0; i < 4; i++ {
+	inner:
+		for j := 0; j < 4; j++ {
+			for k := 0; k < 4; k++ {
+				if k == i {
+					continue inner
+				}
+				if k == j {
+					break outer
+				}
+				sum += k
+			}
+		}
+		if i == 2 {
+			continue outer
+		}
+	}
+	return sum
+}
@@ -0,0 +1,46 @@
+cbuffer Uniforms : register(b0) {
+	int U0 : packoffset(c0);
+}
+
+int F0(void);
+int F1(void);
+int F2(void);
+
+int F0(void) {
+	int l0 = 0;
+	l0 = 0;
+	while ((l0) < (10)) {
+		l0 = (l0) + (3);
+	}
+	return l0;
+}
+
+int F1(void) {
+	int l0 = 0;
+	l0 = 0;
+	{
+		int l1 = 0;
+		l1 = 0;
+		while ((l1) < (U0)) {
+			if ((l1) == (2)) {
+				l1 = (l1) + (1);
+				continue;
+			}
+			l0 = (l0) + (l1);
+			l1 = (l1) + (1);
+		}
+	}
+	return l0;
+}
+
+int F2(void) {
+	int l0 = 0;
+	l0 = 1;
+	while (true) {
+		l0 = (l0) * (2);
+		if ((l0) > (100)) {
+			break;
+		}
+	}
+	return l0;
+}
@@ -0,0 +1,33 @@
+package main
+
+var N int
+
+func Foo() int {
+	i := 0
+	for i < 10 {
+		i += 3
+	}
+	return i
+}
+
+func Bar() int {
+	sum := 0
+	for i := 0; i < N; i++ {
+		if i == 2 {
+			continue
+		}
+		sum += i
+	}
+	return sum
+}
+
+func Baz() int {
+	x := 1
+	for {
+		x *= 2
+		if x > 100 {
+			break
+		}
+	}
+	return x
+}
@@ -0,0 +1,13 @@
+bool F0(in vec2 l0);
+vec4 F1(in vec2 l0);
+
+bool F0(in vec2 l0) {
+	return (((l0).x) >= (0.0)) && (((l0).y) >= (0.0));
+}
+
+vec4 F1(in vec2 l0) {
+	if (F0(l0)) {
+		return vec4(1.0);
+	}
+	return vec4(0.0);
+}
@@ -0,0 +1,12 @@
+package main
+
+func isInside(p vec2) bool {
+	return p.x >= 0 && p.y >= 0
+}
+
+func Foo(p vec2) vec4 {
+	if isInside(p) {
+		return vec4(1)
+	}
+	return vec4(0)
+}
@@ -0,0 +1,14 @@
+void F0(void);
+void F1(void);
+void F2(void);
+
+void F0(void) {
+}
+
+void F1(void) {
+}
+
+void F2(void) {
+	F0();
+	F1();
+}
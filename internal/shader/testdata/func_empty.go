@@ -0,0 +1,13 @@
+package main
+
+func Empty() {
+}
+
+func Return() {
+	return
+}
+
+func Foo() {
+	Empty()
+	Return()
+}
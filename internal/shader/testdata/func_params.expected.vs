@@ -0,0 +1,5 @@
+vec3 F0(in vec3 l0, in vec3 l1, in vec3 l2, in float l3);
+
+vec3 F0(in vec3 l0, in vec3 l1, in vec3 l2, in float l3) {
+	return ((l0) + (l1)) + ((l2) * (l3));
+}
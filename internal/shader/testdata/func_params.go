@@ -0,0 +1,5 @@
+package main
+
+func Foo(a, b, c vec3, d float) vec3 {
+	return a + b + c*d
+}
@@ -0,0 +1,11 @@
+float2 F0(void);
+
+float2 F0(void) {
+	bool l0 = false;
+	l0 = true;
+	if (l0) {
+		return (float2)(0.0);
+	} else {
+		return (float2)(1.0);
+	}
+}
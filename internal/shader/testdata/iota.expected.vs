@@ -0,0 +1,5 @@
+ivec4 F0(in int l0);
+
+ivec4 F0(in int l0) {
+	return ivec4(2, 1, 2, (l0) & (4));
+}
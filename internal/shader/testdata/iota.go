@@ -0,0 +1,17 @@
+package main
+
+const (
+	ModeA = iota
+	ModeB
+	ModeC
+)
+
+const (
+	FlagA = 1 << iota
+	FlagB
+	FlagC
+)
+
+func Foo(flags int) ivec4 {
+	return ivec4(ModeC, FlagA, FlagB, flags&FlagC)
+}
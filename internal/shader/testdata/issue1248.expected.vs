@@ -12,14 +12,14 @@ vec2 F0(void) {
 	vec2 l8 = vec2(0);
 	vec2 l9 = vec2(0);
 	l2 = l1;
-	l0 = l2;
 	l3 = l0;
+	l0 = l2;
 	l1 = l3;
 	l7 = l5;
-	l4 = l7;
 	l8 = l6;
-	l5 = l8;
 	l9 = l4;
+	l4 = l7;
+	l5 = l8;
 	l6 = l9;
 	return l0;
 }
@@ -0,0 +1,11 @@
+bool F0(in float l0, in int l1);
+
+bool F0(in float l0, in int l1) {
+	if (((l0) > (0.0)) && ((l0) < (1.0))) {
+		return true;
+	}
+	if (((l1) == (0)) || (((l0) >= (2.0)) && ((l1) != (1)))) {
+		return false;
+	}
+	return (!((l0) < (0.0))) || (((l1) > (3)) && ((l0) != (5.0)));
+}
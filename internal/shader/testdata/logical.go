@@ -0,0 +1,11 @@
+package main
+
+func Foo(x float, y int) bool {
+	if x > 0 && x < 1 {
+		return true
+	}
+	if y == 0 || (x >= 2 && y != 1) {
+		return false
+	}
+	return !(x < 0) || y > 3 && x != 5
+}
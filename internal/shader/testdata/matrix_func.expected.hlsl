@@ -0,0 +1,37 @@
+float2x2 F0(in float l0);
+float3x3 F1(in float3x3 l0, in float l1);
+void F2(in float2x2 l0, out float2 l1, out float2 l2);
+float2 F3(in float2 l0, in float l1);
+
+float2x2 F0(in float l0) {
+	float l1 = 0.0;
+	float l2 = 0.0;
+	l1 = cos(l0);
+	l2 = sin(l0);
+	return float2x2(l1, l2, -(l2), l1);
+}
+
+float3x3 F1(in float3x3 l0, in float l1) {
+	return mul(l1, l0);
+}
+
+void F2(in float2x2 l0, out float2 l1, out float2 l2) {
+	l1 = (l0)[0];
+	l2 = (l0)[1];
+	return;
+}
+
+float2 F3(in float2 l0, in float l1) {
+	float2x2 l2 = 0.0;
+	float2 l3 = 0.0;
+	float2 l4 = 0.0;
+	float2 l5 = 0.0;
+	float2 l6 = 0.0;
+	float3x3 l7 = 0.0;
+	l2 = F0(l1);
+	F2(l2, l3, l4);
+	l5 = l3;
+	l6 = l4;
+	l7 = F1(float3x3FromScalar(1.0), 2.0);
+	return (((mul(l0, l2)) + (l5)) + (l6)) + (((l7)[0]).xy);
+}
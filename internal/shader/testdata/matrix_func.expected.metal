@@ -0,0 +1,37 @@
+float2x2 F0(float l0);
+float3x3 F1(float3x3 l0, float l1);
+void F2(float2x2 l0, thread float2& l1, thread float2& l2);
+float2 F3(float2 l0, float l1);
+
+float2x2 F0(float l0) {
+	float l1 = float(0);
+	float l2 = float(0);
+	l1 = cos(l0);
+	l2 = sin(l0);
+	return float2x2(l1, l2, -(l2), l1);
+}
+
+float3x3 F1(float3x3 l0, float l1) {
+	return (l0) * (l1);
+}
+
+void F2(float2x2 l0, thread float2& l1, thread float2& l2) {
+	l1 = (l0)[0];
+	l2 = (l0)[1];
+	return;
+}
+
+float2 F3(float2 l0, float l1) {
+	float2x2 l2 = float2x2(0);
+	float2 l3 = float2(0);
+	float2 l4 = float2(0);
+	float2 l5 = float2(0);
+	float2 l6 = float2(0);
+	float3x3 l7 = float3x3(0);
+	l2 = F0(l1);
+	F2(l2, l3, l4);
+	l5 = l3;
+	l6 = l4;
+	l7 = F1(float3x3(1.0), 2.0);
+	return ((((l2) * (l0)) + (l5)) + (l6)) + (((l7)[0]).xy);
+}
@@ -0,0 +1,37 @@
+mat2 F0(in float l0);
+mat3 F1(in mat3 l0, in float l1);
+void F2(in mat2 l0, out vec2 l1, out vec2 l2);
+vec2 F3(in vec2 l0, in float l1);
+
+mat2 F0(in float l0) {
+	float l1 = float(0);
+	float l2 = float(0);
+	l1 = cos(l0);
+	l2 = sin(l0);
+	return mat2(l1, l2, -(l2), l1);
+}
+
+mat3 F1(in mat3 l0, in float l1) {
+	return (l0) * (l1);
+}
+
+void F2(in mat2 l0, out vec2 l1, out vec2 l2) {
+	l1 = (l0)[0];
+	l2 = (l0)[1];
+	return;
+}
+
+vec2 F3(in vec2 l0, in float l1) {
+	mat2 l2 = mat2(0);
+	vec2 l3 = vec2(0);
+	vec2 l4 = vec2(0);
+	vec2 l5 = vec2(0);
+	vec2 l6 = vec2(0);
+	mat3 l7 = mat3(0);
+	l2 = F0(l1);
+	F2(l2, l3, l4);
+	l5 = l3;
+	l6 = l4;
+	l7 = F1(mat3(1.0), 2.0);
+	return ((((l2) * (l0)) + (l5)) + (l6)) + (((l7)[0]).xy);
+}
@@ -0,0 +1,32 @@
+fn F0(l0: f32) -> mat2x2<f32> {
+	var l1: f32;
+	var l2: f32;
+	l1 = cos(l0);
+	l2 = sin(l0);
+	return mat2x2<f32>(l1, l2, -(l2), l1);
+}
+
+fn F1(l0: mat3x3<f32>, l1: f32) -> mat3x3<f32> {
+	return (l0) * (l1);
+}
+
+fn F2(l0: mat2x2<f32>, l1: ptr<function, vec2<f32>>, l2: ptr<function, vec2<f32>>) {
+	(*l1) = (l0)[0];
+	(*l2) = (l0)[1];
+	return;
+}
+
+fn F3(l0: vec2<f32>, l1: f32) -> vec2<f32> {
+	var l2: mat2x2<f32>;
+	var l3: vec2<f32>;
+	var l4: vec2<f32>;
+	var l5: vec2<f32>;
+	var l6: vec2<f32>;
+	var l7: mat3x3<f32>;
+	l2 = F0(l1);
+	F2(l2, &l3, &l4);
+	l5 = l3;
+	l6 = l4;
+	l7 = F1(mat3x3FromScalar(1.0), 2.0);
+	return ((((l2) * (l0)) + (l5)) + (l6)) + (((l7)[0]).xy);
+}
@@ -0,0 +1,22 @@
+package main
+
+func rotate(angle float) mat2 {
+	c := cos(angle)
+	s := sin(angle)
+	return mat2(c, s, -s, c)
+}
+
+func scale(m mat3, s float) mat3 {
+	return m * s
+}
+
+func decompose(m mat2) (vec2, vec2) {
+	return m[0], m[1]
+}
+
+func Foo(v vec2, angle float) vec2 {
+	r := rotate(angle)
+	x, y := decompose(r)
+	m := scale(mat3(1), 2)
+	return r*v + x + y + m[0].xy
+}
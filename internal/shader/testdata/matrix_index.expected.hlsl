@@ -0,0 +1,15 @@
+float3 F0(in float3x3 l0);
+float F1(in float3x3 l0);
+float4 F2(in float4x4 l0, in int l1);
+
+float3 F0(in float3x3 l0) {
+	return (l0)[1];
+}
+
+float F1(in float3x3 l0) {
+	return (((l0)[1]).y) + (((l0)[2])[0]);
+}
+
+float4 F2(in float4x4 l0, in int l1) {
+	return (l0)[l1];
+}
@@ -0,0 +1,15 @@
+vec3 F0(in mat3 l0);
+float F1(in mat3 l0);
+vec4 F2(in mat4 l0, in int l1);
+
+vec3 F0(in mat3 l0) {
+	return (l0)[1];
+}
+
+float F1(in mat3 l0) {
+	return (((l0)[1]).y) + (((l0)[2])[0]);
+}
+
+vec4 F2(in mat4 l0, in int l1) {
+	return (l0)[l1];
+}
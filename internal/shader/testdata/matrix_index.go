@@ -0,0 +1,13 @@
+package main
+
+func Column(m mat3) vec3 {
+	return m[1]
+}
+
+func Element(m mat3) float {
+	return m[1].y + m[2][0]
+}
+
+func DynamicColumn(m mat4, i int) vec4 {
+	return m[i]
+}
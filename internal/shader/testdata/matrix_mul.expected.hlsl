@@ -0,0 +1,17 @@
+float4 F0(in float4 l0, in float3 l1);
+float4x4 F1(in float4x4 l0, in float4x4 l1);
+float4 F2(in float4 l0, in float4x4 l1);
+
+float4 F0(in float4 l0, in float3 l1) {
+	float4x4 l2 = 0.0;
+	l2 = float4x4(1.0, 0.0, 0.0, 0.0, 0.0, 1.0, 0.0, 0.0, 0.0, 0.0, 1.0, 0.0, (l1).x, (l1).y, (l1).z, 1.0);
+	return mul(l0, l2);
+}
+
+float4x4 F1(in float4x4 l0, in float4x4 l1) {
+	return mul(l1, l0);
+}
+
+float4 F2(in float4 l0, in float4x4 l1) {
+	return mul(l1, l0);
+}
@@ -0,0 +1,17 @@
+vec4 F0(in vec4 l0, in vec3 l1);
+mat4 F1(in mat4 l0, in mat4 l1);
+vec4 F2(in vec4 l0, in mat4 l1);
+
+vec4 F0(in vec4 l0, in vec3 l1) {
+	mat4 l2 = mat4(0);
+	l2 = mat4(1.0, 0.0, 0.0, 0.0, 0.0, 1.0, 0.0, 0.0, 0.0, 0.0, 1.0, 0.0, (l1).x, (l1).y, (l1).z, 1.0);
+	return (l2) * (l0);
+}
+
+mat4 F1(in mat4 l0, in mat4 l1) {
+	return (l0) * (l1);
+}
+
+vec4 F2(in vec4 l0, in mat4 l1) {
+	return (l0) * (l1);
+}
@@ -0,0 +1,20 @@
+package main
+
+func Translate(v vec4, d vec3) vec4 {
+	// The matrix is column-major. The last column is the translation.
+	m := mat4(
+		1, 0, 0, 0,
+		0, 1, 0, 0,
+		0, 0, 1, 0,
+		d.x, d.y, d.z, 1,
+	)
+	return m * v
+}
+
+func Compose(a, b mat4) mat4 {
+	return a * b
+}
+
+func RowVector(v vec4, m mat4) vec4 {
+	return v * m
+}
@@ -0,0 +1,15 @@
+float3 F0(in float3 l0, in float l1);
+
+float3 F0(in float3 l0, in float l1) {
+	float3 l2 = 0.0;
+	float3 l3 = 0.0;
+	float3 l4 = 0.0;
+	float3 l5 = 0.0;
+	float3 l6 = 0.0;
+	l2 = clamp(l0, 0.0, 1.0);
+	l3 = clamp((float3)(l1), (float3)(0.0), (float3)(1.0));
+	l4 = clamp(l0, (float3)(0.0), (float3)(l1));
+	l5 = min((float3)(l1), l0);
+	l6 = max(l0, l1);
+	return ((((l2) + (l3)) + (l4)) + (l5)) + (l6);
+}
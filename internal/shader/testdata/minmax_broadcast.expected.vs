@@ -0,0 +1,15 @@
+vec3 F0(in vec3 l0, in float l1);
+
+vec3 F0(in vec3 l0, in float l1) {
+	vec3 l2 = vec3(0);
+	vec3 l3 = vec3(0);
+	vec3 l4 = vec3(0);
+	vec3 l5 = vec3(0);
+	vec3 l6 = vec3(0);
+	l2 = clamp(l0, 0.0, 1.0);
+	l3 = clamp(vec3(l1), vec3(0.0), vec3(1.0));
+	l4 = clamp(l0, vec3(0.0), vec3(l1));
+	l5 = min(vec3(l1), l0);
+	l6 = max(l0, l1);
+	return ((((l2) + (l3)) + (l4)) + (l5)) + (l6);
+}
@@ -0,0 +1,10 @@
+package main
+
+func Foo(v vec3, f float) vec3 {
+	a := clamp(v, 0.0, 1.0)
+	b := clamp(f, vec3(0), vec3(1))
+	c := clamp(v, vec3(0), f)
+	d := min(f, v)
+	e := max(v, f)
+	return a + b + c + d + e
+}
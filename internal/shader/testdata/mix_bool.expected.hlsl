@@ -0,0 +1,11 @@
+float4 F0(in float l0, in float3 l1);
+
+float4 F0(in float l0, in float3 l1) {
+	float l2 = 0.0;
+	float3 l3 = 0.0;
+	float3 l4 = 0.0;
+	l2 = ((l0) > (5.0000000000e-01)) ? (1.0) : (0.0);
+	l3 = ((l0) < (0.0)) ? ((float3)(1.0)) : (l1);
+	l4 = (float3)(1.0);
+	return float4(l2, (l3).x, (l4).y, 1.0);
+}
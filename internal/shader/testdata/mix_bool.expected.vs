@@ -0,0 +1,11 @@
+vec4 F0(in float l0, in vec3 l1);
+
+vec4 F0(in float l0, in vec3 l1) {
+	float l2 = float(0);
+	vec3 l3 = vec3(0);
+	vec3 l4 = vec3(0);
+	l2 = ((l0) > (5.0000000000e-01)) ? (1.0) : (0.0);
+	l3 = ((l0) < (0.0)) ? (vec3(1.0)) : (l1);
+	l4 = vec3(1.0);
+	return vec4(l2, (l3).x, (l4).y, 1.0);
+}
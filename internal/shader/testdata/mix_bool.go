@@ -0,0 +1,8 @@
+package main
+
+func Foo(x float, v vec3) vec4 {
+	a := mix(0.0, 1.0, x > 0.5)
+	b := mix(v, vec3(1), x < 0)
+	c := mix(v, vec3(1), true)
+	return vec4(a, b.x, c.y, 1)
+}
@@ -0,0 +1,12 @@
+float3 F0(in float3 l0, in float3 l1, in bool3 l2);
+int2 F1(in int2 l0, in int2 l1);
+
+float3 F0(in float3 l0, in float3 l1, in bool3 l2) {
+	return float3(((l2).x) ? ((l1).x) : ((l0).x), ((l2).y) ? ((l1).y) : ((l0).y), ((l2).z) ? ((l1).z) : ((l0).z));
+}
+
+int2 F1(in int2 l0, in int2 l1) {
+	bool2 l2 = false;
+	l2 = bool2(true, false);
+	return int2(((l2).x) ? ((l1).x) : ((l0).x), ((l2).y) ? ((l1).y) : ((l0).y));
+}
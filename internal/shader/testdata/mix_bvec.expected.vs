@@ -0,0 +1,12 @@
+vec3 F0(in vec3 l0, in vec3 l1, in bvec3 l2);
+ivec2 F1(in ivec2 l0, in ivec2 l1);
+
+vec3 F0(in vec3 l0, in vec3 l1, in bvec3 l2) {
+	return vec3(((l2).x) ? ((l1).x) : ((l0).x), ((l2).y) ? ((l1).y) : ((l0).y), ((l2).z) ? ((l1).z) : ((l0).z));
+}
+
+ivec2 F1(in ivec2 l0, in ivec2 l1) {
+	bvec2 l2 = bvec2(false);
+	l2 = bvec2(true, false);
+	return ivec2(((l2).x) ? ((l1).x) : ((l0).x), ((l2).y) ? ((l1).y) : ((l0).y));
+}
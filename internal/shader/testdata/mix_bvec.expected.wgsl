@@ -0,0 +1,9 @@
+fn F0(l0: vec3<f32>, l1: vec3<f32>, l2: vec3<bool>) -> vec3<f32> {
+	return vec3<f32>(select((l0).x, (l1).x, (l2).x), select((l0).y, (l1).y, (l2).y), select((l0).z, (l1).z, (l2).z));
+}
+
+fn F1(l0: vec2<i32>, l1: vec2<i32>) -> vec2<i32> {
+	var l2: vec2<bool>;
+	l2 = vec2<bool>(true, false);
+	return vec2<i32>(select((l0).x, (l1).x, (l2).x), select((l0).y, (l1).y, (l2).y));
+}
@@ -0,0 +1,9 @@
+package main
+
+func Foo(x, y vec3, a bvec3) vec3 {
+	return mix(x, y, a)
+}
+
+func Bar(x, y ivec2) ivec2 {
+	return mix(x, y, bvec2(true, false))
+}
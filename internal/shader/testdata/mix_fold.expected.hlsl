@@ -0,0 +1,11 @@
+float2 F0(in float l0);
+
+float2 F0(in float l0) {
+	float l1 = 0.0;
+	float l2 = 0.0;
+	float l3 = 0.0;
+	l1 = 1.5000000000e+00;
+	l2 = 5.0000000000e-01;
+	l3 = lerp(1.0, 3.0, l0);
+	return float2((l1) + (l2), l3);
+}
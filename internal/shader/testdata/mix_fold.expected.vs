@@ -0,0 +1,11 @@
+vec2 F0(in float l0);
+
+vec2 F0(in float l0) {
+	float l1 = float(0);
+	float l2 = float(0);
+	float l3 = float(0);
+	l1 = 1.5000000000e+00;
+	l2 = 5.0000000000e-01;
+	l3 = mix(1.0, 3.0, l0);
+	return vec2((l1) + (l2), l3);
+}
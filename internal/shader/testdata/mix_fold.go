@@ -0,0 +1,8 @@
+package main
+
+func Foo(t float) vec2 {
+	a := mix(1.0, 3.0, 0.25)
+	b := mix(0, 1, 0.5)
+	c := mix(1.0, 3.0, t)
+	return vec2(a+b, c)
+}
@@ -0,0 +1,19 @@
+void F0(float l0, float l1, float2 l2, thread float& l3, thread float2& l4, thread float2& l5, thread int& l6);
+
+void F0(float l0, float l1, float2 l2, thread float& l3, thread float2& l4, thread float2& l5, thread int& l6) {
+	float l7 = float(0);
+	float2 l8 = float2(0);
+	float2 l9 = float2(0);
+	int l10 = 0;
+	l7 = mod(l0, l1);
+	l8 = mod(l2, 2.0);
+	l9 = mod(l2, float2(l0, l1));
+	l9 = mod(l9, 5.0000000000e-01);
+	l10 = static_cast<int>(l0);
+	l10 = (l10) % (3);
+	l3 = l7;
+	l4 = l8;
+	l5 = l9;
+	l6 = (l10) % (2);
+	return;
+}
@@ -0,0 +1,19 @@
+void F0(in float l0, in float l1, in vec2 l2, out float l3, out vec2 l4, out vec2 l5, out int l6);
+
+void F0(in float l0, in float l1, in vec2 l2, out float l3, out vec2 l4, out vec2 l5, out int l6) {
+	float l7 = float(0);
+	vec2 l8 = vec2(0);
+	vec2 l9 = vec2(0);
+	int l10 = 0;
+	l7 = mod(l0, l1);
+	l8 = mod(l2, 2.0);
+	l9 = mod(l2, vec2(l0, l1));
+	l9 = mod(l9, 5.0000000000e-01);
+	l10 = int(l0);
+	l10 = modInt((l10), (3));
+	l3 = l7;
+	l4 = l8;
+	l5 = l9;
+	l6 = modInt((l10), (2));
+	return;
+}
@@ -0,0 +1,11 @@
+package main
+
+func Foo(x, y float, v vec2) (float, vec2, vec2, int) {
+	a := x % y
+	b := v % 2
+	c := v % vec2(x, y)
+	c %= 0.5
+	i := int(x)
+	i %= 3
+	return a, b, c, i % 2
+}
@@ -0,0 +1,7 @@
+void F0(in vec3 l0, in float l1, out vec3 l2, out vec3 l3);
+
+void F0(in vec3 l0, in float l1, out vec3 l2, out vec3 l3) {
+	l2 = pow(l0, vec3(4.5454545455e-01));
+	l3 = pow(l0, vec3(l1));
+	return;
+}
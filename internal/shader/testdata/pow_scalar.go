@@ -0,0 +1,5 @@
+package main
+
+func Foo(c vec3, e float) (vec3, vec3) {
+	return pow(c, 1.0/2.2), pow(c, e)
+}
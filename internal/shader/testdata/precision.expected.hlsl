@@ -0,0 +1,15 @@
+float4 F0(in float4 l0);
+
+float4 F0(in float4 l0) {
+	float4 l1 = 0.0;
+	float l2 = 0.0;
+	int l3 = 0;
+	float4 l4 = 0.0;
+	float l5 = 0.0;
+	l1 = l0;
+	l2 = 1.0;
+	l3 = 2;
+	l4 = (l1) * (l2);
+	l5 = 3.0;
+	return ((l4) * (l5)) * (float(l3));
+}
@@ -0,0 +1,15 @@
+vec4 F0(in vec4 l0);
+
+vec4 F0(in vec4 l0) {
+	mediump vec4 l1 = vec4(0);
+	lowp float l2 = float(0);
+	highp int l3 = 0;
+	vec4 l4 = vec4(0);
+	mediump float l5 = float(0);
+	l1 = l0;
+	l2 = 1.0;
+	l3 = 2;
+	l4 = (l1) * (l2);
+	l5 = 3.0;
+	return ((l4) * (l5)) * (float(l3));
+}
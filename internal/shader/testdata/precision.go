@@ -0,0 +1,11 @@
+package main
+
+func Foo(x vec4) vec4 {
+	a := mediump(x)
+	b := lowp(1.0)
+	c := highp(2)
+	// The precision is not inferred from the expressions.
+	d := a * b
+	var e float = mediump(3.0)
+	return d * e * float(c)
+}
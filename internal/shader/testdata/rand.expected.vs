@@ -0,0 +1,7 @@
+void F0(in vec2 l0, in vec3 l1, out float l2, out float l3);
+
+void F0(in vec2 l0, in vec3 l1, out float l2, out float l3) {
+	l2 = fract((sin(dot(l0, vec2(1.2989800000e+01, 7.8233000000e+01)))) * (4.3758545300e+04));
+	l3 = fract((sin(dot(l1, vec3(1.2989800000e+01, 7.8233000000e+01, 3.7719000000e+01)))) * (4.3758545300e+04));
+	return;
+}
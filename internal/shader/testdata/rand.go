@@ -0,0 +1,5 @@
+package main
+
+func Foo(p vec2, q vec3) (float, float) {
+	return rand(p), rand(q)
+}
@@ -0,0 +1,29 @@
+void F0(in float l0, out float3 l1, out float l2);
+void F1(in float l0, out float3 l1, out float l2);
+float4 F2(void);
+
+void F0(in float l0, out float3 l1, out float l2) {
+	l1 = (float3)(l0);
+	l2 = (l0) * (2.0);
+	return;
+}
+
+void F1(in float l0, out float3 l1, out float l2) {
+	float3 l3 = 0.0;
+	float l4 = 0.0;
+	F0(l0, l3, l4);
+	l1 = l3;
+	l2 = l4;
+	return;
+}
+
+float4 F2(void) {
+	float3 l0 = 0.0;
+	float l1 = 0.0;
+	float3 l2 = 0.0;
+	float l3 = 0.0;
+	F1(1.0, l0, l1);
+	l2 = l0;
+	l3 = l1;
+	return float4(l2, l3);
+}
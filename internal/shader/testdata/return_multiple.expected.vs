@@ -0,0 +1,29 @@
+void F0(in float l0, out vec3 l1, out float l2);
+void F1(in float l0, out vec3 l1, out float l2);
+vec4 F2(void);
+
+void F0(in float l0, out vec3 l1, out float l2) {
+	l1 = vec3(l0);
+	l2 = (l0) * (2.0);
+	return;
+}
+
+void F1(in float l0, out vec3 l1, out float l2) {
+	vec3 l3 = vec3(0);
+	float l4 = float(0);
+	F0(l0, l3, l4);
+	l1 = l3;
+	l2 = l4;
+	return;
+}
+
+vec4 F2(void) {
+	vec3 l0 = vec3(0);
+	float l1 = float(0);
+	vec3 l2 = vec3(0);
+	float l3 = float(0);
+	F1(1.0, l0, l1);
+	l2 = l0;
+	l3 = l1;
+	return vec4(l2, l3);
+}
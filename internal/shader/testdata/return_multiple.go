@@ -0,0 +1,14 @@
+package main
+
+func Foo(x float) (vec3, float) {
+	return vec3(x), x * 2
+}
+
+func Bar(x float) (vec3, float) {
+	return Foo(x)
+}
+
+func Baz() vec4 {
+	a, b := Bar(1)
+	return vec4(a, b)
+}
@@ -0,0 +1,5 @@
+float3 F0(in float3 l0);
+
+float3 F0(in float3 l0) {
+	return smoothstep(0.0, 1.0, l0);
+}
@@ -0,0 +1,5 @@
+vec3 F0(in vec3 l0);
+
+vec3 F0(in vec3 l0) {
+	return smoothstep(0.0, 1.0, l0);
+}
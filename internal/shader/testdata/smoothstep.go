@@ -0,0 +1,5 @@
+package main
+
+func Foo(v vec3) vec3 {
+	return smoothstep(0.0, 1.0, v)
+}
@@ -0,0 +1,10 @@
+void F0(float3 l0, float4 l1, thread float3& l2, thread float4& l3, thread float4& l4);
+
+void F0(float3 l0, float4 l1, thread float3& l2, thread float4& l3, thread float4& l4) {
+	float4 l5 = float4(0);
+	l5 = (l1) * (2.0);
+	l2 = mix(((1.0550000000e+00) * (pow(max(l0, float3(0.0)), float3(4.1666666667e-01)))) - (5.5000000000e-02), (l0) * (1.2920000000e+01), step(l0, float3(3.1308000000e-03)));
+	l3 = float4(mix(pow(((max((l1).rgb, float3(0.0))) + (5.5000000000e-02)) / (1.0550000000e+00), float3(2.4000000000e+00)), ((l1).rgb) / (1.2920000000e+01), step((l1).rgb, float3(4.0450000000e-02))), (l1).a);
+	l4 = float4(mix(((1.0550000000e+00) * (pow(max((l5).rgb, float3(0.0)), float3(4.1666666667e-01)))) - (5.5000000000e-02), ((l5).rgb) * (1.2920000000e+01), step((l5).rgb, float3(3.1308000000e-03))), (l5).a);
+	return;
+}
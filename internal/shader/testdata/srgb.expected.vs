@@ -0,0 +1,10 @@
+void F0(in vec3 l0, in vec4 l1, out vec3 l2, out vec4 l3, out vec4 l4);
+
+void F0(in vec3 l0, in vec4 l1, out vec3 l2, out vec4 l3, out vec4 l4) {
+	vec4 l5 = vec4(0);
+	l5 = (l1) * (2.0);
+	l2 = mix(((1.0550000000e+00) * (pow(max(l0, vec3(0.0)), vec3(4.1666666667e-01)))) - (5.5000000000e-02), (l0) * (1.2920000000e+01), step(l0, vec3(3.1308000000e-03)));
+	l3 = vec4(mix(pow(((max((l1).rgb, vec3(0.0))) + (5.5000000000e-02)) / (1.0550000000e+00), vec3(2.4000000000e+00)), ((l1).rgb) / (1.2920000000e+01), step((l1).rgb, vec3(4.0450000000e-02))), (l1).a);
+	l4 = vec4(mix(((1.0550000000e+00) * (pow(max((l5).rgb, vec3(0.0)), vec3(4.1666666667e-01)))) - (5.5000000000e-02), ((l5).rgb) * (1.2920000000e+01), step((l5).rgb, vec3(3.1308000000e-03))), (l5).a);
+	return;
+}
@@ -0,0 +1,5 @@
+package main
+
+func Foo(c vec3, d vec4) (vec3, vec4, vec4) {
+	return linearToSRGB(c), srgbToLinear(d), linearToSRGB(d * 2)
+}
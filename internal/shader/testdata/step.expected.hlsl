@@ -0,0 +1,23 @@
+void F0(in float3 l0, in float l1, out float l2, out float l3, out float3 l4, out float3 l5, out float3 l6, out float3 l7);
+
+void F0(in float3 l0, in float l1, out float l2, out float l3, out float3 l4, out float3 l5, out float3 l6, out float3 l7) {
+	float l8 = 0.0;
+	float l9 = 0.0;
+	float3 l10 = 0.0;
+	float3 l11 = 0.0;
+	float3 l12 = 0.0;
+	float3 l13 = 0.0;
+	l8 = step(5.0000000000e-01, l1);
+	l9 = smoothstep(0.0, 1.0, l1);
+	l10 = step((float3)(5.0000000000e-01), l0);
+	l11 = step(5.0000000000e-01, l0);
+	l12 = smoothstep(0.0, 1.0, l0);
+	l13 = smoothstep((float3)(0.0), (float3)(1.0), l0);
+	l2 = l8;
+	l3 = l9;
+	l4 = l10;
+	l5 = l11;
+	l6 = l12;
+	l7 = l13;
+	return;
+}
@@ -0,0 +1,23 @@
+void F0(float3 l0, float l1, thread float& l2, thread float& l3, thread float3& l4, thread float3& l5, thread float3& l6, thread float3& l7);
+
+void F0(float3 l0, float l1, thread float& l2, thread float& l3, thread float3& l4, thread float3& l5, thread float3& l6, thread float3& l7) {
+	float l8 = float(0);
+	float l9 = float(0);
+	float3 l10 = float3(0);
+	float3 l11 = float3(0);
+	float3 l12 = float3(0);
+	float3 l13 = float3(0);
+	l8 = step(5.0000000000e-01, l1);
+	l9 = smoothstep(0.0, 1.0, l1);
+	l10 = step(float3(5.0000000000e-01), l0);
+	l11 = step(5.0000000000e-01, l0);
+	l12 = smoothstep(0.0, 1.0, l0);
+	l13 = smoothstep(float3(0.0), float3(1.0), l0);
+	l2 = l8;
+	l3 = l9;
+	l4 = l10;
+	l5 = l11;
+	l6 = l12;
+	l7 = l13;
+	return;
+}
@@ -0,0 +1,23 @@
+void F0(in vec3 l0, in float l1, out float l2, out float l3, out vec3 l4, out vec3 l5, out vec3 l6, out vec3 l7);
+
+void F0(in vec3 l0, in float l1, out float l2, out float l3, out vec3 l4, out vec3 l5, out vec3 l6, out vec3 l7) {
+	float l8 = float(0);
+	float l9 = float(0);
+	vec3 l10 = vec3(0);
+	vec3 l11 = vec3(0);
+	vec3 l12 = vec3(0);
+	vec3 l13 = vec3(0);
+	l8 = step(5.0000000000e-01, l1);
+	l9 = smoothstep(0.0, 1.0, l1);
+	l10 = step(vec3(5.0000000000e-01), l0);
+	l11 = step(5.0000000000e-01, l0);
+	l12 = smoothstep(0.0, 1.0, l0);
+	l13 = smoothstep(vec3(0.0), vec3(1.0), l0);
+	l2 = l8;
+	l3 = l9;
+	l4 = l10;
+	l5 = l11;
+	l6 = l12;
+	l7 = l13;
+	return;
+}
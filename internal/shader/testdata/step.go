@@ -0,0 +1,11 @@
+package main
+
+func Foo(v vec3, f float) (float, float, vec3, vec3, vec3, vec3) {
+	a := step(0.5, f)
+	b := smoothstep(0, 1, f)
+	c := step(vec3(0.5), v)
+	d := step(0.5, v)
+	e := smoothstep(0, 1, v)
+	g := smoothstep(vec3(0), 1, v)
+	return a, b, c, d, e, g
+}
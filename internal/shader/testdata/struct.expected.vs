@@ -0,0 +1,29 @@
+struct S0 {
+	vec3 M0;
+	vec3 M1;
+};
+struct S1 {
+	vec3 M0;
+	float M1;
+	S0 M2;
+};
+
+vec3 F0(in S1 l0);
+S1 F1(in vec3 l0);
+
+vec3 F0(in S1 l0) {
+	(l0).M1 = (1.0) - ((l0).M1);
+	return (((l0).M0) * ((l0).M1)) * (((l0).M2).M1);
+}
+
+S1 F1(in vec3 l0) {
+	S1 l1 = S1(vec3(0), float(0), S0(vec3(0), vec3(0)));
+	S1 l2 = S1(vec3(0), float(0), S0(vec3(0), vec3(0)));
+	S0 l3 = S0(vec3(0), vec3(0));
+	(l1).M0 = l0;
+	(l2).M0 = l0;
+	(l3).M0 = vec3(1.0);
+	(l3).M1 = vec3(5.0000000000e-01);
+	(l2).M2 = l3;
+	return l2;
+}
@@ -0,0 +1,27 @@
+struct S0 {
+	M0: vec3<f32>,
+	M1: vec3<f32>,
+}
+struct S1 {
+	M0: vec3<f32>,
+	M1: f32,
+	M2: S0,
+}
+
+fn F0(p0: S1) -> vec3<f32> {
+	var l0: S1 = p0;
+	(l0).M1 = (1.0) - ((l0).M1);
+	return (((l0).M0) * ((l0).M1)) * (((l0).M2).M1);
+}
+
+fn F1(l0: vec3<f32>) -> S1 {
+	var l1: S1;
+	var l2: S1;
+	var l3: S0;
+	(l1).M0 = l0;
+	(l2).M0 = l0;
+	(l3).M0 = vec3<f32>(1.0);
+	(l3).M1 = vec3<f32>(5.0000000000e-01);
+	(l2).M2 = l3;
+	return l2;
+}
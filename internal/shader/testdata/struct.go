@@ -0,0 +1,23 @@
+package main
+
+type Light struct {
+	Pos   vec3
+	Color vec3
+}
+
+type Material struct {
+	Albedo vec3
+	Rough  float
+	Light  Light
+}
+
+func Foo(m Material) vec3 {
+	m.Rough = 1 - m.Rough
+	return m.Albedo * m.Rough * m.Light.Color
+}
+
+func Bar(albedo vec3) Material {
+	var m Material
+	m.Albedo = albedo
+	return Material{Albedo: albedo, Light: Light{vec3(1), vec3(0.5)}}
+}
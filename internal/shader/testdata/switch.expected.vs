@@ -0,0 +1,21 @@
+vec4 F0(in int l0);
+
+vec4 F0(in int l0) {
+	vec4 l1 = vec4(0);
+	int l2 = 0;
+	l1 = vec4(0.0);
+	if ((l0) == (0)) {
+		l1 = vec4(1.0, 0.0, 0.0, 1.0);
+	} else {
+		if ((((l0) == (1)) || ((l0) == (2))) || ((l0) == (3))) {
+			l1 = vec4(0.0, 1.0, 0.0, 1.0);
+		} else {
+			l1 = vec4(0.0, 0.0, 1.0, 1.0);
+		}
+	}
+	l2 = (l0) * (2);
+	if ((l2) == (4)) {
+		(l1).a = 5.0000000000e-01;
+	}
+	return l1;
+}
@@ -0,0 +1,18 @@
+package main
+
+func Foo(mode int) vec4 {
+	c := vec4(0)
+	switch mode {
+	case 0:
+		c = vec4(1, 0, 0, 1)
+	case 1, 2, 3:
+		c = vec4(0, 1, 0, 1)
+	default:
+		c = vec4(0, 0, 1, 1)
+	}
+	switch mode * 2 {
+	case 4:
+		c.a = 0.5
+	}
+	return c
+}
@@ -0,0 +1,34 @@
+int F0(in int l0, in int l1);
+
+int F0(in int l0, in int l1) {
+	int l2 = 0;
+	int l3 = 0;
+	bool l4 = false;
+	l2 = 0;
+	l3 = l0;
+	l4 = false;
+	if ((l3) == (0)) {
+		l2 = (l2) + (1);
+		l4 = true;
+	}
+	if ((l4) || ((l3) == (1))) {
+		int l5 = 0;
+		bool l6 = false;
+		l4 = false;
+		l2 = (l2) + (2);
+		l5 = l1;
+		l6 = false;
+		if ((l5) == (0)) {
+			l2 = (l2) * (2);
+			l6 = true;
+		}
+		if ((l6) || (!((l5) == (0)))) {
+			l6 = false;
+			l2 = (l2) + (3);
+		}
+	}
+	if (!(((l3) == (0)) || ((l3) == (1)))) {
+		l2 = -1;
+	}
+	return l2;
+}
@@ -0,0 +1,22 @@
+package main
+
+func Foo(mode int, kind int) int {
+	x := 0
+	switch mode {
+	case 0:
+		x += 1
+		fallthrough
+	case 1:
+		x += 2
+		switch kind {
+		case 0:
+			x *= 2
+			fallthrough
+		default:
+			x += 3
+		}
+	default:
+		x = -1
+	}
+	return x
+}
@@ -0,0 +1,25 @@
+vec4 F0(in int l0);
+vec4 F1(in int l0);
+
+vec4 F0(in int l0) {
+	if ((l0) == (0)) {
+		return vec4(1.0, 0.0, 0.0, 1.0);
+	} else {
+		if ((l0) == (1)) {
+			return vec4(0.0, 1.0, 0.0, 1.0);
+		} else {
+			return vec4(0.0, 0.0, 1.0, 1.0);
+		}
+	}
+}
+
+vec4 F1(in int l0) {
+	if ((l0) == (0)) {
+		return vec4(1.0, 0.0, 0.0, 1.0);
+	} else {
+		if (((l0) == (1)) || ((l0) == (2))) {
+			return vec4(0.0, 1.0, 0.0, 1.0);
+		}
+	}
+	return vec4(0.0);
+}
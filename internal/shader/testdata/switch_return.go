@@ -0,0 +1,22 @@
+package main
+
+func Foo(mode int) vec4 {
+	switch mode {
+	case 0:
+		return vec4(1, 0, 0, 1)
+	case 1:
+		return vec4(0, 1, 0, 1)
+	default:
+		return vec4(0, 0, 1, 1)
+	}
+}
+
+func Bar(mode int) vec4 {
+	switch mode {
+	case 0:
+		return vec4(1, 0, 0, 1)
+	case 1, 2:
+		return vec4(0, 1, 0, 1)
+	}
+	return vec4(0)
+}
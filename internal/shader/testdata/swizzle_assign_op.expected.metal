@@ -0,0 +1,23 @@
+int F0(int l0);
+float4 F1(int l0);
+
+int F0(int l0) {
+	return (l0) + (1);
+}
+
+float4 F1(int l0) {
+	float4 l1 = float4(0);
+	array<float4, 3> l2 = {};
+	int l3 = 0;
+	array<int, 3> l4 = {};
+	int l5 = 0;
+	(l1).rgb = ((l1).rgb) + (float3(1.0));
+	(l1).xy = ((l1).xy) * (2.0);
+	(l1).a = ((l1).a) - ((l1).r);
+	((l2)[l0]).zw = (((l2)[l0]).zw) / (float2(2.0));
+	l3 = F0(l0);
+	((l2)[l3]).xy = (((l2)[l3]).xy) + ((l1).xy);
+	l5 = F0(l0);
+	(l4)[l5] = ((l4)[l5]) + (1);
+	return ((l1) + ((l2)[0])) + (float4(static_cast<float>((l4)[0])));
+}
@@ -0,0 +1,29 @@
+int F0(in int l0);
+vec4 F1(in int l0);
+
+int F0(in int l0) {
+	return (l0) + (1);
+}
+
+vec4 F1(in int l0) {
+	vec4 l1 = vec4(0);
+	vec4 l2[3];
+	l2[0] = vec4(0);
+	l2[1] = vec4(0);
+	l2[2] = vec4(0);
+	int l3 = 0;
+	int l4[3];
+	l4[0] = 0;
+	l4[1] = 0;
+	l4[2] = 0;
+	int l5 = 0;
+	(l1).rgb = ((l1).rgb) + (vec3(1.0));
+	(l1).xy = ((l1).xy) * (2.0);
+	(l1).a = ((l1).a) - ((l1).r);
+	((l2)[l0]).zw = (((l2)[l0]).zw) / (vec2(2.0));
+	l3 = F0(l0);
+	((l2)[l3]).xy = (((l2)[l3]).xy) + ((l1).xy);
+	l5 = F0(l0);
+	(l4)[l5] = ((l4)[l5]) + (1);
+	return ((l1) + ((l2)[0])) + (vec4(float((l4)[0])));
+}
@@ -0,0 +1,37 @@
+fn F0(l0: i32) -> i32 {
+	return (l0) + (1);
+}
+
+fn F1(l0: i32) -> vec4<f32> {
+	var l1: vec4<f32>;
+	var l2: array<vec4<f32>, 3>;
+	var l3: i32;
+	var l4: array<i32, 3>;
+	var l5: i32;
+	{
+		let swizzled = ((l1).rgb) + (vec3<f32>(1.0));
+		(l1).r = swizzled.x;
+		(l1).g = swizzled.y;
+		(l1).b = swizzled.z;
+	}
+	{
+		let swizzled = ((l1).xy) * (2.0);
+		(l1).x = swizzled.x;
+		(l1).y = swizzled.y;
+	}
+	(l1).a = ((l1).a) - ((l1).r);
+	{
+		let swizzled = (((l2)[l0]).zw) / (vec2<f32>(2.0));
+		((l2)[l0]).z = swizzled.x;
+		((l2)[l0]).w = swizzled.y;
+	}
+	l3 = F0(l0);
+	{
+		let swizzled = (((l2)[l3]).xy) + ((l1).xy);
+		((l2)[l3]).x = swizzled.x;
+		((l2)[l3]).y = swizzled.y;
+	}
+	l5 = F0(l0);
+	(l4)[l5] = ((l4)[l5]) + (1);
+	return ((l1) + ((l2)[0])) + (vec4<f32>(f32((l4)[0])));
+}
@@ -0,0 +1,20 @@
+package main
+
+func Index(i int) int {
+	return i + 1
+}
+
+func Foo(i int) vec4 {
+	var c vec4
+	c.rgb += vec3(1)
+	c.xy *= 2
+	c.a -= c.r
+
+	var a [3]vec4
+	a[i].zw /= vec2(2)
+	a[Index(i)].xy += c.xy
+
+	var b [3]int
+	b[Index(i)]++
+	return c + a[0] + vec4(float(b[0]))
+}
@@ -0,0 +1,10 @@
+void F0(in vec3 l0, in mat2 l1, in ivec2 l2, out vec3 l3, out vec3 l4, out mat2 l5, out ivec2 l6, out ivec3 l7);
+
+void F0(in vec3 l0, in mat2 l1, in ivec2 l2, out vec3 l3, out vec3 l4, out mat2 l5, out ivec2 l6, out ivec3 l7) {
+	l3 = -(l0);
+	l4 = vec3(-1.0, -2.0, -3.0);
+	l5 = -(l1);
+	l6 = -(l2);
+	l7 = ivec3(-1, 2, -3);
+	return;
+}
@@ -0,0 +1,5 @@
+package main
+
+func Foo(x vec3, m mat2, i ivec2) (vec3, vec3, mat2, ivec2, ivec3) {
+	return -x, -vec3(1, 2, 3), -m, -i, -ivec3(1, -2, 3)
+}
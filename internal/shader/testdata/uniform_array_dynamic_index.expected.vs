@@ -0,0 +1,21 @@
+uniform vec3 U0[8];
+uniform int U1;
+
+float F0(in vec3 l0);
+
+float F0(in vec3 l0) {
+	float l1 = float(0);
+	l1 = 0.0;
+	for (int l2 = 0; l2 < 8; l2++) {
+		l1 = (l1) + (distance((U0)[l2], l0));
+	}
+	{
+		int l3 = 0;
+		l3 = 0;
+		while ((l3) < (U1)) {
+			l1 = (l1) + (length((U0)[l3]));
+			l3 = (l3) + (1);
+		}
+	}
+	return (l1) + (((U0)[(U1) - (1)]).x);
+}
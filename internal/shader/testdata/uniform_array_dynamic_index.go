@@ -0,0 +1,15 @@
+package main
+
+var Lights [8]vec3
+var Count int
+
+func Foo(pos vec3) float {
+	sum := 0.0
+	for i := 0; i < 8; i++ {
+		sum += distance(Lights[i], pos)
+	}
+	for i := 0; i < Count; i++ {
+		sum += length(Lights[i])
+	}
+	return sum + Lights[Count-1].x
+}
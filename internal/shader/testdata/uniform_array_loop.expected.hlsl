@@ -0,0 +1,14 @@
+cbuffer Uniforms : register(b0) {
+	float4 U0[4] : packoffset(c0);
+}
+
+float4 F0(void);
+
+float4 F0(void) {
+	float4 l0 = 0.0;
+	l0 = (float4)(0.0);
+	for (int l1 = 0; l1 < 4; l1++) {
+		l0 = (l0) + ((U0)[l1]);
+	}
+	return l0;
+}
@@ -0,0 +1,10 @@
+float4 F0(constant array<float4, 4>& U0);
+
+float4 F0(constant array<float4, 4>& U0) {
+	float4 l0 = float4(0);
+	l0 = float4(0.0);
+	for (int l1 = 0; l1 < 4; l1++) {
+		l0 = (l0) + ((U0)[l1]);
+	}
+	return l0;
+}
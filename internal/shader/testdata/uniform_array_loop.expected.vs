@@ -0,0 +1,12 @@
+uniform vec4 U0[4];
+
+vec4 F0(void);
+
+vec4 F0(void) {
+	vec4 l0 = vec4(0);
+	l0 = vec4(0.0);
+	for (int l1 = 0; l1 < 4; l1++) {
+		l0 = (l0) + ((U0)[l1]);
+	}
+	return l0;
+}
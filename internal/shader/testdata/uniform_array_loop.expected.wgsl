@@ -0,0 +1,14 @@
+struct Uniforms {
+	U0: array<vec4<f32>, 4>,
+}
+
+@group(0) @binding(0) var<storage, read> uniforms: Uniforms;
+
+fn F0() -> vec4<f32> {
+	var l0: vec4<f32>;
+	l0 = vec4<f32>(0.0);
+	for (var l1: i32 = 0; l1 < 4; l1++) {
+		l0 = (l0) + ((uniforms.U0)[l1]);
+	}
+	return l0;
+}
@@ -0,0 +1,11 @@
+package main
+
+var LightColor [4]vec4
+
+func Foo() vec4 {
+	sum := vec4(0)
+	for i := 0; i < 4; i++ {
+		sum += LightColor[i]
+	}
+	return sum
+}
@@ -0,0 +1,14 @@
+uniform vec4 U0;
+uniform float U1;
+uniform float U2[3];
+
+vec4 F0(void);
+float F1(void);
+
+vec4 F0(void) {
+	return ((U0) * (U1)) * ((U2)[2]);
+}
+
+float F1(void) {
+	return (U1) + (1.0);
+}
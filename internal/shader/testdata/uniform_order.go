@@ -0,0 +1,19 @@
+package main
+
+func Foo() vec4 {
+	return Color * Scale * Weights[N-1]
+}
+
+const C = 1.0
+
+var Color vec4
+
+func Bar() float {
+	return Scale + C
+}
+
+var Scale float
+
+var Weights [N]float
+
+const N = 3
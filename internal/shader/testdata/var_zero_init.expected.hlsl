@@ -0,0 +1,12 @@
+void F0(in bool l0, out float l1);
+
+void F0(in bool l0, out float l1) {
+	float l2 = 0.0;
+	l1 = 0.0;
+	if (l0) {
+		l2 = 1.0;
+		l1 = 2.0;
+	}
+	l1 = (l1) + (l2);
+	return;
+}
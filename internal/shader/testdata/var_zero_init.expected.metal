@@ -0,0 +1,12 @@
+void F0(bool l0, thread float& l1);
+
+void F0(bool l0, thread float& l1) {
+	float l2 = float(0);
+	l1 = float(0);
+	if (l0) {
+		l2 = 1.0;
+		l1 = 2.0;
+	}
+	l1 = (l1) + (l2);
+	return;
+}
@@ -0,0 +1,12 @@
+package main
+
+func Foo(c bool) (r float) {
+	// x and r are read after being written only in one branch.
+	// Like Go, they are zero values in the other branch.
+	var x float
+	if c {
+		x = 1.0
+		r = 2.0
+	}
+	return r + x
+}
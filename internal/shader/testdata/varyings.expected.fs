@@ -0,0 +1,14 @@
+in vec2 V0;
+in vec4 V1;
+in float V2;
+in vec3 V3;
+
+vec4 F0(in vec4 l0, in vec2 l1, in vec4 l2, in float l3, in vec3 l4);
+
+vec4 F0(in vec4 l0, in vec2 l1, in vec4 l2, in float l3, in vec3 l4) {
+	return ((l2) * (l3)) + (vec4(l4, 0.0));
+}
+
+void main(void) {
+	fragColor = F0(gl_FragCoord, V0, V1, V2, V3);
+}
@@ -0,0 +1,30 @@
+struct Attributes {
+	float2 M0;
+	float2 M1;
+	float4 M2;
+};
+
+struct Varyings {
+	float4 Position [[position]];
+	float2 M0;
+	float4 M1;
+	float M2;
+	float3 M3;
+};
+
+vertex Varyings Vertex(
+	uint vid [[vertex_id]],
+	const device Attributes* attributes [[buffer(0)]]) {
+	Varyings varyings = {};
+	varyings.Position = float4(attributes[vid].M0, 0.0, 1.0);
+	varyings.M0 = attributes[vid].M1;
+	varyings.M1 = attributes[vid].M2;
+	varyings.M2 = (attributes[vid].M0).x;
+	varyings.M3 = float3(attributes[vid].M1, 1.0);
+	return varyings;
+}
+
+fragment float4 Fragment(
+	Varyings varyings [[stage_in]]) {
+	return ((varyings.M1) * (varyings.M2)) + (float4(varyings.M3, 0.0));
+}
@@ -0,0 +1,9 @@
+package main
+
+func Vertex(dstPos vec2, srcPos vec2, color vec4) (vec4, vec2, vec4, float, vec3) {
+	return vec4(dstPos, 0, 1), srcPos, color, dstPos.x, vec3(srcPos, 1)
+}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4, depth float, n vec3) vec4 {
+	return color*depth + vec4(n, 0)
+}
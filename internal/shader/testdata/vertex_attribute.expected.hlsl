@@ -0,0 +1,7 @@
+Varyings VSMain(float2 A0 : POSITION, float2 A1 : TEXCOORD, float4 A2 : COLOR, float3 A3 : TEXCOORD1, float A4 : TEXCOORD2) {
+	Varyings varyings;
+	varyings.Position = float4(A0, 0.0, 1.0);
+	varyings.M0 = A1;
+	varyings.M1 = float4((A3) * (A4), (A2).a);
+	return varyings;
+}
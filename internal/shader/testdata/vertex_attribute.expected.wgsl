@@ -0,0 +1,27 @@
+struct Attributes {
+	@location(0) M0: vec2<f32>,
+	@location(1) M1: vec2<f32>,
+	@location(2) M2: vec4<f32>,
+	@location(3) M3: vec3<f32>,
+	@location(4) M4: f32,
+}
+
+struct Varyings {
+	@builtin(position) Position: vec4<f32>,
+	@location(0) M0: vec2<f32>,
+	@location(1) M1: vec4<f32>,
+}
+
+@vertex
+fn Vertex(attributes: Attributes) -> Varyings {
+	var varyings: Varyings;
+	varyings.Position = vec4<f32>(attributes.M0, 0.0, 1.0);
+	varyings.M0 = attributes.M1;
+	varyings.M1 = vec4<f32>((attributes.M3) * (attributes.M4), (attributes.M2).a);
+	return varyings;
+}
+
+@fragment
+fn Fragment(varyings: Varyings) -> @location(0) vec4<f32> {
+	return varyings.M1;
+}
@@ -0,0 +1,12 @@
+//kage:attribute Normal vec3
+//kage:attribute Weight float
+
+package main
+
+func Vertex(dstPos vec2, srcPos vec2, color vec4) (vec4, vec2, vec4) {
+	return vec4(dstPos, 0, 1), srcPos, vec4(Normal*Weight, color.a)
+}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return color
+}
@@ -33,6 +33,18 @@ func (cs *compileState) parseType(block *block, fname string, expr ast.Expr) (sh
 			return shaderir.Type{Main: shaderir.Int}, true
 		case "float":
 			return shaderir.Type{Main: shaderir.Float}, true
+		case "int32":
+			// int32 is an alias of int as int in Kage is 32-bit.
+			return shaderir.Type{Main: shaderir.Int}, true
+		case "float32":
+			// float32 is an alias of float as float in Kage is 32-bit.
+			return shaderir.Type{Main: shaderir.Float}, true
+		case "int64":
+			cs.addWarning(t.Pos(), "int64 is treated as int, which is 32-bit")
+			return shaderir.Type{Main: shaderir.Int}, true
+		case "float64":
+			cs.addWarning(t.Pos(), "float64 is treated as float, which is 32-bit")
+			return shaderir.Type{Main: shaderir.Float}, true
 		case "vec2":
 			return shaderir.Type{Main: shaderir.Vec2}, true
 		case "vec3":
@@ -45,6 +57,12 @@ func (cs *compileState) parseType(block *block, fname string, expr ast.Expr) (sh
 			return shaderir.Type{Main: shaderir.IVec3}, true
 		case "ivec4":
 			return shaderir.Type{Main: shaderir.IVec4}, true
+		case "bvec2":
+			return shaderir.Type{Main: shaderir.BVec2}, true
+		case "bvec3":
+			return shaderir.Type{Main: shaderir.BVec3}, true
+		case "bvec4":
+			return shaderir.Type{Main: shaderir.BVec4}, true
 		case "mat2":
 			return shaderir.Type{Main: shaderir.Mat2}, true
 		case "mat3":
@@ -52,6 +70,11 @@ func (cs *compileState) parseType(block *block, fname string, expr ast.Expr) (sh
 		case "mat4":
 			return shaderir.Type{Main: shaderir.Mat4}, true
 		default:
+			if block != nil {
+				if t, ok := block.findType(t.Name); ok {
+					return t, true
+				}
+			}
 			cs.addError(t.Pos(), fmt.Sprintf("unexpected type: %s", t.Name))
 			return shaderir.Type{}, false
 		}
@@ -76,9 +99,20 @@ func (cs *compileState) parseType(block *block, fname string, expr ast.Expr) (sh
 				cs.addError(t.Pos(), "length of array must be a constant number")
 				return shaderir.Type{}, false
 			}
-			l, ok := gconstant.Int64Val(exprs[0].Const)
+			// A constant like 2.0 is available as an integer, but a constant like 2.5 or true is not.
+			c := gconstant.ToInt(exprs[0].Const)
+			if c.Kind() != gconstant.Int {
+				cs.addError(t.Pos(), "array length must be an integer constant")
+				return shaderir.Type{}, false
+			}
+			l, ok := gconstant.Int64Val(c)
 			if !ok {
-				cs.addError(t.Pos(), "length of array must be an integer")
+				cs.addError(t.Pos(), fmt.Sprintf("array length %s overflows int", c.String()))
+				return shaderir.Type{}, false
+			}
+			// An empty array is not available in GLSL.
+			if l <= 0 {
+				cs.addError(t.Pos(), fmt.Sprintf("length of array must be positive but %d", l))
 				return shaderir.Type{}, false
 			}
 			length = int(l)
@@ -92,20 +126,86 @@ func (cs *compileState) parseType(block *block, fname string, expr ast.Expr) (sh
 			cs.addError(t.Pos(), "array of array is forbidden")
 			return shaderir.Type{}, false
 		}
+		if elm.Main == shaderir.Struct {
+			cs.addError(t.Pos(), "array of struct is not implemented")
+			return shaderir.Type{}, false
+		}
 		return shaderir.Type{
 			Main:   shaderir.Array,
 			Sub:    []shaderir.Type{elm},
 			Length: length,
 		}, true
 	case *ast.StructType:
-		cs.addError(t.Pos(), "struct is not implemented")
-		return shaderir.Type{}, false
+		// An empty struct is not available in GLSL.
+		if t.Fields == nil || len(t.Fields.List) == 0 {
+			cs.addError(t.Pos(), "struct must have at least one field")
+			return shaderir.Type{}, false
+		}
+		st := shaderir.Type{
+			Main: shaderir.Struct,
+		}
+		for _, f := range t.Fields.List {
+			if len(f.Names) == 0 {
+				cs.addError(f.Pos(), "embedded field is not available")
+				return shaderir.Type{}, false
+			}
+			ft, ok := cs.parseType(block, fname, f.Type)
+			if !ok {
+				return shaderir.Type{}, false
+			}
+			for _, n := range f.Names {
+				if n.Name != "_" {
+					for _, name := range st.FieldNames {
+						if name == n.Name {
+							cs.addError(n.Pos(), fmt.Sprintf("%s redeclared", n.Name))
+							return shaderir.Type{}, false
+						}
+					}
+				}
+				st.Sub = append(st.Sub, ft)
+				st.FieldNames = append(st.FieldNames, n.Name)
+			}
+		}
+		return st, true
 	default:
 		cs.addError(t.Pos(), fmt.Sprintf("unepxected type: %v", t))
 		return shaderir.Type{}, false
 	}
 }
 
+// refersToType reports whether the type expression expr refers to the type name.
+func refersToType(expr ast.Expr, name string) bool {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name == name
+	case *ast.ArrayType:
+		return refersToType(t.Elt, name)
+	case *ast.StructType:
+		if t.Fields == nil {
+			return false
+		}
+		for _, f := range t.Fields.List {
+			if refersToType(f.Type, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// structFieldIndex returns the index of the struct member name in t.
+func structFieldIndex(t *shaderir.Type, name string) (int, bool) {
+	if name == "_" {
+		return 0, false
+	}
+	for i, n := range t.FieldNames {
+		if n == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
 func isFloat(expr shaderir.Expr, t shaderir.Type) bool {
 	if expr.Const != nil {
 		if t.Main == shaderir.Float {
@@ -140,6 +240,13 @@ func isInt(expr shaderir.Expr, t shaderir.Type) bool {
 	return false
 }
 
+func isBool(expr shaderir.Expr, t shaderir.Type) bool {
+	if expr.Const != nil {
+		return expr.Const.Kind() == gconstant.Bool
+	}
+	return t.Main == shaderir.Bool
+}
+
 func checkArgsForBoolBuiltinFunc(args []shaderir.Expr, argts []shaderir.Type) error {
 	if len(args) != len(argts) {
 		return fmt.Errorf("the number of arguments and types doesn't match: %d vs %d", len(args), len(argts))
@@ -419,6 +526,43 @@ func checkArgsForIVec4BuiltinFunc(args []shaderir.Expr, argts []shaderir.Type) e
 	return fmt.Errorf("invalid arguments for ivec4: (%s)", strings.Join(str, ", "))
 }
 
+// checkArgsForBVecBuiltinFunc checks the arguments of the boolean vector constructor f with n components.
+// Unlike the other vector constructors, the arguments must be n bools, one bool to broadcast, or one boolean vector with n components.
+func checkArgsForBVecBuiltinFunc(f shaderir.BuiltinFunc, n int, args []shaderir.Expr, argts []shaderir.Type) error {
+	if len(args) != len(argts) {
+		return fmt.Errorf("the number of arguments and types doesn't match: %d vs %d", len(args), len(argts))
+	}
+
+	switch len(args) {
+	case 1:
+		if isBool(args[0], argts[0]) {
+			return nil
+		}
+		if argts[0].IsBoolVector() && argts[0].VectorElementCount() == n {
+			return nil
+		}
+	case n:
+		ok := true
+		for i := range args {
+			if !isBool(args[i], argts[i]) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return nil
+		}
+	default:
+		return fmt.Errorf("invalid number of arguments for %s", f)
+	}
+
+	var str []string
+	for _, t := range argts {
+		str = append(str, t.String())
+	}
+	return fmt.Errorf("invalid arguments for %s: (%s)", f, strings.Join(str, ", "))
+}
+
 func checkArgsForMat2BuiltinFunc(args []shaderir.Expr, argts []shaderir.Type) error {
 	if len(args) != len(argts) {
 		return fmt.Errorf("the number of arguments and types doesn't match: %d vs %d", len(args), len(argts))
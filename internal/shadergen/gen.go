@@ -0,0 +1,73 @@
+// Copyright 2026 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build ignore
+
+// gen.go generates a Go source file embedding a Kage shader precompiled for every backend.
+//
+// Usage:
+//
+//	go run gen.go -pkg shaders -name Fill -o fill_shader.go fill.kage
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/hajimehoshi/ebiten/v2/internal/shadergen"
+)
+
+var (
+	flagPackage = flag.String("pkg", "main", "package name of the generated file")
+	flagName    = flag.String("name", "", "variable name holding the shader (required)")
+	flagOutput  = flag.String("o", "", "output file (default: stdout)")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: go run gen.go -name name [-pkg package] [-o output] source\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	if *flagName == "" || flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	src, err := os.ReadFile(flag.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	out, err := shadergen.Generate(*flagPackage, *flagName, src)
+	if err != nil {
+		return err
+	}
+
+	if *flagOutput == "" {
+		_, err := os.Stdout.Write(out)
+		return err
+	}
+	return os.WriteFile(*flagOutput, out, 0644)
+}
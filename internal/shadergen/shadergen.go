@@ -0,0 +1,95 @@
+// Copyright 2026 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:generate go run gen.go -pkg shaders -name Fill -o testdata/fill.expected.go testdata/fill.go
+
+// Package shadergen generates Go source embedding precompiled Kage shaders.
+package shadergen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2/internal/graphics"
+	"github.com/hajimehoshi/ebiten/v2/internal/shaderir/glsl"
+	"github.com/hajimehoshi/ebiten/v2/internal/shaderir/hlsl"
+	"github.com/hajimehoshi/ebiten/v2/internal/shaderir/msl"
+)
+
+// Generate compiles the Kage source src and returns a Go source file for the package pkg.
+// The file declares a variable name holding the shader sources for every backend and the uniform variables.
+func Generate(pkg, name string, src []byte) ([]byte, error) {
+	if !token.IsIdentifier(pkg) {
+		return nil, fmt.Errorf("shadergen: invalid package name: %q", pkg)
+	}
+	if !token.IsIdentifier(name) {
+		return nil, fmt.Errorf("shadergen: invalid variable name: %q", name)
+	}
+
+	ir, err := graphics.CompileShader(src)
+	if err != nil {
+		return nil, err
+	}
+
+	glslVS, glslFS := glsl.Compile(ir, glsl.GLSLVersionDefault)
+	glslESVS, glslESFS := glsl.Compile(ir, glsl.GLSLVersionES300)
+	hlslVS, hlslPS, offsets := hlsl.Compile(ir)
+	metal := msl.Compile(ir, "Vertex", "Fragment")
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by shadergen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", pkg)
+	fmt.Fprintf(&buf, "var %s = struct {\n", name)
+	buf.WriteString("GLSLVertex string\n")
+	buf.WriteString("GLSLFragment string\n")
+	buf.WriteString("GLSLESVertex string\n")
+	buf.WriteString("GLSLESFragment string\n")
+	buf.WriteString("HLSLVertex string\n")
+	buf.WriteString("HLSLPixel string\n")
+	buf.WriteString("MSL string\n")
+	buf.WriteString("Uniforms []struct {\nName string\nType string\nHLSLOffset int\n}\n")
+	buf.WriteString("}{\n")
+	fmt.Fprintf(&buf, "GLSLVertex: %s,\n", quote(glslVS))
+	fmt.Fprintf(&buf, "GLSLFragment: %s,\n", quote(glslFS))
+	fmt.Fprintf(&buf, "GLSLESVertex: %s,\n", quote(glslESVS))
+	fmt.Fprintf(&buf, "GLSLESFragment: %s,\n", quote(glslESFS))
+	fmt.Fprintf(&buf, "HLSLVertex: %s,\n", quote(hlslVS))
+	fmt.Fprintf(&buf, "HLSLPixel: %s,\n", quote(hlslPS))
+	fmt.Fprintf(&buf, "MSL: %s,\n", quote(metal))
+	buf.WriteString("Uniforms: []struct {\nName string\nType string\nHLSLOffset int\n}{\n")
+	for i, n := range ir.UniformNames {
+		fmt.Fprintf(&buf, "{Name: %q, Type: %q, HLSLOffset: %d},\n", n, ir.Uniforms[i].String(), offsets[i])
+	}
+	buf.WriteString("},\n")
+	buf.WriteString("}\n")
+
+	out, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("shadergen: formatting the generated source failed: %w", err)
+	}
+	return out, nil
+}
+
+// quote returns a Go string literal for s.
+// A raw string literal is preferred for readability.
+func quote(s string) string {
+	if strings.ContainsAny(s, "`\r") {
+		return strconv.Quote(s)
+	}
+	return "`" + s + "`"
+}
@@ -0,0 +1,55 @@
+// Copyright 2026 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shadergen_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2/internal/shadergen"
+)
+
+func TestGenerate(t *testing.T) {
+	src, err := os.ReadFile(filepath.Join("testdata", "fill.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile(filepath.Join("testdata", "fill.expected.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := shadergen.Generate("shaders", "Fill", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestGenerateInvalidName(t *testing.T) {
+	src, err := os.ReadFile(filepath.Join("testdata", "fill.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := shadergen.Generate("shaders", "1Fill", src); err == nil {
+		t.Errorf("Generate with an invalid variable name must return an error")
+	}
+	if _, err := shadergen.Generate("shaders", "Fill", []byte("package main\n\nfunc Fragment(")); err == nil {
+		t.Errorf("Generate with an invalid source must return an error")
+	}
+}
@@ -0,0 +1,546 @@
+// Code generated by shadergen. DO NOT EDIT.
+
+package shaders
+
+var Fill = struct {
+	GLSLVertex     string
+	GLSLFragment   string
+	GLSLESVertex   string
+	GLSLESFragment string
+	HLSLVertex     string
+	HLSLPixel      string
+	MSL            string
+	Uniforms       []struct {
+		Name       string
+		Type       string
+		HLSLOffset int
+	}
+}{
+	GLSLVertex: `#version 150
+
+int modInt(int x, int y) {
+	return x - y*(x/y);
+}
+
+ivec2 modInt(ivec2 x, int y) {
+	return x - y*(x/y);
+}
+
+ivec3 modInt(ivec3 x, int y) {
+	return x - y*(x/y);
+}
+
+ivec4 modInt(ivec4 x, int y) {
+	return x - y*(x/y);
+}
+
+ivec2 modInt(ivec2 x, ivec2 y) {
+	return x - y*(x/y);
+}
+
+ivec3 modInt(ivec3 x, ivec3 y) {
+	return x - y*(x/y);
+}
+
+ivec4 modInt(ivec4 x, ivec4 y) {
+	return x - y*(x/y);
+}
+
+uniform vec2 U0;
+uniform vec2 U1[4];
+uniform vec2 U2;
+uniform vec2 U3;
+uniform vec2 U4[4];
+uniform vec2 U5[4];
+uniform mat4 U6;
+uniform vec4 U7;
+uniform sampler2D T0;
+uniform sampler2D T1;
+uniform sampler2D T2;
+uniform sampler2D T3;
+in vec2 A0;
+in vec2 A1;
+in vec4 A2;
+out vec2 V0;
+out vec4 V1;
+
+float touchUniforms() {
+	return float(U1[3].x) + float(U4[3].x) + float(U5[3].x);
+}
+
+void main(void) {
+	touchUniforms();
+	gl_Position = (U6) * (vec4(A0, 0.0, 1.0));
+	V0 = A1;
+	V1 = A2;
+	return;
+}
+`,
+	GLSLFragment: `#version 150
+
+#if defined(GL_ES)
+precision highp float;
+precision highp int;
+#else
+#define lowp
+#define mediump
+#define highp
+#endif
+
+out vec4 fragColor;
+
+int modInt(int x, int y) {
+	return x - y*(x/y);
+}
+
+ivec2 modInt(ivec2 x, int y) {
+	return x - y*(x/y);
+}
+
+ivec3 modInt(ivec3 x, int y) {
+	return x - y*(x/y);
+}
+
+ivec4 modInt(ivec4 x, int y) {
+	return x - y*(x/y);
+}
+
+ivec2 modInt(ivec2 x, ivec2 y) {
+	return x - y*(x/y);
+}
+
+ivec3 modInt(ivec3 x, ivec3 y) {
+	return x - y*(x/y);
+}
+
+ivec4 modInt(ivec4 x, ivec4 y) {
+	return x - y*(x/y);
+}
+
+uniform vec2 U0;
+uniform vec2 U1[4];
+uniform vec2 U2;
+uniform vec2 U3;
+uniform vec2 U4[4];
+uniform vec2 U5[4];
+uniform mat4 U6;
+uniform vec4 U7;
+uniform sampler2D T0;
+uniform sampler2D T1;
+uniform sampler2D T2;
+uniform sampler2D T3;
+in vec2 V0;
+in vec4 V1;
+
+vec4 F9(in vec2 l0);
+vec4 F22(in vec4 l0, in vec2 l1, in vec4 l2);
+
+vec4 F9(in vec2 l0) {
+	vec2 l1 = vec2(0);
+	l1 = (step((U4)[0], l0)) - (step(((U4)[0]) + ((U5)[0]), l0));
+	return ((texelFetch(T0, ivec2(l0), 0)) * ((l1).x)) * ((l1).y);
+}
+
+vec4 F22(in vec4 l0, in vec2 l1, in vec4 l2) {
+	return (U7) * ((F9(l1)).a);
+}
+
+void main(void) {
+	fragColor = F22(gl_FragCoord, V0, V1);
+}
+`,
+	GLSLESVertex: `#version 300 es
+
+uniform vec2 U0;
+uniform vec2 U1[4];
+uniform vec2 U2;
+uniform vec2 U3;
+uniform vec2 U4[4];
+uniform vec2 U5[4];
+uniform mat4 U6;
+uniform vec4 U7;
+uniform sampler2D T0;
+uniform sampler2D T1;
+uniform sampler2D T2;
+uniform sampler2D T3;
+in vec2 A0;
+in vec2 A1;
+in vec4 A2;
+out vec2 V0;
+out vec4 V1;
+
+float touchUniforms() {
+	return float(U1[3].x) + float(U4[3].x) + float(U5[3].x);
+}
+
+void main(void) {
+	touchUniforms();
+	gl_Position = (U6) * (vec4(A0, 0.0, 1.0));
+	V0 = A1;
+	V1 = A2;
+	return;
+}
+`,
+	GLSLESFragment: `#version 300 es
+
+#if defined(GL_ES)
+precision highp float;
+precision highp int;
+#else
+#define lowp
+#define mediump
+#define highp
+#endif
+
+out vec4 fragColor;
+
+uniform vec2 U0;
+uniform vec2 U1[4];
+uniform vec2 U2;
+uniform vec2 U3;
+uniform vec2 U4[4];
+uniform vec2 U5[4];
+uniform mat4 U6;
+uniform vec4 U7;
+uniform sampler2D T0;
+uniform sampler2D T1;
+uniform sampler2D T2;
+uniform sampler2D T3;
+in vec2 V0;
+in vec4 V1;
+
+vec4 F9(in vec2 l0);
+vec4 F22(in vec4 l0, in vec2 l1, in vec4 l2);
+
+vec4 F9(in vec2 l0) {
+	vec2 l1 = vec2(0);
+	l1 = (step((U4)[0], l0)) - (step(((U4)[0]) + ((U5)[0]), l0));
+	return ((texelFetch(T0, ivec2(l0), 0)) * ((l1).x)) * ((l1).y);
+}
+
+vec4 F22(in vec4 l0, in vec2 l1, in vec4 l2) {
+	return (U7) * ((F9(l1)).a);
+}
+
+void main(void) {
+	fragColor = F22(gl_FragCoord, V0, V1);
+}
+`,
+	HLSLVertex: `struct Varyings {
+	float4 Position : SV_POSITION;
+	float2 M0 : TEXCOORD0;
+	float4 M1 : COLOR;
+};
+
+float mod(float x, float y) {
+	return x - y * floor(x/y);
+}
+
+float2 mod(float2 x, float2 y) {
+	return x - y * floor(x/y);
+}
+
+float3 mod(float3 x, float3 y) {
+	return x - y * floor(x/y);
+}
+
+float4 mod(float4 x, float4 y) {
+	return x - y * floor(x/y);
+}
+
+float2x2 float2x2FromScalar(float x) {
+	return float2x2(x, 0, 0, x);
+}
+
+float3x3 float3x3FromScalar(float x) {
+	return float3x3(x, 0, 0, 0, x, 0, 0, 0, x);
+}
+
+float4x4 float4x4FromScalar(float x) {
+	return float4x4(x, 0, 0, 0, 0, x, 0, 0, 0, 0, x, 0, 0, 0, 0, x);
+}
+
+cbuffer Uniforms : register(b0) {
+	float2 U0 : packoffset(c0);
+	float2 U1[4] : packoffset(c1);
+	float2 U2 : packoffset(c4.z);
+	float2 U3 : packoffset(c5);
+	float2 U4[4] : packoffset(c6);
+	float2 U5[4] : packoffset(c10);
+	float4x4 U6 : packoffset(c14);
+	float4 U7 : packoffset(c18);
+}
+
+Texture2D T0 : register(t0);
+Texture2D T1 : register(t1);
+Texture2D T2 : register(t2);
+Texture2D T3 : register(t3);
+
+Varyings VSMain(float2 A0 : POSITION, float2 A1 : TEXCOORD, float4 A2 : COLOR) {
+	Varyings varyings;
+	varyings.Position = mul(float4(A0, 0.0, 1.0), U6);
+	varyings.M0 = A1;
+	varyings.M1 = A2;
+	return varyings;
+}
+`,
+	HLSLPixel: `struct Varyings {
+	float4 Position : SV_POSITION;
+	float2 M0 : TEXCOORD0;
+	float4 M1 : COLOR;
+};
+
+float mod(float x, float y) {
+	return x - y * floor(x/y);
+}
+
+float2 mod(float2 x, float2 y) {
+	return x - y * floor(x/y);
+}
+
+float3 mod(float3 x, float3 y) {
+	return x - y * floor(x/y);
+}
+
+float4 mod(float4 x, float4 y) {
+	return x - y * floor(x/y);
+}
+
+float2x2 float2x2FromScalar(float x) {
+	return float2x2(x, 0, 0, x);
+}
+
+float3x3 float3x3FromScalar(float x) {
+	return float3x3(x, 0, 0, 0, x, 0, 0, 0, x);
+}
+
+float4x4 float4x4FromScalar(float x) {
+	return float4x4(x, 0, 0, 0, 0, x, 0, 0, 0, 0, x, 0, 0, 0, 0, x);
+}
+
+cbuffer Uniforms : register(b0) {
+	float2 U0 : packoffset(c0);
+	float2 U1[4] : packoffset(c1);
+	float2 U2 : packoffset(c4.z);
+	float2 U3 : packoffset(c5);
+	float2 U4[4] : packoffset(c6);
+	float2 U5[4] : packoffset(c10);
+	float4x4 U6 : packoffset(c14);
+	float4 U7 : packoffset(c18);
+}
+
+Texture2D T0 : register(t0);
+Texture2D T1 : register(t1);
+Texture2D T2 : register(t2);
+Texture2D T3 : register(t3);
+
+float4 F9(in float2 l0);
+
+float4 F9(in float2 l0) {
+	float2 l1 = 0.0;
+	l1 = (step((U4)[0], l0)) - (step(((U4)[0]) + ((U5)[0]), l0));
+	return ((T0.Load(int3(l0, 0))) * ((l1).x)) * ((l1).y);
+}
+
+float4 PSMain(Varyings varyings) : SV_TARGET {
+	return (U7) * ((F9(varyings.M0)).a);
+}
+`,
+	MSL: `#include <metal_stdlib>
+
+using namespace metal;
+
+template<typename T, typename U>
+T mod(T x, U y) {
+	return x - y * floor(x/y);
+}
+
+struct Attributes {
+	float2 M0;
+	float2 M1;
+	float4 M2;
+};
+
+struct Varyings {
+	float4 Position [[position]];
+	float2 M0;
+	float4 M1;
+};
+
+float2 F0(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3);
+float2 F1(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3);
+void F2(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3, thread float2& l0, thread float2& l1);
+float2 F3(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3);
+float2 F4(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3);
+void F5(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3, thread float2& l0, thread float2& l1);
+float2 F6(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3);
+float2 F7(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3);
+float4 F8(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3, float2 l0);
+float4 F9(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3, float2 l0);
+float2 F10(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3);
+float2 F11(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3);
+float4 F12(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3, float2 l0);
+float4 F13(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3, float2 l0);
+float2 F14(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3);
+float2 F15(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3);
+float4 F16(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3, float2 l0);
+float4 F17(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3, float2 l0);
+float2 F18(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3);
+float2 F19(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3);
+float4 F20(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3, float2 l0);
+float4 F21(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3, float2 l0);
+
+float2 F0(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3) {
+	return U0;
+}
+
+float2 F1(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3) {
+	return (U1)[0];
+}
+
+void F2(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3, thread float2& l0, thread float2& l1) {
+	l0 = U2;
+	l1 = U3;
+	return;
+}
+
+float2 F3(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3) {
+	return U2;
+}
+
+float2 F4(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3) {
+	return U3;
+}
+
+void F5(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3, thread float2& l0, thread float2& l1) {
+	l0 = (U4)[0];
+	l1 = (U5)[0];
+	return;
+}
+
+float2 F6(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3) {
+	return (U4)[0];
+}
+
+float2 F7(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3) {
+	return (U5)[0];
+}
+
+float4 F8(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3, float2 l0) {
+	return T0.read(static_cast<uint2>(l0));
+}
+
+float4 F9(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3, float2 l0) {
+	float2 l1 = float2(0);
+	l1 = (step((U4)[0], l0)) - (step(((U4)[0]) + ((U5)[0]), l0));
+	return ((T0.read(static_cast<uint2>(l0))) * ((l1).x)) * ((l1).y);
+}
+
+float2 F10(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3) {
+	return (U4)[1];
+}
+
+float2 F11(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3) {
+	return (U5)[1];
+}
+
+float4 F12(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3, float2 l0) {
+	return T1.read(static_cast<uint2>(((l0) - ((U4)[0])) + ((U4)[1])));
+}
+
+float4 F13(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3, float2 l0) {
+	float2 l1 = float2(0);
+	l1 = (step((U4)[0], l0)) - (step(((U4)[0]) + ((U5)[1]), l0));
+	return ((T1.read(static_cast<uint2>(((l0) - ((U4)[0])) + ((U4)[1])))) * ((l1).x)) * ((l1).y);
+}
+
+float2 F14(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3) {
+	return (U4)[2];
+}
+
+float2 F15(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3) {
+	return (U5)[2];
+}
+
+float4 F16(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3, float2 l0) {
+	return T2.read(static_cast<uint2>(((l0) - ((U4)[0])) + ((U4)[2])));
+}
+
+float4 F17(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3, float2 l0) {
+	float2 l1 = float2(0);
+	l1 = (step((U4)[0], l0)) - (step(((U4)[0]) + ((U5)[2]), l0));
+	return ((T2.read(static_cast<uint2>(((l0) - ((U4)[0])) + ((U4)[2])))) * ((l1).x)) * ((l1).y);
+}
+
+float2 F18(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3) {
+	return (U4)[3];
+}
+
+float2 F19(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3) {
+	return (U5)[3];
+}
+
+float4 F20(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3, float2 l0) {
+	return T3.read(static_cast<uint2>(((l0) - ((U4)[0])) + ((U4)[3])));
+}
+
+float4 F21(constant float2& U0, constant array<float2, 4>& U1, constant float2& U2, constant float2& U3, constant array<float2, 4>& U4, constant array<float2, 4>& U5, constant float4x4& U6, constant float4& U7, texture2d<float> T0, texture2d<float> T1, texture2d<float> T2, texture2d<float> T3, float2 l0) {
+	float2 l1 = float2(0);
+	l1 = (step((U4)[0], l0)) - (step(((U4)[0]) + ((U5)[3]), l0));
+	return ((T3.read(static_cast<uint2>(((l0) - ((U4)[0])) + ((U4)[3])))) * ((l1).x)) * ((l1).y);
+}
+
+vertex Varyings Vertex(
+	uint vid [[vertex_id]],
+	const device Attributes* attributes [[buffer(0)]],
+	constant float2& U0 [[buffer(1)]],
+	constant array<float2, 4>& U1 [[buffer(2)]],
+	constant float2& U2 [[buffer(3)]],
+	constant float2& U3 [[buffer(4)]],
+	constant array<float2, 4>& U4 [[buffer(5)]],
+	constant array<float2, 4>& U5 [[buffer(6)]],
+	constant float4x4& U6 [[buffer(7)]],
+	constant float4& U7 [[buffer(8)]],
+	texture2d<float> T0 [[texture(0)]],
+	texture2d<float> T1 [[texture(1)]],
+	texture2d<float> T2 [[texture(2)]],
+	texture2d<float> T3 [[texture(3)]]) {
+	Varyings varyings = {};
+	varyings.Position = (U6) * (float4(attributes[vid].M0, 0.0, 1.0));
+	varyings.M0 = attributes[vid].M1;
+	varyings.M1 = attributes[vid].M2;
+	return varyings;
+}
+
+fragment float4 Fragment(
+	Varyings varyings [[stage_in]],
+	constant float2& U0 [[buffer(1)]],
+	constant array<float2, 4>& U1 [[buffer(2)]],
+	constant float2& U2 [[buffer(3)]],
+	constant float2& U3 [[buffer(4)]],
+	constant array<float2, 4>& U4 [[buffer(5)]],
+	constant array<float2, 4>& U5 [[buffer(6)]],
+	constant float4x4& U6 [[buffer(7)]],
+	constant float4& U7 [[buffer(8)]],
+	texture2d<float> T0 [[texture(0)]],
+	texture2d<float> T1 [[texture(1)]],
+	texture2d<float> T2 [[texture(2)]],
+	texture2d<float> T3 [[texture(3)]]) {
+	return (U7) * ((F9(U0, U1, U2, U3, U4, U5, U6, U7, T0, T1, T2, T3, varyings.M0)).a);
+}
+`,
+	Uniforms: []struct {
+		Name       string
+		Type       string
+		HLSLOffset int
+	}{
+		{Name: "__imageDstTextureSize", Type: "vec2", HLSLOffset: 0},
+		{Name: "__imageSrcTextureSizes", Type: "[4]vec2", HLSLOffset: 16},
+		{Name: "__imageDstRegionOrigin", Type: "vec2", HLSLOffset: 72},
+		{Name: "__imageDstRegionSize", Type: "vec2", HLSLOffset: 80},
+		{Name: "__imageSrcRegionOrigins", Type: "[4]vec2", HLSLOffset: 96},
+		{Name: "__imageSrcRegionSizes", Type: "[4]vec2", HLSLOffset: 160},
+		{Name: "__projectionMatrix", Type: "mat4", HLSLOffset: 224},
+		{Name: "Color", Type: "vec4", HLSLOffset: 288},
+	},
+}
@@ -0,0 +1,9 @@
+//kage:unit pixels
+
+package main
+
+var Color vec4
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return Color * imageSrc0At(srcPos).a
+}
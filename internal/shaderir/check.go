@@ -129,12 +129,17 @@ func TypeFromBinaryOp(op Op, lhst, rhst Type, lhsConst, rhsConst constant.Value)
 	}
 
 	if op == VectorEqualOp || op == VectorNotEqualOp {
-		if (lhst.IsFloatVector() || lhst.IsIntVector()) && (rhst.IsFloatVector() || lhst.IsIntVector()) && lhst.Equal(&rhst) {
+		if (lhst.IsFloatVector() || lhst.IsIntVector() || lhst.IsBoolVector()) && lhst.Equal(&rhst) {
 			return Type{Main: Bool}, true
 		}
 		return Type{}, false
 	}
 
+	// Boolean vectors can only be compared with == and !=.
+	if lhst.IsBoolVector() || rhst.IsBoolVector() {
+		return Type{}, false
+	}
+
 	if op == LessThanOp || op == LessThanEqualOp || op == GreaterThanOp || op == GreaterThanEqualOp {
 		if (lhst.Main == Int && rhst.Main == Int) || (lhst.Main == Float && rhst.Main == Float) {
 			return Type{Main: Bool}, true
@@ -170,6 +175,13 @@ func TypeFromBinaryOp(op Op, lhst, rhst Type, lhsConst, rhsConst constant.Value)
 		if (lhst.Main == Int || lhst.IsIntVector()) && rhst.Main == Int {
 			return lhst, true
 		}
+		// % for floats is valid as the built-in function mod, which takes a vector and a scalar too.
+		if lhst.Main == Float && rhst.Main == Float {
+			return Type{Main: Float}, true
+		}
+		if lhst.IsFloatVector() && (rhst.Main == Float || lhst.Equal(&rhst)) {
+			return lhst, true
+		}
 		return Type{}, false
 	}
 
@@ -195,6 +207,17 @@ func TypeFromBinaryOp(op Op, lhst, rhst Type, lhsConst, rhsConst constant.Value)
 		return Type{}, false
 	}
 
+	// The right-hand side of a shift must be an int, or an integer vector of the same size.
+	if op == LeftShift || op == RightShift {
+		if lhst.Main == Int && rhst.Main == Int {
+			return Type{Main: Int}, true
+		}
+		if lhst.IsIntVector() && (rhst.Main == Int || lhst.Equal(&rhst)) {
+			return lhst, true
+		}
+		return Type{}, false
+	}
+
 	if lhst.Equal(&rhst) {
 		if lhst.Main == None {
 			return rhst, true
@@ -20,6 +20,7 @@ import (
 	"go/token"
 	"math"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2/internal/shaderir"
@@ -62,16 +63,36 @@ ivec4 modInt(ivec4 x, ivec4 y) {
 }`
 
 func VertexPrelude(version GLSLVersion) string {
+	return vertexPrelude(version, shaderir.PrecisionDefault, shaderir.PrecisionDefault)
+}
+
+func vertexPrelude(version GLSLVersion, floatPrecision, intPrecision shaderir.Precision) string {
+	var prelude string
 	switch version {
 	case GLSLVersionDefault:
-		return `#version 150` + "\n\n" + utilFunctions
+		prelude = `#version 150`
 	case GLSLVersionES300:
-		return `#version 300 es`
+		prelude = `#version 300 es`
 	}
-	return ""
+	// The default precisions in vertex shaders are highp. Specify them only when they are changed,
+	// as the precisions of uniform variables must be the same between a vertex shader and a fragment shader.
+	if floatPrecision != shaderir.PrecisionDefault || intPrecision != shaderir.PrecisionDefault {
+		prelude += "\n\n" + `#if defined(GL_ES)
+precision ` + defaultPrecisionString(floatPrecision) + ` float;
+precision ` + defaultPrecisionString(intPrecision) + ` int;
+#endif`
+	}
+	if version == GLSLVersionDefault {
+		prelude += "\n\n" + utilFunctions
+	}
+	return prelude
 }
 
 func FragmentPrelude(version GLSLVersion) string {
+	return fragmentPrelude(version, shaderir.PrecisionDefault, shaderir.PrecisionDefault)
+}
+
+func fragmentPrelude(version GLSLVersion, floatPrecision, intPrecision shaderir.Precision) string {
 	var prefix string
 	switch version {
 	case GLSLVersionDefault:
@@ -80,8 +101,8 @@ func FragmentPrelude(version GLSLVersion) string {
 		prefix = `#version 300 es` + "\n\n"
 	}
 	prelude := prefix + `#if defined(GL_ES)
-precision highp float;
-precision highp int;
+precision ` + defaultPrecisionString(floatPrecision) + ` float;
+precision ` + defaultPrecisionString(intPrecision) + ` int;
 #else
 #define lowp
 #define mediump
@@ -97,6 +118,8 @@ out vec4 fragColor;`
 
 type compileContext struct {
 	version     GLSLVersion
+	indent      string
+	sourceMap   bool
 	structNames map[string]string
 	structTypes []shaderir.Type
 	unit        shaderir.Unit
@@ -110,17 +133,67 @@ func (c *compileContext) structName(p *shaderir.Program, t *shaderir.Type) strin
 	if n, ok := c.structNames[s]; ok {
 		return n
 	}
+	// Member struct types must be declared before this struct type.
+	for i := range t.Sub {
+		if t.Sub[i].Main == shaderir.Struct {
+			c.structName(p, &t.Sub[i])
+		}
+	}
 	n := fmt.Sprintf("S%d", len(c.structNames))
 	c.structNames[s] = n
 	c.structTypes = append(c.structTypes, *t)
 	return n
 }
 
+// Features returns the features the given GLSL version supports.
+// Features returns nil for an unknown version.
+func Features(version GLSLVersion) []shaderir.Feature {
+	switch version {
+	case GLSLVersionDefault:
+		// GLSL 1.50 has derivative functions and int uniforms in the core.
+		return []shaderir.Feature{
+			shaderir.FeatureDerivatives,
+			shaderir.FeatureIntUniforms,
+		}
+	case GLSLVersionES300:
+		// Unlike GLSL ES 1.00, GLSL ES 3.00 has derivative functions without OES_standard_derivatives.
+		return []shaderir.Feature{
+			shaderir.FeatureDerivatives,
+			shaderir.FeatureIntUniforms,
+		}
+	}
+	return nil
+}
+
 func Compile(p *shaderir.Program, version GLSLVersion) (vertexShader, fragmentShader string) {
+	vs, fs, _, _ := compile(p, version, "\t", false)
+	return vs, fs
+}
+
+// CompileWithIndent is like Compile but uses indent as the unit of indentation.
+// Statements in a nested block are indented one more unit than the block.
+func CompileWithIndent(p *shaderir.Program, version GLSLVersion, indent string) (vertexShader, fragmentShader string) {
+	vs, fs, _, _ := compile(p, version, indent, false)
+	return vs, fs
+}
+
+// CompileWithSourceMap is like Compile but also returns the source maps of the shaders.
+// A source map maps a line of the generated shader to a line of the source program:
+// vertexSourceMap[i] is the line of the statement in the source that the line i+1 of the vertex shader is generated from.
+// The value is 0 when the line is not generated from a statement, e.g. a declaration.
+//
+// Source maps are useful to map errors reported by a driver against generated shaders back to the source program.
+func CompileWithSourceMap(p *shaderir.Program, version GLSLVersion) (vertexShader, fragmentShader string, vertexSourceMap, fragmentSourceMap []int) {
+	return compile(p, version, "\t", true)
+}
+
+func compile(p *shaderir.Program, version GLSLVersion, indent string, sourceMap bool) (vertexShader, fragmentShader string, vertexSourceMap, fragmentSourceMap []int) {
 	p = adjustProgram(p)
 
 	c := &compileContext{
 		version:     version,
+		indent:      indent,
+		sourceMap:   sourceMap,
 		structNames: map[string]string{},
 		unit:        p.Unit,
 	}
@@ -128,7 +201,7 @@ func Compile(p *shaderir.Program, version GLSLVersion) (vertexShader, fragmentSh
 	// Vertex func
 	var vslines []string
 	{
-		vslines = append(vslines, strings.Split(VertexPrelude(version), "\n")...)
+		vslines = append(vslines, strings.Split(vertexPrelude(version, p.FloatPrecision, p.IntPrecision), "\n")...)
 		vslines = append(vslines, "", "{{.Structs}}")
 		if len(p.Uniforms) > 0 || p.TextureCount > 0 || len(p.Attributes) > 0 || len(p.Varyings) > 0 {
 			vslines = append(vslines, "")
@@ -194,7 +267,7 @@ func Compile(p *shaderir.Program, version GLSLVersion) (vertexShader, fragmentSh
 		var touchUniformsFunc []string
 		if len(touchedUniforms) > 0 {
 			touchUniformsFunc = append(touchUniformsFunc, "float touchUniforms() {")
-			touchUniformsFunc = append(touchUniformsFunc, fmt.Sprintf("\treturn %s;", strings.Join(touchedUniforms, " + ")))
+			touchUniformsFunc = append(touchUniformsFunc, fmt.Sprintf("%sreturn %s;", c.indent, strings.Join(touchedUniforms, " + ")))
 			touchUniformsFunc = append(touchUniformsFunc, "}")
 
 		}
@@ -207,7 +280,7 @@ func Compile(p *shaderir.Program, version GLSLVersion) (vertexShader, fragmentSh
 			vslines = append(vslines, "")
 			vslines = append(vslines, "void main(void) {")
 			if len(touchUniformsFunc) > 0 {
-				vslines = append(vslines, "\ttouchUniforms();")
+				vslines = append(vslines, c.indent+"touchUniforms();")
 			}
 			vslines = append(vslines, c.block(p, p.VertexFunc.Block, p.VertexFunc.Block, 0)...)
 			vslines = append(vslines, "}")
@@ -217,7 +290,7 @@ func Compile(p *shaderir.Program, version GLSLVersion) (vertexShader, fragmentSh
 	// Fragment func
 	var fslines []string
 	{
-		fslines = append(fslines, strings.Split(FragmentPrelude(version), "\n")...)
+		fslines = append(fslines, strings.Split(fragmentPrelude(version, p.FloatPrecision, p.IntPrecision), "\n")...)
 		fslines = append(fslines, "", "{{.Structs}}")
 		if len(p.Uniforms) > 0 || p.TextureCount > 0 || len(p.Varyings) > 0 {
 			fslines = append(fslines, "")
@@ -273,7 +346,7 @@ func Compile(p *shaderir.Program, version GLSLVersion) (vertexShader, fragmentSh
 		for i, t := range c.structTypes {
 			stlines = append(stlines, fmt.Sprintf("struct S%d {", i))
 			for j, st := range t.Sub {
-				stlines = append(stlines, fmt.Sprintf("\t%s;", c.varDecl(p, &st, fmt.Sprintf("M%d", j))))
+				stlines = append(stlines, fmt.Sprintf("%s%s;", c.indent, c.varDecl(p, &st, fmt.Sprintf("M%d", j))))
 			}
 			stlines = append(stlines, "};")
 		}
@@ -292,7 +365,35 @@ func Compile(p *shaderir.Program, version GLSLVersion) (vertexShader, fragmentSh
 	vs = strings.TrimSpace(vs) + "\n"
 	fs = strings.TrimSpace(fs) + "\n"
 
-	return vs, fs
+	if !sourceMap {
+		return vs, fs, nil, nil
+	}
+	vs, vsMap := extractSourceLines(vs)
+	fs, fsMap := extractSourceLines(fs)
+	return vs, fs, vsMap, fsMap
+}
+
+// sourceLineMarker encloses a source line number at the head of a generated line.
+const sourceLineMarker = "\x00"
+
+// extractSourceLines removes the source line markers from src, and returns the source lines for each line.
+func extractSourceLines(src string) (string, []int) {
+	lines := strings.Split(strings.TrimSuffix(src, "\n"), "\n")
+	srcLines := make([]int, len(lines))
+	for i, l := range lines {
+		if !strings.HasPrefix(l, sourceLineMarker) {
+			continue
+		}
+		l = l[len(sourceLineMarker):]
+		idx := strings.Index(l, sourceLineMarker)
+		n, err := strconv.Atoi(l[:idx])
+		if err != nil {
+			panic(fmt.Sprintf("glsl: invalid source line marker: %v", err))
+		}
+		srcLines[i] = n
+		lines[i] = l[idx+len(sourceLineMarker):]
+	}
+	return strings.Join(lines, "\n") + "\n", srcLines
 }
 
 func (c *compileContext) typ(p *shaderir.Program, t *shaderir.Type) (string, string) {
@@ -314,7 +415,7 @@ func (c *compileContext) varDecl(p *shaderir.Program, t *shaderir.Type, varname
 		return fmt.Sprintf("%s %s", c.structName(p, t), varname)
 	default:
 		t0, t1 := typeString(t)
-		return fmt.Sprintf("%s %s%s", t0, varname, t1)
+		return fmt.Sprintf("%s%s %s%s", precisionString(t.Precision), t0, varname, t1)
 	}
 }
 
@@ -331,7 +432,11 @@ func (c *compileContext) varInit(p *shaderir.Program, t *shaderir.Type) string {
 		t0, t1 := typeString(t)
 		return fmt.Sprintf("%s%s(%s)", t0, t1, strings.Join(es, ", "))
 	case shaderir.Struct:
-		panic("not implemented")
+		es := make([]string, 0, len(t.Sub))
+		for i := range t.Sub {
+			es = append(es, c.varInit(p, &t.Sub[i]))
+		}
+		return fmt.Sprintf("%s(%s)", c.structName(p, t), strings.Join(es, ", "))
 	case shaderir.Bool:
 		return "false"
 	case shaderir.Int:
@@ -340,6 +445,8 @@ func (c *compileContext) varInit(p *shaderir.Program, t *shaderir.Type) string {
 		shaderir.IVec2, shaderir.IVec3, shaderir.IVec4,
 		shaderir.Mat2, shaderir.Mat3, shaderir.Mat4:
 		return fmt.Sprintf("%s(0)", basicTypeString(t.Main))
+	case shaderir.BVec2, shaderir.BVec3, shaderir.BVec4:
+		return fmt.Sprintf("%s(false)", basicTypeString(t.Main))
 	default:
 		t0, t1 := c.typ(p, t)
 		panic(fmt.Sprintf("?(unexpected type: %s%s)", t0, t1))
@@ -390,6 +497,10 @@ func constantToNumberLiteral(v constant.Value) string {
 	case constant.Float:
 		x, _ := constant.Float64Val(v)
 		if i := math.Floor(x); i == x {
+			// A large integral value might not fit in int64.
+			if math.Abs(i) >= 1<<53 {
+				return strconv.FormatFloat(x, 'e', -1, 32)
+			}
 			return fmt.Sprintf("%d.0", int64(i))
 		}
 		return fmt.Sprintf("%.10e", x)
@@ -428,7 +539,7 @@ func (c *compileContext) localVariableName(p *shaderir.Program, topBlock *shader
 }
 
 func (c *compileContext) initVariable(p *shaderir.Program, topBlock, block *shaderir.Block, index int, decl bool, level int) []string {
-	idt := strings.Repeat("\t", level+1)
+	idt := strings.Repeat(c.indent, level+1)
 	name := c.localVariableName(p, topBlock, index)
 	t := p.LocalVariableType(topBlock, block, index)
 
@@ -510,6 +621,9 @@ func (c *compileContext) block(p *shaderir.Program, topBlock, block *shaderir.Bl
 			}
 			f := expr(&e.Exprs[0])
 			if f == "texelFetch" {
+				if e.Exprs[0].BuiltinFunc == shaderir.TextureLod {
+					return fmt.Sprintf("%s(%s, ivec2(%s), int(%s))", f, args[0], args[1], args[2])
+				}
 				return fmt.Sprintf("%s(%s, ivec2(%s), 0)", f, args[0], args[1])
 			}
 			// Using parentheses at the callee is illegal.
@@ -523,8 +637,9 @@ func (c *compileContext) block(p *shaderir.Program, topBlock, block *shaderir.Bl
 		}
 	}
 
-	idt := strings.Repeat("\t", level+1)
+	idt := strings.Repeat(c.indent, level+1)
 	for _, s := range block.Stmts {
+		n := len(lines)
 		switch s.Type {
 		case shaderir.ExprStmt:
 			lines = append(lines, fmt.Sprintf("%s%s;", idt, expr(&s.Exprs[0])))
@@ -540,7 +655,7 @@ func (c *compileContext) block(p *shaderir.Program, topBlock, block *shaderir.Bl
 					for i := 0; i < t.Length; i++ {
 						lines = append(lines, fmt.Sprintf("%[1]s%[2]s[%[3]d] = %[4]s[%[3]d];", idt, expr(&lhs), i, expr(&rhs)))
 					}
-					continue
+					break
 				}
 			}
 			lines = append(lines, fmt.Sprintf("%s%s = %s;", idt, expr(&lhs), expr(&rhs)))
@@ -588,6 +703,10 @@ func (c *compileContext) block(p *shaderir.Program, topBlock, block *shaderir.Bl
 			lines = append(lines, fmt.Sprintf("%sfor (%s %s%s = %s; %s %s %s; %s) {", idt, t0, v, t1, init, v, op, end, delta))
 			lines = append(lines, c.block(p, topBlock, s.Blocks[0], level+1)...)
 			lines = append(lines, fmt.Sprintf("%s}", idt))
+		case shaderir.While:
+			lines = append(lines, fmt.Sprintf("%swhile (%s) {", idt, expr(&s.Exprs[0])))
+			lines = append(lines, c.block(p, topBlock, s.Blocks[0], level+1)...)
+			lines = append(lines, fmt.Sprintf("%s}", idt))
 		case shaderir.Continue:
 			lines = append(lines, idt+"continue;")
 		case shaderir.Break:
@@ -603,16 +722,43 @@ func (c *compileContext) block(p *shaderir.Program, topBlock, block *shaderir.Bl
 				lines = append(lines, fmt.Sprintf("%sreturn %s;", idt, expr(&s.Exprs[0])))
 			}
 		case shaderir.Discard:
-			// 'discard' is invoked only in the fragment shader entry point.
-			lines = append(lines, idt+"discard;", idt+"return vec4(0.0);")
+			// 'discard' is invoked only in the fragment shader entry point and the functions called from it.
+			// Returning is required only in the entry point, as a function might return a different type.
+			// The entry point's body is moved to a function returning vec4 (see adjustProgram).
+			if f, ok := funcFromBlock(p, topBlock); ok && f.Return.Main == shaderir.Vec4 {
+				lines = append(lines, idt+"discard;", idt+"return vec4(0.0);")
+			} else {
+				lines = append(lines, idt+"discard;")
+			}
 		default:
 			lines = append(lines, fmt.Sprintf("%s?(unexpected stmt: %d)", idt, s.Type))
 		}
+
+		// Mark the lines generated from the statement with its source line.
+		// The lines of the statements in nested blocks are already marked with their own lines.
+		if c.sourceMap && s.Line > 0 {
+			for i := n; i < len(lines); i++ {
+				if strings.HasPrefix(lines[i], sourceLineMarker) {
+					continue
+				}
+				lines[i] = fmt.Sprintf("%s%d%s%s", sourceLineMarker, s.Line, sourceLineMarker, lines[i])
+			}
+		}
 	}
 
 	return lines
 }
 
+// funcFromBlock returns the function whose body is block.
+func funcFromBlock(p *shaderir.Program, block *shaderir.Block) (*shaderir.Func, bool) {
+	for i := range p.Funcs {
+		if p.Funcs[i].Block == block {
+			return &p.Funcs[i], true
+		}
+	}
+	return nil, false
+}
+
 func adjustProgram(p *shaderir.Program) *shaderir.Program {
 	if p.FragmentFunc.Block == nil {
 		return p
@@ -20,6 +20,32 @@ import (
 	"github.com/hajimehoshi/ebiten/v2/internal/shaderir"
 )
 
+func precisionString(precision shaderir.Precision) string {
+	switch precision {
+	case shaderir.PrecisionLow:
+		return "lowp "
+	case shaderir.PrecisionMedium:
+		return "mediump "
+	case shaderir.PrecisionHigh:
+		return "highp "
+	default:
+		return ""
+	}
+}
+
+// defaultPrecisionString returns the qualifier for a precision statement.
+// PrecisionDefault is highp for correctness.
+func defaultPrecisionString(precision shaderir.Precision) string {
+	switch precision {
+	case shaderir.PrecisionLow:
+		return "lowp"
+	case shaderir.PrecisionMedium:
+		return "mediump"
+	default:
+		return "highp"
+	}
+}
+
 func opString(op shaderir.Op) string {
 	switch op {
 	case shaderir.Add:
@@ -98,6 +124,12 @@ func basicTypeString(t shaderir.BasicType) string {
 		return "ivec3"
 	case shaderir.IVec4:
 		return "ivec4"
+	case shaderir.BVec2:
+		return "bvec2"
+	case shaderir.BVec3:
+		return "bvec3"
+	case shaderir.BVec4:
+		return "bvec4"
 	case shaderir.Mat2:
 		return "mat2"
 	case shaderir.Mat3:
@@ -126,6 +158,11 @@ func (c *compileContext) builtinFuncString(f shaderir.BuiltinFunc) string {
 			return "texelFetch"
 		}
 		return "texture"
+	case shaderir.TextureLod:
+		if c.unit == shaderir.Pixels {
+			return "texelFetch"
+		}
+		return "textureLod"
 	default:
 		return string(f)
 	}
@@ -20,6 +20,7 @@ import (
 	"go/token"
 	"math"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2/internal/shaderir"
@@ -43,6 +44,12 @@ func (c *compileContext) structName(p *shaderir.Program, t *shaderir.Type) strin
 	if n, ok := c.structNames[s]; ok {
 		return n
 	}
+	// Member struct types must be declared before this struct type.
+	for i := range t.Sub {
+		if t.Sub[i].Main == shaderir.Struct {
+			c.structName(p, &t.Sub[i])
+		}
+	}
 	n := fmt.Sprintf("S%d", len(c.structNames))
 	if c.structNames == nil {
 		c.structNames = map[string]string{}
@@ -52,13 +59,29 @@ func (c *compileContext) structName(p *shaderir.Program, t *shaderir.Type) strin
 	return n
 }
 
-const Prelude = `struct Varyings {
-	float4 Position : SV_POSITION;
-	float2 M0 : TEXCOORD0;
-	float4 M1 : COLOR;
-};
+// Prelude returns the prelude for a program with the given varyings.
+func Prelude(varyings []shaderir.Type) string {
+	lines := []string{"struct Varyings {", "\tfloat4 Position : SV_POSITION;"}
+	for i, t := range varyings {
+		// The semantics of the first two are TEXCOORD0 and COLOR for Ebitengine's default varyings.
+		// The rest use TEXCOORD1 and later.
+		var semantic string
+		switch i {
+		case 0:
+			semantic = "TEXCOORD0"
+		case 1:
+			semantic = "COLOR"
+		default:
+			semantic = fmt.Sprintf("TEXCOORD%d", i-1)
+		}
+		t0, t1 := typeString(&t)
+		lines = append(lines, fmt.Sprintf("\t%s M%d%s : %s;", t0, i, t1, semantic))
+	}
+	lines = append(lines, "};")
+	return strings.Join(lines, "\n") + "\n\n" + utilFunctions
+}
 
-float mod(float x, float y) {
+const utilFunctions = `float mod(float x, float y) {
 	return x - y * floor(x/y);
 }
 
@@ -86,6 +109,14 @@ float4x4 float4x4FromScalar(float x) {
 	return float4x4(x, 0, 0, 0, 0, x, 0, 0, 0, 0, x, 0, 0, 0, 0, x);
 }`
 
+// Features returns the features HLSL supports.
+func Features() []shaderir.Feature {
+	return []shaderir.Feature{
+		shaderir.FeatureDerivatives,
+		shaderir.FeatureIntUniforms,
+	}
+}
+
 func Compile(p *shaderir.Program) (vertexShader, pixelShader string, offsets []int) {
 	offsets = calculateMemoryOffsets(p.Uniforms)
 
@@ -94,7 +125,7 @@ func Compile(p *shaderir.Program) (vertexShader, pixelShader string, offsets []i
 	}
 
 	var lines []string
-	lines = append(lines, strings.Split(Prelude, "\n")...)
+	lines = append(lines, strings.Split(Prelude(p.Varyings), "\n")...)
 	lines = append(lines, "", "{{.Structs}}")
 
 	if len(p.Uniforms) > 0 {
@@ -156,7 +187,12 @@ func Compile(p *shaderir.Program) (vertexShader, pixelShader string, offsets []i
 	}
 	if p.VertexFunc.Block != nil && len(p.VertexFunc.Block.Stmts) > 0 {
 		vslines = append(vslines, "")
-		vslines = append(vslines, "Varyings VSMain(float2 A0 : POSITION, float2 A1 : TEXCOORD, float4 A2 : COLOR) {")
+		params := "float2 A0 : POSITION, float2 A1 : TEXCOORD, float4 A2 : COLOR"
+		// Additional attributes use TEXCOORD1 and later, as TEXCOORD is TEXCOORD0.
+		for i := 3; i < len(p.Attributes); i++ {
+			params += fmt.Sprintf(", %s : TEXCOORD%d", c.varDecl(p, &p.Attributes[i], fmt.Sprintf("A%d", i)), i-2)
+		}
+		vslines = append(vslines, fmt.Sprintf("Varyings VSMain(%s) {", params))
 		vslines = append(vslines, fmt.Sprintf("\tVaryings %s;", vsOut))
 		vslines = append(vslines, c.block(p, p.VertexFunc.Block, p.VertexFunc.Block, 0)...)
 		if last := fmt.Sprintf("\treturn %s;", vsOut); vslines[len(vslines)-1] != last {
@@ -266,8 +302,9 @@ func (c *compileContext) varInit(p *shaderir.Program, t *shaderir.Type) string {
 		t0, t1 := typeString(t)
 		return fmt.Sprintf("%s%s(%s)", t0, t1, strings.Join(es, ", "))
 	case shaderir.Struct:
-		panic("not implemented")
-	case shaderir.Bool:
+		// Casting 0 to a struct type zero-initializes all the members.
+		return fmt.Sprintf("(%s)0", c.structName(p, t))
+	case shaderir.Bool, shaderir.BVec2, shaderir.BVec3, shaderir.BVec4:
 		return "false"
 	case shaderir.Int, shaderir.IVec2, shaderir.IVec3, shaderir.IVec4:
 		return "0"
@@ -323,6 +360,10 @@ func constantToNumberLiteral(v constant.Value) string {
 	case constant.Float:
 		x, _ := constant.Float64Val(v)
 		if i := math.Floor(x); i == x {
+			// A large integral value might not fit in int64.
+			if math.Abs(i) >= 1<<53 {
+				return strconv.FormatFloat(x, 'e', -1, 32)
+			}
 			return fmt.Sprintf("%d.0", int64(i))
 		}
 		return fmt.Sprintf("%.10e", x)
@@ -450,7 +491,7 @@ func (c *compileContext) block(p *shaderir.Program, topBlock, block *shaderir.Bl
 			}
 			if callee.Type == shaderir.BuiltinFuncExpr {
 				switch callee.BuiltinFunc {
-				case shaderir.Vec2F, shaderir.Vec3F, shaderir.Vec4F, shaderir.IVec2F, shaderir.IVec3F, shaderir.IVec4F:
+				case shaderir.Vec2F, shaderir.Vec3F, shaderir.Vec4F, shaderir.IVec2F, shaderir.IVec3F, shaderir.IVec4F, shaderir.BVec2F, shaderir.BVec3F, shaderir.BVec4F:
 					if len(args) == 1 {
 						// Use casting. For example, `float4(1)` doesn't work.
 						return fmt.Sprintf("(%s)(%s)", expr(&e.Exprs[0]), args[0])
@@ -469,6 +510,21 @@ func (c *compileContext) block(p *shaderir.Program, topBlock, block *shaderir.Bl
 					if len(args) == 1 {
 						return fmt.Sprintf("float4x4FromScalar(%s)", args[0])
 					}
+				// The comparison operators work component-wise and return boolean vectors in HLSL.
+				case shaderir.LessThan:
+					return fmt.Sprintf("(%s) < (%s)", args[0], args[1])
+				case shaderir.LessThanEqual:
+					return fmt.Sprintf("(%s) <= (%s)", args[0], args[1])
+				case shaderir.GreaterThan:
+					return fmt.Sprintf("(%s) > (%s)", args[0], args[1])
+				case shaderir.GreaterThanEqual:
+					return fmt.Sprintf("(%s) >= (%s)", args[0], args[1])
+				case shaderir.Equal:
+					return fmt.Sprintf("(%s) == (%s)", args[0], args[1])
+				case shaderir.NotEqual:
+					return fmt.Sprintf("(%s) != (%s)", args[0], args[1])
+				case shaderir.Not:
+					return fmt.Sprintf("!(%s)", args[0])
 				case shaderir.TexelAt:
 					switch c.unit {
 					case shaderir.Pixels:
@@ -478,6 +534,15 @@ func (c *compileContext) block(p *shaderir.Program, topBlock, block *shaderir.Bl
 					default:
 						panic(fmt.Sprintf("hlsl: unexpected unit: %d", p.Unit))
 					}
+				case shaderir.TextureLod:
+					switch c.unit {
+					case shaderir.Pixels:
+						return fmt.Sprintf("%s.Load(int3(%s, int(%s)))", args[0], args[1], args[2])
+					case shaderir.Texels:
+						return fmt.Sprintf("%s.SampleLevel(samp, %s, %s)", args[0], args[1], args[2])
+					default:
+						panic(fmt.Sprintf("hlsl: unexpected unit: %d", p.Unit))
+					}
 				}
 			}
 			return fmt.Sprintf("%s(%s)", expr(&e.Exprs[0]), strings.Join(args, ", "))
@@ -555,6 +620,10 @@ func (c *compileContext) block(p *shaderir.Program, topBlock, block *shaderir.Bl
 			lines = append(lines, fmt.Sprintf("%sfor (%s %s%s = %s; %s %s %s; %s) {", idt, t0, v, t1, init, v, op, end, delta))
 			lines = append(lines, c.block(p, topBlock, s.Blocks[0], level+1)...)
 			lines = append(lines, fmt.Sprintf("%s}", idt))
+		case shaderir.While:
+			lines = append(lines, fmt.Sprintf("%swhile (%s) {", idt, expr(&s.Exprs[0])))
+			lines = append(lines, c.block(p, topBlock, s.Blocks[0], level+1)...)
+			lines = append(lines, fmt.Sprintf("%s}", idt))
 		case shaderir.Continue:
 			lines = append(lines, idt+"continue;")
 		case shaderir.Break:
@@ -569,8 +638,12 @@ func (c *compileContext) block(p *shaderir.Program, topBlock, block *shaderir.Bl
 				lines = append(lines, fmt.Sprintf("%sreturn %s;", idt, expr(&s.Exprs[0])))
 			}
 		case shaderir.Discard:
-			// 'discard' is invoked only in the fragment shader entry point.
-			lines = append(lines, idt+"discard;", idt+"return float4(0.0, 0.0, 0.0, 0.0);")
+			// 'discard' is invoked only in the fragment shader entry point and the functions called from it.
+			// Returning is required only in the entry point, as a function might return a different type.
+			lines = append(lines, idt+"discard;")
+			if topBlock == p.FragmentFunc.Block {
+				lines = append(lines, idt+"return float4(0.0, 0.0, 0.0, 0.0);")
+			}
 		default:
 			lines = append(lines, fmt.Sprintf("%s?(unexpected stmt: %d)", idt, s.Type))
 		}
@@ -98,6 +98,12 @@ func basicTypeString(t shaderir.BasicType) string {
 		return "int3"
 	case shaderir.IVec4:
 		return "int4"
+	case shaderir.BVec2:
+		return "bool2"
+	case shaderir.BVec3:
+		return "bool3"
+	case shaderir.BVec4:
+		return "bool4"
 	case shaderir.Mat2:
 		return "float2x2"
 	case shaderir.Mat3:
@@ -127,6 +133,12 @@ func (c *compileContext) builtinFuncString(f shaderir.BuiltinFunc) string {
 		return "int3"
 	case shaderir.IVec4F:
 		return "int4"
+	case shaderir.BVec2F:
+		return "bool2"
+	case shaderir.BVec3F:
+		return "bool3"
+	case shaderir.BVec4F:
+		return "bool4"
 	case shaderir.Mat2F:
 		return "float2x2"
 	case shaderir.Mat3F:
@@ -145,6 +157,8 @@ func (c *compileContext) builtinFuncString(f shaderir.BuiltinFunc) string {
 		return "ddy"
 	case shaderir.TexelAt:
 		return "?(__texelAt)"
+	case shaderir.TextureLod:
+		return "?(__textureLod)"
 	default:
 		return string(f)
 	}
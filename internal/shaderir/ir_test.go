@@ -16,6 +16,7 @@ package shaderir_test
 
 import (
 	"go/constant"
+	"strings"
 	"testing"
 
 	"github.com/hajimehoshi/ebiten/v2/internal/shaderir"
@@ -1099,3 +1100,97 @@ void main(void) {
 		})
 	}
 }
+
+func TestOutputIndent(t *testing.T) {
+	p := shaderir.Program{
+		Unit: shaderir.Pixels,
+		Funcs: []shaderir.Func{
+			{
+				Index: 0,
+				InParams: []shaderir.Type{
+					{Main: shaderir.Float},
+					{Main: shaderir.Float},
+				},
+				OutParams: []shaderir.Type{
+					{Main: shaderir.Float},
+				},
+				Block: block(
+					nil,
+					3,
+					ifStmt(
+						binaryExpr(shaderir.EqualOp, localVariableExpr(0), localVariableExpr(1)),
+						block(
+							nil,
+							3,
+							blockStmt(
+								block(
+									[]shaderir.Type{
+										{},
+									},
+									3,
+									forStmt(
+										shaderir.Type{Main: shaderir.Int},
+										3,
+										0,
+										100,
+										shaderir.LessThanOp,
+										1,
+										block(
+											nil,
+											4,
+											assignStmt(
+												localVariableExpr(2),
+												localVariableExpr(0),
+											),
+										),
+									),
+								),
+							),
+						),
+						block(
+							nil,
+							3,
+							assignStmt(
+								localVariableExpr(2),
+								localVariableExpr(1),
+							),
+						),
+					),
+				),
+			},
+		},
+	}
+
+	for _, indent := range []string{"\t", "  ", "    "} {
+		vs, fs := glsl.CompileWithIndent(&p, glsl.GLSLVersionDefault, indent)
+		for _, src := range []string{
+			strings.TrimPrefix(vs, glsl.VertexPrelude(glsl.GLSLVersionDefault)),
+			strings.TrimPrefix(fs, glsl.FragmentPrelude(glsl.GLSLVersionDefault)),
+		} {
+			var depth int
+			for _, line := range strings.Split(src, "\n") {
+				if strings.HasPrefix(strings.TrimLeft(line, " \t"), "}") {
+					depth--
+				}
+				if line != "" {
+					if got, want := line[:len(line)-len(strings.TrimLeft(line, " \t"))], strings.Repeat(indent, depth); got != want {
+						t.Errorf("indent %q: line %q: got indentation %q, want %q", indent, line, got, want)
+					}
+				}
+				if strings.HasSuffix(line, "{") {
+					depth++
+				}
+			}
+			if depth != 0 {
+				t.Errorf("indent %q: unbalanced braces: %s", indent, src)
+			}
+		}
+	}
+
+	// Compile is the same as CompileWithIndent with a tab.
+	vs0, fs0 := glsl.Compile(&p, glsl.GLSLVersionDefault)
+	vs1, fs1 := glsl.CompileWithIndent(&p, glsl.GLSLVersionDefault, "\t")
+	if vs0 != vs1 || fs0 != fs1 {
+		t.Errorf("glsl.Compile and glsl.CompileWithIndent with a tab must return the same results")
+	}
+}
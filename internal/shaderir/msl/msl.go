@@ -20,6 +20,7 @@ import (
 	"go/token"
 	"math"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2/internal/shaderir"
@@ -42,6 +43,12 @@ func (c *compileContext) structName(p *shaderir.Program, t *shaderir.Type) strin
 	if n, ok := c.structNames[s]; ok {
 		return n
 	}
+	// Member struct types must be declared before this struct type.
+	for i := range t.Sub {
+		if t.Sub[i].Main == shaderir.Struct {
+			c.structName(p, &t.Sub[i])
+		}
+	}
 	n := fmt.Sprintf("S%d", len(c.structNames))
 	c.structNames[s] = n
 	c.structTypes = append(c.structTypes, *t)
@@ -65,6 +72,14 @@ constexpr sampler texture_sampler{filter::nearest};`
 	return str
 }
 
+// Features returns the features Metal Shading Language supports.
+func Features() []shaderir.Feature {
+	return []shaderir.Feature{
+		shaderir.FeatureDerivatives,
+		shaderir.FeatureIntUniforms,
+	}
+}
+
 func Compile(p *shaderir.Program, vertex, fragment string) (shader string) {
 	c := &compileContext{
 		structNames: map[string]string{},
@@ -214,6 +229,8 @@ func (c *compileContext) varInit(p *shaderir.Program, t *shaderir.Type) string {
 		shaderir.IVec2, shaderir.IVec3, shaderir.IVec4,
 		shaderir.Mat2, shaderir.Mat3, shaderir.Mat4:
 		return fmt.Sprintf("%s(0)", basicTypeString(t.Main))
+	case shaderir.BVec2, shaderir.BVec3, shaderir.BVec4:
+		return fmt.Sprintf("%s(false)", basicTypeString(t.Main))
 	default:
 		t := c.typ(p, t)
 		panic(fmt.Sprintf("?(unexpected type: %s)", t))
@@ -273,6 +290,10 @@ func constantToNumberLiteral(v constant.Value) string {
 	case constant.Float:
 		x, _ := constant.Float64Val(v)
 		if i := math.Floor(x); i == x {
+			// A large integral value might not fit in int64.
+			if math.Abs(i) >= 1<<53 {
+				return strconv.FormatFloat(x, 'e', -1, 32)
+			}
 			return fmt.Sprintf("%d.0", int64(i))
 		}
 		return fmt.Sprintf("%.10e", x)
@@ -404,6 +425,35 @@ func (c *compileContext) block(p *shaderir.Program, topBlock, block *shaderir.Bl
 					panic(fmt.Sprintf("msl: unexpected unit: %d", p.Unit))
 				}
 			}
+			if callee.Type == shaderir.BuiltinFuncExpr && callee.BuiltinFunc == shaderir.TextureLod {
+				switch p.Unit {
+				case shaderir.Texels:
+					return fmt.Sprintf("%s.sample(texture_sampler, %s, level(%s))", args[0], args[1], args[2])
+				case shaderir.Pixels:
+					return fmt.Sprintf("%s.read(static_cast<uint2>(%s), static_cast<uint>(%s))", args[0], args[1], args[2])
+				default:
+					panic(fmt.Sprintf("msl: unexpected unit: %d", p.Unit))
+				}
+			}
+			if callee.Type == shaderir.BuiltinFuncExpr {
+				// The comparison operators work component-wise and return boolean vectors in MSL.
+				switch callee.BuiltinFunc {
+				case shaderir.LessThan:
+					return fmt.Sprintf("(%s) < (%s)", args[0], args[1])
+				case shaderir.LessThanEqual:
+					return fmt.Sprintf("(%s) <= (%s)", args[0], args[1])
+				case shaderir.GreaterThan:
+					return fmt.Sprintf("(%s) > (%s)", args[0], args[1])
+				case shaderir.GreaterThanEqual:
+					return fmt.Sprintf("(%s) >= (%s)", args[0], args[1])
+				case shaderir.Equal:
+					return fmt.Sprintf("(%s) == (%s)", args[0], args[1])
+				case shaderir.NotEqual:
+					return fmt.Sprintf("(%s) != (%s)", args[0], args[1])
+				case shaderir.Not:
+					return fmt.Sprintf("!(%s)", args[0])
+				}
+			}
 			return fmt.Sprintf("%s(%s)", expr(&callee), strings.Join(args, ", "))
 		case shaderir.FieldSelector:
 			return fmt.Sprintf("(%s).%s", expr(&e.Exprs[0]), expr(&e.Exprs[1]))
@@ -480,6 +530,10 @@ func (c *compileContext) block(p *shaderir.Program, topBlock, block *shaderir.Bl
 			lines = append(lines, fmt.Sprintf("%sfor (%s %s = %s; %s %s %s; %s) {", idt, ts, v, init, v, op, end, delta))
 			lines = append(lines, c.block(p, topBlock, s.Blocks[0], level+1)...)
 			lines = append(lines, fmt.Sprintf("%s}", idt))
+		case shaderir.While:
+			lines = append(lines, fmt.Sprintf("%swhile (%s) {", idt, expr(&s.Exprs[0])))
+			lines = append(lines, c.block(p, topBlock, s.Blocks[0], level+1)...)
+			lines = append(lines, fmt.Sprintf("%s}", idt))
 		case shaderir.Continue:
 			lines = append(lines, idt+"continue;")
 		case shaderir.Break:
@@ -494,8 +548,12 @@ func (c *compileContext) block(p *shaderir.Program, topBlock, block *shaderir.Bl
 				lines = append(lines, fmt.Sprintf("%sreturn %s;", idt, expr(&s.Exprs[0])))
 			}
 		case shaderir.Discard:
-			// 'discard' is invoked only in the fragment shader entry point.
-			lines = append(lines, idt+"discard_fragment();", idt+"return float4(0.0);")
+			// 'discard' is invoked only in the fragment shader entry point and the functions called from it.
+			// Returning is required only in the entry point, as a function might return a different type.
+			lines = append(lines, idt+"discard_fragment();")
+			if topBlock == p.FragmentFunc.Block {
+				lines = append(lines, idt+"return float4(0.0);")
+			}
 		default:
 			lines = append(lines, fmt.Sprintf("%s?(unexpected stmt: %d)", idt, s.Type))
 		}
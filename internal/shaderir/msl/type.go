@@ -106,6 +106,12 @@ func basicTypeString(t shaderir.BasicType) string {
 		return "int3"
 	case shaderir.IVec4:
 		return "int4"
+	case shaderir.BVec2:
+		return "bool2"
+	case shaderir.BVec3:
+		return "bool3"
+	case shaderir.BVec4:
+		return "bool4"
 	case shaderir.Mat2:
 		return "float2x2"
 	case shaderir.Mat3:
@@ -141,6 +147,12 @@ func builtinFuncString(f shaderir.BuiltinFunc) string {
 		return "int3"
 	case shaderir.IVec4F:
 		return "int4"
+	case shaderir.BVec2F:
+		return "bool2"
+	case shaderir.BVec3F:
+		return "bool3"
+	case shaderir.BVec4F:
+		return "bool4"
 	case shaderir.Mat2F:
 		return "float2x2"
 	case shaderir.Mat3F:
@@ -151,6 +163,8 @@ func builtinFuncString(f shaderir.BuiltinFunc) string {
 		return "rsqrt"
 	case shaderir.TexelAt:
 		return "?(__texelAt)"
+	case shaderir.TextureLod:
+		return "?(__textureLod)"
 	}
 	return string(f)
 }
@@ -16,6 +16,7 @@
 package shaderir
 
 import (
+	"fmt"
 	"go/constant"
 	"go/token"
 	"sort"
@@ -29,6 +30,19 @@ const (
 	Pixels
 )
 
+// TimeUniformName is the name of the uniform variable for the time builtin.
+// When a shader refers to this name without declaring it, the compiler declares the uniform variable implicitly.
+// The value is the time of the current frame in seconds, and is populated by the runtime.
+const TimeUniformName = "Time"
+
+// Feature represents a feature of a backend that a shader program can require.
+type Feature string
+
+const (
+	FeatureDerivatives Feature = "derivatives"
+	FeatureIntUniforms Feature = "intuniforms"
+)
+
 type Program struct {
 	UniformNames []string
 	Uniforms     []Type
@@ -40,9 +54,37 @@ type Program struct {
 	FragmentFunc FragmentFunc
 	Unit         Unit
 
+	// ImplicitTime reports whether the uniform variable TimeUniformName is declared implicitly.
+	ImplicitTime bool
+
+	// RequiredFeatures is the features the shader program requires from a backend.
+	RequiredFeatures []Feature
+
+	// FloatPrecision and IntPrecision are the default precisions of floats and ints specified by //kage:precision.
+	// These are used only for GLSL ES. PrecisionDefault means highp.
+	FloatPrecision Precision
+	IntPrecision   Precision
+
 	uniformFactors []uint32
 }
 
+// CheckFeatures returns an error if the program requires a feature that is not in available.
+func (p *Program) CheckFeatures(available []Feature) error {
+	for _, f := range p.RequiredFeatures {
+		var found bool
+		for _, a := range available {
+			if f == a {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("shaderir: the shader requires the feature %q but the target doesn't support it", f)
+		}
+	}
+	return nil
+}
+
 type Func struct {
 	Index     int
 	InParams  []Type
@@ -84,6 +126,10 @@ type Stmt struct {
 	ForOp       Op
 	ForDelta    constant.Value
 	InitIndex   int
+
+	// Line is the line number of the statement in the source, starting from 1.
+	// Line is 0 when the statement doesn't correspond to a source line.
+	Line int
 }
 
 type StmtType int
@@ -95,6 +141,8 @@ const (
 	Init
 	If
 	For
+	// While is a loop without a constant bound. Exprs[0] is the condition and Blocks[0] is the body.
+	While
 	Continue
 	Break
 	Return
@@ -192,12 +240,12 @@ func OpFromToken(t token.Token, lhs, rhs Type) (Op, bool) {
 	case token.GEQ:
 		return GreaterThanEqualOp, true
 	case token.EQL:
-		if lhs.IsFloatVector() || lhs.IsIntVector() || rhs.IsFloatVector() || rhs.IsIntVector() {
+		if lhs.IsFloatVector() || lhs.IsIntVector() || lhs.IsBoolVector() || rhs.IsFloatVector() || rhs.IsIntVector() || rhs.IsBoolVector() {
 			return VectorEqualOp, true
 		}
 		return EqualOp, true
 	case token.NEQ:
-		if lhs.IsFloatVector() || lhs.IsIntVector() || rhs.IsFloatVector() || rhs.IsIntVector() {
+		if lhs.IsFloatVector() || lhs.IsIntVector() || lhs.IsBoolVector() || rhs.IsFloatVector() || rhs.IsIntVector() || rhs.IsBoolVector() {
 			return VectorNotEqualOp, true
 		}
 		return NotEqualOp, true
@@ -229,6 +277,9 @@ const (
 	IVec2F      BuiltinFunc = "ivec2"
 	IVec3F      BuiltinFunc = "ivec3"
 	IVec4F      BuiltinFunc = "ivec4"
+	BVec2F      BuiltinFunc = "bvec2"
+	BVec3F      BuiltinFunc = "bvec3"
+	BVec4F      BuiltinFunc = "bvec4"
 	Mat2F       BuiltinFunc = "mat2"
 	Mat3F       BuiltinFunc = "mat3"
 	Mat4F       BuiltinFunc = "mat4"
@@ -273,7 +324,32 @@ const (
 	Dfdy        BuiltinFunc = "dfdy"
 	Fwidth      BuiltinFunc = "fwidth"
 	DiscardF    BuiltinFunc = "discard"
+	Rand        BuiltinFunc = "rand"
 	TexelAt     BuiltinFunc = "__texelAt"
+	TextureLod  BuiltinFunc = "__textureLod"
+
+	// Component-wise comparisons returning boolean vectors, and the functions taking boolean vectors.
+	LessThan         BuiltinFunc = "lessThan"
+	LessThanEqual    BuiltinFunc = "lessThanEqual"
+	GreaterThan      BuiltinFunc = "greaterThan"
+	GreaterThanEqual BuiltinFunc = "greaterThanEqual"
+	Equal            BuiltinFunc = "equal"
+	NotEqual         BuiltinFunc = "notEqual"
+	Any              BuiltinFunc = "any"
+	All              BuiltinFunc = "all"
+	Not              BuiltinFunc = "not"
+)
+
+// Built-in functions that the compiler lowers to other expressions.
+const (
+	LinearToSRGB   BuiltinFunc = "linearToSRGB"
+	SRGBToLinear   BuiltinFunc = "srgbToLinear"
+	Convolve3x3    BuiltinFunc = "convolve3x3"
+	BilinearSample BuiltinFunc = "bilinearSample"
+	AAStep         BuiltinFunc = "aastep"
+	LowpF          BuiltinFunc = "lowp"
+	MediumpF       BuiltinFunc = "mediump"
+	HighpF         BuiltinFunc = "highp"
 )
 
 func ParseBuiltinFunc(str string) (BuiltinFunc, bool) {
@@ -289,6 +365,9 @@ func ParseBuiltinFunc(str string) (BuiltinFunc, bool) {
 		IVec2F,
 		IVec3F,
 		IVec4F,
+		BVec2F,
+		BVec3F,
+		BVec4F,
 		Mat2F,
 		Mat3F,
 		Mat4F,
@@ -330,8 +409,27 @@ func ParseBuiltinFunc(str string) (BuiltinFunc, bool) {
 		Dfdx,
 		Dfdy,
 		Fwidth,
+		LessThan,
+		LessThanEqual,
+		GreaterThan,
+		GreaterThanEqual,
+		Equal,
+		NotEqual,
+		Any,
+		All,
+		Not,
 		DiscardF,
-		TexelAt:
+		Rand,
+		LinearToSRGB,
+		SRGBToLinear,
+		Convolve3x3,
+		BilinearSample,
+		AAStep,
+		LowpF,
+		MediumpF,
+		HighpF,
+		TexelAt,
+		TextureLod:
 		return BuiltinFunc(str), true
 	}
 	return "", false
@@ -374,6 +472,58 @@ func IsValidSwizzling(s string) bool {
 	return false
 }
 
+// UnusedVaryings returns the indices of the varyings that the fragment entry point doesn't use.
+func (p *Program) UnusedVaryings() []int {
+	if p.FragmentFunc.Block == nil {
+		return nil
+	}
+
+	// In the fragment entry point, the local variable 0 is the position and the varyings follow it.
+	used := make([]bool, len(p.Varyings))
+	walkExprs(func(expr *Expr) {
+		if expr.Type != LocalVariable {
+			return
+		}
+		if i := expr.Index - 1; i >= 0 && i < len(used) {
+			used[i] = true
+		}
+	}, p.FragmentFunc.Block)
+
+	var indices []int
+	for i, u := range used {
+		if !u {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// UnusedAttributes returns the indices of the vertex attributes that the vertex entry point doesn't use.
+func (p *Program) UnusedAttributes() []int {
+	if p.VertexFunc.Block == nil {
+		return nil
+	}
+
+	// In the vertex entry point, the local variables from 0 are the attributes.
+	used := make([]bool, len(p.Attributes))
+	walkExprs(func(expr *Expr) {
+		if expr.Type != LocalVariable {
+			return
+		}
+		if i := expr.Index; i < len(used) {
+			used[i] = true
+		}
+	}, p.VertexFunc.Block)
+
+	var indices []int
+	for i, u := range used {
+		if !u {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
 func (p *Program) ReachableFuncsFromBlock(block *Block) []*Func {
 	indexToFunc := map[int]*Func{}
 	for _, f := range p.Funcs {
@@ -461,16 +611,33 @@ func (p *Program) appendReachableUniformVariablesFromBlock(indices []int, block
 	return indices
 }
 
+// reachableUniformVariables returns whether each uniform variable is used in the vertex or the fragment entry point.
+func (p *Program) reachableUniformVariables() []bool {
+	indices := p.appendReachableUniformVariablesFromBlock(nil, p.VertexFunc.Block)
+	indices = p.appendReachableUniformVariablesFromBlock(indices, p.FragmentFunc.Block)
+	reachable := make([]bool, len(p.Uniforms))
+	for _, idx := range indices {
+		reachable[idx] = true
+	}
+	return reachable
+}
+
+// UnusedUniformVariables returns the indices of the uniform variables that are used in neither the vertex nor the fragment entry point.
+func (p *Program) UnusedUniformVariables() []int {
+	var indices []int
+	for i, r := range p.reachableUniformVariables() {
+		if !r {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
 // FilterUniformVariables replaces uniform variables with nil when they are not used.
 // By minimizing uniform variables, more commands can be merged in the graphicscommand package.
 func (p *Program) FilterUniformVariables(uniforms []uint32) {
 	if p.uniformFactors == nil {
-		indices := p.appendReachableUniformVariablesFromBlock(nil, p.VertexFunc.Block)
-		indices = p.appendReachableUniformVariablesFromBlock(indices, p.FragmentFunc.Block)
-		reachableUniforms := make([]bool, len(p.Uniforms))
-		for _, idx := range indices {
-			reachableUniforms[idx] = true
-		}
+		reachableUniforms := p.reachableUniformVariables()
 		p.uniformFactors = make([]uint32, len(uniforms))
 		var idx int
 		for i, typ := range p.Uniforms {
@@ -0,0 +1,217 @@
+// Copyright 2023 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spirv
+
+import (
+	"github.com/hajimehoshi/ebiten/v2/internal/shaderir"
+)
+
+func (c *compileContext) block(block *shaderir.Block) {
+	if block == nil {
+		return
+	}
+
+	for i, t := range block.LocalVars {
+		// The type is None e.g., when the variable is a for-loop counter.
+		if t.Main == shaderir.None {
+			continue
+		}
+		// A variable without an initializer is initialized with the zero value every time the block is executed.
+		id := c.variable(t)
+		c.emit(opStore, id, c.constNull(t))
+		c.locals[block.LocalVarIndexOffset+i] = local{id: id, pointer: true, storage: storageClassFunction, typ: t}
+	}
+
+	for _, s := range block.Stmts {
+		// A block cannot have instructions after its termination. The rest of the statements are unreachable.
+		if c.terminated {
+			break
+		}
+		c.stmt(&s)
+	}
+}
+
+func (c *compileContext) stmt(s *shaderir.Stmt) {
+	p := c.p
+
+	switch s.Type {
+	case shaderir.ExprStmt:
+		c.expr(&s.Exprs[0], shaderir.None)
+	case shaderir.BlockStmt:
+		c.block(s.Blocks[0])
+	case shaderir.Assign:
+		ptr, t, swizzling := c.lvalue(&s.Exprs[0])
+		rhs := c.expr(&s.Exprs[1], componentType(t))
+		c.store(ptr, t, swizzling, rhs)
+	case shaderir.Init:
+		if c.topBlock == p.VertexFunc.Block {
+			// In the vertex function, varying values are the output parameters.
+			// These values are not needed to be initialized.
+			if s.InitIndex < len(p.Attributes)+len(p.Varyings)+1 {
+				return
+			}
+		}
+		l := c.locals[s.InitIndex]
+		c.emit(opStore, l.id, c.constNull(l.typ))
+	case shaderir.If:
+		cond := c.expr(&s.Exprs[0], shaderir.Bool)
+		then := c.newID()
+		merge := c.newID()
+		els := merge
+		if len(s.Blocks) > 1 {
+			els = c.newID()
+		}
+		c.emit(opSelectionMerge, merge, selectionControlNone)
+		c.terminate(opBranchConditional, cond.id, then, els)
+
+		c.startBlock(then)
+		c.block(s.Blocks[0])
+		if !c.terminated {
+			c.terminate(opBranch, merge)
+		}
+		if len(s.Blocks) > 1 {
+			c.startBlock(els)
+			c.block(s.Blocks[1])
+			if !c.terminated {
+				c.terminate(opBranch, merge)
+			}
+		}
+		c.startBlock(merge)
+	case shaderir.For:
+		t := s.ForVarType
+		v := c.variable(t)
+		c.locals[s.ForVarIndex] = local{id: v, pointer: true, storage: storageClassFunction, typ: t}
+		c.emit(opStore, v, c.constantValue(s.ForInit, componentType(t)).id)
+
+		c.loop(func() value {
+			i := c.emitValue(t, opLoad, v)
+			end := c.constantValue(s.ForEnd, componentType(t))
+			return c.binaryOp(s.ForOp, i, end)
+		}, s.Blocks[0], func() {
+			i := c.emitValue(t, opLoad, v)
+			delta := c.constantValue(s.ForDelta, componentType(t))
+			c.emit(opStore, v, c.binaryOp(shaderir.Add, i, delta).id)
+		})
+	case shaderir.While:
+		c.loop(func() value {
+			return c.expr(&s.Exprs[0], shaderir.Bool)
+		}, s.Blocks[0], nil)
+	case shaderir.Continue:
+		c.terminate(opBranch, c.loops[len(c.loops)-1].continueLabel)
+	case shaderir.Break:
+		c.terminate(opBranch, c.loops[len(c.loops)-1].mergeLabel)
+	case shaderir.Return:
+		switch {
+		case c.topBlock == p.VertexFunc.Block:
+			c.terminate(opReturn)
+		case c.topBlock == p.FragmentFunc.Block:
+			v := c.expr(&s.Exprs[0], shaderir.Float)
+			c.emit(opStore, c.fragColor, v.id)
+			c.terminate(opReturn)
+		case len(s.Exprs) == 0:
+			c.terminate(opReturn)
+		default:
+			v := c.expr(&s.Exprs[0], componentType(c.ret))
+			c.terminate(opReturnValue, v.id)
+		}
+	case shaderir.Discard:
+		c.terminate(opKill)
+	default:
+		c.errorf("unexpected statement: %d", s.Type)
+	}
+}
+
+// loop emits a structured loop.
+// cond returns the condition to continue the loop, and cont is called at the end of every iteration if not nil.
+func (c *compileContext) loop(cond func() value, body *shaderir.Block, cont func()) {
+	header := c.newID()
+	condLabel := c.newID()
+	bodyLabel := c.newID()
+	contLabel := c.newID()
+	merge := c.newID()
+
+	c.terminate(opBranch, header)
+
+	c.startBlock(header)
+	c.emit(opLoopMerge, merge, contLabel, loopControlNone)
+	c.terminate(opBranch, condLabel)
+
+	c.startBlock(condLabel)
+	v := cond()
+	c.terminate(opBranchConditional, v.id, bodyLabel, merge)
+
+	c.startBlock(bodyLabel)
+	c.loops = append(c.loops, loop{
+		continueLabel: contLabel,
+		mergeLabel:    merge,
+	})
+	c.block(body)
+	c.loops = c.loops[:len(c.loops)-1]
+	if !c.terminated {
+		c.terminate(opBranch, contLabel)
+	}
+
+	c.startBlock(contLabel)
+	if cont != nil {
+		cont()
+	}
+	c.terminate(opBranch, header)
+
+	c.startBlock(merge)
+}
+
+// lvalue returns the pointer to the variable that e refers to, and the type of the variable.
+// If e is a swizzling with multiple components, the pointer is to the vector and swizzling is not empty.
+func (c *compileContext) lvalue(e *shaderir.Expr) (ptr uint32, t shaderir.Type, swizzling string) {
+	if e.Type == shaderir.FieldSelector && e.Exprs[1].Type == shaderir.SwizzlingExpr && len(e.Exprs[1].Swizzling) > 1 {
+		if !c.addressable(&e.Exprs[0]) {
+			c.errorf("cannot assign to a swizzling of a non-variable")
+			return 0, shaderir.Type{}, ""
+		}
+		ref := c.reference(&e.Exprs[0])
+		return c.accessChain(ref), ref.typ, e.Exprs[1].Swizzling
+	}
+	if !c.addressable(e) {
+		c.errorf("cannot assign to a non-variable")
+		return 0, shaderir.Type{}, ""
+	}
+	ref := c.reference(e)
+	return c.accessChain(ref), ref.typ, ""
+}
+
+// store stores v to ptr to a variable of t.
+// If swizzling is not empty, only the components of the vector specified by swizzling are replaced.
+func (c *compileContext) store(ptr uint32, t shaderir.Type, swizzling string, v value) {
+	if c.err != nil {
+		return
+	}
+	if swizzling == "" {
+		c.emit(opStore, ptr, v.id)
+		return
+	}
+
+	// Shuffle the components of the current vector and v.
+	old := c.emitValue(t, opLoad, ptr)
+	n := vectorLength(t)
+	comps := make([]uint32, n)
+	for i := range comps {
+		comps[i] = uint32(i)
+	}
+	for i := 0; i < len(swizzling); i++ {
+		comps[swizzleIndex(swizzling[i])] = uint32(n + i)
+	}
+	nv := c.emitValue(t, opVectorShuffle, append([]uint32{old.id, v.id}, comps...)...)
+	c.emit(opStore, ptr, nv.id)
+}
@@ -0,0 +1,448 @@
+// Copyright 2023 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spirv
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type operandKind int
+
+const (
+	kindID operandKind = iota
+	kindLiteral
+	kindString
+	kindConstant
+	kindIDs
+	kindLiterals
+	kindCapability
+	kindAddressingModel
+	kindMemoryModel
+	kindExecutionModel
+	kindExecutionMode
+	kindStorageClass
+	kindDecoration
+	kindDim
+	kindImageFormat
+	kindImageOperands
+	kindFunctionControl
+	kindSelectionControl
+	kindLoopControl
+	kindExtInst
+)
+
+type instructionInfo struct {
+	name       string
+	resultType bool
+	result     bool
+	operands   []operandKind
+}
+
+func typeInfo(name string, operands ...operandKind) instructionInfo {
+	return instructionInfo{name: name, result: true, operands: operands}
+}
+
+func valueInfo(name string, operands ...operandKind) instructionInfo {
+	return instructionInfo{name: name, resultType: true, result: true, operands: operands}
+}
+
+func info(name string, operands ...operandKind) instructionInfo {
+	return instructionInfo{name: name, operands: operands}
+}
+
+var instructionInfos = map[opcode]instructionInfo{
+	opName:                   info("OpName", kindID, kindString),
+	opExtInstImport:          typeInfo("OpExtInstImport", kindString),
+	opExtInst:                valueInfo("OpExtInst", kindID, kindExtInst, kindIDs),
+	opMemoryModel:            info("OpMemoryModel", kindAddressingModel, kindMemoryModel),
+	opEntryPoint:             info("OpEntryPoint", kindExecutionModel, kindID, kindString, kindIDs),
+	opExecutionMode:          info("OpExecutionMode", kindID, kindExecutionMode, kindLiterals),
+	opCapability:             info("OpCapability", kindCapability),
+	opTypeVoid:               typeInfo("OpTypeVoid"),
+	opTypeBool:               typeInfo("OpTypeBool"),
+	opTypeInt:                typeInfo("OpTypeInt", kindLiteral, kindLiteral),
+	opTypeFloat:              typeInfo("OpTypeFloat", kindLiteral),
+	opTypeVector:             typeInfo("OpTypeVector", kindID, kindLiteral),
+	opTypeMatrix:             typeInfo("OpTypeMatrix", kindID, kindLiteral),
+	opTypeImage:              typeInfo("OpTypeImage", kindID, kindDim, kindLiteral, kindLiteral, kindLiteral, kindLiteral, kindImageFormat),
+	opTypeSampledImage:       typeInfo("OpTypeSampledImage", kindID),
+	opTypeArray:              typeInfo("OpTypeArray", kindID, kindID),
+	opTypeStruct:             typeInfo("OpTypeStruct", kindIDs),
+	opTypePointer:            typeInfo("OpTypePointer", kindStorageClass, kindID),
+	opTypeFunction:           typeInfo("OpTypeFunction", kindID, kindIDs),
+	opConstantTrue:           valueInfo("OpConstantTrue"),
+	opConstantFalse:          valueInfo("OpConstantFalse"),
+	opConstant:               valueInfo("OpConstant", kindConstant),
+	opConstantNull:           valueInfo("OpConstantNull"),
+	opFunction:               valueInfo("OpFunction", kindFunctionControl, kindID),
+	opFunctionParameter:      valueInfo("OpFunctionParameter"),
+	opFunctionEnd:            info("OpFunctionEnd"),
+	opFunctionCall:           valueInfo("OpFunctionCall", kindID, kindIDs),
+	opVariable:               valueInfo("OpVariable", kindStorageClass, kindIDs),
+	opLoad:                   valueInfo("OpLoad", kindID),
+	opStore:                  info("OpStore", kindID, kindID),
+	opAccessChain:            valueInfo("OpAccessChain", kindID, kindIDs),
+	opDecorate:               info("OpDecorate", kindID, kindDecoration),
+	opMemberDecorate:         info("OpMemberDecorate", kindID, kindLiteral, kindDecoration),
+	opVectorExtractDynamic:   valueInfo("OpVectorExtractDynamic", kindID, kindID),
+	opVectorShuffle:          valueInfo("OpVectorShuffle", kindID, kindID, kindLiterals),
+	opCompositeConstruct:     valueInfo("OpCompositeConstruct", kindIDs),
+	opCompositeExtract:       valueInfo("OpCompositeExtract", kindID, kindLiterals),
+	opTranspose:              valueInfo("OpTranspose", kindID),
+	opImageSampleExplicitLod: valueInfo("OpImageSampleExplicitLod", kindID, kindID, kindImageOperands),
+	opImageFetch:             valueInfo("OpImageFetch", kindID, kindID, kindImageOperands),
+	opImage:                  valueInfo("OpImage", kindID),
+	opConvertFToS:            valueInfo("OpConvertFToS", kindID),
+	opConvertSToF:            valueInfo("OpConvertSToF", kindID),
+	opSNegate:                valueInfo("OpSNegate", kindID),
+	opFNegate:                valueInfo("OpFNegate", kindID),
+	opIAdd:                   valueInfo("OpIAdd", kindID, kindID),
+	opFAdd:                   valueInfo("OpFAdd", kindID, kindID),
+	opISub:                   valueInfo("OpISub", kindID, kindID),
+	opFSub:                   valueInfo("OpFSub", kindID, kindID),
+	opIMul:                   valueInfo("OpIMul", kindID, kindID),
+	opFMul:                   valueInfo("OpFMul", kindID, kindID),
+	opSDiv:                   valueInfo("OpSDiv", kindID, kindID),
+	opFDiv:                   valueInfo("OpFDiv", kindID, kindID),
+	opSRem:                   valueInfo("OpSRem", kindID, kindID),
+	opFRem:                   valueInfo("OpFRem", kindID, kindID),
+	opFMod:                   valueInfo("OpFMod", kindID, kindID),
+	opVectorTimesScalar:      valueInfo("OpVectorTimesScalar", kindID, kindID),
+	opMatrixTimesScalar:      valueInfo("OpMatrixTimesScalar", kindID, kindID),
+	opVectorTimesMatrix:      valueInfo("OpVectorTimesMatrix", kindID, kindID),
+	opMatrixTimesVector:      valueInfo("OpMatrixTimesVector", kindID, kindID),
+	opMatrixTimesMatrix:      valueInfo("OpMatrixTimesMatrix", kindID, kindID),
+	opDot:                    valueInfo("OpDot", kindID, kindID),
+	opAny:                    valueInfo("OpAny", kindID),
+	opAll:                    valueInfo("OpAll", kindID),
+	opLogicalEqual:           valueInfo("OpLogicalEqual", kindID, kindID),
+	opLogicalNotEqual:        valueInfo("OpLogicalNotEqual", kindID, kindID),
+	opLogicalOr:              valueInfo("OpLogicalOr", kindID, kindID),
+	opLogicalAnd:             valueInfo("OpLogicalAnd", kindID, kindID),
+	opLogicalNot:             valueInfo("OpLogicalNot", kindID),
+	opSelect:                 valueInfo("OpSelect", kindID, kindID, kindID),
+	opIEqual:                 valueInfo("OpIEqual", kindID, kindID),
+	opINotEqual:              valueInfo("OpINotEqual", kindID, kindID),
+	opSGreaterThan:           valueInfo("OpSGreaterThan", kindID, kindID),
+	opSGreaterThanEqual:      valueInfo("OpSGreaterThanEqual", kindID, kindID),
+	opSLessThan:              valueInfo("OpSLessThan", kindID, kindID),
+	opSLessThanEqual:         valueInfo("OpSLessThanEqual", kindID, kindID),
+	opFOrdEqual:              valueInfo("OpFOrdEqual", kindID, kindID),
+	opFUnordNotEqual:         valueInfo("OpFUnordNotEqual", kindID, kindID),
+	opFOrdLessThan:           valueInfo("OpFOrdLessThan", kindID, kindID),
+	opFOrdGreaterThan:        valueInfo("OpFOrdGreaterThan", kindID, kindID),
+	opFOrdLessThanEqual:      valueInfo("OpFOrdLessThanEqual", kindID, kindID),
+	opFOrdGreaterThanEqual:   valueInfo("OpFOrdGreaterThanEqual", kindID, kindID),
+	opShiftRightArithmetic:   valueInfo("OpShiftRightArithmetic", kindID, kindID),
+	opShiftLeftLogical:       valueInfo("OpShiftLeftLogical", kindID, kindID),
+	opBitwiseOr:              valueInfo("OpBitwiseOr", kindID, kindID),
+	opBitwiseXor:             valueInfo("OpBitwiseXor", kindID, kindID),
+	opBitwiseAnd:             valueInfo("OpBitwiseAnd", kindID, kindID),
+	opDPdx:                   valueInfo("OpDPdx", kindID),
+	opDPdy:                   valueInfo("OpDPdy", kindID),
+	opFwidth:                 valueInfo("OpFwidth", kindID),
+	opPhi:                    valueInfo("OpPhi", kindIDs),
+	opLoopMerge:              info("OpLoopMerge", kindID, kindID, kindLoopControl),
+	opSelectionMerge:         info("OpSelectionMerge", kindID, kindSelectionControl),
+	opLabel:                  typeInfo("OpLabel"),
+	opBranch:                 info("OpBranch", kindID),
+	opBranchConditional:      info("OpBranchConditional", kindID, kindID, kindID, kindLiterals),
+	opKill:                   info("OpKill"),
+	opReturn:                 info("OpReturn"),
+	opReturnValue:            info("OpReturnValue", kindID),
+	opUnreachable:            info("OpUnreachable"),
+}
+
+var enumNames = map[operandKind]map[uint32]string{
+	kindCapability: {
+		capabilityShader:  "Shader",
+		capabilityLinkage: "Linkage",
+	},
+	kindAddressingModel: {
+		addressingModelLogical: "Logical",
+	},
+	kindMemoryModel: {
+		memoryModelGLSL450: "GLSL450",
+	},
+	kindExecutionModel: {
+		executionModelVertex:   "Vertex",
+		executionModelFragment: "Fragment",
+	},
+	kindExecutionMode: {
+		executionModeOriginUpperLeft: "OriginUpperLeft",
+	},
+	kindStorageClass: {
+		storageClassUniformConstant: "UniformConstant",
+		storageClassInput:           "Input",
+		storageClassUniform:         "Uniform",
+		storageClassOutput:          "Output",
+		storageClassFunction:        "Function",
+	},
+	kindDecoration: {
+		decorationBufferBlock:   "BufferBlock",
+		decorationColMajor:      "ColMajor",
+		decorationArrayStride:   "ArrayStride",
+		decorationMatrixStride:  "MatrixStride",
+		decorationBuiltIn:       "BuiltIn",
+		decorationFlat:          "Flat",
+		decorationNonWritable:   "NonWritable",
+		decorationLocation:      "Location",
+		decorationBinding:       "Binding",
+		decorationDescriptorSet: "DescriptorSet",
+		decorationOffset:        "Offset",
+	},
+	kindDim: {
+		dim2D: "2D",
+	},
+	kindImageFormat: {
+		imageFormatUnknown: "Unknown",
+	},
+	kindImageOperands: {
+		imageOperandsLod: "Lod",
+	},
+	kindFunctionControl: {
+		functionControlNone: "None",
+	},
+	kindSelectionControl: {
+		selectionControlNone: "None",
+	},
+	kindLoopControl: {
+		loopControlNone: "None",
+	},
+	kindExtInst: {
+		glslFAbs:        "FAbs",
+		glslSAbs:        "SAbs",
+		glslFSign:       "FSign",
+		glslSSign:       "SSign",
+		glslFloor:       "Floor",
+		glslCeil:        "Ceil",
+		glslFract:       "Fract",
+		glslRadians:     "Radians",
+		glslDegrees:     "Degrees",
+		glslSin:         "Sin",
+		glslCos:         "Cos",
+		glslTan:         "Tan",
+		glslAsin:        "Asin",
+		glslAcos:        "Acos",
+		glslAtan:        "Atan",
+		glslAtan2:       "Atan2",
+		glslPow:         "Pow",
+		glslExp:         "Exp",
+		glslLog:         "Log",
+		glslExp2:        "Exp2",
+		glslLog2:        "Log2",
+		glslSqrt:        "Sqrt",
+		glslInverseSqrt: "InverseSqrt",
+		glslFMin:        "FMin",
+		glslSMin:        "SMin",
+		glslFMax:        "FMax",
+		glslSMax:        "SMax",
+		glslFClamp:      "FClamp",
+		glslSClamp:      "SClamp",
+		glslFMix:        "FMix",
+		glslStep:        "Step",
+		glslSmoothStep:  "SmoothStep",
+		glslLength:      "Length",
+		glslDistance:    "Distance",
+		glslCross:       "Cross",
+		glslNormalize:   "Normalize",
+		glslFaceForward: "FaceForward",
+		glslReflect:     "Reflect",
+		glslRefract:     "Refract",
+	},
+}
+
+var builtInNames = map[uint32]string{
+	builtInPosition:  "Position",
+	builtInFragCoord: "FragCoord",
+}
+
+func enumName(kind operandKind, v uint32) string {
+	if n, ok := enumNames[kind][v]; ok {
+		return n
+	}
+	return strconv.FormatUint(uint64(v), 10)
+}
+
+// Disassemble returns the text representation of a SPIR-V module generated by Compile.
+//
+// Disassemble accepts only the instructions Compile emits.
+// Disassemble returns an error if the module is malformed, e.g., an instruction refers to an undefined ID.
+func Disassemble(module []byte) (string, error) {
+	if len(module)%4 != 0 || len(module) < 4*5 {
+		return "", fmt.Errorf("spirv: invalid module size: %d", len(module))
+	}
+	words := make([]uint32, len(module)/4)
+	for i := range words {
+		words[i] = binary.LittleEndian.Uint32(module[4*i:])
+	}
+	if words[0] != magicNumber {
+		return "", fmt.Errorf("spirv: invalid magic number: 0x%08x", words[0])
+	}
+	bound := words[3]
+
+	var lines []string
+	lines = append(lines, "; SPIR-V")
+	lines = append(lines, fmt.Sprintf("; Version: %d.%d", words[1]>>16&0xff, words[1]>>8&0xff))
+	lines = append(lines, fmt.Sprintf("; Bound: %d", bound))
+
+	defined := map[uint32]struct{}{}
+	var referred []uint32
+	floatTypes := map[uint32]struct{}{}
+
+	for i := 5; i < len(words); {
+		count := int(words[i] >> 16)
+		op := opcode(words[i] & 0xffff)
+		if count == 0 || i+count > len(words) {
+			return "", fmt.Errorf("spirv: invalid word count %d at word %d", count, i)
+		}
+		operands := words[i+1 : i+count]
+		i += count
+
+		info, ok := instructionInfos[op]
+		if !ok {
+			return "", fmt.Errorf("spirv: unexpected opcode: %d", op)
+		}
+
+		var strs []string
+		var resultType uint32
+		var prefix string
+		if info.resultType {
+			if len(operands) == 0 {
+				return "", fmt.Errorf("spirv: %s: missing result type", info.name)
+			}
+			resultType = operands[0]
+			referred = append(referred, resultType)
+			strs = append(strs, fmt.Sprintf("%%%d", resultType))
+			operands = operands[1:]
+		}
+		if info.result {
+			if len(operands) == 0 {
+				return "", fmt.Errorf("spirv: %s: missing result", info.name)
+			}
+			id := operands[0]
+			if id == 0 || id >= bound {
+				return "", fmt.Errorf("spirv: %s: ID %d is out of the bound", info.name, id)
+			}
+			if _, ok := defined[id]; ok {
+				return "", fmt.Errorf("spirv: %s: ID %d is defined twice", info.name, id)
+			}
+			defined[id] = struct{}{}
+			prefix = fmt.Sprintf("%%%d = ", id)
+			if op == opTypeFloat {
+				floatTypes[id] = struct{}{}
+			}
+			operands = operands[1:]
+		}
+
+		for _, kind := range info.operands {
+			switch kind {
+			case kindIDs:
+				for _, id := range operands {
+					referred = append(referred, id)
+					strs = append(strs, fmt.Sprintf("%%%d", id))
+				}
+				operands = nil
+				continue
+			case kindLiterals:
+				for _, l := range operands {
+					strs = append(strs, strconv.FormatUint(uint64(l), 10))
+				}
+				operands = nil
+				continue
+			}
+
+			if len(operands) == 0 {
+				return "", fmt.Errorf("spirv: %s: missing operands", info.name)
+			}
+			switch kind {
+			case kindID:
+				referred = append(referred, operands[0])
+				strs = append(strs, fmt.Sprintf("%%%d", operands[0]))
+				operands = operands[1:]
+			case kindLiteral:
+				strs = append(strs, strconv.FormatUint(uint64(operands[0]), 10))
+				operands = operands[1:]
+			case kindConstant:
+				if _, ok := floatTypes[resultType]; ok {
+					strs = append(strs, strconv.FormatFloat(float64(math.Float32frombits(operands[0])), 'g', -1, 32))
+				} else {
+					strs = append(strs, strconv.FormatInt(int64(int32(operands[0])), 10))
+				}
+				operands = operands[1:]
+			case kindString:
+				var bs []byte
+				n := -1
+				for j, w := range operands {
+					var b [4]byte
+					binary.LittleEndian.PutUint32(b[:], w)
+					if idx := strings.IndexByte(string(b[:]), 0); idx >= 0 {
+						bs = append(bs, b[:idx]...)
+						n = j + 1
+						break
+					}
+					bs = append(bs, b[:]...)
+				}
+				if n < 0 {
+					return "", fmt.Errorf("spirv: %s: unterminated string", info.name)
+				}
+				strs = append(strs, strconv.Quote(string(bs)))
+				operands = operands[n:]
+			case kindDecoration:
+				strs = append(strs, enumName(kind, operands[0]))
+				if operands[0] == decorationBuiltIn && len(operands) > 1 {
+					strs = append(strs, builtInNames[operands[1]])
+					operands = operands[2:]
+					continue
+				}
+				for _, l := range operands[1:] {
+					strs = append(strs, strconv.FormatUint(uint64(l), 10))
+				}
+				operands = nil
+			case kindImageOperands:
+				strs = append(strs, enumName(kind, operands[0]))
+				for _, id := range operands[1:] {
+					referred = append(referred, id)
+					strs = append(strs, fmt.Sprintf("%%%d", id))
+				}
+				operands = nil
+			default:
+				strs = append(strs, enumName(kind, operands[0]))
+				operands = operands[1:]
+			}
+		}
+		if len(operands) > 0 {
+			return "", fmt.Errorf("spirv: %s: too many operands", info.name)
+		}
+
+		line := prefix + info.name
+		if len(strs) > 0 {
+			line += " " + strings.Join(strs, " ")
+		}
+		lines = append(lines, line)
+	}
+
+	for _, id := range referred {
+		if _, ok := defined[id]; !ok {
+			return "", fmt.Errorf("spirv: ID %d is not defined", id)
+		}
+	}
+
+	return strings.Join(lines, "\n") + "\n", nil
+}
@@ -0,0 +1,876 @@
+// Copyright 2023 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spirv
+
+import (
+	"go/constant"
+
+	"github.com/hajimehoshi/ebiten/v2/internal/shaderir"
+)
+
+var glslInstructions = map[shaderir.BuiltinFunc]uint32{
+	shaderir.Radians:     glslRadians,
+	shaderir.Degrees:     glslDegrees,
+	shaderir.Sin:         glslSin,
+	shaderir.Cos:         glslCos,
+	shaderir.Tan:         glslTan,
+	shaderir.Asin:        glslAsin,
+	shaderir.Acos:        glslAcos,
+	shaderir.Atan:        glslAtan,
+	shaderir.Atan2:       glslAtan2,
+	shaderir.Pow:         glslPow,
+	shaderir.Exp:         glslExp,
+	shaderir.Log:         glslLog,
+	shaderir.Exp2:        glslExp2,
+	shaderir.Log2:        glslLog2,
+	shaderir.Sqrt:        glslSqrt,
+	shaderir.Inversesqrt: glslInverseSqrt,
+	shaderir.Floor:       glslFloor,
+	shaderir.Ceil:        glslCeil,
+	shaderir.Fract:       glslFract,
+	shaderir.Cross:       glslCross,
+	shaderir.Normalize:   glslNormalize,
+	shaderir.Faceforward: glslFaceForward,
+	shaderir.Reflect:     glslReflect,
+	shaderir.Refract:     glslRefract,
+}
+
+// expr evaluates e.
+// An untyped constant in e is converted to hint if possible.
+func (c *compileContext) expr(e *shaderir.Expr, hint shaderir.BasicType) value {
+	if c.err != nil {
+		return value{}
+	}
+
+	switch e.Type {
+	case shaderir.NumberExpr:
+		return c.constantValue(e.Const, hint)
+	case shaderir.UniformVariable, shaderir.LocalVariable, shaderir.FieldSelector, shaderir.Index:
+		if e.Type == shaderir.LocalVariable {
+			if _, ok := c.locals[e.Index]; !ok {
+				return c.errorf("unexpected local variable: %d", e.Index)
+			}
+		}
+		if c.addressable(e) {
+			return c.load(c.reference(e))
+		}
+		switch e.Type {
+		case shaderir.LocalVariable:
+			l := c.locals[e.Index]
+			return value{id: l.id, typ: l.typ}
+		case shaderir.FieldSelector:
+			return c.fieldSelector(e)
+		case shaderir.Index:
+			return c.index(e)
+		}
+	case shaderir.Unary:
+		v := c.expr(&e.Exprs[0], hint)
+		switch e.Op {
+		case shaderir.Add:
+			return v
+		case shaderir.Sub:
+			switch {
+			case v.typ.IsMatrix():
+				return c.emitValue(v.typ, opMatrixTimesScalar, v.id, c.constFloat(-1))
+			case componentType(v.typ) == shaderir.Float:
+				return c.emitValue(v.typ, opFNegate, v.id)
+			case componentType(v.typ) == shaderir.Int:
+				return c.emitValue(v.typ, opSNegate, v.id)
+			}
+		case shaderir.NotOp:
+			return c.emitValue(v.typ, opLogicalNot, v.id)
+		}
+		return c.errorf("unexpected unary operator %d for %s", e.Op, v.typ.String())
+	case shaderir.Binary:
+		if (e.Op == shaderir.AndAnd || e.Op == shaderir.OrOr) && hasCall(&e.Exprs[1]) {
+			return c.shortCircuit(e)
+		}
+		switch e.Op {
+		case shaderir.LessThanOp, shaderir.LessThanEqualOp, shaderir.GreaterThanOp, shaderir.GreaterThanEqualOp,
+			shaderir.EqualOp, shaderir.NotEqualOp, shaderir.VectorEqualOp, shaderir.VectorNotEqualOp,
+			shaderir.AndAnd, shaderir.OrOr:
+			// The operands of a comparison don't have the type of the result.
+			hint = shaderir.None
+		}
+		vs := c.exprs(e.Exprs, hint)
+		return c.binaryOp(e.Op, vs[0], vs[1])
+	case shaderir.Selection:
+		return c.selection(e, hint)
+	case shaderir.Call:
+		switch callee := &e.Exprs[0]; callee.Type {
+		case shaderir.BuiltinFuncExpr:
+			return c.builtinCall(callee.BuiltinFunc, e.Exprs[1:])
+		case shaderir.FunctionExpr:
+			return c.call(callee.Index, e.Exprs[1:])
+		}
+	}
+	return c.errorf("unexpected expression: %d", e.Type)
+}
+
+// exprs evaluates es in order.
+// An untyped constant is converted to the component type of the first non-constant value, or hint if all the values are constants.
+// As a constant doesn't emit any instruction, the evaluation order is kept.
+func (c *compileContext) exprs(es []shaderir.Expr, hint shaderir.BasicType) []value {
+	vs := make([]value, len(es))
+	comp := shaderir.None
+	for i := range es {
+		if es[i].Type == shaderir.NumberExpr {
+			continue
+		}
+		vs[i] = c.expr(&es[i], hint)
+		if comp == shaderir.None {
+			comp = componentType(vs[i].typ)
+		}
+	}
+	if comp == shaderir.None {
+		comp = hint
+	}
+	for i := range es {
+		if es[i].Type == shaderir.NumberExpr {
+			vs[i] = c.expr(&es[i], comp)
+		}
+	}
+	return vs
+}
+
+// addressable reports whether e refers to a variable or a part of a variable.
+func (c *compileContext) addressable(e *shaderir.Expr) bool {
+	switch e.Type {
+	case shaderir.UniformVariable:
+		return true
+	case shaderir.LocalVariable:
+		return c.locals[e.Index].pointer
+	case shaderir.FieldSelector:
+		if e.Exprs[1].Type == shaderir.SwizzlingExpr && len(e.Exprs[1].Swizzling) > 1 {
+			return false
+		}
+		return c.addressable(&e.Exprs[0])
+	case shaderir.Index:
+		return c.addressable(&e.Exprs[0])
+	}
+	return false
+}
+
+// reference returns the reference to the variable that e refers to.
+// e must be addressable.
+func (c *compileContext) reference(e *shaderir.Expr) reference {
+	switch e.Type {
+	case shaderir.UniformVariable:
+		return reference{
+			base:    c.uniforms,
+			storage: storageClassUniform,
+			indices: []uint32{c.constInt(int32(e.Index))},
+			typ:     c.p.Uniforms[e.Index],
+			layout:  true,
+		}
+	case shaderir.LocalVariable:
+		l := c.locals[e.Index]
+		return reference{
+			base:    l.id,
+			storage: l.storage,
+			typ:     l.typ,
+		}
+	case shaderir.FieldSelector:
+		ref := c.reference(&e.Exprs[0])
+		var idx uint32
+		switch sel := &e.Exprs[1]; sel.Type {
+		case shaderir.StructMember:
+			idx = uint32(sel.Index)
+			ref.typ = ref.typ.Sub[sel.Index]
+		case shaderir.SwizzlingExpr:
+			idx = swizzleIndex(sel.Swizzling[0])
+			ref.typ = shaderir.Type{Main: componentType(ref.typ)}
+		}
+		ref.indices = append(ref.indices, c.constInt(int32(idx)))
+		return ref
+	case shaderir.Index:
+		ref := c.reference(&e.Exprs[0])
+		idx := c.expr(&e.Exprs[1], shaderir.Int)
+		ref.indices = append(ref.indices, idx.id)
+		ref.typ = elementType(ref.typ)
+		return ref
+	}
+	c.errorf("unexpected expression for a reference: %d", e.Type)
+	return reference{}
+}
+
+func (c *compileContext) accessChain(ref reference) uint32 {
+	if len(ref.indices) == 0 {
+		return ref.base
+	}
+	ptr := c.pointerType(ref.storage, c.typ(&ref.typ, ref.layout))
+	return c.emitID(ptr, opAccessChain, append([]uint32{ref.base}, ref.indices...)...)
+}
+
+func (c *compileContext) load(ref reference) value {
+	if ref.layout && ref.typ.Main == shaderir.Array {
+		// An array in the uniform block has an explicit layout and is a different type from a regular array.
+		// Load the elements one by one.
+		ids := make([]uint32, ref.typ.Length)
+		for i := range ids {
+			elem := ref
+			elem.indices = append(append([]uint32{}, ref.indices...), c.constInt(int32(i)))
+			elem.typ = ref.typ.Sub[0]
+			ids[i] = c.load(elem).id
+		}
+		return c.emitValue(ref.typ, opCompositeConstruct, ids...)
+	}
+	return c.emitValue(ref.typ, opLoad, c.accessChain(ref))
+}
+
+func (c *compileContext) fieldSelector(e *shaderir.Expr) value {
+	base := c.expr(&e.Exprs[0], shaderir.None)
+	switch sel := &e.Exprs[1]; sel.Type {
+	case shaderir.StructMember:
+		return c.emitValue(base.typ.Sub[sel.Index], opCompositeExtract, base.id, uint32(sel.Index))
+	case shaderir.SwizzlingExpr:
+		if !shaderir.IsValidSwizzling(sel.Swizzling) {
+			return c.errorf("unexpected swizzling: %s", sel.Swizzling)
+		}
+		comp := componentType(base.typ)
+		if len(sel.Swizzling) == 1 {
+			return c.emitValue(shaderir.Type{Main: comp}, opCompositeExtract, base.id, swizzleIndex(sel.Swizzling[0]))
+		}
+		operands := []uint32{base.id, base.id}
+		for i := 0; i < len(sel.Swizzling); i++ {
+			operands = append(operands, swizzleIndex(sel.Swizzling[i]))
+		}
+		return c.emitValue(vectorType(comp, len(sel.Swizzling)), opVectorShuffle, operands...)
+	}
+	return c.errorf("unexpected selector: %d", e.Exprs[1].Type)
+}
+
+func (c *compileContext) index(e *shaderir.Expr) value {
+	base := c.expr(&e.Exprs[0], shaderir.None)
+	t := elementType(base.typ)
+	if idx := &e.Exprs[1]; idx.Type == shaderir.NumberExpr {
+		i, _ := constant.Int64Val(constant.ToInt(idx.Const))
+		return c.emitValue(t, opCompositeExtract, base.id, uint32(i))
+	}
+	if vectorLength(base.typ) > 0 {
+		idx := c.expr(&e.Exprs[1], shaderir.Int)
+		return c.emitValue(t, opVectorExtractDynamic, base.id, idx.id)
+	}
+	// A value cannot be indexed dynamically. Copy the value to a variable.
+	v := c.variable(base.typ)
+	c.emit(opStore, v, base.id)
+	idx := c.expr(&e.Exprs[1], shaderir.Int)
+	ptr := c.emitID(c.pointerType(storageClassFunction, c.typ(&t, false)), opAccessChain, v, idx.id)
+	return c.emitValue(t, opLoad, ptr)
+}
+
+// splat returns a vector of n components with the scalar value v.
+// If n is 0 or v is already a vector, splat returns v.
+func (c *compileContext) splat(v value, n int) value {
+	if n == 0 || vectorLength(v.typ) > 0 {
+		return v
+	}
+	ids := make([]uint32, n)
+	for i := range ids {
+		ids[i] = v.id
+	}
+	return c.emitValue(vectorType(componentType(v.typ), n), opCompositeConstruct, ids...)
+}
+
+// convert converts the components of v to comp.
+func (c *compileContext) convert(v value, comp shaderir.BasicType) value {
+	from := componentType(v.typ)
+	if from == comp {
+		return v
+	}
+	t := vectorType(comp, vectorLength(v.typ))
+	switch {
+	case from == shaderir.Int && comp == shaderir.Float:
+		return c.emitValue(t, opConvertSToF, v.id)
+	case from == shaderir.Float && comp == shaderir.Int:
+		return c.emitValue(t, opConvertFToS, v.id)
+	}
+	return c.errorf("cannot convert %s to %s", v.typ.String(), t.String())
+}
+
+func compareOp(op shaderir.Op, comp shaderir.BasicType) (opcode, bool) {
+	switch comp {
+	case shaderir.Float:
+		switch op {
+		case shaderir.LessThanOp:
+			return opFOrdLessThan, true
+		case shaderir.LessThanEqualOp:
+			return opFOrdLessThanEqual, true
+		case shaderir.GreaterThanOp:
+			return opFOrdGreaterThan, true
+		case shaderir.GreaterThanEqualOp:
+			return opFOrdGreaterThanEqual, true
+		case shaderir.EqualOp, shaderir.VectorEqualOp:
+			return opFOrdEqual, true
+		case shaderir.NotEqualOp, shaderir.VectorNotEqualOp:
+			return opFUnordNotEqual, true
+		}
+	case shaderir.Int:
+		switch op {
+		case shaderir.LessThanOp:
+			return opSLessThan, true
+		case shaderir.LessThanEqualOp:
+			return opSLessThanEqual, true
+		case shaderir.GreaterThanOp:
+			return opSGreaterThan, true
+		case shaderir.GreaterThanEqualOp:
+			return opSGreaterThanEqual, true
+		case shaderir.EqualOp, shaderir.VectorEqualOp:
+			return opIEqual, true
+		case shaderir.NotEqualOp, shaderir.VectorNotEqualOp:
+			return opINotEqual, true
+		}
+	case shaderir.Bool:
+		switch op {
+		case shaderir.EqualOp, shaderir.VectorEqualOp:
+			return opLogicalEqual, true
+		case shaderir.NotEqualOp, shaderir.VectorNotEqualOp:
+			return opLogicalNotEqual, true
+		}
+	}
+	return 0, false
+}
+
+func (c *compileContext) binaryOp(op shaderir.Op, lhs, rhs value) value {
+	if c.err != nil {
+		return value{}
+	}
+
+	boolType := shaderir.Type{Main: shaderir.Bool}
+
+	switch op {
+	case shaderir.AndAnd:
+		return c.emitValue(boolType, opLogicalAnd, lhs.id, rhs.id)
+	case shaderir.OrOr:
+		return c.emitValue(boolType, opLogicalOr, lhs.id, rhs.id)
+	case shaderir.LessThanOp, shaderir.LessThanEqualOp, shaderir.GreaterThanOp, shaderir.GreaterThanEqualOp, shaderir.EqualOp, shaderir.NotEqualOp:
+		opc, ok := compareOp(op, componentType(lhs.typ))
+		if !ok || vectorLength(lhs.typ) > 0 {
+			break
+		}
+		return c.emitValue(boolType, opc, lhs.id, rhs.id)
+	case shaderir.VectorEqualOp, shaderir.VectorNotEqualOp:
+		n := vectorLength(lhs.typ)
+		opc, ok := compareOp(op, componentType(lhs.typ))
+		if !ok || n == 0 {
+			break
+		}
+		v := c.emitValue(vectorType(shaderir.Bool, n), opc, lhs.id, rhs.id)
+		if op == shaderir.VectorEqualOp {
+			return c.emitValue(boolType, opAll, v.id)
+		}
+		return c.emitValue(boolType, opAny, v.id)
+	case shaderir.MatrixMul:
+		switch {
+		case lhs.typ.IsMatrix() && rhs.typ.IsMatrix():
+			return c.emitValue(lhs.typ, opMatrixTimesMatrix, lhs.id, rhs.id)
+		case lhs.typ.IsMatrix() && rhs.typ.IsFloatVector():
+			return c.emitValue(rhs.typ, opMatrixTimesVector, lhs.id, rhs.id)
+		case lhs.typ.IsFloatVector() && rhs.typ.IsMatrix():
+			return c.emitValue(lhs.typ, opVectorTimesMatrix, lhs.id, rhs.id)
+		case lhs.typ.IsMatrix() && rhs.typ.Main == shaderir.Float:
+			return c.emitValue(lhs.typ, opMatrixTimesScalar, lhs.id, rhs.id)
+		case lhs.typ.Main == shaderir.Float && rhs.typ.IsMatrix():
+			return c.emitValue(rhs.typ, opMatrixTimesScalar, rhs.id, lhs.id)
+		}
+	case shaderir.Add, shaderir.Sub, shaderir.ComponentWiseMul, shaderir.Div, shaderir.ModOp,
+		shaderir.LeftShift, shaderir.RightShift, shaderir.And, shaderir.Or, shaderir.Xor:
+		if lhs.typ.IsMatrix() || rhs.typ.IsMatrix() {
+			return c.columnWise(op, lhs, rhs)
+		}
+		return c.arithmetic(op, lhs, rhs)
+	}
+	return c.errorf("unexpected binary operator %d for %s and %s", op, lhs.typ.String(), rhs.typ.String())
+}
+
+func (c *compileContext) arithmetic(op shaderir.Op, lhs, rhs value) value {
+	comp := componentType(lhs.typ)
+	n := vectorLength(lhs.typ)
+	if m := vectorLength(rhs.typ); m > n {
+		n = m
+	}
+	t := vectorType(comp, n)
+
+	if op == shaderir.ComponentWiseMul && comp == shaderir.Float && n > 0 {
+		switch {
+		case vectorLength(lhs.typ) == 0:
+			return c.emitValue(t, opVectorTimesScalar, rhs.id, lhs.id)
+		case vectorLength(rhs.typ) == 0:
+			return c.emitValue(t, opVectorTimesScalar, lhs.id, rhs.id)
+		}
+	}
+
+	lhs = c.splat(lhs, n)
+	rhs = c.splat(rhs, n)
+
+	var opc opcode
+	switch comp {
+	case shaderir.Float:
+		switch op {
+		case shaderir.Add:
+			opc = opFAdd
+		case shaderir.Sub:
+			opc = opFSub
+		case shaderir.ComponentWiseMul:
+			opc = opFMul
+		case shaderir.Div:
+			opc = opFDiv
+		case shaderir.ModOp:
+			opc = opFRem
+		}
+	case shaderir.Int:
+		switch op {
+		case shaderir.Add:
+			opc = opIAdd
+		case shaderir.Sub:
+			opc = opISub
+		case shaderir.ComponentWiseMul:
+			opc = opIMul
+		case shaderir.Div:
+			opc = opSDiv
+		case shaderir.ModOp:
+			opc = opSRem
+		case shaderir.LeftShift:
+			opc = opShiftLeftLogical
+		case shaderir.RightShift:
+			opc = opShiftRightArithmetic
+		case shaderir.And:
+			opc = opBitwiseAnd
+		case shaderir.Or:
+			opc = opBitwiseOr
+		case shaderir.Xor:
+			opc = opBitwiseXor
+		}
+	}
+	if opc == 0 {
+		return c.errorf("unexpected binary operator %d for %s and %s", op, lhs.typ.String(), rhs.typ.String())
+	}
+	return c.emitValue(t, opc, lhs.id, rhs.id)
+}
+
+// columnWise applies the operator to each column of the matrices, as arithmetic instructions don't take matrices.
+func (c *compileContext) columnWise(op shaderir.Op, lhs, rhs value) value {
+	t := lhs.typ
+	if !t.IsMatrix() {
+		t = rhs.typ
+	}
+	col := columnType(t)
+	column := func(v value, i int) value {
+		if !v.typ.IsMatrix() {
+			return v
+		}
+		return c.emitValue(col, opCompositeExtract, v.id, uint32(i))
+	}
+
+	ids := make([]uint32, vectorLength(col))
+	for i := range ids {
+		ids[i] = c.arithmetic(op, column(lhs, i), column(rhs, i)).id
+	}
+	return c.emitValue(t, opCompositeConstruct, ids...)
+}
+
+func (c *compileContext) phi(t shaderir.Type, values []value, labels []uint32) value {
+	var operands []uint32
+	for i, v := range values {
+		operands = append(operands, v.id, labels[i])
+	}
+	return c.emitValue(t, opPhi, operands...)
+}
+
+// shortCircuit evaluates the right-hand side of && or || only when needed, as it calls a function that might have side effects.
+func (c *compileContext) shortCircuit(e *shaderir.Expr) value {
+	lhs := c.expr(&e.Exprs[0], shaderir.Bool)
+	lhsLabel := c.label
+	rhsLabel := c.newID()
+	merge := c.newID()
+	c.emit(opSelectionMerge, merge, selectionControlNone)
+	if e.Op == shaderir.AndAnd {
+		c.terminate(opBranchConditional, lhs.id, rhsLabel, merge)
+	} else {
+		c.terminate(opBranchConditional, lhs.id, merge, rhsLabel)
+	}
+
+	c.startBlock(rhsLabel)
+	rhs := c.expr(&e.Exprs[1], shaderir.Bool)
+	rhsLabel = c.label
+	c.terminate(opBranch, merge)
+
+	c.startBlock(merge)
+	return c.phi(shaderir.Type{Main: shaderir.Bool}, []value{lhs, rhs}, []uint32{lhsLabel, rhsLabel})
+}
+
+func (c *compileContext) selection(e *shaderir.Expr, hint shaderir.BasicType) value {
+	cond := c.expr(&e.Exprs[0], shaderir.Bool)
+
+	if !hasCall(&e.Exprs[1]) && !hasCall(&e.Exprs[2]) {
+		vs := c.exprs(e.Exprs[1:], hint)
+		t := vs[0].typ
+		if n := vectorLength(t); n > 0 || componentType(t) != shaderir.None && !t.IsMatrix() {
+			return c.emitValue(t, opSelect, c.splat(cond, n).id, vs[0].id, vs[1].id)
+		}
+
+		// OpSelect doesn't take composite types other than vectors.
+		then := c.newID()
+		els := c.newID()
+		merge := c.newID()
+		c.emit(opSelectionMerge, merge, selectionControlNone)
+		c.terminate(opBranchConditional, cond.id, then, els)
+		c.startBlock(then)
+		c.terminate(opBranch, merge)
+		c.startBlock(els)
+		c.terminate(opBranch, merge)
+		c.startBlock(merge)
+		return c.phi(t, vs, []uint32{then, els})
+	}
+
+	// Evaluate only one of the values, as a function call might have side effects.
+	then := c.newID()
+	els := c.newID()
+	merge := c.newID()
+	c.emit(opSelectionMerge, merge, selectionControlNone)
+	c.terminate(opBranchConditional, cond.id, then, els)
+
+	var vs [2]value
+	var labels [2]uint32
+	c.startBlock(then)
+	if e.Exprs[1].Type != shaderir.NumberExpr {
+		vs[0] = c.expr(&e.Exprs[1], hint)
+	}
+	labels[0] = c.label
+	c.terminate(opBranch, merge)
+
+	c.startBlock(els)
+	h := hint
+	if e.Exprs[1].Type != shaderir.NumberExpr {
+		h = componentType(vs[0].typ)
+	}
+	vs[1] = c.expr(&e.Exprs[2], h)
+	labels[1] = c.label
+	c.terminate(opBranch, merge)
+
+	// A constant doesn't emit any instruction and can be evaluated here.
+	if e.Exprs[1].Type == shaderir.NumberExpr {
+		vs[0] = c.expr(&e.Exprs[1], componentType(vs[1].typ))
+	}
+
+	c.startBlock(merge)
+	return c.phi(vs[0].typ, vs[:], labels[:])
+}
+
+func (c *compileContext) call(index int, args []shaderir.Expr) value {
+	f := findFunc(c.p, index)
+	if f == nil {
+		return c.errorf("function not found: %d", index)
+	}
+
+	ids := []uint32{c.funcIDs[index]}
+	for i, t := range f.InParams {
+		v := c.expr(&args[i], componentType(t))
+		ids = append(ids, v.id)
+	}
+
+	// An output argument must be a variable in the function storage. Pass a temporary variable and copy the result.
+	temps := make([]uint32, len(f.OutParams))
+	for i, t := range f.OutParams {
+		temps[i] = c.variable(t)
+		ids = append(ids, temps[i])
+	}
+
+	ret := c.emitValue(f.Return, opFunctionCall, ids...)
+
+	for i, t := range f.OutParams {
+		v := c.emitValue(t, opLoad, temps[i])
+		ptr, t, swizzling := c.lvalue(&args[len(f.InParams)+i])
+		c.store(ptr, t, swizzling, v)
+	}
+
+	return ret
+}
+
+func (c *compileContext) builtinCall(f shaderir.BuiltinFunc, args []shaderir.Expr) value {
+	switch f {
+	case shaderir.TexelAt, shaderir.TextureLod:
+		return c.texelAt(f, args)
+	}
+
+	var hint shaderir.BasicType
+	switch f {
+	case shaderir.BoolF, shaderir.Abs, shaderir.Sign, shaderir.Min, shaderir.Max, shaderir.Clamp,
+		shaderir.LessThan, shaderir.LessThanEqual, shaderir.GreaterThan, shaderir.GreaterThanEqual, shaderir.Equal, shaderir.NotEqual,
+		shaderir.Any, shaderir.All, shaderir.Not, shaderir.BVec2F, shaderir.BVec3F, shaderir.BVec4F:
+		hint = shaderir.None
+	case shaderir.IntF, shaderir.IVec2F, shaderir.IVec3F, shaderir.IVec4F:
+		hint = shaderir.Int
+	default:
+		hint = shaderir.Float
+	}
+	vs := c.exprs(args, hint)
+	if c.err != nil {
+		return value{}
+	}
+
+	floatType := shaderir.Type{Main: shaderir.Float}
+
+	switch f {
+	case shaderir.BoolF:
+		return c.convert(vs[0], shaderir.Bool)
+	case shaderir.IntF:
+		return c.convert(vs[0], shaderir.Int)
+	case shaderir.FloatF:
+		return c.convert(vs[0], shaderir.Float)
+	case shaderir.Vec2F:
+		return c.vectorConstructor(shaderir.Type{Main: shaderir.Vec2}, vs)
+	case shaderir.Vec3F:
+		return c.vectorConstructor(shaderir.Type{Main: shaderir.Vec3}, vs)
+	case shaderir.Vec4F:
+		return c.vectorConstructor(shaderir.Type{Main: shaderir.Vec4}, vs)
+	case shaderir.IVec2F:
+		return c.vectorConstructor(shaderir.Type{Main: shaderir.IVec2}, vs)
+	case shaderir.IVec3F:
+		return c.vectorConstructor(shaderir.Type{Main: shaderir.IVec3}, vs)
+	case shaderir.IVec4F:
+		return c.vectorConstructor(shaderir.Type{Main: shaderir.IVec4}, vs)
+	case shaderir.BVec2F:
+		return c.vectorConstructor(shaderir.Type{Main: shaderir.BVec2}, vs)
+	case shaderir.BVec3F:
+		return c.vectorConstructor(shaderir.Type{Main: shaderir.BVec3}, vs)
+	case shaderir.BVec4F:
+		return c.vectorConstructor(shaderir.Type{Main: shaderir.BVec4}, vs)
+	case shaderir.Mat2F:
+		return c.matrixConstructor(shaderir.Type{Main: shaderir.Mat2}, vs)
+	case shaderir.Mat3F:
+		return c.matrixConstructor(shaderir.Type{Main: shaderir.Mat3}, vs)
+	case shaderir.Mat4F:
+		return c.matrixConstructor(shaderir.Type{Main: shaderir.Mat4}, vs)
+	case shaderir.Abs:
+		if componentType(vs[0].typ) == shaderir.Int {
+			return c.extInst(vs[0].typ, glslSAbs, vs...)
+		}
+		return c.extInst(vs[0].typ, glslFAbs, vs...)
+	case shaderir.Sign:
+		if componentType(vs[0].typ) == shaderir.Int {
+			return c.extInst(vs[0].typ, glslSSign, vs...)
+		}
+		return c.extInst(vs[0].typ, glslFSign, vs...)
+	case shaderir.Min, shaderir.Max, shaderir.Clamp:
+		t := vs[0].typ
+		for i := range vs {
+			vs[i] = c.splat(vs[i], vectorLength(t))
+		}
+		var inst uint32
+		switch f {
+		case shaderir.Min:
+			inst = glslFMin
+			if componentType(t) == shaderir.Int {
+				inst = glslSMin
+			}
+		case shaderir.Max:
+			inst = glslFMax
+			if componentType(t) == shaderir.Int {
+				inst = glslSMax
+			}
+		case shaderir.Clamp:
+			inst = glslFClamp
+			if componentType(t) == shaderir.Int {
+				inst = glslSClamp
+			}
+		}
+		return c.extInst(t, inst, vs...)
+	case shaderir.Mix, shaderir.Step, shaderir.Smoothstep, shaderir.Mod:
+		// The type of the result is the type of the last argument for step and smoothstep, or the first argument otherwise.
+		t := vs[0].typ
+		if f == shaderir.Step || f == shaderir.Smoothstep {
+			t = vs[len(vs)-1].typ
+		}
+		for i := range vs {
+			vs[i] = c.splat(vs[i], vectorLength(t))
+		}
+		switch f {
+		case shaderir.Mix:
+			return c.extInst(t, glslFMix, vs...)
+		case shaderir.Step:
+			return c.extInst(t, glslStep, vs...)
+		case shaderir.Smoothstep:
+			return c.extInst(t, glslSmoothStep, vs...)
+		case shaderir.Mod:
+			// OpFMod's result has the sign of the second operand like mod in GLSL.
+			return c.emitValue(t, opFMod, vs[0].id, vs[1].id)
+		}
+	case shaderir.Length:
+		return c.extInst(floatType, glslLength, vs...)
+	case shaderir.Distance:
+		return c.extInst(floatType, glslDistance, vs...)
+	case shaderir.Dot:
+		if vectorLength(vs[0].typ) == 0 {
+			return c.emitValue(floatType, opFMul, vs[0].id, vs[1].id)
+		}
+		return c.emitValue(floatType, opDot, vs[0].id, vs[1].id)
+	case shaderir.Transpose:
+		return c.emitValue(vs[0].typ, opTranspose, vs[0].id)
+	case shaderir.Dfdx:
+		return c.emitValue(vs[0].typ, opDPdx, vs[0].id)
+	case shaderir.Dfdy:
+		return c.emitValue(vs[0].typ, opDPdy, vs[0].id)
+	case shaderir.Fwidth:
+		return c.emitValue(vs[0].typ, opFwidth, vs[0].id)
+	case shaderir.LessThan, shaderir.LessThanEqual, shaderir.GreaterThan, shaderir.GreaterThanEqual, shaderir.Equal, shaderir.NotEqual:
+		var op shaderir.Op
+		switch f {
+		case shaderir.LessThan:
+			op = shaderir.LessThanOp
+		case shaderir.LessThanEqual:
+			op = shaderir.LessThanEqualOp
+		case shaderir.GreaterThan:
+			op = shaderir.GreaterThanOp
+		case shaderir.GreaterThanEqual:
+			op = shaderir.GreaterThanEqualOp
+		case shaderir.Equal:
+			op = shaderir.EqualOp
+		case shaderir.NotEqual:
+			op = shaderir.NotEqualOp
+		}
+		opc, ok := compareOp(op, componentType(vs[0].typ))
+		if !ok {
+			break
+		}
+		return c.emitValue(vectorType(shaderir.Bool, vectorLength(vs[0].typ)), opc, vs[0].id, vs[1].id)
+	case shaderir.Any:
+		return c.emitValue(shaderir.Type{Main: shaderir.Bool}, opAny, vs[0].id)
+	case shaderir.All:
+		return c.emitValue(shaderir.Type{Main: shaderir.Bool}, opAll, vs[0].id)
+	case shaderir.Not:
+		return c.emitValue(vs[0].typ, opLogicalNot, vs[0].id)
+	}
+
+	if inst, ok := glslInstructions[f]; ok {
+		return c.extInst(vs[0].typ, inst, vs...)
+	}
+	return c.errorf("unexpected built-in function: %s", f)
+}
+
+func (c *compileContext) extInst(t shaderir.Type, inst uint32, args ...value) value {
+	operands := []uint32{c.glsl, inst}
+	for _, a := range args {
+		operands = append(operands, a.id)
+	}
+	return c.emitValue(t, opExtInst, operands...)
+}
+
+func (c *compileContext) vectorConstructor(t shaderir.Type, args []value) value {
+	comp := componentType(t)
+	n := vectorLength(t)
+	if len(args) == 1 {
+		v := c.convert(args[0], comp)
+		switch vectorLength(v.typ) {
+		case 0:
+			return c.splat(v, n)
+		case n:
+			return v
+		}
+	}
+
+	var count int
+	ids := make([]uint32, 0, len(args))
+	for _, a := range args {
+		v := c.convert(a, comp)
+		if m := vectorLength(v.typ); m > 0 {
+			count += m
+		} else {
+			count++
+		}
+		ids = append(ids, v.id)
+	}
+	if count != n {
+		return c.errorf("unexpected number of components for %s: %d", t.String(), count)
+	}
+	return c.emitValue(t, opCompositeConstruct, ids...)
+}
+
+func (c *compileContext) matrixConstructor(t shaderir.Type, args []value) value {
+	col := columnType(t)
+	n := vectorLength(col)
+
+	switch {
+	case len(args) == 1 && args[0].typ.Main == shaderir.Float:
+		// A diagonal matrix.
+		zero := c.constFloat(0)
+		cols := make([]uint32, n)
+		for i := range cols {
+			comps := make([]uint32, n)
+			for j := range comps {
+				comps[j] = zero
+				if i == j {
+					comps[j] = args[0].id
+				}
+			}
+			cols[i] = c.emitValue(col, opCompositeConstruct, comps...).id
+		}
+		return c.emitValue(t, opCompositeConstruct, cols...)
+	case len(args) == 1 && args[0].typ.Equal(&t):
+		return args[0]
+	case len(args) == n:
+		cols := make([]uint32, n)
+		for i, a := range args {
+			if !a.typ.Equal(&col) {
+				return c.errorf("unexpected argument for %s: %s", t.String(), a.typ.String())
+			}
+			cols[i] = a.id
+		}
+		return c.emitValue(t, opCompositeConstruct, cols...)
+	case len(args) == n*n:
+		cols := make([]uint32, n)
+		for i := range cols {
+			comps := make([]uint32, n)
+			for j := range comps {
+				a := args[i*n+j]
+				if a.typ.Main != shaderir.Float {
+					return c.errorf("unexpected argument for %s: %s", t.String(), a.typ.String())
+				}
+				comps[j] = a.id
+			}
+			cols[i] = c.emitValue(col, opCompositeConstruct, comps...).id
+		}
+		return c.emitValue(t, opCompositeConstruct, cols...)
+	}
+	return c.errorf("unexpected arguments for %s", t.String())
+}
+
+func (c *compileContext) texelAt(f shaderir.BuiltinFunc, args []shaderir.Expr) value {
+	if args[0].Type != shaderir.TextureVariable {
+		return c.errorf("unexpected texture: %d", args[0].Type)
+	}
+	vec4 := shaderir.Type{Main: shaderir.Vec4}
+	sampledImage := c.emitID(c.sampledImageType(), opLoad, c.textures[args[0].Index])
+	pos := c.expr(&args[1], shaderir.Float)
+	lod := value{id: c.constFloat(0), typ: shaderir.Type{Main: shaderir.Float}}
+	if f == shaderir.TextureLod {
+		lod = c.expr(&args[2], shaderir.Float)
+	}
+
+	switch c.p.Unit {
+	case shaderir.Texels:
+		// An explicit level of detail is used so that sampling is available in non-uniform control flow.
+		return c.emitValue(vec4, opImageSampleExplicitLod, sampledImage, pos.id, imageOperandsLod, lod.id)
+	case shaderir.Pixels:
+		image := c.emitID(c.imageType(), opImage, sampledImage)
+		ipos := c.convert(pos, shaderir.Int)
+		ilod := value{id: c.constInt(0)}
+		if f == shaderir.TextureLod {
+			ilod = c.convert(lod, shaderir.Int)
+		}
+		return c.emitValue(vec4, opImageFetch, image, ipos.id, imageOperandsLod, ilod.id)
+	}
+	return c.errorf("unexpected unit: %d", c.p.Unit)
+}
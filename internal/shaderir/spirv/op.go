@@ -0,0 +1,210 @@
+// Copyright 2023 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spirv
+
+// The opcodes and the enumerants are from the SPIR-V specification 1.0 and the GLSL.std.450 extended instruction set.
+// Only the ones the compiler emits are listed.
+
+type opcode uint16
+
+const (
+	opName                   opcode = 5
+	opExtInstImport          opcode = 11
+	opExtInst                opcode = 12
+	opMemoryModel            opcode = 14
+	opEntryPoint             opcode = 15
+	opExecutionMode          opcode = 16
+	opCapability             opcode = 17
+	opTypeVoid               opcode = 19
+	opTypeBool               opcode = 20
+	opTypeInt                opcode = 21
+	opTypeFloat              opcode = 22
+	opTypeVector             opcode = 23
+	opTypeMatrix             opcode = 24
+	opTypeImage              opcode = 25
+	opTypeSampledImage       opcode = 27
+	opTypeArray              opcode = 28
+	opTypeStruct             opcode = 30
+	opTypePointer            opcode = 32
+	opTypeFunction           opcode = 33
+	opConstantTrue           opcode = 41
+	opConstantFalse          opcode = 42
+	opConstant               opcode = 43
+	opConstantNull           opcode = 46
+	opFunction               opcode = 54
+	opFunctionParameter      opcode = 55
+	opFunctionEnd            opcode = 56
+	opFunctionCall           opcode = 57
+	opVariable               opcode = 59
+	opLoad                   opcode = 61
+	opStore                  opcode = 62
+	opAccessChain            opcode = 65
+	opDecorate               opcode = 71
+	opMemberDecorate         opcode = 72
+	opVectorExtractDynamic   opcode = 77
+	opVectorShuffle          opcode = 79
+	opCompositeConstruct     opcode = 80
+	opCompositeExtract       opcode = 81
+	opTranspose              opcode = 84
+	opImageSampleExplicitLod opcode = 88
+	opImageFetch             opcode = 95
+	opImage                  opcode = 100
+	opConvertFToS            opcode = 110
+	opConvertSToF            opcode = 111
+	opSNegate                opcode = 126
+	opFNegate                opcode = 127
+	opIAdd                   opcode = 128
+	opFAdd                   opcode = 129
+	opISub                   opcode = 130
+	opFSub                   opcode = 131
+	opIMul                   opcode = 132
+	opFMul                   opcode = 133
+	opSDiv                   opcode = 135
+	opFDiv                   opcode = 136
+	opSRem                   opcode = 138
+	opFRem                   opcode = 140
+	opFMod                   opcode = 141
+	opVectorTimesScalar      opcode = 142
+	opMatrixTimesScalar      opcode = 143
+	opVectorTimesMatrix      opcode = 144
+	opMatrixTimesVector      opcode = 145
+	opMatrixTimesMatrix      opcode = 146
+	opDot                    opcode = 148
+	opAny                    opcode = 154
+	opAll                    opcode = 155
+	opLogicalEqual           opcode = 164
+	opLogicalNotEqual        opcode = 165
+	opLogicalOr              opcode = 166
+	opLogicalAnd             opcode = 167
+	opLogicalNot             opcode = 168
+	opSelect                 opcode = 169
+	opIEqual                 opcode = 170
+	opINotEqual              opcode = 171
+	opSGreaterThan           opcode = 173
+	opSGreaterThanEqual      opcode = 175
+	opSLessThan              opcode = 177
+	opSLessThanEqual         opcode = 179
+	opFOrdEqual              opcode = 180
+	opFUnordNotEqual         opcode = 183
+	opFOrdLessThan           opcode = 184
+	opFOrdGreaterThan        opcode = 186
+	opFOrdLessThanEqual      opcode = 188
+	opFOrdGreaterThanEqual   opcode = 190
+	opShiftRightArithmetic   opcode = 195
+	opShiftLeftLogical       opcode = 196
+	opBitwiseOr              opcode = 197
+	opBitwiseXor             opcode = 198
+	opBitwiseAnd             opcode = 199
+	opDPdx                   opcode = 207
+	opDPdy                   opcode = 208
+	opFwidth                 opcode = 209
+	opPhi                    opcode = 245
+	opLoopMerge              opcode = 246
+	opSelectionMerge         opcode = 247
+	opLabel                  opcode = 248
+	opBranch                 opcode = 249
+	opBranchConditional      opcode = 250
+	opKill                   opcode = 252
+	opReturn                 opcode = 253
+	opReturnValue            opcode = 254
+	opUnreachable            opcode = 255
+)
+
+const (
+	capabilityShader  = 1
+	capabilityLinkage = 5
+
+	addressingModelLogical = 0
+	memoryModelGLSL450     = 1
+
+	executionModelVertex   = 0
+	executionModelFragment = 4
+
+	executionModeOriginUpperLeft = 7
+
+	storageClassUniformConstant = 0
+	storageClassInput           = 1
+	storageClassUniform         = 2
+	storageClassOutput          = 3
+	storageClassFunction        = 7
+
+	decorationBufferBlock   = 3
+	decorationColMajor      = 5
+	decorationArrayStride   = 6
+	decorationMatrixStride  = 7
+	decorationBuiltIn       = 11
+	decorationFlat          = 14
+	decorationNonWritable   = 24
+	decorationLocation      = 30
+	decorationBinding       = 33
+	decorationDescriptorSet = 34
+	decorationOffset        = 35
+
+	builtInPosition  = 0
+	builtInFragCoord = 15
+
+	dim2D = 1
+
+	imageFormatUnknown = 0
+
+	imageOperandsLod = 0x2
+
+	functionControlNone  = 0
+	selectionControlNone = 0
+	loopControlNone      = 0
+)
+
+// The instructions of the GLSL.std.450 extended instruction set.
+const (
+	glslFAbs        = 4
+	glslSAbs        = 5
+	glslFSign       = 6
+	glslSSign       = 7
+	glslFloor       = 8
+	glslCeil        = 9
+	glslFract       = 10
+	glslRadians     = 11
+	glslDegrees     = 12
+	glslSin         = 13
+	glslCos         = 14
+	glslTan         = 15
+	glslAsin        = 16
+	glslAcos        = 17
+	glslAtan        = 18
+	glslAtan2       = 25
+	glslPow         = 26
+	glslExp         = 27
+	glslLog         = 28
+	glslExp2        = 29
+	glslLog2        = 30
+	glslSqrt        = 31
+	glslInverseSqrt = 32
+	glslFMin        = 37
+	glslSMin        = 39
+	glslFMax        = 40
+	glslSMax        = 42
+	glslFClamp      = 43
+	glslSClamp      = 45
+	glslFMix        = 46
+	glslStep        = 48
+	glslSmoothStep  = 49
+	glslLength      = 66
+	glslDistance    = 67
+	glslCross       = 68
+	glslNormalize   = 69
+	glslFaceForward = 70
+	glslReflect     = 71
+	glslRefract     = 72
+)
@@ -0,0 +1,800 @@
+// Copyright 2023 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package spirv compiles a shader program to a SPIR-V module for Vulkan.
+package spirv
+
+import (
+	"encoding/binary"
+	"fmt"
+	"go/constant"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2/internal/shaderir"
+)
+
+const (
+	magicNumber = 0x07230203
+	version     = 0x00010000
+)
+
+// value is a result of an expression.
+type value struct {
+	id  uint32
+	typ shaderir.Type
+}
+
+// local is a local variable.
+// A local variable is a pointer to a variable, or a value when the variable is an input parameter never modified.
+type local struct {
+	id      uint32
+	pointer bool
+	storage uint32
+	typ     shaderir.Type
+}
+
+// reference is a pointer to a variable or a part of a variable.
+type reference struct {
+	base    uint32
+	storage uint32
+	indices []uint32
+	typ     shaderir.Type
+
+	// layout reports whether the variable is in the uniform block, where the types have an explicit layout.
+	layout bool
+}
+
+type loop struct {
+	continueLabel uint32
+	mergeLabel    uint32
+}
+
+type compileContext struct {
+	p *shaderir.Program
+
+	nextID uint32
+	err    error
+
+	// The sections of a module in the order of the logical layout.
+	capabilities   []uint32
+	extInstImports []uint32
+	memoryModel    []uint32
+	entryPoints    []uint32
+	executionModes []uint32
+	names          []uint32
+	annotations    []uint32
+	globals        []uint32
+	functions      []uint32
+
+	glsl     uint32
+	typeIDs  map[string]uint32
+	constIDs map[string]uint32
+	funcIDs  map[int]uint32
+
+	uniforms    uint32
+	textures    []uint32
+	attributes  []uint32
+	position    uint32
+	varyingsOut []uint32
+	fragCoord   uint32
+	varyingsIn  []uint32
+	fragColor   uint32
+
+	// The states of the function being compiled.
+	topBlock   *shaderir.Block
+	ret        shaderir.Type
+	vars       []uint32
+	body       []uint32
+	firstLabel uint32
+	label      uint32
+	terminated bool
+	locals     map[int]local
+	loops      []loop
+}
+
+// Features returns the features SPIR-V supports.
+func Features() []shaderir.Feature {
+	return []shaderir.Feature{
+		shaderir.FeatureDerivatives,
+		shaderir.FeatureIntUniforms,
+	}
+}
+
+// Compile compiles the program to a SPIR-V module with the entry points named vertex and fragment.
+//
+// The bindings are:
+//
+//   - set 0, binding 0: a storage buffer of all the uniform variables in the std430 layout
+//   - set 1, binding i: the i-th texture as a combined image sampler
+//
+// The uniform variables are in a read-only storage buffer instead of a uniform buffer,
+// as an array in the std140 layout must have a stride of a multiple of 16 bytes.
+func Compile(p *shaderir.Program, vertex, fragment string) ([]byte, error) {
+	c := &compileContext{
+		p:        p,
+		nextID:   1,
+		typeIDs:  map[string]uint32{},
+		constIDs: map[string]uint32{},
+		funcIDs:  map[int]uint32{},
+	}
+
+	hasVertex := p.VertexFunc.Block != nil && len(p.VertexFunc.Block.Stmts) > 0
+	hasFragment := p.FragmentFunc.Block != nil && len(p.FragmentFunc.Block.Stmts) > 0
+
+	c.capabilities = appendInstruction(c.capabilities, opCapability, capabilityShader)
+	if !hasVertex && !hasFragment {
+		// A module without entry points is valid only as a library.
+		c.capabilities = appendInstruction(c.capabilities, opCapability, capabilityLinkage)
+	}
+	c.glsl = c.newID()
+	c.extInstImports = appendInstruction(c.extInstImports, opExtInstImport, append([]uint32{c.glsl}, stringWords("GLSL.std.450")...)...)
+	c.memoryModel = appendInstruction(c.memoryModel, opMemoryModel, addressingModelLogical, memoryModelGLSL450)
+
+	c.declareUniforms()
+	c.declareTextures()
+
+	for _, f := range p.Funcs {
+		id := c.newID()
+		c.funcIDs[f.Index] = id
+		c.names = appendInstruction(c.names, opName, append([]uint32{id}, stringWords(fmt.Sprintf("F%d", f.Index))...)...)
+	}
+	for _, f := range p.Funcs {
+		c.function(&f)
+	}
+
+	if hasVertex {
+		var interfaces []uint32
+		for i, a := range p.Attributes {
+			id := c.globalVariable(storageClassInput, a)
+			c.decorate(id, decorationLocation, uint32(i))
+			c.attributes = append(c.attributes, id)
+			interfaces = append(interfaces, id)
+		}
+		c.position = c.globalVariable(storageClassOutput, shaderir.Type{Main: shaderir.Vec4})
+		c.decorate(c.position, decorationBuiltIn, builtInPosition)
+		interfaces = append(interfaces, c.position)
+		for i, v := range p.Varyings {
+			id := c.globalVariable(storageClassOutput, v)
+			c.decorate(id, decorationLocation, uint32(i))
+			if componentType(v) == shaderir.Int {
+				c.decorate(id, decorationFlat)
+			}
+			c.varyingsOut = append(c.varyingsOut, id)
+			interfaces = append(interfaces, id)
+		}
+		c.entryPoint(executionModelVertex, vertex, interfaces, c.vertexFunction)
+	}
+
+	if hasFragment {
+		var interfaces []uint32
+		c.fragCoord = c.globalVariable(storageClassInput, shaderir.Type{Main: shaderir.Vec4})
+		c.decorate(c.fragCoord, decorationBuiltIn, builtInFragCoord)
+		interfaces = append(interfaces, c.fragCoord)
+		for i, v := range p.Varyings {
+			id := c.globalVariable(storageClassInput, v)
+			c.decorate(id, decorationLocation, uint32(i))
+			if componentType(v) == shaderir.Int {
+				c.decorate(id, decorationFlat)
+			}
+			c.varyingsIn = append(c.varyingsIn, id)
+			interfaces = append(interfaces, id)
+		}
+		c.fragColor = c.globalVariable(storageClassOutput, shaderir.Type{Main: shaderir.Vec4})
+		c.decorate(c.fragColor, decorationLocation, 0)
+		interfaces = append(interfaces, c.fragColor)
+		c.entryPoint(executionModelFragment, fragment, interfaces, c.fragmentFunction)
+	}
+
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	words := []uint32{magicNumber, version, 0, c.nextID, 0}
+	for _, s := range [][]uint32{
+		c.capabilities,
+		c.extInstImports,
+		c.memoryModel,
+		c.entryPoints,
+		c.executionModes,
+		c.names,
+		c.annotations,
+		c.globals,
+		c.functions,
+	} {
+		words = append(words, s...)
+	}
+
+	bs := make([]byte, 4*len(words))
+	for i, w := range words {
+		binary.LittleEndian.PutUint32(bs[4*i:], w)
+	}
+	return bs, nil
+}
+
+func appendInstruction(words []uint32, op opcode, operands ...uint32) []uint32 {
+	words = append(words, uint32(len(operands)+1)<<16|uint32(op))
+	return append(words, operands...)
+}
+
+// stringWords returns the words of a null-terminated UTF-8 string literal.
+func stringWords(str string) []uint32 {
+	bs := append([]byte(str), 0)
+	for len(bs)%4 != 0 {
+		bs = append(bs, 0)
+	}
+	words := make([]uint32, len(bs)/4)
+	for i := range words {
+		words[i] = binary.LittleEndian.Uint32(bs[4*i:])
+	}
+	return words
+}
+
+func (c *compileContext) newID() uint32 {
+	id := c.nextID
+	c.nextID++
+	return id
+}
+
+// errorf records the first error and returns a dummy value so that the compilation can continue.
+func (c *compileContext) errorf(format string, args ...interface{}) value {
+	if c.err == nil {
+		c.err = fmt.Errorf("spirv: "+format, args...)
+	}
+	return value{}
+}
+
+func (c *compileContext) decorate(id uint32, decoration uint32, operands ...uint32) {
+	c.annotations = appendInstruction(c.annotations, opDecorate, append([]uint32{id, decoration}, operands...)...)
+}
+
+func (c *compileContext) memberDecorate(id uint32, member int, decoration uint32, operands ...uint32) {
+	c.annotations = appendInstruction(c.annotations, opMemberDecorate, append([]uint32{id, uint32(member), decoration}, operands...)...)
+}
+
+func (c *compileContext) declareType(key string, op opcode, operands ...uint32) uint32 {
+	id := c.newID()
+	c.globals = appendInstruction(c.globals, op, append([]uint32{id}, operands...)...)
+	c.typeIDs[key] = id
+	return id
+}
+
+// typ returns the type ID of t.
+// If layout is true, an array type has the explicit layout for the uniform block.
+func (c *compileContext) typ(t *shaderir.Type, layout bool) uint32 {
+	key := t.String()
+	if layout && t.Main == shaderir.Array {
+		key = "layout " + key
+	}
+	if id, ok := c.typeIDs[key]; ok {
+		return id
+	}
+
+	switch t.Main {
+	case shaderir.None:
+		return c.declareType(key, opTypeVoid)
+	case shaderir.Bool:
+		return c.declareType(key, opTypeBool)
+	case shaderir.Int:
+		return c.declareType(key, opTypeInt, 32, 1)
+	case shaderir.Float:
+		return c.declareType(key, opTypeFloat, 32)
+	case shaderir.Vec2, shaderir.Vec3, shaderir.Vec4, shaderir.IVec2, shaderir.IVec3, shaderir.IVec4, shaderir.BVec2, shaderir.BVec3, shaderir.BVec4:
+		comp := c.typ(&shaderir.Type{Main: componentType(*t)}, false)
+		return c.declareType(key, opTypeVector, comp, uint32(t.VectorElementCount()))
+	case shaderir.Mat2, shaderir.Mat3, shaderir.Mat4:
+		col := columnType(*t)
+		return c.declareType(key, opTypeMatrix, c.typ(&col, false), uint32(col.VectorElementCount()))
+	case shaderir.Array:
+		elem := c.typ(&t.Sub[0], layout)
+		length := c.constInt(int32(t.Length))
+		id := c.declareType(key, opTypeArray, elem, length)
+		if layout {
+			size, align := std430(&t.Sub[0])
+			c.decorate(id, decorationArrayStride, uint32(roundUp(size, align)))
+		}
+		return id
+	case shaderir.Struct:
+		members := make([]uint32, 0, len(t.Sub))
+		for i := range t.Sub {
+			members = append(members, c.typ(&t.Sub[i], false))
+		}
+		return c.declareType(key, opTypeStruct, members...)
+	default:
+		c.errorf("unexpected type: %s", t.String())
+		return 0
+	}
+}
+
+func (c *compileContext) pointerType(storage uint32, typ uint32) uint32 {
+	key := fmt.Sprintf("*%d %d", storage, typ)
+	if id, ok := c.typeIDs[key]; ok {
+		return id
+	}
+	return c.declareType(key, opTypePointer, storage, typ)
+}
+
+func (c *compileContext) functionType(ret uint32, params []uint32) uint32 {
+	key := fmt.Sprintf("func %d %v", ret, params)
+	if id, ok := c.typeIDs[key]; ok {
+		return id
+	}
+	return c.declareType(key, opTypeFunction, append([]uint32{ret}, params...)...)
+}
+
+func (c *compileContext) imageType() uint32 {
+	if id, ok := c.typeIDs["image"]; ok {
+		return id
+	}
+	f := c.typ(&shaderir.Type{Main: shaderir.Float}, false)
+	return c.declareType("image", opTypeImage, f, dim2D, 0, 0, 0, 1, imageFormatUnknown)
+}
+
+func (c *compileContext) sampledImageType() uint32 {
+	if id, ok := c.typeIDs["sampled image"]; ok {
+		return id
+	}
+	img := c.imageType()
+	return c.declareType("sampled image", opTypeSampledImage, img)
+}
+
+func (c *compileContext) constant(typ uint32, op opcode, operands ...uint32) uint32 {
+	key := fmt.Sprintf("%d %d %v", typ, op, operands)
+	if id, ok := c.constIDs[key]; ok {
+		return id
+	}
+	id := c.newID()
+	c.globals = appendInstruction(c.globals, op, append([]uint32{typ, id}, operands...)...)
+	c.constIDs[key] = id
+	return id
+}
+
+func (c *compileContext) constInt(v int32) uint32 {
+	return c.constant(c.typ(&shaderir.Type{Main: shaderir.Int}, false), opConstant, uint32(v))
+}
+
+func (c *compileContext) constFloat(v float32) uint32 {
+	return c.constant(c.typ(&shaderir.Type{Main: shaderir.Float}, false), opConstant, math.Float32bits(v))
+}
+
+func (c *compileContext) constBool(v bool) uint32 {
+	t := c.typ(&shaderir.Type{Main: shaderir.Bool}, false)
+	if v {
+		return c.constant(t, opConstantTrue)
+	}
+	return c.constant(t, opConstantFalse)
+}
+
+func (c *compileContext) constNull(t shaderir.Type) uint32 {
+	return c.constant(c.typ(&t, false), opConstantNull)
+}
+
+// constantValue returns the value of the constant v.
+// An untyped numeric constant is converted to hint if possible.
+func (c *compileContext) constantValue(v constant.Value, hint shaderir.BasicType) value {
+	switch v.Kind() {
+	case constant.Bool:
+		return value{id: c.constBool(constant.BoolVal(v)), typ: shaderir.Type{Main: shaderir.Bool}}
+	case constant.Int:
+		if hint == shaderir.Float {
+			f, _ := constant.Float32Val(constant.ToFloat(v))
+			return value{id: c.constFloat(f), typ: shaderir.Type{Main: shaderir.Float}}
+		}
+		i, _ := constant.Int64Val(v)
+		return value{id: c.constInt(int32(i)), typ: shaderir.Type{Main: shaderir.Int}}
+	case constant.Float:
+		if hint == shaderir.Int {
+			if i := constant.ToInt(v); i.Kind() == constant.Int {
+				x, _ := constant.Int64Val(i)
+				return value{id: c.constInt(int32(x)), typ: shaderir.Type{Main: shaderir.Int}}
+			}
+		}
+		f, _ := constant.Float32Val(v)
+		return value{id: c.constFloat(f), typ: shaderir.Type{Main: shaderir.Float}}
+	}
+	return c.errorf("unexpected constant: %s", v.String())
+}
+
+func (c *compileContext) globalVariable(storage uint32, t shaderir.Type) uint32 {
+	ptr := c.pointerType(storage, c.typ(&t, false))
+	id := c.newID()
+	c.globals = appendInstruction(c.globals, opVariable, ptr, id, storage)
+	return id
+}
+
+func (c *compileContext) declareUniforms() {
+	if len(c.p.Uniforms) == 0 {
+		return
+	}
+
+	members := make([]uint32, 0, len(c.p.Uniforms))
+	for _, u := range c.p.Uniforms {
+		if u.Main == shaderir.Bool || u.IsBoolVector() || u.Main == shaderir.Struct {
+			c.errorf("a uniform variable of %s is not supported", u.String())
+			return
+		}
+		members = append(members, c.typ(&u, true))
+	}
+	st := c.newID()
+	c.globals = appendInstruction(c.globals, opTypeStruct, append([]uint32{st}, members...)...)
+	c.decorate(st, decorationBufferBlock)
+
+	var offset int
+	for i, u := range c.p.Uniforms {
+		size, align := std430(&u)
+		offset = roundUp(offset, align)
+		c.memberDecorate(st, i, decorationOffset, uint32(offset))
+		c.memberDecorate(st, i, decorationNonWritable)
+		m := u
+		if m.Main == shaderir.Array {
+			m = m.Sub[0]
+		}
+		if m.IsMatrix() {
+			c.memberDecorate(st, i, decorationColMajor)
+			_, colAlign := std430(&shaderir.Type{Main: columnType(m).Main})
+			c.memberDecorate(st, i, decorationMatrixStride, uint32(colAlign))
+		}
+		offset += size
+	}
+
+	ptr := c.pointerType(storageClassUniform, st)
+	c.uniforms = c.newID()
+	c.globals = appendInstruction(c.globals, opVariable, ptr, c.uniforms, storageClassUniform)
+	c.decorate(c.uniforms, decorationDescriptorSet, 0)
+	c.decorate(c.uniforms, decorationBinding, 0)
+}
+
+func (c *compileContext) declareTextures() {
+	for i := 0; i < c.p.TextureCount; i++ {
+		ptr := c.pointerType(storageClassUniformConstant, c.sampledImageType())
+		id := c.newID()
+		c.globals = appendInstruction(c.globals, opVariable, ptr, id, storageClassUniformConstant)
+		c.decorate(id, decorationDescriptorSet, 1)
+		c.decorate(id, decorationBinding, uint32(i))
+		c.textures = append(c.textures, id)
+	}
+}
+
+// std430 returns the size and the alignment of t in the std430 layout.
+func std430(t *shaderir.Type) (size, align int) {
+	switch t.Main {
+	case shaderir.Int, shaderir.Float:
+		return 4, 4
+	case shaderir.Vec2, shaderir.IVec2:
+		return 8, 8
+	case shaderir.Vec3, shaderir.IVec3:
+		return 12, 16
+	case shaderir.Vec4, shaderir.IVec4:
+		return 16, 16
+	case shaderir.Mat2:
+		return 16, 8
+	case shaderir.Mat3:
+		return 48, 16
+	case shaderir.Mat4:
+		return 64, 16
+	case shaderir.Array:
+		size, align := std430(&t.Sub[0])
+		return roundUp(size, align) * t.Length, align
+	}
+	return 0, 0
+}
+
+func roundUp(x, align int) int {
+	return (x + align - 1) / align * align
+}
+
+// componentType returns the type of the components of t.
+func componentType(t shaderir.Type) shaderir.BasicType {
+	switch {
+	case t.Main == shaderir.Float || t.IsFloatVector() || t.IsMatrix():
+		return shaderir.Float
+	case t.Main == shaderir.Int || t.IsIntVector():
+		return shaderir.Int
+	case t.Main == shaderir.Bool || t.IsBoolVector():
+		return shaderir.Bool
+	}
+	return shaderir.None
+}
+
+// vectorLength returns the number of the components of a vector t, or 0 if t is not a vector.
+func vectorLength(t shaderir.Type) int {
+	if n := t.VectorElementCount(); n > 0 {
+		return n
+	}
+	return 0
+}
+
+// vectorType returns the vector type of n components, or the scalar type if n is 0.
+func vectorType(comp shaderir.BasicType, n int) shaderir.Type {
+	if n == 0 {
+		return shaderir.Type{Main: comp}
+	}
+	switch comp {
+	case shaderir.Float:
+		return shaderir.Type{Main: shaderir.Vec2 + shaderir.BasicType(n-2)}
+	case shaderir.Int:
+		return shaderir.Type{Main: shaderir.IVec2 + shaderir.BasicType(n-2)}
+	case shaderir.Bool:
+		return shaderir.Type{Main: shaderir.BVec2 + shaderir.BasicType(n-2)}
+	}
+	return shaderir.Type{}
+}
+
+func columnType(t shaderir.Type) shaderir.Type {
+	switch t.Main {
+	case shaderir.Mat2:
+		return shaderir.Type{Main: shaderir.Vec2}
+	case shaderir.Mat3:
+		return shaderir.Type{Main: shaderir.Vec3}
+	case shaderir.Mat4:
+		return shaderir.Type{Main: shaderir.Vec4}
+	}
+	return shaderir.Type{}
+}
+
+// elementType returns the type of an element of t by indexing.
+func elementType(t shaderir.Type) shaderir.Type {
+	switch {
+	case t.Main == shaderir.Array:
+		return t.Sub[0]
+	case t.IsMatrix():
+		return columnType(t)
+	}
+	return shaderir.Type{Main: componentType(t)}
+}
+
+func swizzleIndex(c byte) uint32 {
+	for _, s := range []string{"xyzw", "rgba", "stpq"} {
+		for i := 0; i < len(s); i++ {
+			if s[i] == c {
+				return uint32(i)
+			}
+		}
+	}
+	return 0
+}
+
+func findFunc(p *shaderir.Program, index int) *shaderir.Func {
+	for i := range p.Funcs {
+		if p.Funcs[i].Index == index {
+			return &p.Funcs[i]
+		}
+	}
+	return nil
+}
+
+// assignedLocalVariables returns the indices of the local variables that are assigned or passed as output arguments in block.
+func assignedLocalVariables(p *shaderir.Program, block *shaderir.Block) map[int]struct{} {
+	indices := map[int]struct{}{}
+	mark := func(e *shaderir.Expr) {
+		for e.Type == shaderir.FieldSelector || e.Type == shaderir.Index {
+			e = &e.Exprs[0]
+		}
+		if e.Type == shaderir.LocalVariable {
+			indices[e.Index] = struct{}{}
+		}
+	}
+
+	var walkExpr func(e *shaderir.Expr)
+	walkExpr = func(e *shaderir.Expr) {
+		if e.Type == shaderir.Call && e.Exprs[0].Type == shaderir.FunctionExpr {
+			if callee := findFunc(p, e.Exprs[0].Index); callee != nil {
+				for i := 1 + len(callee.InParams); i < len(e.Exprs); i++ {
+					mark(&e.Exprs[i])
+				}
+			}
+		}
+		for i := range e.Exprs {
+			walkExpr(&e.Exprs[i])
+		}
+	}
+
+	var walkBlock func(b *shaderir.Block)
+	walkBlock = func(b *shaderir.Block) {
+		if b == nil {
+			return
+		}
+		for _, s := range b.Stmts {
+			if s.Type == shaderir.Assign {
+				mark(&s.Exprs[0])
+			}
+			for i := range s.Exprs {
+				walkExpr(&s.Exprs[i])
+			}
+			for _, b := range s.Blocks {
+				walkBlock(b)
+			}
+		}
+	}
+	walkBlock(block)
+
+	return indices
+}
+
+// hasCall reports whether e calls a user-defined function, which might have side effects.
+func hasCall(e *shaderir.Expr) bool {
+	if e.Type == shaderir.Call && e.Exprs[0].Type == shaderir.FunctionExpr {
+		return true
+	}
+	for i := range e.Exprs {
+		if hasCall(&e.Exprs[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *compileContext) emit(op opcode, operands ...uint32) {
+	c.body = appendInstruction(c.body, op, operands...)
+}
+
+func (c *compileContext) emitID(typ uint32, op opcode, operands ...uint32) uint32 {
+	id := c.newID()
+	c.emit(op, append([]uint32{typ, id}, operands...)...)
+	return id
+}
+
+func (c *compileContext) emitValue(t shaderir.Type, op opcode, operands ...uint32) value {
+	return value{
+		id:  c.emitID(c.typ(&t, false), op, operands...),
+		typ: t,
+	}
+}
+
+func (c *compileContext) startBlock(label uint32) {
+	c.emit(opLabel, label)
+	c.label = label
+	c.terminated = false
+}
+
+func (c *compileContext) terminate(op opcode, operands ...uint32) {
+	c.emit(op, operands...)
+	c.terminated = true
+}
+
+// variable declares a variable in the function storage.
+// The variables are declared at the beginning of the function as SPIR-V requires.
+func (c *compileContext) variable(t shaderir.Type) uint32 {
+	id := c.newID()
+	c.vars = appendInstruction(c.vars, opVariable, c.pointerType(storageClassFunction, c.typ(&t, false)), id, storageClassFunction)
+	return id
+}
+
+func (c *compileContext) beginFunction(topBlock *shaderir.Block, ret shaderir.Type) {
+	c.topBlock = topBlock
+	c.ret = ret
+	c.vars = nil
+	c.body = nil
+	c.label = c.newID()
+	c.firstLabel = c.label
+	c.terminated = false
+	c.locals = map[int]local{}
+	c.loops = nil
+}
+
+// endFunction appends the function to the module with the header instructions.
+func (c *compileContext) endFunction(header []uint32) {
+	if !c.terminated {
+		if c.ret.Main == shaderir.None {
+			c.terminate(opReturn)
+		} else {
+			// The end of a function with a returned value must be unreachable.
+			c.terminate(opUnreachable)
+		}
+	}
+	c.functions = append(c.functions, header...)
+	c.functions = appendInstruction(c.functions, opLabel, c.firstLabel)
+	c.functions = append(c.functions, c.vars...)
+	c.functions = append(c.functions, c.body...)
+	c.functions = appendInstruction(c.functions, opFunctionEnd)
+}
+
+func (c *compileContext) function(f *shaderir.Func) {
+	ret := c.typ(&f.Return, false)
+	var paramTypes []uint32
+	for _, t := range f.InParams {
+		paramTypes = append(paramTypes, c.typ(&t, false))
+	}
+	// Output parameters are pointers.
+	for _, t := range f.OutParams {
+		paramTypes = append(paramTypes, c.pointerType(storageClassFunction, c.typ(&t, false)))
+	}
+
+	c.beginFunction(f.Block, f.Return)
+
+	var header []uint32
+	header = appendInstruction(header, opFunction, ret, c.funcIDs[f.Index], functionControlNone, c.functionType(ret, paramTypes))
+
+	// Parameters are immutable. Copy the parameters modified in the function to variables.
+	modified := assignedLocalVariables(c.p, f.Block)
+	for i, t := range f.InParams {
+		id := c.newID()
+		header = appendInstruction(header, opFunctionParameter, paramTypes[i], id)
+		if _, ok := modified[i]; !ok {
+			c.locals[i] = local{id: id, typ: t}
+			continue
+		}
+		v := c.variable(t)
+		c.emit(opStore, v, id)
+		c.locals[i] = local{id: v, pointer: true, storage: storageClassFunction, typ: t}
+	}
+	for i, t := range f.OutParams {
+		id := c.newID()
+		idx := len(f.InParams) + i
+		header = appendInstruction(header, opFunctionParameter, paramTypes[idx], id)
+		c.locals[idx] = local{id: id, pointer: true, storage: storageClassFunction, typ: t}
+	}
+
+	c.block(f.Block)
+	c.endFunction(header)
+}
+
+func (c *compileContext) entryPoint(model uint32, name string, interfaces []uint32, body func()) {
+	id := c.newID()
+	c.entryPoints = appendInstruction(c.entryPoints, opEntryPoint, append(append([]uint32{model, id}, stringWords(name)...), interfaces...)...)
+	if model == executionModelFragment {
+		c.executionModes = appendInstruction(c.executionModes, opExecutionMode, id, executionModeOriginUpperLeft)
+	}
+	c.names = appendInstruction(c.names, opName, append([]uint32{id}, stringWords(name)...)...)
+
+	void := c.typ(&shaderir.Type{}, false)
+	var header []uint32
+	header = appendInstruction(header, opFunction, void, id, functionControlNone, c.functionType(void, nil))
+	body()
+	c.endFunction(header)
+}
+
+// inputVariable returns the local variable for the input variable id.
+// Input variables are read-only. If the variable is modified, the value is copied to a variable.
+func (c *compileContext) inputVariable(id uint32, t shaderir.Type, modified bool) local {
+	if !modified {
+		return local{id: id, pointer: true, storage: storageClassInput, typ: t}
+	}
+	v := c.variable(t)
+	val := c.emitValue(t, opLoad, id)
+	c.emit(opStore, v, val.id)
+	return local{id: v, pointer: true, storage: storageClassFunction, typ: t}
+}
+
+func (c *compileContext) vertexFunction() {
+	p := c.p
+	c.beginFunction(p.VertexFunc.Block, shaderir.Type{})
+
+	modified := assignedLocalVariables(p, p.VertexFunc.Block)
+	na := len(p.Attributes)
+	for i, a := range p.Attributes {
+		_, ok := modified[i]
+		c.locals[i] = c.inputVariable(c.attributes[i], a, ok)
+	}
+	c.locals[na] = local{id: c.position, pointer: true, storage: storageClassOutput, typ: shaderir.Type{Main: shaderir.Vec4}}
+	for i, v := range p.Varyings {
+		c.locals[na+1+i] = local{id: c.varyingsOut[i], pointer: true, storage: storageClassOutput, typ: v}
+	}
+
+	c.block(p.VertexFunc.Block)
+}
+
+func (c *compileContext) fragmentFunction() {
+	p := c.p
+	c.beginFunction(p.FragmentFunc.Block, shaderir.Type{})
+
+	modified := assignedLocalVariables(p, p.FragmentFunc.Block)
+	_, ok := modified[0]
+	c.locals[0] = c.inputVariable(c.fragCoord, shaderir.Type{Main: shaderir.Vec4}, ok)
+	for i, v := range p.Varyings {
+		_, ok := modified[i+1]
+		c.locals[i+1] = c.inputVariable(c.varyingsIn[i], v, ok)
+	}
+
+	c.block(p.FragmentFunc.Block)
+}
@@ -23,8 +23,26 @@ type Type struct {
 	Main   BasicType
 	Sub    []Type
 	Length int
+
+	// Precision is the precision qualifier of the type.
+	// Precision is used only for GLSL, and is not considered in Equal.
+	Precision Precision
+
+	// FieldNames is the names of the members of a struct in the original source.
+	// FieldNames is used only for the shader compiler, and is not considered in Equal.
+	FieldNames []string
 }
 
+// Precision represents a precision qualifier.
+type Precision int
+
+const (
+	PrecisionDefault Precision = iota
+	PrecisionLow
+	PrecisionMedium
+	PrecisionHigh
+)
+
 func (t *Type) Equal(rhs *Type) bool {
 	if t.Main != rhs.Main {
 		return false
@@ -65,6 +83,12 @@ func (t *Type) String() string {
 		return "ivec3"
 	case IVec4:
 		return "ivec4"
+	case BVec2:
+		return "bvec2"
+	case BVec3:
+		return "bvec3"
+	case BVec4:
+		return "bvec4"
 	case Mat2:
 		return "mat2"
 	case Mat3:
@@ -134,6 +158,14 @@ func (t *Type) IsIntVector() bool {
 	return false
 }
 
+func (t *Type) IsBoolVector() bool {
+	switch t.Main {
+	case BVec2, BVec3, BVec4:
+		return true
+	}
+	return false
+}
+
 func (t *Type) VectorElementCount() int {
 	switch t.Main {
 	case Vec2:
@@ -148,6 +180,12 @@ func (t *Type) VectorElementCount() int {
 		return 3
 	case IVec4:
 		return 4
+	case BVec2:
+		return 2
+	case BVec3:
+		return 3
+	case BVec4:
+		return 4
 	default:
 		return -1
 	}
@@ -174,6 +212,9 @@ const (
 	IVec2
 	IVec3
 	IVec4
+	BVec2
+	BVec3
+	BVec4
 	Mat2
 	Mat3
 	Mat4
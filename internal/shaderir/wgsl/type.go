@@ -0,0 +1,154 @@
+// Copyright 2023 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wgsl
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2/internal/shaderir"
+)
+
+func opString(op shaderir.Op) string {
+	switch op {
+	case shaderir.Add:
+		return "+"
+	case shaderir.Sub:
+		return "-"
+	case shaderir.NotOp:
+		return "!"
+	case shaderir.ComponentWiseMul, shaderir.MatrixMul:
+		return "*"
+	case shaderir.Div:
+		return "/"
+	case shaderir.ModOp:
+		return "%"
+	case shaderir.LeftShift:
+		return "<<"
+	case shaderir.RightShift:
+		return ">>"
+	case shaderir.LessThanOp:
+		return "<"
+	case shaderir.LessThanEqualOp:
+		return "<="
+	case shaderir.GreaterThanOp:
+		return ">"
+	case shaderir.GreaterThanEqualOp:
+		return ">="
+	case shaderir.EqualOp:
+		return "=="
+	case shaderir.NotEqualOp:
+		return "!="
+	case shaderir.And:
+		return "&"
+	case shaderir.Xor:
+		return "^"
+	case shaderir.Or:
+		return "|"
+	case shaderir.AndAnd:
+		return "&&"
+	case shaderir.OrOr:
+		return "||"
+	}
+	return fmt.Sprintf("?(unexpected operator: %d)", op)
+}
+
+func basicTypeString(t shaderir.BasicType) string {
+	switch t {
+	case shaderir.None:
+		return "?(none)"
+	case shaderir.Bool:
+		return "bool"
+	case shaderir.Int:
+		return "i32"
+	case shaderir.Float:
+		return "f32"
+	case shaderir.Vec2:
+		return "vec2<f32>"
+	case shaderir.Vec3:
+		return "vec3<f32>"
+	case shaderir.Vec4:
+		return "vec4<f32>"
+	case shaderir.IVec2:
+		return "vec2<i32>"
+	case shaderir.IVec3:
+		return "vec3<i32>"
+	case shaderir.IVec4:
+		return "vec4<i32>"
+	case shaderir.BVec2:
+		return "vec2<bool>"
+	case shaderir.BVec3:
+		return "vec3<bool>"
+	case shaderir.BVec4:
+		return "vec4<bool>"
+	case shaderir.Mat2:
+		return "mat2x2<f32>"
+	case shaderir.Mat3:
+		return "mat3x3<f32>"
+	case shaderir.Mat4:
+		return "mat4x4<f32>"
+	case shaderir.Array:
+		return "?(array)"
+	case shaderir.Struct:
+		return "?(struct)"
+	default:
+		return fmt.Sprintf("?(unknown type: %d)", t)
+	}
+}
+
+func builtinFuncString(f shaderir.BuiltinFunc) string {
+	switch f {
+	case shaderir.IntF:
+		return "i32"
+	case shaderir.FloatF:
+		return "f32"
+	case shaderir.Vec2F:
+		return "vec2<f32>"
+	case shaderir.Vec3F:
+		return "vec3<f32>"
+	case shaderir.Vec4F:
+		return "vec4<f32>"
+	case shaderir.IVec2F:
+		return "vec2<i32>"
+	case shaderir.IVec3F:
+		return "vec3<i32>"
+	case shaderir.IVec4F:
+		return "vec4<i32>"
+	case shaderir.BVec2F:
+		return "vec2<bool>"
+	case shaderir.BVec3F:
+		return "vec3<bool>"
+	case shaderir.BVec4F:
+		return "vec4<bool>"
+	case shaderir.Mat2F:
+		return "mat2x2<f32>"
+	case shaderir.Mat3F:
+		return "mat3x3<f32>"
+	case shaderir.Mat4F:
+		return "mat4x4<f32>"
+	case shaderir.Inversesqrt:
+		return "inverseSqrt"
+	case shaderir.Faceforward:
+		return "faceForward"
+	case shaderir.Dfdx:
+		return "dpdx"
+	case shaderir.Dfdy:
+		return "dpdy"
+	case shaderir.TexelAt:
+		return "?(__texelAt)"
+	case shaderir.TextureLod:
+		return "?(__textureLod)"
+	}
+	return string(f)
+}
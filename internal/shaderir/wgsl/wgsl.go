@@ -0,0 +1,692 @@
+// Copyright 2023 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package wgsl compiles a shader program to WebGPU Shading Language (WGSL).
+package wgsl
+
+import (
+	"fmt"
+	"go/constant"
+	"go/token"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2/internal/shaderir"
+)
+
+const (
+	vertexIn  = "attributes"
+	vertexOut = "varyings"
+	uniforms  = "uniforms"
+)
+
+type compileContext struct {
+	structNames map[string]string
+	structTypes []shaderir.Type
+}
+
+func (c *compileContext) structName(p *shaderir.Program, t *shaderir.Type) string {
+	if t.Main != shaderir.Struct {
+		panic("wgsl: the given type at structName must be a struct")
+	}
+	s := t.String()
+	if n, ok := c.structNames[s]; ok {
+		return n
+	}
+	// Member struct types must be declared before this struct type.
+	for i := range t.Sub {
+		if t.Sub[i].Main == shaderir.Struct {
+			c.structName(p, &t.Sub[i])
+		}
+	}
+	n := fmt.Sprintf("S%d", len(c.structNames))
+	c.structNames[s] = n
+	c.structTypes = append(c.structTypes, *t)
+	return n
+}
+
+// Prelude returns the declarations that every WGSL program from Compile starts with.
+//
+// The derivative uniformity diagnostic is disabled as Kage allows derivatives in non-uniform control flow like GLSL.
+// There is no implicit conversion from a scalar to a matrix in WGSL, then the helper functions are used instead.
+func Prelude(unit shaderir.Unit) string {
+	str := `diagnostic(off, derivative_uniformity);
+
+fn mat2x2FromScalar(x: f32) -> mat2x2<f32> {
+	return mat2x2<f32>(x, 0.0, 0.0, x);
+}
+
+fn mat3x3FromScalar(x: f32) -> mat3x3<f32> {
+	return mat3x3<f32>(x, 0.0, 0.0, 0.0, x, 0.0, 0.0, 0.0, x);
+}
+
+fn mat4x4FromScalar(x: f32) -> mat4x4<f32> {
+	return mat4x4<f32>(x, 0.0, 0.0, 0.0, 0.0, x, 0.0, 0.0, 0.0, 0.0, x, 0.0, 0.0, 0.0, 0.0, x);
+}`
+	if unit == shaderir.Texels {
+		str += `
+
+@group(2) @binding(0) var texture_sampler: sampler;`
+	}
+	return str
+}
+
+// Features returns the features WGSL supports.
+func Features() []shaderir.Feature {
+	return []shaderir.Feature{
+		shaderir.FeatureDerivatives,
+		shaderir.FeatureIntUniforms,
+	}
+}
+
+// Compile compiles the program to a WGSL module with the entry points named vertex and fragment.
+//
+// The bindings are:
+//
+//   - @group(0) @binding(0): a storage buffer of all the uniform variables
+//   - @group(1) @binding(i): the i-th texture
+//   - @group(2) @binding(0): the sampler for textures, only in the texels unit
+//
+// The uniform variables are in a read-only storage buffer instead of a uniform buffer,
+// as an array in the uniform address space must have a stride of a multiple of 16 bytes.
+func Compile(p *shaderir.Program, vertex, fragment string) (shader string) {
+	c := &compileContext{
+		structNames: map[string]string{},
+	}
+
+	var lines []string
+	lines = append(lines, strings.Split(Prelude(p.Unit), "\n")...)
+	lines = append(lines, "", "{{.Structs}}")
+
+	if len(p.Uniforms) > 0 {
+		lines = append(lines, "")
+		lines = append(lines, "struct Uniforms {")
+		for i, u := range p.Uniforms {
+			lines = append(lines, fmt.Sprintf("\tU%d: %s,", i, c.typ(p, &u)))
+		}
+		lines = append(lines, "}")
+		lines = append(lines, "")
+		lines = append(lines, fmt.Sprintf("@group(0) @binding(0) var<storage, read> %s: Uniforms;", uniforms))
+	}
+
+	if p.TextureCount > 0 {
+		lines = append(lines, "")
+		for i := 0; i < p.TextureCount; i++ {
+			lines = append(lines, fmt.Sprintf("@group(1) @binding(%[1]d) var T%[1]d: texture_2d<f32>;", i))
+		}
+	}
+
+	if len(p.Attributes) > 0 {
+		lines = append(lines, "")
+		lines = append(lines, "struct Attributes {")
+		for i, a := range p.Attributes {
+			lines = append(lines, fmt.Sprintf("\t@location(%[1]d) M%[1]d: %[2]s,", i, c.typ(p, &a)))
+		}
+		lines = append(lines, "}")
+	}
+
+	if p.VertexFunc.Block != nil || p.FragmentFunc.Block != nil {
+		lines = append(lines, "")
+		lines = append(lines, "struct Varyings {")
+		lines = append(lines, "\t@builtin(position) Position: vec4<f32>,")
+		for i, v := range p.Varyings {
+			lines = append(lines, fmt.Sprintf("\t@location(%[1]d) M%[1]d: %[2]s,", i, c.typ(p, &v)))
+		}
+		lines = append(lines, "}")
+	}
+
+	for _, f := range p.Funcs {
+		lines = append(lines, "")
+		lines = append(lines, c.function(p, &f)...)
+	}
+
+	if p.VertexFunc.Block != nil && len(p.VertexFunc.Block.Stmts) > 0 {
+		var arg string
+		if len(p.Attributes) > 0 {
+			arg = fmt.Sprintf("%s: Attributes", vertexIn)
+		}
+		lines = append(lines, "")
+		lines = append(lines, "@vertex")
+		lines = append(lines, fmt.Sprintf("fn %s(%s) -> Varyings {", vertex, arg))
+		lines = append(lines, fmt.Sprintf("\tvar %s: Varyings;", vertexOut))
+		lines = append(lines, c.block(p, p.VertexFunc.Block, p.VertexFunc.Block, 0)...)
+		if last := fmt.Sprintf("\treturn %s;", vertexOut); lines[len(lines)-1] != last {
+			lines = append(lines, last)
+		}
+		lines = append(lines, "}")
+	}
+
+	if p.FragmentFunc.Block != nil && len(p.FragmentFunc.Block.Stmts) > 0 {
+		lines = append(lines, "")
+		lines = append(lines, "@fragment")
+		lines = append(lines, fmt.Sprintf("fn %s(%s: Varyings) -> @location(0) vec4<f32> {", fragment, vertexOut))
+		lines = append(lines, c.block(p, p.FragmentFunc.Block, p.FragmentFunc.Block, 0)...)
+		lines = append(lines, "}")
+	}
+
+	ls := strings.Join(lines, "\n")
+
+	// Struct types are determined after converting the program.
+	if len(c.structTypes) > 0 {
+		var stlines []string
+		for i, t := range c.structTypes {
+			stlines = append(stlines, fmt.Sprintf("struct S%d {", i))
+			for j, st := range t.Sub {
+				stlines = append(stlines, fmt.Sprintf("\tM%d: %s,", j, c.typ(p, &st)))
+			}
+			stlines = append(stlines, "}")
+		}
+		ls = strings.ReplaceAll(ls, "{{.Structs}}", strings.Join(stlines, "\n"))
+	} else {
+		ls = strings.ReplaceAll(ls, "{{.Structs}}", "")
+	}
+
+	nls := regexp.MustCompile(`\n\n+`)
+	ls = nls.ReplaceAllString(ls, "\n\n")
+	ls = strings.TrimSpace(ls) + "\n"
+
+	return ls
+}
+
+func (c *compileContext) typ(p *shaderir.Program, t *shaderir.Type) string {
+	switch t.Main {
+	case shaderir.Array:
+		return fmt.Sprintf("array<%s, %d>", c.typ(p, &t.Sub[0]), t.Length)
+	case shaderir.Struct:
+		return c.structName(p, t)
+	default:
+		return basicTypeString(t.Main)
+	}
+}
+
+func (c *compileContext) function(p *shaderir.Program, f *shaderir.Func) []string {
+	// Parameters are immutable in WGSL. Copy the parameters modified in the function to variables.
+	modified := modifiedInParams(p, f)
+
+	var args []string
+	var copies []string
+	var idx int
+	for _, t := range f.InParams {
+		if _, ok := modified[idx]; ok {
+			args = append(args, fmt.Sprintf("p%d: %s", idx, c.typ(p, &t)))
+			copies = append(copies, fmt.Sprintf("\tvar l%[1]d: %[2]s = p%[1]d;", idx, c.typ(p, &t)))
+		} else {
+			args = append(args, fmt.Sprintf("l%d: %s", idx, c.typ(p, &t)))
+		}
+		idx++
+	}
+	// There are no output parameters in WGSL. Use pointers instead.
+	for _, t := range f.OutParams {
+		args = append(args, fmt.Sprintf("l%d: ptr<function, %s>", idx, c.typ(p, &t)))
+		idx++
+	}
+
+	sig := fmt.Sprintf("fn F%d(%s)", f.Index, strings.Join(args, ", "))
+	if f.Return.Main != shaderir.None {
+		sig += " -> " + c.typ(p, &f.Return)
+	}
+
+	var lines []string
+	lines = append(lines, fmt.Sprintf("%s {", sig))
+	lines = append(lines, copies...)
+	lines = append(lines, c.block(p, f.Block, f.Block, 0)...)
+	lines = append(lines, "}")
+
+	return lines
+}
+
+// modifiedInParams returns the indices of the input parameters of f that are assigned or passed as output arguments.
+func modifiedInParams(p *shaderir.Program, f *shaderir.Func) map[int]struct{} {
+	indices := map[int]struct{}{}
+	mark := func(e *shaderir.Expr) {
+		for e.Type == shaderir.FieldSelector || e.Type == shaderir.Index {
+			e = &e.Exprs[0]
+		}
+		if e.Type == shaderir.LocalVariable && e.Index < len(f.InParams) {
+			indices[e.Index] = struct{}{}
+		}
+	}
+
+	var walkExpr func(e *shaderir.Expr)
+	walkExpr = func(e *shaderir.Expr) {
+		if e.Type == shaderir.Call && e.Exprs[0].Type == shaderir.FunctionExpr {
+			if callee := findFunc(p, e.Exprs[0].Index); callee != nil {
+				for i := 1 + len(callee.InParams); i < len(e.Exprs); i++ {
+					mark(&e.Exprs[i])
+				}
+			}
+		}
+		for i := range e.Exprs {
+			walkExpr(&e.Exprs[i])
+		}
+	}
+
+	var walkBlock func(b *shaderir.Block)
+	walkBlock = func(b *shaderir.Block) {
+		if b == nil {
+			return
+		}
+		for _, s := range b.Stmts {
+			if s.Type == shaderir.Assign {
+				mark(&s.Exprs[0])
+			}
+			for i := range s.Exprs {
+				walkExpr(&s.Exprs[i])
+			}
+			for _, b := range s.Blocks {
+				walkBlock(b)
+			}
+		}
+	}
+	walkBlock(f.Block)
+
+	return indices
+}
+
+func findFunc(p *shaderir.Program, index int) *shaderir.Func {
+	for i := range p.Funcs {
+		if p.Funcs[i].Index == index {
+			return &p.Funcs[i]
+		}
+	}
+	return nil
+}
+
+func constantToNumberLiteral(v constant.Value) string {
+	switch v.Kind() {
+	case constant.Bool:
+		if constant.BoolVal(v) {
+			return "true"
+		}
+		return "false"
+	case constant.Int:
+		x, _ := constant.Int64Val(v)
+		return fmt.Sprintf("%d", x)
+	case constant.Float:
+		x, _ := constant.Float64Val(v)
+		if i := math.Floor(x); i == x {
+			// A large integral value might not fit in int64.
+			if math.Abs(i) >= 1<<53 {
+				return strconv.FormatFloat(x, 'e', -1, 32)
+			}
+			return fmt.Sprintf("%d.0", int64(i))
+		}
+		return fmt.Sprintf("%.10e", x)
+	}
+	return fmt.Sprintf("?(unexpected literal: %s)", v)
+}
+
+func localVariableName(p *shaderir.Program, topBlock *shaderir.Block, idx int) string {
+	switch topBlock {
+	case p.VertexFunc.Block:
+		na := len(p.Attributes)
+		nv := len(p.Varyings)
+		switch {
+		case idx < na:
+			return fmt.Sprintf("%s.M%d", vertexIn, idx)
+		case idx == na:
+			return fmt.Sprintf("%s.Position", vertexOut)
+		case idx < na+nv+1:
+			return fmt.Sprintf("%s.M%d", vertexOut, idx-na-1)
+		default:
+			return fmt.Sprintf("l%d", idx-(na+nv+1))
+		}
+	case p.FragmentFunc.Block:
+		nv := len(p.Varyings)
+		switch {
+		case idx == 0:
+			return fmt.Sprintf("%s.Position", vertexOut)
+		case idx < nv+1:
+			return fmt.Sprintf("%s.M%d", vertexOut, idx-1)
+		default:
+			return fmt.Sprintf("l%d", idx-(nv+1))
+		}
+	default:
+		// An output parameter is a pointer.
+		for _, f := range p.Funcs {
+			if f.Block != topBlock {
+				continue
+			}
+			if n := len(f.InParams); n <= idx && idx < n+len(f.OutParams) {
+				return fmt.Sprintf("(*l%d)", idx)
+			}
+			break
+		}
+		return fmt.Sprintf("l%d", idx)
+	}
+}
+
+// intVectorLength returns the number of the components if the integer expression e is a vector, or 0 if e is a scalar.
+func intVectorLength(p *shaderir.Program, topBlock, block *shaderir.Block, e *shaderir.Expr) int {
+	var t shaderir.Type
+	switch e.Type {
+	case shaderir.LocalVariable:
+		t = p.LocalVariableType(topBlock, block, e.Index)
+	case shaderir.UniformVariable:
+		t = p.Uniforms[e.Index]
+	case shaderir.FieldSelector:
+		if e.Exprs[1].Type == shaderir.SwizzlingExpr && len(e.Exprs[1].Swizzling) > 1 {
+			return len(e.Exprs[1].Swizzling)
+		}
+		return 0
+	case shaderir.Index:
+		// An element of an array variable.
+		var at shaderir.Type
+		switch base := &e.Exprs[0]; base.Type {
+		case shaderir.LocalVariable:
+			at = p.LocalVariableType(topBlock, block, base.Index)
+		case shaderir.UniformVariable:
+			at = p.Uniforms[base.Index]
+		}
+		if at.Main == shaderir.Array {
+			t = at.Sub[0]
+		}
+	case shaderir.Unary:
+		return intVectorLength(p, topBlock, block, &e.Exprs[0])
+	case shaderir.Binary:
+		// One of the operands might be a scalar.
+		if n := intVectorLength(p, topBlock, block, &e.Exprs[0]); n > 0 {
+			return n
+		}
+		return intVectorLength(p, topBlock, block, &e.Exprs[1])
+	case shaderir.Selection:
+		return intVectorLength(p, topBlock, block, &e.Exprs[1])
+	case shaderir.Call:
+		callee := &e.Exprs[0]
+		switch {
+		case callee.Type == shaderir.FunctionExpr:
+			if f := findFunc(p, callee.Index); f != nil {
+				t = f.Return
+			}
+		case callee.BuiltinFunc == shaderir.IVec2F:
+			return 2
+		case callee.BuiltinFunc == shaderir.IVec3F:
+			return 3
+		case callee.BuiltinFunc == shaderir.IVec4F:
+			return 4
+		case callee.BuiltinFunc == shaderir.IntF:
+			return 0
+		default:
+			// Other built-in functions like abs, min, and max return the type of the arguments.
+			for i := 1; i < len(e.Exprs); i++ {
+				if n := intVectorLength(p, topBlock, block, &e.Exprs[i]); n > 0 {
+					return n
+				}
+			}
+			return 0
+		}
+	}
+	if t.IsIntVector() {
+		return t.VectorElementCount()
+	}
+	return 0
+}
+
+// swizzling returns the swizzling s with the components xyzw, as the components stpq are not available in WGSL.
+func swizzling(s string) string {
+	if strings.IndexByte("stpq", s[0]) < 0 {
+		return s
+	}
+	return strings.NewReplacer("s", "x", "t", "y", "p", "z", "q", "w").Replace(s)
+}
+
+func (c *compileContext) block(p *shaderir.Program, topBlock, block *shaderir.Block, level int) []string {
+	if block == nil {
+		return nil
+	}
+
+	idt := strings.Repeat("\t", level+1)
+
+	var lines []string
+	for i, t := range block.LocalVars {
+		// The type is None e.g., when the variable is a for-loop counter.
+		if t.Main != shaderir.None {
+			// A variable without an initializer is initialized with the zero value.
+			lines = append(lines, fmt.Sprintf("%svar %s: %s;", idt, localVariableName(p, topBlock, block.LocalVarIndexOffset+i), c.typ(p, &t)))
+		}
+	}
+
+	var expr func(e *shaderir.Expr) string
+	expr = func(e *shaderir.Expr) string {
+		switch e.Type {
+		case shaderir.NumberExpr:
+			return constantToNumberLiteral(e.Const)
+		case shaderir.UniformVariable:
+			return fmt.Sprintf("%s.U%d", uniforms, e.Index)
+		case shaderir.TextureVariable:
+			return fmt.Sprintf("T%d", e.Index)
+		case shaderir.LocalVariable:
+			return localVariableName(p, topBlock, e.Index)
+		case shaderir.StructMember:
+			return fmt.Sprintf("M%d", e.Index)
+		case shaderir.BuiltinFuncExpr:
+			return builtinFuncString(e.BuiltinFunc)
+		case shaderir.SwizzlingExpr:
+			if !shaderir.IsValidSwizzling(e.Swizzling) {
+				return fmt.Sprintf("?(unexpected swizzling: %s)", e.Swizzling)
+			}
+			return swizzling(e.Swizzling)
+		case shaderir.FunctionExpr:
+			return fmt.Sprintf("F%d", e.Index)
+		case shaderir.Unary:
+			switch e.Op {
+			case shaderir.Add:
+				// There is no unary plus operator in WGSL.
+				return fmt.Sprintf("(%s)", expr(&e.Exprs[0]))
+			case shaderir.Sub, shaderir.NotOp:
+				return fmt.Sprintf("%s(%s)", opString(e.Op), expr(&e.Exprs[0]))
+			default:
+				return fmt.Sprintf("?(unexpected op: %d)(%s)", e.Op, expr(&e.Exprs[0]))
+			}
+		case shaderir.Binary:
+			switch e.Op {
+			case shaderir.VectorEqualOp:
+				return fmt.Sprintf("all((%s) == (%s))", expr(&e.Exprs[0]), expr(&e.Exprs[1]))
+			case shaderir.VectorNotEqualOp:
+				return fmt.Sprintf("!all((%s) == (%s))", expr(&e.Exprs[0]), expr(&e.Exprs[1]))
+			case shaderir.LeftShift, shaderir.RightShift:
+				// The right-hand side of a shift must be unsigned with the same number of components as the left-hand side in WGSL.
+				rhs := fmt.Sprintf("u32(%s)", expr(&e.Exprs[1]))
+				if n := intVectorLength(p, topBlock, block, &e.Exprs[0]); n > 0 {
+					rhs = fmt.Sprintf("vec%d<u32>(%s)", n, expr(&e.Exprs[1]))
+				}
+				return fmt.Sprintf("(%s) %s %s", expr(&e.Exprs[0]), opString(e.Op), rhs)
+			}
+			return fmt.Sprintf("(%s) %s (%s)", expr(&e.Exprs[0]), opString(e.Op), expr(&e.Exprs[1]))
+		case shaderir.Selection:
+			return fmt.Sprintf("select(%s, %s, %s)", expr(&e.Exprs[2]), expr(&e.Exprs[1]), expr(&e.Exprs[0]))
+		case shaderir.Call:
+			callee := e.Exprs[0]
+			var args []string
+			for _, exp := range e.Exprs[1:] {
+				args = append(args, expr(&exp))
+			}
+			if callee.Type == shaderir.FunctionExpr {
+				// Output arguments are passed as pointers.
+				if f := findFunc(p, callee.Index); f != nil {
+					for i := len(f.InParams); i < len(args); i++ {
+						args[i] = "&" + args[i]
+					}
+				}
+			}
+			if callee.Type == shaderir.BuiltinFuncExpr {
+				switch callee.BuiltinFunc {
+				case shaderir.Mat2F:
+					if len(args) == 1 {
+						return fmt.Sprintf("mat2x2FromScalar(%s)", args[0])
+					}
+				case shaderir.Mat3F:
+					if len(args) == 1 {
+						return fmt.Sprintf("mat3x3FromScalar(%s)", args[0])
+					}
+				case shaderir.Mat4F:
+					if len(args) == 1 {
+						return fmt.Sprintf("mat4x4FromScalar(%s)", args[0])
+					}
+				case shaderir.Mod:
+					// The % operator for floats truncates toward zero in WGSL, unlike mod in GLSL.
+					return fmt.Sprintf("((%[1]s) - (%[2]s) * floor((%[1]s) / (%[2]s)))", args[0], args[1])
+				// The comparison operators work component-wise and return boolean vectors in WGSL.
+				case shaderir.LessThan:
+					return fmt.Sprintf("(%s) < (%s)", args[0], args[1])
+				case shaderir.LessThanEqual:
+					return fmt.Sprintf("(%s) <= (%s)", args[0], args[1])
+				case shaderir.GreaterThan:
+					return fmt.Sprintf("(%s) > (%s)", args[0], args[1])
+				case shaderir.GreaterThanEqual:
+					return fmt.Sprintf("(%s) >= (%s)", args[0], args[1])
+				case shaderir.Equal:
+					return fmt.Sprintf("(%s) == (%s)", args[0], args[1])
+				case shaderir.NotEqual:
+					return fmt.Sprintf("(%s) != (%s)", args[0], args[1])
+				case shaderir.Not:
+					return fmt.Sprintf("!(%s)", args[0])
+				// textureSampleLevel is used instead of textureSample, which is available only in uniform control flow in fragment shaders.
+				case shaderir.TexelAt:
+					switch p.Unit {
+					case shaderir.Texels:
+						return fmt.Sprintf("textureSampleLevel(%s, texture_sampler, %s, 0.0)", args[0], args[1])
+					case shaderir.Pixels:
+						return fmt.Sprintf("textureLoad(%s, vec2<i32>(%s), 0)", args[0], args[1])
+					default:
+						panic(fmt.Sprintf("wgsl: unexpected unit: %d", p.Unit))
+					}
+				case shaderir.TextureLod:
+					switch p.Unit {
+					case shaderir.Texels:
+						return fmt.Sprintf("textureSampleLevel(%s, texture_sampler, %s, %s)", args[0], args[1], args[2])
+					case shaderir.Pixels:
+						return fmt.Sprintf("textureLoad(%s, vec2<i32>(%s), i32(%s))", args[0], args[1], args[2])
+					default:
+						panic(fmt.Sprintf("wgsl: unexpected unit: %d", p.Unit))
+					}
+				}
+			}
+			return fmt.Sprintf("%s(%s)", expr(&callee), strings.Join(args, ", "))
+		case shaderir.FieldSelector:
+			return fmt.Sprintf("(%s).%s", expr(&e.Exprs[0]), expr(&e.Exprs[1]))
+		case shaderir.Index:
+			return fmt.Sprintf("(%s)[%s]", expr(&e.Exprs[0]), expr(&e.Exprs[1]))
+		default:
+			return fmt.Sprintf("?(unexpected expr: %d)", e.Type)
+		}
+	}
+
+	for _, s := range block.Stmts {
+		switch s.Type {
+		case shaderir.ExprStmt:
+			lines = append(lines, fmt.Sprintf("%s%s;", idt, expr(&s.Exprs[0])))
+		case shaderir.BlockStmt:
+			lines = append(lines, idt+"{")
+			lines = append(lines, c.block(p, topBlock, s.Blocks[0], level+1)...)
+			lines = append(lines, idt+"}")
+		case shaderir.Assign:
+			lhs := s.Exprs[0]
+			if lhs.Type == shaderir.FieldSelector && lhs.Exprs[1].Type == shaderir.SwizzlingExpr && len(lhs.Exprs[1].Swizzling) > 1 {
+				// Assigning to a swizzling with multiple components is not available in WGSL.
+				// Assign the components one by one.
+				lines = append(lines, idt+"{")
+				lines = append(lines, fmt.Sprintf("%s\tlet swizzled = %s;", idt, expr(&s.Exprs[1])))
+				v := expr(&lhs.Exprs[0])
+				for i, comp := range swizzling(lhs.Exprs[1].Swizzling) {
+					lines = append(lines, fmt.Sprintf("%s\t(%s).%c = swizzled.%c;", idt, v, comp, "xyzw"[i]))
+				}
+				lines = append(lines, idt+"}")
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("%s%s = %s;", idt, expr(&lhs), expr(&s.Exprs[1])))
+		case shaderir.Init:
+			if topBlock == p.VertexFunc.Block {
+				// In the vertex function, varying values are the output parameters.
+				// These values are represented as a struct and not needed to be initialized.
+				na := len(p.Attributes)
+				nv := len(p.Varyings)
+				if s.InitIndex < na+nv+1 {
+					continue
+				}
+			}
+			t := p.LocalVariableType(topBlock, block, s.InitIndex)
+			lines = append(lines, fmt.Sprintf("%s%s = %s();", idt, localVariableName(p, topBlock, s.InitIndex), c.typ(p, &t)))
+		case shaderir.If:
+			lines = append(lines, fmt.Sprintf("%sif (%s) {", idt, expr(&s.Exprs[0])))
+			lines = append(lines, c.block(p, topBlock, s.Blocks[0], level+1)...)
+			if len(s.Blocks) > 1 {
+				lines = append(lines, fmt.Sprintf("%s} else {", idt))
+				lines = append(lines, c.block(p, topBlock, s.Blocks[1], level+1)...)
+			}
+			lines = append(lines, fmt.Sprintf("%s}", idt))
+		case shaderir.For:
+			v := localVariableName(p, topBlock, s.ForVarIndex)
+			t := s.ForVarType
+			var delta string
+			switch val, _ := constant.Float64Val(s.ForDelta); {
+			case val == 0:
+				delta = fmt.Sprintf("?(unexpected delta: %v)", s.ForDelta)
+			// The increment and decrement statements are available only for integers.
+			case val == 1 && t.Main == shaderir.Int:
+				delta = fmt.Sprintf("%s++", v)
+			case val == -1 && t.Main == shaderir.Int:
+				delta = fmt.Sprintf("%s--", v)
+			case val > 0:
+				delta = fmt.Sprintf("%s += %s", v, constantToNumberLiteral(s.ForDelta))
+			default:
+				d := constant.UnaryOp(token.SUB, s.ForDelta, 0)
+				delta = fmt.Sprintf("%s -= %s", v, constantToNumberLiteral(d))
+			}
+			var op string
+			switch s.ForOp {
+			case shaderir.LessThanOp, shaderir.LessThanEqualOp, shaderir.GreaterThanOp, shaderir.GreaterThanEqualOp, shaderir.EqualOp, shaderir.NotEqualOp:
+				op = opString(s.ForOp)
+			default:
+				op = fmt.Sprintf("?(unexpected op: %d)", s.ForOp)
+			}
+
+			init := constantToNumberLiteral(s.ForInit)
+			end := constantToNumberLiteral(s.ForEnd)
+			lines = append(lines, fmt.Sprintf("%sfor (var %s: %s = %s; %s %s %s; %s) {", idt, v, c.typ(p, &t), init, v, op, end, delta))
+			lines = append(lines, c.block(p, topBlock, s.Blocks[0], level+1)...)
+			lines = append(lines, fmt.Sprintf("%s}", idt))
+		case shaderir.While:
+			lines = append(lines, fmt.Sprintf("%swhile (%s) {", idt, expr(&s.Exprs[0])))
+			lines = append(lines, c.block(p, topBlock, s.Blocks[0], level+1)...)
+			lines = append(lines, fmt.Sprintf("%s}", idt))
+		case shaderir.Continue:
+			lines = append(lines, idt+"continue;")
+		case shaderir.Break:
+			lines = append(lines, idt+"break;")
+		case shaderir.Return:
+			switch {
+			case topBlock == p.VertexFunc.Block:
+				lines = append(lines, fmt.Sprintf("%sreturn %s;", idt, vertexOut))
+			case len(s.Exprs) == 0:
+				lines = append(lines, idt+"return;")
+			default:
+				lines = append(lines, fmt.Sprintf("%sreturn %s;", idt, expr(&s.Exprs[0])))
+			}
+		case shaderir.Discard:
+			// 'discard' is invoked only in the fragment shader entry point and the functions called from it.
+			// Returning is required only in the entry point, as a function might return a different type.
+			lines = append(lines, idt+"discard;")
+			if topBlock == p.FragmentFunc.Block {
+				lines = append(lines, idt+"return vec4<f32>(0.0);")
+			}
+		default:
+			lines = append(lines, fmt.Sprintf("%s?(unexpected stmt: %d)", idt, s.Type))
+		}
+	}
+
+	return lines
+}
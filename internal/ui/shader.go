@@ -20,6 +20,7 @@ import (
 	"reflect"
 
 	"github.com/hajimehoshi/ebiten/v2/internal/atlas"
+	"github.com/hajimehoshi/ebiten/v2/internal/clock"
 	"github.com/hajimehoshi/ebiten/v2/internal/graphics"
 	"github.com/hajimehoshi/ebiten/v2/internal/shaderir"
 )
@@ -30,6 +31,7 @@ type Shader struct {
 	uniformNames       []string
 	uniformTypes       []shaderir.Type
 	uniformUint32Count int
+	implicitTime       bool
 }
 
 func NewShader(ir *shaderir.Program) *Shader {
@@ -37,6 +39,7 @@ func NewShader(ir *shaderir.Program) *Shader {
 		shader:       atlas.NewShader(ir),
 		uniformNames: ir.UniformNames[graphics.PreservedUniformVariablesCount:],
 		uniformTypes: ir.Uniforms[graphics.PreservedUniformVariablesCount:],
+		implicitTime: ir.ImplicitTime,
 	}
 }
 
@@ -65,6 +68,15 @@ func (s *Shader) AppendUniforms(dst []uint32, uniforms map[string]any) []uint32
 	for i, name := range s.uniformNames {
 		typ := s.uniformTypes[i]
 
+		// The implicit time uniform variable is populated with the current frame's time unless specified.
+		if s.implicitTime && name == shaderir.TimeUniformName {
+			if _, ok := uniforms[name]; !ok {
+				dst[idx] = math.Float32bits(float32(clock.FrameTime().Seconds()))
+				idx += typ.Uint32Count()
+				continue
+			}
+		}
+
 		// Ignore if an unused name is specified (#2710).
 		if uv, ok := uniforms[name]; ok {
 			v := reflect.ValueOf(uv)
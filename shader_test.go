@@ -2394,3 +2394,101 @@ func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
 		}
 	}
 }
+
+func TestShaderBilinearSample(t *testing.T) {
+	const w, h = 16, 16
+
+	// Make a horizontal ramp.
+	src := ebiten.NewImage(w, h)
+	pix := make([]byte, 4*w*h)
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			idx := 4 * (i + j*w)
+			pix[idx] = byte(i * 0x10)
+			pix[idx+3] = 0xff
+		}
+	}
+	src.WritePixels(pix)
+
+	s, err := ebiten.NewShader([]byte(`//kage:unit pixels
+
+package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	origin := imageSrc0Origin()
+	return bilinearSample(imageSrc0UnsafeAt, origin+(srcPos-origin)/2)
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst := ebiten.NewImage(w, h)
+	op := &ebiten.DrawRectShaderOptions{}
+	op.Images[0] = src
+	dst.DrawRectShader(w, h, s, op)
+
+	// Scale the source image by the hardware bilinear filter.
+	ref := ebiten.NewImage(w, h)
+	op2 := &ebiten.DrawImageOptions{}
+	op2.GeoM.Scale(2, 2)
+	op2.Filter = ebiten.FilterLinear
+	ref.DrawImage(src, op2)
+
+	// The pixels at the left and the top edges refer to the outside of the source image.
+	for j := 1; j < h; j++ {
+		for i := 1; i < w; i++ {
+			got := dst.At(i, j).(color.RGBA)
+			want := ref.At(i, j).(color.RGBA)
+			if !sameColors(got, want, 2) {
+				t.Errorf("dst.At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestShaderAAStep(t *testing.T) {
+	const w, h = 16, 16
+
+	s, err := ebiten.NewShader([]byte(`//kage:unit pixels
+
+package main
+
+var Scale float
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	x := (dstPos.x - imageDstOrigin().x) * Scale
+	v := aastep(8*Scale, x)
+	return vec4(v, v, v, 1)
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Whatever the gradient of the value is, the transition is within about one pixel.
+	for _, scale := range []float32{0.25, 1, 4} {
+		dst := ebiten.NewImage(w, h)
+		op := &ebiten.DrawRectShaderOptions{}
+		op.Uniforms = map[string]any{
+			"Scale": scale,
+		}
+		dst.DrawRectShader(w, h, s, op)
+
+		for j := 0; j < h; j++ {
+			for i := 0; i < w; i++ {
+				if i == 7 || i == 8 {
+					continue
+				}
+				got := dst.At(i, j).(color.RGBA)
+				want := color.RGBA{A: 0xff}
+				if i > 8 {
+					want = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+				}
+				if !sameColors(got, want, 2) {
+					t.Errorf("scale: %f, dst.At(%d, %d): got: %v, want: %v", scale, i, j, got, want)
+				}
+			}
+		}
+	}
+}